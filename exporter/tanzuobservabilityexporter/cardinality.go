@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tanzuobservabilityexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/tanzuobservabilityexporter"
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// overflowTagValue replaces the values of a tag once the tag has more distinct values than allowed
+const overflowTagValue = "__overflow__"
+
+// tagLimiter caps the number of distinct values of every tag key of the points sent to Tanzu
+// Observability. The first maxValues distinct values of a key are kept, any further value is
+// replaced with overflowTagValue. It is shared by all workers so that the cap applies to the exporter.
+type tagLimiter struct {
+	maxValues int
+	logger    *zap.Logger
+	metrics   *opencensusMetrics
+
+	mu     sync.Mutex
+	values map[string]map[string]struct{}
+}
+
+func newTagLimiter(maxValues int, logger *zap.Logger, metrics *opencensusMetrics) *tagLimiter {
+	return &tagLimiter{
+		maxValues: maxValues,
+		logger:    logger,
+		metrics:   metrics,
+		values:    map[string]map[string]struct{}{},
+	}
+}
+
+// limit replaces the values of the given tags that exceed the cardinality of their key in place.
+// A nil tagLimiter leaves the tags as they are.
+func (l *tagLimiter) limit(tags map[string]string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key, value := range tags {
+		values, ok := l.values[key]
+		if !ok {
+			values = map[string]struct{}{}
+			l.values[key] = values
+		}
+		if _, ok = values[value]; ok {
+			continue
+		}
+		if len(values) < l.maxValues {
+			values[value] = struct{}{}
+			continue
+		}
+		if _, overflowed := values[overflowTagValue]; !overflowed {
+			// the sentinel does not count towards the cap, it marks that the key has overflowed
+			values[overflowTagValue] = struct{}{}
+			l.logger.Warn("Tag exceeded the maximum number of distinct values, further values are replaced",
+				zap.String("tag", key), zap.Int("max_tag_cardinality", l.maxValues), zap.String("replacement", overflowTagValue))
+		}
+		tags[key] = overflowTagValue
+		l.metrics.recordTagOverflow()
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tanzuobservabilityexporter
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+func TestTagLimiter(t *testing.T) {
+	metrics, err := newOpenCensusMetrics(t.Name(), signalMetrics)
+	require.NoError(t, err)
+	limiter := newTagLimiter(3, zap.NewNop(), metrics)
+
+	var pods []string
+	for i := 0; i < 10; i++ {
+		tags := map[string]string{"pod": fmt.Sprintf("pod-%d", i), "env": "prod"}
+		limiter.limit(tags)
+		assert.Equal(t, "prod", tags["env"])
+		pods = append(pods, tags["pod"])
+	}
+	assert.Equal(t, []string{
+		"pod-0", "pod-1", "pod-2",
+		overflowTagValue, overflowTagValue, overflowTagValue, overflowTagValue,
+		overflowTagValue, overflowTagValue, overflowTagValue,
+	}, pods)
+	assert.Equal(t, float64(7), tagOverflowsValue(t, t.Name()))
+
+	// values seen before the cap was reached are still kept
+	tags := map[string]string{"pod": "pod-1"}
+	limiter.limit(tags)
+	assert.Equal(t, "pod-1", tags["pod"])
+}
+
+func TestNilTagLimiter(t *testing.T) {
+	var limiter *tagLimiter
+	tags := map[string]string{"pod": "pod-0"}
+	limiter.limit(tags)
+	assert.Equal(t, map[string]string{"pod": "pod-0"}, tags)
+}
+
+func TestMetricsExporterLimitsTagCardinality(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ExporterSettings = config.NewExporterSettings(component.NewIDWithName(exporterType, t.Name()))
+	cfg.Metrics.Endpoint = "http://localhost:2878"
+	cfg.Metrics.AppTagsExcluded = true
+	cfg.Metrics.MaxTagCardinality = 2
+	cfg.Metrics.NumWorkers = 2
+	sender := &mockGaugeSender{}
+	creator := func(metricsConfig MetricsConfig, settings component.TelemetrySettings, otelVersion string) (*metricsConsumer, error) {
+		return newMetricsConsumer([]typedMetricConsumer{newGaugeConsumer(sender, settings)}, &mockFlushCloser{}, false, metricsConfig), nil
+	}
+	exp, err := newMetricsExporter(componenttest.NewNopExporterCreateSettings(), cfg, creator)
+	require.NoError(t, err)
+
+	// the cap is shared by the workers, so it applies across the metrics they send
+	for i := 0; i < 4; i++ {
+		gauge := newMetric("gauge", pmetric.MetricTypeGauge)
+		addDataPoint(1, 1640123456, map[string]interface{}{"pod": fmt.Sprintf("pod-%d", i)}, gauge.Gauge().DataPoints())
+		require.NoError(t, exp.pushMetricsData(context.Background(), constructMetrics(gauge)))
+	}
+	require.NoError(t, exp.shutdown(context.Background()))
+
+	require.Len(t, sender.metrics, 4)
+	var pods []string
+	for _, metric := range sender.metrics {
+		pods = append(pods, metric.Tags["pod"])
+	}
+	assert.Equal(t, []string{"pod-0", "pod-1", overflowTagValue, overflowTagValue}, pods)
+	assert.Equal(t, float64(2), tagOverflowsValue(t, t.Name()))
+}
+
+func tagOverflowsValue(t *testing.T, instanceName string) float64 {
+	data := retrieveInstanceData(t, tagOverflowsView.Name, instanceName)
+	require.NotNil(t, data)
+	return data.(*view.SumData).Value
+}
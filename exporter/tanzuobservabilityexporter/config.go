@@ -18,15 +18,43 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"regexp"
 	"strconv"
+	"time"
 
+	"github.com/google/uuid"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 )
 
+// metricTypes maps the names accepted by metrics.enabled_types to the OTEL metric types they enable.
+var metricTypes = map[string][]pmetric.MetricType{
+	"gauge":     {pmetric.MetricTypeGauge},
+	"sum":       {pmetric.MetricTypeSum},
+	"histogram": {pmetric.MetricTypeHistogram, pmetric.MetricTypeExponentialHistogram},
+	"summary":   {pmetric.MetricTypeSummary},
+}
+
+// timestampGranularities maps the names accepted by metrics.timestamp_granularity to the granularity the timestamps are rounded to.
+var timestampGranularities = map[string]time.Duration{
+	"second": time.Second,
+	"minute": time.Minute,
+}
+
 type TracesConfig struct {
 	confighttp.HTTPClientSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
+	// MaxSpansPerSecond is the maximum rate at which spans are sent to the proxy, allowing bursts of up to
+	// one second worth of spans. Spans exceeding the rate are dropped. Unlimited if 0.
+	MaxSpansPerSecond int `mapstructure:"max_spans_per_second"`
+	// TagAllowlist is the list of span attributes that become tags of the spans, any other span attribute
+	// is dropped. All span attributes become tags if empty.
+	TagAllowlist []string `mapstructure:"tag_allowlist"`
+	// MaxTagsSize is the maximum total size in bytes of the keys and values of the tags of a span, the tags
+	// exceeding it are moved into a span log of the span. Unlimited if 0.
+	MaxTagsSize int `mapstructure:"max_tags_size"`
 }
 
 type MetricsConfig struct {
@@ -35,6 +63,151 @@ type MetricsConfig struct {
 	// AppTagsExcluded will exclude the Resource Attributes `application`, `service.name` -> (service),
 	// `cluster`, and `shard` from the transformed TObs metric if set to true.
 	AppTagsExcluded bool `mapstructure:"app_tags_excluded"`
+	// EnabledTypes is the list of metric types that are sent to TObs, one of gauge, sum,
+	// histogram, and summary. Metrics of any other type are dropped. All types are sent if empty.
+	EnabledTypes []string `mapstructure:"enabled_types"`
+	// DropNamePatterns is the list of regular expressions of the names of the metrics that are dropped,
+	// such as the internal metrics of an instrumentation library. No metric is dropped by name if empty.
+	DropNamePatterns []string `mapstructure:"drop_name_patterns"`
+	// DistributionPort is the port of the proxy that Wavefront distributions, which delta
+	// histograms are sent as, are sent to. Defaults to the port of the endpoint.
+	DistributionPort int `mapstructure:"distribution_port"`
+	// DistributionInterval is the interval at which distributions are flushed to the proxy, it
+	// should match the aggregation window the proxy expects. Distributions are flushed together
+	// with all other metrics if not set.
+	DistributionInterval time.Duration `mapstructure:"distribution_interval"`
+	// IncludeUnitTag will add the unit of the metric as a point tag, keyed by UnitTagKey, to the
+	// transformed TObs metric if set to true. Metrics without a unit get no unit tag.
+	IncludeUnitTag bool `mapstructure:"include_unit_tag"`
+	// UnitTagKey is the key of the point tag the unit of the metric is added as. Defaults to `unit`.
+	UnitTagKey string `mapstructure:"unit_tag_key"`
+	// SourceFallbacks is the ordered list of resource attributes the source of a metric is taken from
+	// when the resource has no `source` attribute. Defaults to `host.name`, `hostname` and `host.id`.
+	SourceFallbacks []string `mapstructure:"source_fallbacks"`
+	// SourceDefault is the source of metrics whose resource has neither the `source` attribute nor any
+	// of the SourceFallbacks. The hostname of the exporter is used if empty.
+	SourceDefault string `mapstructure:"source_default"`
+	// NumWorkers is the number of workers sending metrics, each with senders and connections of its own,
+	// so that up to NumWorkers batches of metrics are sent concurrently. Defaults to a single worker.
+	NumWorkers int `mapstructure:"num_workers"`
+	// MaxTagCardinality is the maximum number of distinct values of each point tag, further values of
+	// a tag are replaced with `__overflow__`. Unlimited if 0.
+	MaxTagCardinality int `mapstructure:"max_tag_cardinality"`
+	// MaxHistogramBuckets is the maximum number of centroids of the distributions that delta histograms
+	// are sent as, adjacent buckets are merged into one centroid to stay below it. Unlimited if 0.
+	MaxHistogramBuckets int `mapstructure:"max_histogram_buckets"`
+	// Dedup drops the points that are identical, in name, value, timestamp, source and tags, to a point
+	// sent since the last flush, so that duplicate points of a batch are only sent once.
+	Dedup bool `mapstructure:"dedup"`
+	// InternalMetrics defines the periodic sending of the internal metrics of the exporter to TObs.
+	InternalMetrics InternalMetricsConfig `mapstructure:"internal_metrics"`
+	// TagMappings is the list of OTLP attributes that become point tags under another key. The resource
+	// attributes of the mappings are sent whether or not ResourceAttrsIncluded is set, any other attribute
+	// becomes a tag, or not, as usual.
+	TagMappings []TagMapping `mapstructure:"tag_mappings"`
+	// TimestampGranularity rounds the timestamps of the points to the nearest second or minute, so that points
+	// recorded at sub-second offsets are aligned. The timestamps are truncated to the second if empty.
+	TimestampGranularity string `mapstructure:"timestamp_granularity"`
+	// KeepAlive is the interval between the TCP keep-alive probes of the connections the points are sent over.
+	// Defaults to 30 seconds, keep-alive probes are disabled if negative.
+	KeepAlive *time.Duration `mapstructure:"keep_alive"`
+	// DisableKeepAlives closes the connection the points are sent over after every request instead of
+	// keeping it open to be reused by the next request.
+	DisableKeepAlives bool `mapstructure:"disable_keep_alives"`
+	// DialTimeout is the maximum time to establish a connection to the proxy the points are sent to, separate
+	// from the timeout of the whole request. Defaults to 30 seconds.
+	DialTimeout time.Duration `mapstructure:"dial_timeout"`
+	// TLSHandshakeTimeout is the maximum time to wait for the TLS handshake with the proxy the points are
+	// sent to. Defaults to 10 seconds.
+	TLSHandshakeTimeout time.Duration `mapstructure:"tls_handshake_timeout"`
+}
+
+// TagMapping defines the point tag an OTLP point or resource attribute is sent as.
+type TagMapping struct {
+	// Source is the key of the OTLP attribute.
+	Source string `mapstructure:"source"`
+	// Target is the key of the point tag the attribute is sent as.
+	Target string `mapstructure:"target"`
+}
+
+// InternalMetricsConfig defines the sending of the internal metrics of the exporter, such as the dropped points
+// and the latency of the requests, as Wavefront metrics with the metrics sender of the exporter.
+type InternalMetricsConfig struct {
+	// Enabled sends the internal metrics of the exporter every Interval if set to true.
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is the interval at which the internal metrics are sent. Defaults to 1 minute.
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// LogsConfig defines the configuration of the logs exporter, which sends logs to the
+// log ingestion of the proxy.
+type LogsConfig struct {
+	confighttp.HTTPClientSettings `mapstructure:",squash"`
+}
+
+// CollectorInstanceConfig defines the tag identifying the collector that sent a point or span.
+type CollectorInstanceConfig struct {
+	// Enabled adds the `otel.collector.instance` tag to the points and spans sent to TObs if set to true.
+	Enabled bool `mapstructure:"enabled"`
+	// ID is the value of the tag. A UUID generated when the collector starts is used if empty.
+	ID string `mapstructure:"id"`
+}
+
+// ServiceHierarchyConfig defines the derivation of the `application` and `service` tags from the service
+// resource attributes, such as the OTLP `service.namespace` and `service.name`.
+type ServiceHierarchyConfig struct {
+	// Enabled derives the `application` and `service` tags of points and spans whose resource has no
+	// `application` or `service` attribute from ApplicationKey and ServiceKey if set to true.
+	Enabled bool `mapstructure:"enabled"`
+	// ApplicationKey is the resource attribute the `application` tag is derived from. Defaults to `service.namespace`.
+	ApplicationKey string `mapstructure:"application_key"`
+	// ServiceKey is the resource attribute the `service` tag is derived from. Defaults to `service.name`.
+	ServiceKey string `mapstructure:"service_key"`
+}
+
+// generatedCollectorInstanceID identifies the collector if no collector_instance.id is configured,
+// it is shared by all exporters of the collector.
+var generatedCollectorInstanceID = uuid.NewString()
+
+// tagValue returns the value of the `otel.collector.instance` tag, empty if the tag is not enabled.
+func (c CollectorInstanceConfig) tagValue() string {
+	if !c.Enabled {
+		return ""
+	}
+	if c.ID != "" {
+		return c.ID
+	}
+	return generatedCollectorInstanceID
+}
+
+// hasDistributionSender returns true if distributions are sent by a sender of their own.
+func (c MetricsConfig) hasDistributionSender() bool {
+	return c.DistributionPort != 0 || c.DistributionInterval != 0
+}
+
+// sourceAndKey returns the source of the metrics of a resource with the given attributes and the key
+// of the attribute it was taken from, the key is empty if the SourceDefault is used.
+func (c MetricsConfig) sourceAndKey(attributes pcommon.Map) (string, string) {
+	fallbacks := c.SourceFallbacks
+	if len(fallbacks) == 0 {
+		fallbacks = defaultSourceFallbacks
+	}
+	return getSourceAndKeyWithFallbacks(attributes, fallbacks, c.SourceDefault)
+}
+
+// metricTypeEnabled returns true if metrics of the given type should be sent to TObs.
+func (c MetricsConfig) metricTypeEnabled(metricType pmetric.MetricType) bool {
+	if len(c.EnabledTypes) == 0 {
+		return true
+	}
+	for _, name := range c.EnabledTypes {
+		for _, enabled := range metricTypes[name] {
+			if enabled == metricType {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // Config defines configuration options for the exporter.
@@ -46,6 +219,13 @@ type Config struct {
 	// Traces defines the Traces exporter specific configuration
 	Traces  TracesConfig  `mapstructure:"traces"`
 	Metrics MetricsConfig `mapstructure:"metrics"`
+	Logs    LogsConfig    `mapstructure:"logs"`
+
+	// CollectorInstance defines the tag identifying the collector that sent a point or span
+	CollectorInstance CollectorInstanceConfig `mapstructure:"collector_instance"`
+
+	// ServiceHierarchy defines the derivation of the application tags from the service resource attributes
+	ServiceHierarchy ServiceHierarchyConfig `mapstructure:"service_hierarchy"`
 }
 
 func (c *Config) hasMetricsEndpoint() bool {
@@ -56,6 +236,10 @@ func (c *Config) hasTracesEndpoint() bool {
 	return c.Traces.Endpoint != ""
 }
 
+func (c *Config) hasLogsEndpoint() bool {
+	return c.Logs.Endpoint != ""
+}
+
 func (c *Config) parseMetricsEndpoint() (hostName string, port int, err error) {
 	return parseEndpoint(c.Metrics.Endpoint)
 }
@@ -64,6 +248,10 @@ func (c *Config) parseTracesEndpoint() (hostName string, port int, err error) {
 	return parseEndpoint(c.Traces.Endpoint)
 }
 
+func (c *Config) parseLogsEndpoint() (hostName string, port int, err error) {
+	return parseEndpoint(c.Logs.Endpoint)
+}
+
 func (c *Config) Validate() error {
 	var tracesHostName, metricsHostName string
 	var err error
@@ -79,12 +267,96 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("failed to parse metrics.endpoint: %w", err)
 		}
 	}
+	if c.hasLogsEndpoint() {
+		if _, _, err = c.parseLogsEndpoint(); err != nil {
+			return fmt.Errorf("failed to parse logs.endpoint: %w", err)
+		}
+	}
 	if c.hasTracesEndpoint() && c.hasMetricsEndpoint() && tracesHostName != metricsHostName {
 		return errors.New("host for metrics and traces must be the same")
 	}
+	if c.Traces.MaxSpansPerSecond < 0 {
+		return fmt.Errorf("traces.max_spans_per_second must not be negative: %d", c.Traces.MaxSpansPerSecond)
+	}
+	if c.Traces.MaxTagsSize < 0 {
+		return fmt.Errorf("traces.max_tags_size must not be negative: %d", c.Traces.MaxTagsSize)
+	}
+	for _, name := range c.Metrics.EnabledTypes {
+		if _, ok := metricTypes[name]; !ok {
+			return fmt.Errorf("metrics.enabled_types contains invalid value: %q", name)
+		}
+	}
+	for _, pattern := range c.Metrics.DropNamePatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("metrics.drop_name_patterns contains invalid pattern %q: %w", pattern, err)
+		}
+	}
+	if c.Metrics.DistributionPort < 0 || c.Metrics.DistributionPort > 65535 {
+		return fmt.Errorf("metrics.distribution_port must be between 0 and 65535: %d", c.Metrics.DistributionPort)
+	}
+	if c.Metrics.DistributionInterval < 0 || c.Metrics.DistributionInterval%time.Second != 0 {
+		return fmt.Errorf("metrics.distribution_interval must be a non-negative whole number of seconds: %s", c.Metrics.DistributionInterval)
+	}
+	if c.Metrics.NumWorkers < 0 {
+		return fmt.Errorf("metrics.num_workers must not be negative: %d", c.Metrics.NumWorkers)
+	}
+	if c.Metrics.MaxTagCardinality < 0 {
+		return fmt.Errorf("metrics.max_tag_cardinality must not be negative: %d", c.Metrics.MaxTagCardinality)
+	}
+	if c.Metrics.MaxHistogramBuckets < 0 {
+		return fmt.Errorf("metrics.max_histogram_buckets must not be negative: %d", c.Metrics.MaxHistogramBuckets)
+	}
+	if c.Metrics.IncludeUnitTag && c.Metrics.UnitTagKey == "" {
+		return errors.New("metrics.unit_tag_key must not be empty when metrics.include_unit_tag is enabled")
+	}
+	if c.Metrics.InternalMetrics.Enabled && c.Metrics.InternalMetrics.Interval <= 0 {
+		return fmt.Errorf("metrics.internal_metrics.interval must be positive when metrics.internal_metrics.enabled is set: %s", c.Metrics.InternalMetrics.Interval)
+	}
+	if c.Metrics.DialTimeout < 0 {
+		return fmt.Errorf("metrics.dial_timeout must not be negative: %s", c.Metrics.DialTimeout)
+	}
+	if c.Metrics.TLSHandshakeTimeout < 0 {
+		return fmt.Errorf("metrics.tls_handshake_timeout must not be negative: %s", c.Metrics.TLSHandshakeTimeout)
+	}
+	if _, ok := timestampGranularities[c.Metrics.TimestampGranularity]; c.Metrics.TimestampGranularity != "" && !ok {
+		return fmt.Errorf("metrics.timestamp_granularity must be one of second or minute: %q", c.Metrics.TimestampGranularity)
+	}
+	sources := make(map[string]struct{}, len(c.Metrics.TagMappings))
+	for _, mapping := range c.Metrics.TagMappings {
+		if mapping.Source == "" || mapping.Target == "" {
+			return fmt.Errorf("metrics.tag_mappings must have a source and a target: %q -> %q", mapping.Source, mapping.Target)
+		}
+		if _, ok := sources[mapping.Source]; ok {
+			return fmt.Errorf("metrics.tag_mappings contains duplicate source: %q", mapping.Source)
+		}
+		sources[mapping.Source] = struct{}{}
+	}
 	return nil
 }
 
+// unsupportedHTTPClientSettings returns the timeout and connection pool settings of the traces that are
+// configured but cannot be honored. The Wavefront SDK creates and manages the http.Client used to send
+// spans, with a fixed timeout of 10 seconds and the default transport, so these settings are never applied.
+func unsupportedHTTPClientSettings(settings confighttp.HTTPClientSettings) []string {
+	var unsupported []string
+	if settings.Timeout != 0 {
+		unsupported = append(unsupported, "timeout")
+	}
+	if settings.MaxIdleConns != nil {
+		unsupported = append(unsupported, "max_idle_conns")
+	}
+	if settings.MaxIdleConnsPerHost != nil {
+		unsupported = append(unsupported, "max_idle_conns_per_host")
+	}
+	if settings.MaxConnsPerHost != nil {
+		unsupported = append(unsupported, "max_conns_per_host")
+	}
+	if settings.IdleConnTimeout != nil {
+		unsupported = append(unsupported, "idle_conn_timeout")
+	}
+	return unsupported
+}
+
 func parseEndpoint(endpoint string) (hostName string, port int, err error) {
 	if endpoint == "" {
 		return "", 0, errors.New("a non-empty endpoint is required")
@@ -19,6 +19,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
@@ -26,6 +27,7 @@ import (
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/service/servicetest"
 )
 
@@ -42,15 +44,54 @@ func TestLoadConfig(t *testing.T) {
 
 	actual, ok := cfg.Exporters[component.NewID("tanzuobservability")]
 	require.True(t, ok)
+	keepAlive := 15 * time.Second
 	expected := &Config{
 		ExporterSettings: config.NewExporterSettings(component.NewID("tanzuobservability")),
 		Traces: TracesConfig{
 			HTTPClientSettings: confighttp.HTTPClientSettings{Endpoint: "http://localhost:40001"},
+			MaxSpansPerSecond:  5000,
+			TagAllowlist:       []string{"http.method", "http.status_code"},
+			MaxTagsSize:        4096,
 		},
 		Metrics: MetricsConfig{
 			HTTPClientSettings:    confighttp.HTTPClientSettings{Endpoint: "http://localhost:2916"},
 			ResourceAttrsIncluded: true,
 			AppTagsExcluded:       true,
+			EnabledTypes:          []string{"gauge", "sum", "histogram"},
+			DistributionPort:      40000,
+			DistributionInterval:  60 * time.Second,
+			IncludeUnitTag:        true,
+			UnitTagKey:            "metric.unit",
+			SourceFallbacks:       []string{"host.name", "k8s.node.name", "host.id"},
+			SourceDefault:         "otel-collector",
+			MaxTagCardinality:     1000,
+			MaxHistogramBuckets:   100,
+			Dedup:                 true,
+			InternalMetrics: InternalMetricsConfig{
+				Enabled:  true,
+				Interval: 30 * time.Second,
+			},
+			TagMappings: []TagMapping{
+				{Source: "k8s.cluster.name", Target: "cluster"},
+			},
+			TimestampGranularity: "second",
+			DropNamePatterns:     []string{`^otel\.sdk\.`},
+			KeepAlive:            &keepAlive,
+			DisableKeepAlives:    true,
+			DialTimeout:          5 * time.Second,
+			TLSHandshakeTimeout:  5 * time.Second,
+		},
+		Logs: LogsConfig{
+			HTTPClientSettings: confighttp.HTTPClientSettings{Endpoint: "http://localhost:2878"},
+		},
+		CollectorInstance: CollectorInstanceConfig{
+			Enabled: true,
+			ID:      "collector-1",
+		},
+		ServiceHierarchy: ServiceHierarchyConfig{
+			Enabled:        true,
+			ApplicationKey: "k8s.namespace.name",
+			ServiceKey:     "service.name",
 		},
 		QueueSettings: exporterhelper.QueueSettings{
 			Enabled:      true,
@@ -86,6 +127,16 @@ func TestMetricsConfigRequiresValidEndpointUrl(t *testing.T) {
 	assert.Error(t, c.Validate())
 }
 
+func TestLogsConfigRequiresValidEndpointUrl(t *testing.T) {
+	c := &Config{
+		Logs: LogsConfig{
+			HTTPClientSettings: confighttp.HTTPClientSettings{Endpoint: "http#$%^&#$%&#"},
+		},
+	}
+
+	assert.Error(t, c.Validate())
+}
+
 func TestDifferentHostNames(t *testing.T) {
 	c := &Config{
 		Traces: TracesConfig{
@@ -98,6 +149,56 @@ func TestDifferentHostNames(t *testing.T) {
 	assert.Error(t, c.Validate())
 }
 
+func TestConfigRequiresNonNegativeMaxSpansPerSecond(t *testing.T) {
+	c := &Config{
+		Traces: TracesConfig{
+			HTTPClientSettings: confighttp.HTTPClientSettings{Endpoint: "http://localhost:40001"},
+			MaxSpansPerSecond:  -1,
+		},
+	}
+	assert.EqualError(t, c.Validate(), "traces.max_spans_per_second must not be negative: -1")
+}
+
+func TestConfigRequiresNonNegativeMaxTagsSize(t *testing.T) {
+	c := &Config{
+		Traces: TracesConfig{
+			HTTPClientSettings: confighttp.HTTPClientSettings{Endpoint: "http://localhost:40001"},
+			MaxTagsSize:        -1,
+		},
+	}
+	assert.EqualError(t, c.Validate(), "traces.max_tags_size must not be negative: -1")
+}
+
+func TestConfigRequiresNonNegativeNumWorkers(t *testing.T) {
+	c := &Config{
+		Metrics: MetricsConfig{
+			HTTPClientSettings: confighttp.HTTPClientSettings{Endpoint: "http://localhost:2878"},
+			NumWorkers:         -1,
+		},
+	}
+	assert.EqualError(t, c.Validate(), "metrics.num_workers must not be negative: -1")
+}
+
+func TestConfigRequiresNonNegativeMaxTagCardinality(t *testing.T) {
+	c := &Config{
+		Metrics: MetricsConfig{
+			HTTPClientSettings: confighttp.HTTPClientSettings{Endpoint: "http://localhost:2878"},
+			MaxTagCardinality:  -1,
+		},
+	}
+	assert.EqualError(t, c.Validate(), "metrics.max_tag_cardinality must not be negative: -1")
+}
+
+func TestConfigRequiresNonNegativeMaxHistogramBuckets(t *testing.T) {
+	c := &Config{
+		Metrics: MetricsConfig{
+			HTTPClientSettings:  confighttp.HTTPClientSettings{Endpoint: "http://localhost:2878"},
+			MaxHistogramBuckets: -1,
+		},
+	}
+	assert.EqualError(t, c.Validate(), "metrics.max_histogram_buckets must not be negative: -1")
+}
+
 func TestConfigNormal(t *testing.T) {
 	c := &Config{
 		Traces: TracesConfig{
@@ -128,3 +229,137 @@ func TestMetricConfig(t *testing.T) {
 	assert.True(t, c.Metrics.ResourceAttrsIncluded)
 	assert.True(t, c.Metrics.AppTagsExcluded)
 }
+
+func TestMetricsConfigEnabledTypes(t *testing.T) {
+	c := &Config{
+		Metrics: MetricsConfig{
+			EnabledTypes: []string{"gauge", "histogram"},
+		},
+	}
+	assert.NoError(t, c.Validate())
+	assert.True(t, c.Metrics.metricTypeEnabled(pmetric.MetricTypeGauge))
+	assert.True(t, c.Metrics.metricTypeEnabled(pmetric.MetricTypeHistogram))
+	assert.True(t, c.Metrics.metricTypeEnabled(pmetric.MetricTypeExponentialHistogram))
+	assert.False(t, c.Metrics.metricTypeEnabled(pmetric.MetricTypeSum))
+	assert.False(t, c.Metrics.metricTypeEnabled(pmetric.MetricTypeSummary))
+
+	c = &Config{Metrics: MetricsConfig{}}
+	assert.True(t, c.Metrics.metricTypeEnabled(pmetric.MetricTypeSummary))
+
+	c = &Config{
+		Metrics: MetricsConfig{
+			EnabledTypes: []string{"gauge", "counter"},
+		},
+	}
+	assert.EqualError(t, c.Validate(), `metrics.enabled_types contains invalid value: "counter"`)
+}
+
+func TestMetricsConfigDistribution(t *testing.T) {
+	c := &Config{Metrics: MetricsConfig{}}
+	assert.NoError(t, c.Validate())
+	assert.False(t, c.Metrics.hasDistributionSender())
+
+	c = &Config{Metrics: MetricsConfig{DistributionPort: 40000, DistributionInterval: time.Minute}}
+	assert.NoError(t, c.Validate())
+	assert.True(t, c.Metrics.hasDistributionSender())
+
+	c = &Config{Metrics: MetricsConfig{DistributionPort: 70000}}
+	assert.EqualError(t, c.Validate(), "metrics.distribution_port must be between 0 and 65535: 70000")
+
+	c = &Config{Metrics: MetricsConfig{DistributionInterval: 1500 * time.Millisecond}}
+	assert.EqualError(t, c.Validate(), "metrics.distribution_interval must be a non-negative whole number of seconds: 1.5s")
+
+	c = &Config{Metrics: MetricsConfig{DialTimeout: -time.Second}}
+	assert.EqualError(t, c.Validate(), "metrics.dial_timeout must not be negative: -1s")
+
+	c = &Config{Metrics: MetricsConfig{TLSHandshakeTimeout: -time.Second}}
+	assert.EqualError(t, c.Validate(), "metrics.tls_handshake_timeout must not be negative: -1s")
+}
+
+func TestUnsupportedHTTPClientSettings(t *testing.T) {
+	assert.Empty(t, unsupportedHTTPClientSettings(confighttp.HTTPClientSettings{Endpoint: "http://localhost:2878"}))
+
+	maxIdleConns := 100
+	idleConnTimeout := 90 * time.Second
+	settings := confighttp.HTTPClientSettings{
+		Endpoint:        "http://localhost:2878",
+		MaxIdleConns:    &maxIdleConns,
+		IdleConnTimeout: &idleConnTimeout,
+	}
+	assert.Equal(t, []string{"max_idle_conns", "idle_conn_timeout"}, unsupportedHTTPClientSettings(settings))
+
+	settings.Timeout = time.Minute
+	assert.Equal(t, []string{"timeout", "max_idle_conns", "idle_conn_timeout"}, unsupportedHTTPClientSettings(settings))
+}
+
+func TestMetricsConfigUnitTag(t *testing.T) {
+	c := createDefaultConfig().(*Config)
+	assert.False(t, c.Metrics.IncludeUnitTag)
+	assert.Equal(t, "unit", c.Metrics.UnitTagKey)
+
+	c.Metrics.IncludeUnitTag = true
+	assert.NoError(t, c.Validate())
+
+	c.Metrics.UnitTagKey = ""
+	assert.EqualError(t, c.Validate(), "metrics.unit_tag_key must not be empty when metrics.include_unit_tag is enabled")
+}
+
+func TestMetricsConfigInternalMetrics(t *testing.T) {
+	c := createDefaultConfig().(*Config)
+	assert.False(t, c.Metrics.InternalMetrics.Enabled)
+	assert.Equal(t, time.Minute, c.Metrics.InternalMetrics.Interval)
+
+	c.Metrics.InternalMetrics.Enabled = true
+	assert.NoError(t, c.Validate())
+
+	c.Metrics.InternalMetrics.Interval = 0
+	assert.EqualError(t, c.Validate(), "metrics.internal_metrics.interval must be positive when metrics.internal_metrics.enabled is set: 0s")
+}
+
+func TestMetricsConfigTagMappings(t *testing.T) {
+	c := createDefaultConfig().(*Config)
+	c.Metrics.TagMappings = []TagMapping{{Source: "http.method", Target: "method"}}
+	assert.NoError(t, c.Validate())
+
+	c.Metrics.TagMappings = []TagMapping{{Source: "http.method"}}
+	assert.EqualError(t, c.Validate(), `metrics.tag_mappings must have a source and a target: "http.method" -> ""`)
+
+	c.Metrics.TagMappings = []TagMapping{{Source: "http.method", Target: "method"}, {Source: "http.method", Target: "verb"}}
+	assert.EqualError(t, c.Validate(), `metrics.tag_mappings contains duplicate source: "http.method"`)
+}
+
+func TestMetricsConfigDropNamePatterns(t *testing.T) {
+	c := createDefaultConfig().(*Config)
+	c.Metrics.DropNamePatterns = []string{`^otel\.sdk\.`}
+	assert.NoError(t, c.Validate())
+
+	c.Metrics.DropNamePatterns = []string{`^otel\.sdk\.`, `(debug`}
+	assert.ErrorContains(t, c.Validate(), `metrics.drop_name_patterns contains invalid pattern "(debug"`)
+}
+
+func TestMetricsConfigTimestampGranularity(t *testing.T) {
+	c := createDefaultConfig().(*Config)
+	for _, granularity := range []string{"", "second", "minute"} {
+		c.Metrics.TimestampGranularity = granularity
+		assert.NoError(t, c.Validate())
+	}
+
+	c.Metrics.TimestampGranularity = "hour"
+	assert.EqualError(t, c.Validate(), `metrics.timestamp_granularity must be one of second or minute: "hour"`)
+}
+
+func TestCollectorInstanceTagValue(t *testing.T) {
+	c := createDefaultConfig().(*Config)
+	assert.False(t, c.CollectorInstance.Enabled)
+	assert.Equal(t, "", c.CollectorInstance.tagValue())
+
+	c.CollectorInstance.Enabled = true
+	generated := c.CollectorInstance.tagValue()
+	_, err := uuid.Parse(generated)
+	assert.NoError(t, err)
+	// the generated ID identifies the collector, so every exporter uses the same one
+	assert.Equal(t, generated, CollectorInstanceConfig{Enabled: true}.tagValue())
+
+	c.CollectorInstance.ID = "collector-1"
+	assert.Equal(t, "collector-1", c.CollectorInstance.tagValue())
+}
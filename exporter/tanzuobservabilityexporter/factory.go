@@ -17,16 +17,22 @@ package tanzuobservabilityexporter // import "github.com/open-telemetry/opentele
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
 )
 
 const (
 	exporterType = "tanzuobservability"
 	// The stability level of the exporter.
 	stability = component.StabilityLevelBeta
+	// The default key of the point tag the unit of a metric is added as.
+	defaultUnitTagKey = "unit"
+	// The default interval at which the internal metrics are sent.
+	defaultInternalMetricsInterval = time.Minute
 )
 
 // NewFactory creates a factory for the exporter.
@@ -36,6 +42,7 @@ func NewFactory() component.ExporterFactory {
 		createDefaultConfig,
 		component.WithTracesExporter(createTracesExporter, stability),
 		component.WithMetricsExporter(createMetricsExporter, stability),
+		component.WithLogsExporter(createLogsExporter, stability),
 	)
 }
 
@@ -44,6 +51,16 @@ func createDefaultConfig() component.ExporterConfig {
 		ExporterSettings: config.NewExporterSettings(component.NewID(exporterType)),
 		QueueSettings:    exporterhelper.NewDefaultQueueSettings(),
 		RetrySettings:    exporterhelper.NewDefaultRetrySettings(),
+		Metrics: MetricsConfig{
+			UnitTagKey: defaultUnitTagKey,
+			InternalMetrics: InternalMetricsConfig{
+				Interval: defaultInternalMetricsInterval,
+			},
+		},
+		ServiceHierarchy: ServiceHierarchyConfig{
+			ApplicationKey: conventions.AttributeServiceNamespace,
+			ServiceKey:     conventions.AttributeServiceName,
+		},
 	}
 }
 
@@ -94,6 +111,7 @@ func createMetricsExporter(
 		set,
 		cfg,
 		exp.pushMetricsData,
+		exporterhelper.WithStart(exp.start),
 		exporterhelper.WithQueue(tobsCfg.QueueSettings),
 		exporterhelper.WithRetry(tobsCfg.RetrySettings),
 		exporterhelper.WithShutdown(exp.shutdown),
@@ -104,3 +122,28 @@ func createMetricsExporter(
 
 	return exporter, nil
 }
+
+func createLogsExporter(
+	ctx context.Context,
+	set component.ExporterCreateSettings,
+	cfg component.ExporterConfig,
+) (component.LogsExporter, error) {
+	tobsCfg, ok := cfg.(*Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid config: %#v", cfg)
+	}
+	exp, err := newLogsExporter(set, tobsCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return exporterhelper.NewLogsExporter(
+		ctx,
+		set,
+		cfg,
+		exp.pushLogsData,
+		exporterhelper.WithStart(exp.start),
+		exporterhelper.WithQueue(tobsCfg.QueueSettings),
+		exporterhelper.WithRetry(tobsCfg.RetrySettings),
+	)
+}
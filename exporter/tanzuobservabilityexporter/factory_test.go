@@ -21,6 +21,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component/componenttest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestCreateDefaultConfig(t *testing.T) {
@@ -56,6 +58,49 @@ func TestCreateMetricsExporter(t *testing.T) {
 	assert.NotNil(t, te, "failed to create metrics exporter")
 }
 
+func TestCreateLogsExporter(t *testing.T) {
+	defaultConfig := createDefaultConfig()
+	cfg := defaultConfig.(*Config)
+	params := componenttest.NewNopExporterCreateSettings()
+	cfg.Logs.Endpoint = "http://localhost:2878"
+	le, err := createLogsExporter(context.Background(), params, cfg)
+	assert.NoError(t, err)
+	assert.NotNil(t, le, "failed to create logs exporter")
+}
+
+func TestCreateMetricsExporterAppliesHTTPSettings(t *testing.T) {
+	defaultConfig := createDefaultConfig()
+	cfg := defaultConfig.(*Config)
+	cfg.Metrics.Endpoint = "http://localhost:2878"
+	maxIdleConns := 10
+	cfg.Metrics.MaxIdleConns = &maxIdleConns
+
+	core, logs := observer.New(zap.WarnLevel)
+	params := componenttest.NewNopExporterCreateSettings()
+	params.Logger = zap.New(core)
+	te, err := createMetricsExporter(context.Background(), params, cfg)
+	require.NoError(t, err)
+	require.NotNil(t, te)
+	assert.Equal(t, 0, logs.Len())
+}
+
+func TestCreateTracesExporterWarnsOnUnsupportedHTTPSettings(t *testing.T) {
+	defaultConfig := createDefaultConfig()
+	cfg := defaultConfig.(*Config)
+	cfg.Traces.Endpoint = "http://localhost:30001"
+	maxIdleConns := 10
+	cfg.Traces.MaxIdleConns = &maxIdleConns
+
+	core, logs := observer.New(zap.WarnLevel)
+	params := componenttest.NewNopExporterCreateSettings()
+	params.Logger = zap.New(core)
+	te, err := createTracesExporter(context.Background(), params, cfg)
+	require.NoError(t, err)
+	require.NotNil(t, te)
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, []interface{}{"max_idle_conns"}, logs.All()[0].ContextMap()["settings"])
+}
+
 func TestCreateTraceExporterNilConfigError(t *testing.T) {
 	params := componenttest.NewNopExporterCreateSettings()
 	_, err := createTracesExporter(context.Background(), params, nil)
@@ -68,6 +113,18 @@ func TestCreateMetricsExporterNilConfigError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestCreateLogsExporterNilConfigError(t *testing.T) {
+	params := componenttest.NewNopExporterCreateSettings()
+	_, err := createLogsExporter(context.Background(), params, nil)
+	assert.Error(t, err)
+}
+
+func TestCreateLogsExporterMissingEndpointError(t *testing.T) {
+	params := componenttest.NewNopExporterCreateSettings()
+	_, err := createLogsExporter(context.Background(), params, createDefaultConfig())
+	assert.EqualError(t, err, "logs.endpoint required")
+}
+
 func TestCreateTraceExporterInvalidEndpointError(t *testing.T) {
 	params := componenttest.NewNopExporterCreateSettings()
 	defaultConfig := createDefaultConfig()
@@ -86,6 +143,15 @@ func TestCreateMetricsExporterInvalidEndpointError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestCreateLogsExporterInvalidEndpointError(t *testing.T) {
+	params := componenttest.NewNopExporterCreateSettings()
+	defaultConfig := createDefaultConfig()
+	cfg := defaultConfig.(*Config)
+	cfg.Logs.Endpoint = "http:#$%^&#$%&#"
+	_, err := createLogsExporter(context.Background(), params, cfg)
+	assert.Error(t, err)
+}
+
 func TestCreateTraceExporterMissingPortError(t *testing.T) {
 	params := componenttest.NewNopExporterCreateSettings()
 	defaultConfig := createDefaultConfig()
@@ -104,6 +170,15 @@ func TestCreateMetricsExporterMissingPortError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestCreateLogsExporterMissingPortError(t *testing.T) {
+	params := componenttest.NewNopExporterCreateSettings()
+	defaultConfig := createDefaultConfig()
+	cfg := defaultConfig.(*Config)
+	cfg.Logs.Endpoint = "http://localhost"
+	_, err := createLogsExporter(context.Background(), params, cfg)
+	assert.Error(t, err)
+}
+
 func TestCreateTraceExporterInvalidPortError(t *testing.T) {
 	params := componenttest.NewNopExporterCreateSettings()
 	defaultConfig := createDefaultConfig()
@@ -121,3 +196,12 @@ func TestCreateMetricsExporterInvalidPortError(t *testing.T) {
 	_, err := createMetricsExporter(context.Background(), params, cfg)
 	assert.Error(t, err)
 }
+
+func TestCreateLogsExporterInvalidPortError(t *testing.T) {
+	params := componenttest.NewNopExporterCreateSettings()
+	defaultConfig := createDefaultConfig()
+	cfg := defaultConfig.(*Config)
+	cfg.Logs.Endpoint = "http://localhost:c42a"
+	_, err := createLogsExporter(context.Background(), params, cfg)
+	assert.Error(t, err)
+}
@@ -7,6 +7,7 @@ require (
 	github.com/open-telemetry/opentelemetry-collector-contrib/internal/coreinternal v0.64.0
 	github.com/stretchr/testify v1.8.1
 	github.com/wavefronthq/wavefront-sdk-go v0.10.4
+	go.opencensus.io v0.24.0
 	go.opentelemetry.io/collector v0.64.2-0.20221117234814-4565692c50a7
 	go.opentelemetry.io/collector/component v0.0.0-20221117234814-4565692c50a7
 	go.opentelemetry.io/collector/pdata v0.64.2-0.20221117234814-4565692c50a7
@@ -14,6 +15,7 @@ require (
 	go.uber.org/atomic v1.10.0
 	go.uber.org/multierr v1.8.0
 	go.uber.org/zap v1.23.0
+	golang.org/x/time v0.0.0-20220411224347-583f2d630306
 )
 
 require (
@@ -59,7 +61,6 @@ require (
 	github.com/tklauser/go-sysconf v0.3.10 // indirect
 	github.com/tklauser/numcpus v0.4.0 // indirect
 	github.com/yusufpapurcu/wmi v1.2.2 // indirect
-	go.opencensus.io v0.24.0 // indirect
 	go.opentelemetry.io/collector/consumer v0.0.0-20221117234814-4565692c50a7 // indirect
 	go.opentelemetry.io/collector/featuregate v0.0.0-20221117214536-6a117bfc3737 // indirect
 	go.opentelemetry.io/collector/processor/batchprocessor v0.64.2-0.20221117234814-4565692c50a7 // indirect
@@ -0,0 +1,155 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tanzuobservabilityexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/tanzuobservabilityexporter"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	// logsPath is the path of the log ingestion of the proxy, which accepts a JSON array of logs
+	logsPath = "/logs/json_array?f=logs_json_arr"
+
+	labelMessage   = "message"
+	labelTimestamp = "timestamp"
+	labelLevel     = "level"
+	labelTraceID   = "trace_id"
+	labelSpanID    = "span_id"
+)
+
+type logsExporter struct {
+	cfg      *Config
+	settings component.ExporterCreateSettings
+	url      string
+	client   *http.Client
+	metrics  *opencensusMetrics
+}
+
+func newLogsExporter(settings component.ExporterCreateSettings, cfg *Config) (*logsExporter, error) {
+	if !cfg.hasLogsEndpoint() {
+		return nil, fmt.Errorf("logs.endpoint required")
+	}
+	if _, _, err := cfg.parseLogsEndpoint(); err != nil {
+		return nil, fmt.Errorf("failed to parse logs.endpoint: %w", err)
+	}
+	metrics, err := newOpenCensusMetrics(cfg.ID().Name(), signalLogs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register internal metrics: %w", err)
+	}
+
+	return &logsExporter{
+		cfg:      cfg,
+		settings: settings,
+		url:      strings.TrimSuffix(cfg.Logs.Endpoint, "/") + logsPath,
+		metrics:  metrics,
+	}, nil
+}
+
+// start creates the http client used to send logs, unlike traces and metrics logs are not
+// sent by the Wavefront SDK so all the http client settings of the logs are honored.
+func (e *logsExporter) start(_ context.Context, host component.Host) error {
+	client, err := e.cfg.Logs.ToClient(host, e.settings.TelemetrySettings)
+	if err != nil {
+		return fmt.Errorf("failed to create logs http client: %w", err)
+	}
+	e.client = client
+	return nil
+}
+
+func (e *logsExporter) pushLogsData(ctx context.Context, ld plog.Logs) error {
+	logs := make([]map[string]interface{}, 0, ld.LogRecordCount())
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		rlogs := ld.ResourceLogs().At(i)
+		resAttrs := rlogs.Resource().Attributes()
+		source, sourceKey := getSourceAndKey(resAttrs)
+		for j := 0; j < rlogs.ScopeLogs().Len(); j++ {
+			records := rlogs.ScopeLogs().At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				logs = append(logs, transformLogRecord(records.At(k), source, sourceKey, resAttrs))
+			}
+		}
+	}
+	if len(logs) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(logs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal logs: %w", err)
+	}
+	return e.metrics.recordRequest(func() error {
+		return e.send(ctx, body)
+	})
+}
+
+func (e *logsExporter) send(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create logs request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send logs: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to send logs, the proxy responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// transformLogRecord converts a log record into a log of the proxy, the attributes of the
+// record and its resource become tags of the log.
+func transformLogRecord(record plog.LogRecord, source string, sourceKey string, resAttrs pcommon.Map) map[string]interface{} {
+	log := map[string]interface{}{}
+	for k, v := range pointAndResAttrsToTagsAndFixSource(sourceKey, resAttrs, record.Attributes()) {
+		log[k] = v
+	}
+
+	timestamp := record.Timestamp()
+	if timestamp == 0 {
+		timestamp = record.ObservedTimestamp()
+	}
+	log[labelTimestamp] = timestamp.AsTime().UnixMilli()
+	log[labelMessage] = record.Body().AsString()
+	if source != "" {
+		log[labelSource] = source
+	}
+	if record.SeverityText() != "" {
+		log[labelLevel] = record.SeverityText()
+	} else if record.SeverityNumber() != plog.SeverityNumberUnspecified {
+		log[labelLevel] = record.SeverityNumber().String()
+	}
+	if traceID := record.TraceID(); !traceID.IsEmpty() {
+		log[labelTraceID] = traceID.HexString()
+	}
+	if spanID := record.SpanID(); !spanID.IsEmpty() {
+		log[labelSpanID] = spanID.HexString()
+	}
+	return log
+}
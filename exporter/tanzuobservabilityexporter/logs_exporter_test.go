@@ -0,0 +1,95 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tanzuobservabilityexporter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+)
+
+func TestPushLogsData(t *testing.T) {
+	var received []map[string]interface{}
+	var format string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format = r.URL.Query().Get("f")
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	logs := plog.NewLogs()
+	rlogs := logs.ResourceLogs().AppendEmpty()
+	rlogs.Resource().Attributes().PutStr(conventions.AttributeHostName, "node-1")
+	rlogs.Resource().Attributes().PutStr(conventions.AttributeServiceName, "checkout")
+	record := rlogs.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	record.Body().SetStr("order placed")
+	record.SetTimestamp(pcommon.NewTimestampFromTime(time.UnixMilli(1631205001000)))
+	record.SetSeverityNumber(plog.SeverityNumberWarn)
+	record.SetTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	record.Attributes().PutInt("order.items", 3)
+
+	exp := newTestLogsExporter(t, server.URL)
+	require.NoError(t, exp.pushLogsData(context.Background(), logs))
+	// every request sent to the proxy is recorded
+	assert.Equal(t, int64(1), requestLatencyCount(t, t.Name()))
+	assert.Equal(t, float64(0), inflightRequestsValue(t, t.Name()))
+
+	assert.Equal(t, "logs_json_arr", format)
+	require.Len(t, received, 1)
+	assert.Equal(t, map[string]interface{}{
+		"message":     "order placed",
+		"timestamp":   float64(1631205001000),
+		"source":      "node-1",
+		"service":     "checkout",
+		"level":       "Warn",
+		"trace_id":    "0102030405060708090a0b0c0d0e0f10",
+		"order.items": "3",
+	}, received[0])
+}
+
+func TestPushLogsDataErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	logs := plog.NewLogs()
+	logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("test")
+
+	exp := newTestLogsExporter(t, server.URL)
+	assert.EqualError(t, exp.pushLogsData(context.Background(), logs), "failed to send logs, the proxy responded with status 503")
+}
+
+func newTestLogsExporter(t *testing.T, endpoint string) *logsExporter {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ExporterSettings = config.NewExporterSettings(component.NewIDWithName(exporterType, t.Name()))
+	cfg.Logs.Endpoint = endpoint
+	exp, err := newLogsExporter(componenttest.NewNopExporterCreateSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.start(context.Background(), componenttest.NewNopHost()))
+	return exp
+}
@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/wavefronthq/wavefront-sdk-go/histogram"
 	"github.com/wavefronthq/wavefront-sdk-go/senders"
@@ -37,6 +39,11 @@ const (
 	metricTypeString                   = "metric type"
 	malformedHistogramMetricName       = "~sdk.otel.collector.malformed_histogram"
 	noAggregationTemporalityMetricName = "~sdk.otel.collector.no_aggregation_temporality"
+	droppedMetricName                  = "~sdk.otel.collector.dropped_metrics"
+)
+
+const (
+	droppedReasonTypeDisabled = "type_disabled"
 )
 
 const (
@@ -64,6 +71,21 @@ type metricsConsumer struct {
 	sender                flushCloser
 	reportInternalMetrics bool
 	config                MetricsConfig
+	tagLimiter            *tagLimiter
+	// metrics records the conversion errors of the metrics, nothing is recorded if nil
+	metrics *opencensusMetrics
+	// collectorInstance is the value of the otel.collector.instance tag of every point, the tag is not set if empty
+	collectorInstance string
+	// serviceHierarchy derives the application tags of the points from the service resource attributes
+	serviceHierarchy ServiceHierarchyConfig
+	// tagMapper renames the attributes of the metrics.tag_mappings, nothing is renamed if nil
+	tagMapper tagMapper
+	// nameFilter drops the metrics matching the metrics.drop_name_patterns, nothing is dropped if nil
+	nameFilter *nameFilter
+	// partitioningSender sends the points, it is nil if the consumer was not created by createMetricsConsumer
+	partitioningSender *partitioningSender
+	// internalMetricsSender sends the internal metrics of the exporter, they are not sent if nil
+	internalMetricsSender gaugeSender
 }
 
 type metricInfo struct {
@@ -71,6 +93,41 @@ type metricInfo struct {
 	Source        string
 	SourceKey     string
 	ResourceAttrs map[string]string
+	TagLimiter    *tagLimiter
+	// TagMapper renames the point attributes of the metrics.tag_mappings, nothing is renamed if nil
+	TagMapper tagMapper
+	// TimestampGranularity is the granularity the timestamps of the points are rounded to, they are truncated to the second if 0
+	TimestampGranularity time.Duration
+	// CollectorInstance is the value of the otel.collector.instance tag, the tag is not set if empty
+	CollectorInstance string
+	// Metrics records the conversion errors of the metric, nothing is recorded if nil
+	Metrics *opencensusMetrics
+}
+
+// recordConversionError counts a data point, or the whole metric, that could not be converted, by the type of the metric
+func (mi metricInfo) recordConversionError() {
+	mi.Metrics.recordConversionError(strings.ToLower(mi.Type().String()))
+}
+
+// pointTags returns the tags of a point with the given attributes, renamed by the tag mappings, their
+// values are limited to the maximum number of distinct values of each tag if a limit is configured. The
+// tag of the collector instance is added after the limit is applied as it has a single value.
+func (mi metricInfo) pointTags(attributes pcommon.Map) map[string]string {
+	tags := pointAndResAttrsToTagsAndFixSource(mi.SourceKey, mi.TagMapper.mapAttributes(attributes), newMap(mi.ResourceAttrs))
+	mi.TagLimiter.limit(tags)
+	if mi.CollectorInstance != "" {
+		tags[labelCollectorInstance] = mi.CollectorInstance
+	}
+	return tags
+}
+
+// pointTimestamp returns the timestamp of a point in seconds since the epoch, rounded to the timestamp granularity if one is set.
+func (mi metricInfo) pointTimestamp(ts pcommon.Timestamp) int64 {
+	t := ts.AsTime()
+	if mi.TimestampGranularity > 0 {
+		t = t.Round(mi.TimestampGranularity)
+	}
+	return t.Unix()
 }
 
 // newMetricsConsumer returns a new metricsConsumer. consumers are the
@@ -97,6 +154,8 @@ func newMetricsConsumer(
 		sender:                sender,
 		reportInternalMetrics: reportInternalMetrics,
 		config:                config,
+		tagMapper:             newTagMapper(config.TagMappings),
+		nameFilter:            newNameFilter(config.DropNamePatterns),
 	}
 }
 
@@ -109,7 +168,7 @@ func (c *metricsConsumer) Consume(ctx context.Context, md pmetric.Metrics) error
 	rms := md.ResourceMetrics()
 	for i := 0; i < rms.Len(); i++ {
 		resAttrs := rms.At(i).Resource().Attributes()
-		source, sourceKey := getSourceAndKey(resAttrs)
+		source, sourceKey := c.config.sourceAndKey(resAttrs)
 		ilms := rms.At(i).ScopeMetrics()
 		for j := 0; j < ilms.Len(); j++ {
 			ms := ilms.At(j).Metrics()
@@ -121,7 +180,17 @@ func (c *metricsConsumer) Consume(ctx context.Context, md pmetric.Metrics) error
 				} else if !c.config.AppTagsExcluded {
 					resAttrsMap = appAttributesToTags(resAttrs)
 				}
-				mi := metricInfo{Metric: m, Source: source, SourceKey: sourceKey, ResourceAttrs: resAttrsMap}
+				resAttrsMap = c.tagMapper.mapResourceTags(resAttrs, resAttrsMap)
+				if !c.config.AppTagsExcluded {
+					c.serviceHierarchy.applyTags(resAttrs, resAttrsMap)
+				}
+				if c.config.IncludeUnitTag && m.Unit() != "" {
+					if resAttrsMap == nil {
+						resAttrsMap = map[string]string{}
+					}
+					resAttrsMap[c.config.UnitTagKey] = m.Unit()
+				}
+				mi := metricInfo{Metric: m, Source: source, SourceKey: sourceKey, ResourceAttrs: resAttrsMap, TagLimiter: c.tagLimiter, TagMapper: c.tagMapper, TimestampGranularity: timestampGranularities[c.config.TimestampGranularity], CollectorInstance: c.collectorInstance, Metrics: c.metrics}
 				select {
 				case <-ctx.Done():
 					return multierr.Combine(append(errs, errors.New("context canceled"))...)
@@ -154,12 +223,19 @@ func (c *metricsConsumer) pushInternalMetrics(errs *[]error) {
 	for _, consumer := range c.consumerMap {
 		consumer.PushInternalMetrics(errs)
 	}
+	if c.internalMetricsSender != nil {
+		c.nameFilter.pushInternalMetrics(c.internalMetricsSender, errs)
+	}
 }
 
 func (c *metricsConsumer) pushSingleMetric(mi metricInfo, errs *[]error) {
+	if c.nameFilter.drop(mi.Name()) {
+		return
+	}
 	dataType := mi.Type()
 	consumer := c.consumerMap[dataType]
 	if consumer == nil {
+		mi.recordConversionError()
 		*errs = append(
 			*errs, fmt.Errorf("no support for metric type %v", dataType))
 
@@ -236,11 +312,12 @@ func pushGaugeNumberDataPoint(
 	settings component.TelemetrySettings,
 	missingValues *atomic.Int64,
 ) {
-	tags := pointAndResAttrsToTagsAndFixSource(mi.SourceKey, numberDataPoint.Attributes(), newMap(mi.ResourceAttrs))
-	ts := numberDataPoint.Timestamp().AsTime().Unix()
+	tags := mi.pointTags(numberDataPoint.Attributes())
+	ts := mi.pointTimestamp(numberDataPoint.Timestamp())
 	value, err := getValue(numberDataPoint)
 	if err != nil {
 		logMissingValue(mi.Metric, settings, missingValues)
+		mi.recordConversionError()
 		return
 	}
 	err = sender.SendMetric(mi.Name(), value, ts, mi.Source, tags)
@@ -249,6 +326,44 @@ func pushGaugeNumberDataPoint(
 	}
 }
 
+type disabledTypeConsumer struct {
+	metricType pmetric.MetricType
+	sender     gaugeSender
+	settings   component.TelemetrySettings
+	dropped    *atomic.Int64
+	tags       map[string]string
+}
+
+// newDisabledTypeConsumer returns a typedMetricConsumer that drops the metrics of
+// a type that is not enabled in the configuration and counts how many it dropped.
+func newDisabledTypeConsumer(
+	metricType pmetric.MetricType, sender gaugeSender, settings component.TelemetrySettings) typedMetricConsumer {
+	return &disabledTypeConsumer{
+		metricType: metricType,
+		sender:     sender,
+		settings:   settings,
+		dropped:    atomic.NewInt64(0),
+		tags: map[string]string{
+			"type":   strings.ToLower(metricType.String()),
+			"reason": droppedReasonTypeDisabled,
+		},
+	}
+}
+
+func (d *disabledTypeConsumer) Type() pmetric.MetricType {
+	return d.metricType
+}
+
+func (d *disabledTypeConsumer) Consume(mi metricInfo, _ *[]error) {
+	d.settings.Logger.Debug("Metric type disabled, dropping metric",
+		zap.String(metricNameString, mi.Name()), zap.String(metricTypeString, d.metricType.String()))
+	d.dropped.Inc()
+}
+
+func (d *disabledTypeConsumer) PushInternalMetrics(errs *[]error) {
+	report(d.dropped, droppedMetricName, d.tags, d.sender, errs)
+}
+
 // gaugeSender sends gauge metrics to tanzu observability
 type gaugeSender interface {
 	SendMetric(name string, value float64, ts int64, source string, tags map[string]string) error
@@ -336,10 +451,11 @@ func (s *sumConsumer) PushInternalMetrics(errs *[]error) {
 }
 
 func (s *sumConsumer) pushNumberDataPoint(mi metricInfo, numberDataPoint pmetric.NumberDataPoint, errs *[]error) {
-	tags := pointAndResAttrsToTagsAndFixSource(mi.SourceKey, numberDataPoint.Attributes(), newMap(mi.ResourceAttrs))
+	tags := mi.pointTags(numberDataPoint.Attributes())
 	value, err := getValue(numberDataPoint)
 	if err != nil {
 		logMissingValue(mi.Metric, s.settings, s.missingValues)
+		mi.recordConversionError()
 		return
 	}
 	err = s.sender.SendDeltaCounter(mi.Name(), value, mi.Source, tags)
@@ -436,10 +552,12 @@ func (h *histogramConsumer) Consume(mi metricInfo, errs *[]error) {
 		consumer = h.cumulative
 	default:
 		h.reporting.LogNoAggregationTemporality(mi.Metric)
+		mi.recordConversionError()
 		return
 	}
 	points := h.spec.DataPoints(mi.Metric)
 	for _, point := range points {
+		point.SecondsSinceEpoch = mi.pointTimestamp(point.timestamp)
 		consumer.Consume(mi, point, errs, h.reporting)
 	}
 }
@@ -481,10 +599,11 @@ func (c *cumulativeHistogramDataPointConsumer) Consume(
 ) {
 	if !point.Valid() {
 		reporting.LogMalformed(mi.Metric)
+		mi.recordConversionError()
 		return
 	}
 	name := mi.Name()
-	tags := pointAndResAttrsToTagsAndFixSource(mi.SourceKey, point.Attributes, newMap(mi.ResourceAttrs))
+	tags := mi.pointTags(point.Attributes)
 	if leTag, ok := tags["le"]; ok {
 		tags["_le"] = leTag
 	}
@@ -501,13 +620,16 @@ func (c *cumulativeHistogramDataPointConsumer) Consume(
 
 type deltaHistogramDataPointConsumer struct {
 	sender senders.DistributionSender
+	// maxBuckets is the maximum number of centroids of a distribution, unlimited if 0
+	maxBuckets int
 }
 
 // newDeltaHistogramDataPointConsumer returns a consumer for delta
-// histogram data points.
+// histogram data points. Adjacent buckets are merged so that no distribution
+// has more than maxBuckets centroids, unless maxBuckets is 0.
 func newDeltaHistogramDataPointConsumer(
-	sender senders.DistributionSender) histogramDataPointConsumer {
-	return &deltaHistogramDataPointConsumer{sender: sender}
+	sender senders.DistributionSender, maxBuckets int) histogramDataPointConsumer {
+	return &deltaHistogramDataPointConsumer{sender: sender, maxBuckets: maxBuckets}
 }
 
 func (d *deltaHistogramDataPointConsumer) Consume(
@@ -517,12 +639,14 @@ func (d *deltaHistogramDataPointConsumer) Consume(
 	reporting *histogramReporting) {
 	if !point.Valid() {
 		reporting.LogMalformed(mi.Metric)
+		mi.recordConversionError()
 		return
 	}
 	name := mi.Name()
-	tags := pointAndResAttrsToTagsAndFixSource(mi.SourceKey, point.Attributes, newMap(mi.ResourceAttrs))
+	tags := mi.pointTags(point.Attributes)
+	centroids := mergeCentroids(point.AsDelta(), d.maxBuckets)
 	err := d.sender.SendDistribution(
-		name, point.AsDelta(), allGranularity, point.SecondsSinceEpoch, mi.Source, tags)
+		name, centroids, allGranularity, point.SecondsSinceEpoch, mi.Source, tags)
 	if err != nil {
 		*errs = append(*errs, err)
 	}
@@ -562,8 +686,8 @@ func (s *summaryConsumer) sendSummaryDataPoint(
 	mi metricInfo, summaryDataPoint pmetric.SummaryDataPoint, errs *[]error,
 ) {
 	name := mi.Name()
-	ts := summaryDataPoint.Timestamp().AsTime().Unix()
-	tags := pointAndResAttrsToTagsAndFixSource(mi.SourceKey, summaryDataPoint.Attributes(), newMap(mi.ResourceAttrs))
+	ts := mi.pointTimestamp(summaryDataPoint.Timestamp())
+	tags := mi.pointTags(summaryDataPoint.Attributes())
 	count := summaryDataPoint.Count()
 	sum := summaryDataPoint.Sum()
 
@@ -612,6 +736,8 @@ type cumulativeBucket struct {
 type bucketHistogramDataPoint struct {
 	Attributes        pcommon.Map
 	SecondsSinceEpoch int64
+	// timestamp is the timestamp of the OTLP data point, SecondsSinceEpoch is derived from it
+	timestamp pcommon.Timestamp
 
 	// The bucket counts. For exponential histograms, the first and last element of bucketCounts
 	// are always 0.
@@ -681,6 +807,37 @@ func (b *bucketHistogramDataPoint) asDelta(
 	return result
 }
 
+// mergeCentroids merges adjacent centroids into at most maxCentroids centroids, every merged
+// centroid holds the total count of the centroids it replaces at their count weighted mean value,
+// so that the total count and approximate shape of the distribution are preserved. The centroids
+// are returned as they are if maxCentroids is 0 or there are not more centroids than that.
+func mergeCentroids(centroids []histogram.Centroid, maxCentroids int) []histogram.Centroid {
+	if maxCentroids <= 0 || len(centroids) <= maxCentroids {
+		return centroids
+	}
+	groupSize := (len(centroids) + maxCentroids - 1) / maxCentroids
+	result := make([]histogram.Centroid, 0, maxCentroids)
+	for start := 0; start < len(centroids); start += groupSize {
+		end := start + groupSize
+		if end > len(centroids) {
+			end = len(centroids)
+		}
+		var count int
+		var weightedSum, sum float64
+		for _, centroid := range centroids[start:end] {
+			count += centroid.Count
+			weightedSum += centroid.Value * float64(centroid.Count)
+			sum += centroid.Value
+		}
+		value := sum / float64(end-start)
+		if count > 0 {
+			value = weightedSum / float64(count)
+		}
+		result = append(result, histogram.Centroid{Value: value, Count: count})
+	}
+	return result
+}
+
 func (b *bucketHistogramDataPoint) leTagValue(bucketIndex int) string {
 	if bucketIndex == len(b.explicitBounds) {
 		return "+Inf"
@@ -765,6 +922,7 @@ func fromOtelHistogramDataPoint(point pmetric.HistogramDataPoint) bucketHistogra
 	return bucketHistogramDataPoint{
 		Attributes:        point.Attributes(),
 		SecondsSinceEpoch: point.Timestamp().AsTime().Unix(),
+		timestamp:         point.Timestamp(),
 		bucketCounts:      point.BucketCounts().AsRaw(),
 		explicitBounds:    point.ExplicitBounds().AsRaw(),
 	}
@@ -808,6 +966,7 @@ func fromOtelExponentialHistogramDataPoint(
 	return bucketHistogramDataPoint{
 		Attributes:        point.Attributes(),
 		SecondsSinceEpoch: point.Timestamp().AsTime().Unix(),
+		timestamp:         point.Timestamp(),
 		bucketCounts:      bucketCounts,
 		explicitBounds:    explicitBounds,
 		exponential:       true,
@@ -17,36 +17,173 @@ package tanzuobservabilityexporter // import "github.com/open-telemetry/opentele
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/http"
+	"time"
 
 	"github.com/wavefronthq/wavefront-sdk-go/senders"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/atomic"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
 )
 
 type metricsExporter struct {
-	consumer *metricsConsumer
+	// workers holds the consumers that are idle, every consumer has senders of its own
+	// so that the metrics sent by each of them are sent as separate requests
+	workers    chan *metricsConsumer
+	numWorkers int
+	busy       *atomic.Int64
+	metrics    *opencensusMetrics
+
+	instanceName    string
+	internalMetrics InternalMetricsConfig
+	logger          *zap.Logger
+	// stopInternalMetrics stops sending the internal metrics, which is done once internalMetricsDone is closed.
+	// Both are nil if the internal metrics are not sent.
+	stopInternalMetrics context.CancelFunc
+	internalMetricsDone chan struct{}
 }
 
 func createMetricsConsumer(config MetricsConfig, settings component.TelemetrySettings, otelVersion string) (*metricsConsumer, error) {
-	s, err := senders.NewSender(config.Endpoint,
+	reporter, err := newLineReporter(config.Endpoint, newMetricsHTTPClient(config))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create proxy sender: %w", err)
+	}
+	// the points are reported by the partitioningSender, the Wavefront sender only sends the
+	// distributions unless they have a sender of their own, and its internal metrics
+	ws, err := senders.NewSender(config.Endpoint,
 		senders.FlushIntervalSeconds(60),
 		senders.SDKMetricsTags(map[string]string{"otel.metrics.collector_version": otelVersion}),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create proxy sender: %w", err)
 	}
+	s := newPartitioningSender(ws, reporter, settings.Logger)
+	s.dedup = config.Dedup
+	var sender flushCloser = s
+	distributionSender := senders.DistributionSender(s)
+	if config.hasDistributionSender() {
+		ds, err := createDistributionSender(config, otelVersion)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		distributionSender = ds
+		sender = &distributionFlushCloser{flushCloser: s, distributions: ds, flushDistributions: config.DistributionInterval == 0}
+	}
 	cumulative := newCumulativeHistogramDataPointConsumer(s)
-	delta := newDeltaHistogramDataPointConsumer(s)
-	return newMetricsConsumer(
-		[]typedMetricConsumer{
-			newGaugeConsumer(s, settings),
-			newSumConsumer(s, settings),
-			newHistogramConsumer(cumulative, delta, s, regularHistogram, settings),
-			newHistogramConsumer(cumulative, delta, s, exponentialHistogram, settings),
-			newSummaryConsumer(s, settings),
-		},
-		s,
-		true, config), nil
+	delta := newDeltaHistogramDataPointConsumer(distributionSender, config.MaxHistogramBuckets)
+	consumers := []typedMetricConsumer{
+		newGaugeConsumer(s, settings),
+		newSumConsumer(s, settings),
+		newHistogramConsumer(cumulative, delta, s, regularHistogram, settings),
+		newHistogramConsumer(cumulative, delta, s, exponentialHistogram, settings),
+		newSummaryConsumer(s, settings),
+	}
+	for i, consumer := range consumers {
+		if !config.metricTypeEnabled(consumer.Type()) {
+			consumers[i] = newDisabledTypeConsumer(consumer.Type(), s, settings)
+		}
+	}
+	consumer := newMetricsConsumer(consumers, sender, true, config)
+	consumer.partitioningSender = s
+	consumer.internalMetricsSender = s
+	return consumer, nil
+}
+
+// createDistributionSender creates the sender of the distributions, which sends them to the
+// distribution port and flushes them on its own at the distribution interval if one is set.
+func createDistributionSender(config MetricsConfig, otelVersion string) (senders.Sender, error) {
+	_, port, err := parseEndpoint(config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if config.DistributionPort != 0 {
+		port = config.DistributionPort
+	}
+	flushInterval := 60
+	if config.DistributionInterval != 0 {
+		flushInterval = int(config.DistributionInterval / time.Second)
+	}
+	s, err := senders.NewSender(config.Endpoint,
+		senders.MetricsPort(port),
+		senders.FlushIntervalSeconds(flushInterval),
+		senders.SDKMetricsTags(map[string]string{"otel.metrics.collector_version": otelVersion}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create distribution proxy sender: %w", err)
+	}
+	return s, nil
+}
+
+// newMetricsHTTPClient creates the client the points are reported with. Like the client of the Wavefront
+// SDK it has a timeout of 10 seconds, unless the timeout of the metrics is set, and a transport with the
+// defaults of Go, to which the connection pool, keep-alive and timeout settings of the metrics are applied.
+func newMetricsHTTPClient(config MetricsConfig) *http.Client {
+	timeout := 10 * time.Second
+	if config.Timeout != 0 {
+		timeout = config.Timeout
+	}
+	return &http.Client{Timeout: timeout, Transport: newMetricsTransport(config)}
+}
+
+func newMetricsTransport(config MetricsConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.MaxIdleConns != nil {
+		transport.MaxIdleConns = *config.MaxIdleConns
+	}
+	if config.MaxIdleConnsPerHost != nil {
+		transport.MaxIdleConnsPerHost = *config.MaxIdleConnsPerHost
+	}
+	if config.MaxConnsPerHost != nil {
+		transport.MaxConnsPerHost = *config.MaxConnsPerHost
+	}
+	if config.IdleConnTimeout != nil {
+		transport.IdleConnTimeout = *config.IdleConnTimeout
+	}
+	transport.DisableKeepAlives = config.DisableKeepAlives
+	if config.TLSHandshakeTimeout != 0 {
+		transport.TLSHandshakeTimeout = config.TLSHandshakeTimeout
+	}
+	transport.DialContext = newMetricsDialer(config).DialContext
+	return transport
+}
+
+// newMetricsDialer creates the dialer of the transport the points are reported with, which has the
+// defaults of the dialer of the default transport of Go as it cannot be retrieved from the transport.
+func newMetricsDialer(config MetricsConfig) *net.Dialer {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if config.KeepAlive != nil {
+		dialer.KeepAlive = *config.KeepAlive
+	}
+	if config.DialTimeout != 0 {
+		dialer.Timeout = config.DialTimeout
+	}
+	return dialer
+}
+
+// distributionFlushCloser closes both the sender of the metrics and the sender of the
+// distributions. Unless flushDistributions is set, only the sender of the metrics is
+// flushed so that the distributions are flushed at the distribution interval.
+type distributionFlushCloser struct {
+	flushCloser
+	distributions      flushCloser
+	flushDistributions bool
+}
+
+func (d *distributionFlushCloser) Flush() error {
+	err := d.flushCloser.Flush()
+	if d.flushDistributions {
+		err = multierr.Append(err, d.distributions.Flush())
+	}
+	return err
+}
+
+func (d *distributionFlushCloser) Close() {
+	d.flushCloser.Close()
+	d.distributions.Close()
 }
 
 type metricsConsumerCreator func(config MetricsConfig, settings component.TelemetrySettings, otelVersion string) (
@@ -63,20 +200,145 @@ func newMetricsExporter(settings component.ExporterCreateSettings, c component.E
 	if _, _, err := cfg.parseMetricsEndpoint(); err != nil {
 		return nil, fmt.Errorf("failed to parse metrics.endpoint: %w", err)
 	}
-	consumer, err := creator(cfg.Metrics, settings.TelemetrySettings, settings.BuildInfo.Version)
+	metrics, err := newOpenCensusMetrics(cfg.ID().Name(), signalMetrics)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to register internal metrics: %w", err)
+	}
+	var limiter *tagLimiter
+	if cfg.Metrics.MaxTagCardinality > 0 {
+		limiter = newTagLimiter(cfg.Metrics.MaxTagCardinality, settings.Logger, metrics)
 	}
-	return &metricsExporter{
-		consumer: consumer,
-	}, nil
+	numWorkers := cfg.Metrics.NumWorkers
+	if numWorkers == 0 {
+		numWorkers = 1
+	}
+	exp := &metricsExporter{
+		workers:    make(chan *metricsConsumer, numWorkers),
+		numWorkers: numWorkers,
+		busy:       atomic.NewInt64(0),
+		metrics:    metrics,
+
+		instanceName:    cfg.ID().Name(),
+		internalMetrics: cfg.Metrics.InternalMetrics,
+		logger:          settings.Logger,
+	}
+	for i := 0; i < numWorkers; i++ {
+		consumer, err := creator(cfg.Metrics, settings.TelemetrySettings, settings.BuildInfo.Version)
+		if err != nil {
+			exp.closeWorkers(i)
+			return nil, err
+		}
+		if consumer.sender != nil {
+			consumer.sender = &observedFlushCloser{flushCloser: consumer.sender, metrics: metrics}
+		}
+		if consumer.partitioningSender != nil {
+			consumer.partitioningSender.metrics = metrics
+		}
+		consumer.tagLimiter = limiter
+		consumer.metrics = metrics
+		consumer.collectorInstance = cfg.CollectorInstance.tagValue()
+		consumer.serviceHierarchy = cfg.ServiceHierarchy
+		exp.workers <- consumer
+	}
+	return exp, nil
 }
 
+// pushMetricsData sends the metrics with an idle worker, waiting for one to become idle if all
+// workers are busy. Metrics pushed concurrently are sent concurrently by up to num_workers workers.
 func (e *metricsExporter) pushMetricsData(ctx context.Context, md pmetric.Metrics) error {
-	return e.consumer.Consume(ctx, md)
+	var consumer *metricsConsumer
+	select {
+	case consumer = <-e.workers:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	e.metrics.recordBusyWorkers(e.busy.Inc())
+	defer func() {
+		e.metrics.recordBusyWorkers(e.busy.Dec())
+		e.workers <- consumer
+	}()
+	return consumer.Consume(ctx, md)
 }
 
-func (e *metricsExporter) shutdown(_ context.Context) error {
-	e.consumer.Close()
+// start starts sending the internal metrics every metrics.internal_metrics.interval if they are enabled.
+func (e *metricsExporter) start(context.Context, component.Host) error {
+	if !e.internalMetrics.Enabled {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	e.stopInternalMetrics = cancel
+	e.internalMetricsDone = make(chan struct{})
+	go e.sendInternalMetricsPeriodically(ctx)
 	return nil
 }
+
+func (e *metricsExporter) sendInternalMetricsPeriodically(ctx context.Context) {
+	defer close(e.internalMetricsDone)
+	ticker := time.NewTicker(e.internalMetrics.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := e.sendInternalMetrics(ctx); err != nil && ctx.Err() == nil {
+				e.logger.Warn("Failed to send the internal metrics to Tanzu Observability", zap.Error(err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sendInternalMetrics sends the internal metrics of the exporter instance with an idle worker and flushes them.
+// The worker is not counted as busy and its flush is not recorded as a request, so that sending the internal
+// metrics does not change them. Otherwise every send would record new values that need to be sent in turn.
+func (e *metricsExporter) sendInternalMetrics(ctx context.Context) error {
+	var consumer *metricsConsumer
+	select {
+	case consumer = <-e.workers:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() {
+		e.workers <- consumer
+	}()
+	if consumer.internalMetricsSender == nil {
+		return nil
+	}
+	var errs error
+	for _, point := range internalMetricPoints(e.instanceName) {
+		errs = multierr.Append(errs, consumer.internalMetricsSender.SendMetric(point.name, point.value, 0, "", point.tags))
+	}
+	sender := consumer.sender
+	if observed, ok := sender.(*observedFlushCloser); ok {
+		sender = observed.flushCloser
+	}
+	if sender != nil {
+		errs = multierr.Append(errs, sender.Flush())
+	}
+	return errs
+}
+
+// shutdown stops sending the internal metrics, waits for the busy workers to finish sending their metrics
+// and closes all workers.
+func (e *metricsExporter) shutdown(ctx context.Context) error {
+	if e.stopInternalMetrics != nil {
+		e.stopInternalMetrics()
+		<-e.internalMetricsDone
+	}
+	for i := 0; i < e.numWorkers; i++ {
+		select {
+		case consumer := <-e.workers:
+			consumer.Close()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// closeWorkers closes the given number of idle workers.
+func (e *metricsExporter) closeWorkers(count int) {
+	for i := 0; i < count; i++ {
+		(<-e.workers).Close()
+	}
+}
@@ -18,13 +18,22 @@ import (
 	"context"
 	"errors"
 	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/atomic"
 )
 
 func TestPushMetricsData(t *testing.T) {
@@ -35,6 +44,202 @@ func TestPushMetricsDataErrorOnSend(t *testing.T) {
 	assert.Error(t, verifyPushMetricsData(t, true))
 }
 
+func TestPushMetricsDataConcurrentWorkers(t *testing.T) {
+	const numWorkers = 3
+	sender := newBlockingMetricSender(numWorkers + 1)
+	exp := newBlockingMetricsExporter(t, sender, numWorkers)
+
+	pushed := make(chan error, numWorkers+1)
+	for i := 0; i < numWorkers+1; i++ {
+		go func() {
+			pushed <- exp.pushMetricsData(context.Background(), constructMetrics(newMetric("test.metric", pmetric.MetricTypeGauge)))
+		}()
+	}
+	for i := 0; i < numWorkers; i++ {
+		<-sender.flushing
+	}
+	assert.Equal(t, float64(numWorkers), inflightRequestsValue(t, t.Name()))
+	assert.Equal(t, float64(numWorkers), busyWorkersValue(t, t.Name()))
+	// all workers are busy so the last push waits for one of them to become idle
+	select {
+	case <-sender.flushing:
+		t.Fatal("more requests in flight than workers")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(sender.release)
+	for i := 0; i < numWorkers+1; i++ {
+		assert.NoError(t, <-pushed)
+	}
+	assert.Equal(t, float64(0), inflightRequestsValue(t, t.Name()))
+	assert.Equal(t, float64(0), busyWorkersValue(t, t.Name()))
+	require.NoError(t, exp.shutdown(context.Background()))
+	assert.Equal(t, int64(numWorkers), sender.numCloseCalls.Load())
+}
+
+func TestMetricsExporterCollectorInstanceTag(t *testing.T) {
+	for name, instance := range map[string]CollectorInstanceConfig{
+		"enabled":  {Enabled: true, ID: "collector-1"},
+		"disabled": {ID: "collector-1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.ExporterSettings = config.NewExporterSettings(component.NewIDWithName(exporterType, t.Name()))
+			cfg.Metrics.Endpoint = "http://localhost:2878"
+			cfg.CollectorInstance = instance
+			sender := &mockGaugeSender{}
+			creator := func(metricsConfig MetricsConfig, settings component.TelemetrySettings, otelVersion string) (*metricsConsumer, error) {
+				return newMetricsConsumer([]typedMetricConsumer{newGaugeConsumer(sender, settings)}, &mockFlushCloser{}, false, metricsConfig), nil
+			}
+			exp, err := newMetricsExporter(componenttest.NewNopExporterCreateSettings(), cfg, creator)
+			require.NoError(t, err)
+
+			gauge := newMetric("gauge", pmetric.MetricTypeGauge)
+			addDataPoint(1, 1640123456, map[string]interface{}{"pod": "pod-0"}, gauge.Gauge().DataPoints())
+			require.NoError(t, exp.pushMetricsData(context.Background(), constructMetrics(gauge)))
+			require.NoError(t, exp.shutdown(context.Background()))
+
+			require.Len(t, sender.metrics, 1)
+			value, ok := sender.metrics[0].Tags[labelCollectorInstance]
+			assert.Equal(t, instance.Enabled, ok)
+			assert.Equal(t, instance.tagValue(), value)
+		})
+	}
+}
+
+func TestMetricsExporterShutdownDrainsWorkers(t *testing.T) {
+	sender := newBlockingMetricSender(1)
+	exp := newBlockingMetricsExporter(t, sender, 2)
+
+	pushed := make(chan error)
+	go func() {
+		pushed <- exp.pushMetricsData(context.Background(), constructMetrics(newMetric("test.metric", pmetric.MetricTypeGauge)))
+	}()
+	<-sender.flushing
+
+	shutdown := make(chan error)
+	go func() {
+		shutdown <- exp.shutdown(context.Background())
+	}()
+	select {
+	case <-shutdown:
+		t.Fatal("shutdown returned while metrics were being sent")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(sender.release)
+	assert.NoError(t, <-pushed)
+	assert.NoError(t, <-shutdown)
+	assert.Equal(t, int64(1), sender.numFlushCalls.Load())
+	assert.Equal(t, int64(2), sender.numCloseCalls.Load())
+}
+
+func TestMetricsExporterShutdownTimeout(t *testing.T) {
+	sender := newBlockingMetricSender(1)
+	defer close(sender.release)
+	exp := newBlockingMetricsExporter(t, sender, 1)
+
+	go func() {
+		_ = exp.pushMetricsData(context.Background(), constructMetrics(newMetric("test.metric", pmetric.MetricTypeGauge)))
+	}()
+	<-sender.flushing
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, exp.shutdown(ctx), context.DeadlineExceeded)
+}
+
+func TestPushMetricsDataRecordsRequests(t *testing.T) {
+	received := make(chan string, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case received <- r.URL.Query().Get("f"):
+		default:
+		}
+		<-release
+	}))
+	defer server.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.ExporterSettings = config.NewExporterSettings(component.NewIDWithName(exporterType, t.Name()))
+	cfg.Metrics.Endpoint = server.URL
+	exp, err := newMetricsExporter(componenttest.NewNopExporterCreateSettings(), cfg, createMetricsConsumer)
+	require.NoError(t, err)
+
+	metric := newMetric("test.metric", pmetric.MetricTypeGauge)
+	addDataPoint(7, 1631205001, map[string]interface{}{"env": "prod"}, metric.Gauge().DataPoints())
+	pushed := make(chan error)
+	go func() {
+		pushed <- exp.pushMetricsData(context.Background(), constructMetrics(metric))
+	}()
+
+	// the request is in flight until the proxy responds
+	assert.Equal(t, "wavefront", <-received)
+	assert.Equal(t, float64(1), inflightRequestsValue(t, t.Name()))
+	close(release)
+	assert.NoError(t, <-pushed)
+	assert.Equal(t, float64(0), inflightRequestsValue(t, t.Name()))
+	assert.Equal(t, int64(1), requestLatencyCount(t, t.Name()))
+	require.NoError(t, exp.shutdown(context.Background()))
+}
+
+func TestCreateMetricsConsumerDistributionSender(t *testing.T) {
+	metricsServer := newFormatRecordingServer()
+	defer metricsServer.Close()
+	distributionServer := newFormatRecordingServer()
+	defer distributionServer.Close()
+	_, distributionPort, err := parseEndpoint(distributionServer.URL)
+	require.NoError(t, err)
+
+	config := MetricsConfig{
+		HTTPClientSettings:   confighttp.HTTPClientSettings{Endpoint: metricsServer.URL},
+		DistributionPort:     distributionPort,
+		DistributionInterval: time.Second,
+	}
+	consumer, err := createMetricsConsumer(config, componenttest.NewNopTelemetrySettings(), "test")
+	require.NoError(t, err)
+	defer consumer.Close()
+
+	metric := newMetric("a.metric", pmetric.MetricTypeHistogram)
+	metric.Histogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	point := metric.Histogram().DataPoints().AppendEmpty()
+	point.BucketCounts().FromRaw([]uint64{4, 7, 11})
+	point.ExplicitBounds().FromRaw([]float64{1, 10})
+	require.NoError(t, consumer.Consume(context.Background(), constructMetrics(metric)))
+
+	// distributions are not flushed with the other metrics but at the distribution interval
+	assert.Empty(t, distributionServer.formats())
+	assert.Eventually(t, func() bool {
+		return len(distributionServer.formats()) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"histogram"}, distributionServer.formats())
+	assert.NotContains(t, metricsServer.formats(), "histogram")
+}
+
+type formatRecordingServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	received []string
+}
+
+// newFormatRecordingServer starts a server that records the format of every report it receives
+func newFormatRecordingServer() *formatRecordingServer {
+	s := &formatRecordingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.received = append(s.received, r.URL.Query().Get("f"))
+	}))
+	return s
+}
+
+func (s *formatRecordingServer) formats() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.received...)
+}
+
 func verifyPushMetricsData(t *testing.T, errorOnSend bool) error {
 	metric := newMetric("test.metric", pmetric.MetricTypeGauge)
 	dataPoints := metric.Gauge().DataPoints()
@@ -87,6 +292,21 @@ func createMockMetricsExporter(
 	)
 }
 
+// newBlockingMetricsExporter returns an exporter with the given number of workers, which all send
+// their metrics with sender. The exporter is named after the test so its internal metrics can be told apart.
+func newBlockingMetricsExporter(t *testing.T, sender *blockingMetricSender, numWorkers int) *metricsExporter {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ExporterSettings = config.NewExporterSettings(component.NewIDWithName(exporterType, t.Name()))
+	cfg.Metrics.Endpoint = "http://localhost:2878"
+	cfg.Metrics.NumWorkers = numWorkers
+	creator := func(metricsConfig MetricsConfig, settings component.TelemetrySettings, otelVersion string) (*metricsConsumer, error) {
+		return newMetricsConsumer([]typedMetricConsumer{newGaugeConsumer(sender, settings)}, sender, false, metricsConfig), nil
+	}
+	exp, err := newMetricsExporter(componenttest.NewNopExporterCreateSettings(), cfg, creator)
+	require.NoError(t, err)
+	return exp
+}
+
 func consumeMetrics(metrics pmetric.Metrics, sender *mockMetricSender) error {
 	ctx := context.Background()
 	mockOTelMetricsExporter, err := createMockMetricsExporter(sender)
@@ -123,3 +343,250 @@ func (m *mockMetricSender) Flush() error {
 }
 
 func (m *mockMetricSender) Close() { m.numCloseCalls++ }
+
+func TestMetricsExporterRecordsConversionErrors(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ExporterSettings = config.NewExporterSettings(component.NewIDWithName(exporterType, t.Name()))
+	cfg.Metrics.Endpoint = "http://localhost:2878"
+	sender := &mockGaugeSender{}
+	creator := func(metricsConfig MetricsConfig, settings component.TelemetrySettings, otelVersion string) (*metricsConsumer, error) {
+		histogramConsumer := newHistogramConsumer(
+			newCumulativeHistogramDataPointConsumer(sender),
+			newDeltaHistogramDataPointConsumer(&mockDistributionSender{}, 0),
+			sender,
+			regularHistogram,
+			settings,
+		)
+		return newMetricsConsumer([]typedMetricConsumer{newGaugeConsumer(sender, settings), histogramConsumer}, &mockFlushCloser{}, false, metricsConfig), nil
+	}
+	exp, err := newMetricsExporter(componenttest.NewNopExporterCreateSettings(), cfg, creator)
+	require.NoError(t, err)
+
+	// the histogram has one valid point and two points with fewer bucket counts than bounds
+	histogram := newMetric("histogram", pmetric.MetricTypeHistogram)
+	histogram.Histogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	for _, counts := range [][]uint64{{1, 2, 3}, {1}, {}} {
+		point := histogram.Histogram().DataPoints().AppendEmpty()
+		point.ExplicitBounds().FromRaw([]float64{1, 2})
+		point.BucketCounts().FromRaw(counts)
+	}
+	// the gauge has a single point without a value
+	gauge := newMetric("gauge", pmetric.MetricTypeGauge)
+	gauge.Gauge().DataPoints().AppendEmpty()
+	require.NoError(t, exp.pushMetricsData(context.Background(), constructMetrics(histogram, gauge)))
+	require.NoError(t, exp.shutdown(context.Background()))
+
+	assert.Len(t, sender.metrics, 3)
+	assert.Equal(t, float64(2), conversionErrorsValue(t, t.Name(), signalMetrics, "histogram"))
+	assert.Equal(t, float64(1), conversionErrorsValue(t, t.Name(), signalMetrics, "gauge"))
+	assert.Equal(t, float64(0), conversionErrorsValue(t, t.Name(), signalMetrics, "sum"))
+}
+
+func TestMetricsExporterSendsInternalMetrics(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ExporterSettings = config.NewExporterSettings(component.NewIDWithName(exporterType, t.Name()))
+	cfg.Metrics.Endpoint = "http://localhost:2878"
+	cfg.Metrics.InternalMetrics.Enabled = true
+	sender := &mockGaugeSender{}
+	flushCloser := &mockFlushCloser{}
+	creator := func(metricsConfig MetricsConfig, settings component.TelemetrySettings, otelVersion string) (*metricsConsumer, error) {
+		consumer := newMetricsConsumer([]typedMetricConsumer{newGaugeConsumer(sender, settings)}, flushCloser, false, metricsConfig)
+		consumer.internalMetricsSender = sender
+		return consumer, nil
+	}
+	exp, err := newMetricsExporter(componenttest.NewNopExporterCreateSettings(), cfg, creator)
+	require.NoError(t, err)
+
+	gauge := newMetric("gauge", pmetric.MetricTypeGauge)
+	addDataPoint(1, 1640123456, nil, gauge.Gauge().DataPoints())
+	require.NoError(t, exp.pushMetricsData(context.Background(), constructMetrics(gauge)))
+	exp.metrics.recordDroppedPoint(droppedReasonRejected)
+	sender.metrics = nil
+
+	require.NoError(t, exp.sendInternalMetrics(context.Background()))
+	assert.Equal(t, 2, flushCloser.numFlushCalls)
+	tags := map[string]string{"exporter": t.Name(), "signal": signalMetrics}
+	assert.Contains(t, sender.metrics, tobsMetric{Name: "~sdk.otel.collector.tanzu_request_latency.count", Value: 1, Tags: tags})
+	assert.Contains(t, sender.metrics, tobsMetric{Name: "~sdk.otel.collector.tanzu_busy_workers", Value: 0, Tags: tags})
+	assert.Contains(t, sender.metrics, tobsMetric{
+		Name:  "~sdk.otel.collector.tanzu_dropped_points",
+		Value: 1,
+		Tags:  map[string]string{"exporter": t.Name(), "signal": signalMetrics, "reason": droppedReasonRejected},
+	})
+	for _, metric := range sender.metrics {
+		assert.Equal(t, t.Name(), metric.Tags["exporter"])
+	}
+
+	// sending the internal metrics is not recorded in turn, so the same points are sent again
+	sent := sender.metrics
+	sender.metrics = nil
+	require.NoError(t, exp.sendInternalMetrics(context.Background()))
+	assert.ElementsMatch(t, sent, sender.metrics)
+	assert.Equal(t, int64(1), requestLatencyCount(t, t.Name()))
+	require.NoError(t, exp.shutdown(context.Background()))
+}
+
+func TestMetricsExporterSendsInternalMetricsPeriodically(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ExporterSettings = config.NewExporterSettings(component.NewIDWithName(exporterType, t.Name()))
+	cfg.Metrics.Endpoint = "http://localhost:2878"
+	cfg.Metrics.InternalMetrics = InternalMetricsConfig{Enabled: true, Interval: 10 * time.Millisecond}
+	sender := &mockGaugeSender{}
+	flushCloser := newBlockingMetricSender(1)
+	creator := func(metricsConfig MetricsConfig, settings component.TelemetrySettings, otelVersion string) (*metricsConsumer, error) {
+		consumer := newMetricsConsumer([]typedMetricConsumer{newGaugeConsumer(sender, settings)}, flushCloser, false, metricsConfig)
+		consumer.internalMetricsSender = sender
+		return consumer, nil
+	}
+	exp, err := newMetricsExporter(componenttest.NewNopExporterCreateSettings(), cfg, creator)
+	require.NoError(t, err)
+	exp.metrics.recordDroppedPoint(droppedReasonDuplicate)
+
+	require.NoError(t, exp.start(context.Background(), componenttest.NewNopHost()))
+	select {
+	case <-flushCloser.flushing:
+	case <-time.After(5 * time.Second):
+		t.Fatal("internal metrics were not sent")
+	}
+	assert.Contains(t, sender.metrics, tobsMetric{
+		Name:  "~sdk.otel.collector.tanzu_dropped_points",
+		Value: 1,
+		Tags:  map[string]string{"exporter": t.Name(), "signal": signalMetrics, "reason": droppedReasonDuplicate},
+	})
+	close(flushCloser.release)
+	require.NoError(t, exp.shutdown(context.Background()))
+	assert.Equal(t, int64(1), flushCloser.numCloseCalls.Load())
+}
+
+func TestMetricsExporterInternalMetricsDisabled(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ExporterSettings = config.NewExporterSettings(component.NewIDWithName(exporterType, t.Name()))
+	cfg.Metrics.Endpoint = "http://localhost:2878"
+	cfg.Metrics.InternalMetrics.Interval = time.Millisecond
+	creator := func(metricsConfig MetricsConfig, settings component.TelemetrySettings, otelVersion string) (*metricsConsumer, error) {
+		return newMetricsConsumer([]typedMetricConsumer{newGaugeConsumer(&mockGaugeSender{}, settings)}, &mockFlushCloser{}, false, metricsConfig), nil
+	}
+	exp, err := newMetricsExporter(componenttest.NewNopExporterCreateSettings(), cfg, creator)
+	require.NoError(t, err)
+	require.NoError(t, exp.start(context.Background(), componenttest.NewNopHost()))
+	assert.Nil(t, exp.stopInternalMetrics)
+	require.NoError(t, exp.shutdown(context.Background()))
+}
+
+// blockingMetricSender blocks every flush until release is closed, it signals flushing when a flush starts.
+// flushing is buffered for the flushes a test expects so that flushes started after release never block.
+type blockingMetricSender struct {
+	flushing      chan struct{}
+	release       chan struct{}
+	numFlushCalls *atomic.Int64
+	numCloseCalls *atomic.Int64
+}
+
+func newBlockingMetricSender(flushes int) *blockingMetricSender {
+	return &blockingMetricSender{
+		flushing:      make(chan struct{}, flushes),
+		release:       make(chan struct{}),
+		numFlushCalls: atomic.NewInt64(0),
+		numCloseCalls: atomic.NewInt64(0),
+	}
+}
+
+func (b *blockingMetricSender) SendMetric(_ string, _ float64, _ int64, _ string, _ map[string]string) error {
+	return nil
+}
+
+func (b *blockingMetricSender) Flush() error {
+	b.numFlushCalls.Inc()
+	b.flushing <- struct{}{}
+	<-b.release
+	return nil
+}
+
+func (b *blockingMetricSender) Close() { b.numCloseCalls.Inc() }
+
+func TestNewMetricsTransport(t *testing.T) {
+	transport := newMetricsTransport(MetricsConfig{})
+	defaultTransport := http.DefaultTransport.(*http.Transport)
+	assert.Equal(t, defaultTransport.MaxIdleConns, transport.MaxIdleConns)
+	assert.Equal(t, defaultTransport.MaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, defaultTransport.MaxConnsPerHost, transport.MaxConnsPerHost)
+	assert.Equal(t, defaultTransport.IdleConnTimeout, transport.IdleConnTimeout)
+	assert.False(t, transport.DisableKeepAlives)
+	assert.Equal(t, defaultTransport.TLSHandshakeTimeout, transport.TLSHandshakeTimeout)
+
+	maxIdleConns, maxIdleConnsPerHost, maxConnsPerHost := 200, 50, 60
+	idleConnTimeout := 5 * time.Minute
+	transport = newMetricsTransport(MetricsConfig{
+		HTTPClientSettings: confighttp.HTTPClientSettings{
+			MaxIdleConns:        &maxIdleConns,
+			MaxIdleConnsPerHost: &maxIdleConnsPerHost,
+			MaxConnsPerHost:     &maxConnsPerHost,
+			IdleConnTimeout:     &idleConnTimeout,
+		},
+		DisableKeepAlives:   true,
+		TLSHandshakeTimeout: 3 * time.Second,
+	})
+	assert.Equal(t, 200, transport.MaxIdleConns)
+	assert.Equal(t, 50, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 60, transport.MaxConnsPerHost)
+	assert.Equal(t, 5*time.Minute, transport.IdleConnTimeout)
+	assert.True(t, transport.DisableKeepAlives)
+	assert.Equal(t, 3*time.Second, transport.TLSHandshakeTimeout)
+}
+
+func TestNewMetricsDialer(t *testing.T) {
+	dialer := newMetricsDialer(MetricsConfig{})
+	assert.Equal(t, 30*time.Second, dialer.KeepAlive)
+	assert.Equal(t, 30*time.Second, dialer.Timeout)
+
+	keepAlive := time.Minute
+	dialer = newMetricsDialer(MetricsConfig{KeepAlive: &keepAlive, DialTimeout: 2 * time.Second})
+	assert.Equal(t, time.Minute, dialer.KeepAlive)
+	assert.Equal(t, 2*time.Second, dialer.Timeout)
+}
+
+func TestNewMetricsHTTPClientTimeout(t *testing.T) {
+	assert.Equal(t, 10*time.Second, newMetricsHTTPClient(MetricsConfig{}).Timeout)
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	// the request times out after the timeout of the whole request, not the longer dial timeout
+	config := MetricsConfig{
+		HTTPClientSettings: confighttp.HTTPClientSettings{Endpoint: server.URL, Timeout: 50 * time.Millisecond},
+		DialTimeout:        time.Minute,
+	}
+	client := newMetricsHTTPClient(config)
+	assert.Equal(t, 50*time.Millisecond, client.Timeout)
+	reporter, err := newLineReporter(server.URL, client)
+	require.NoError(t, err)
+	start := time.Now()
+	assert.Error(t, reporter.report([]string{"\"test.metric\" 1 source=\"host\"\n"}))
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestCreateMetricsConsumerReusesConnections(t *testing.T) {
+	proxy := newLineCountingProxy()
+	defer proxy.Close()
+	var conns atomic.Int64
+	proxy.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Inc()
+		}
+	}
+
+	consumer, err := createMetricsConsumer(MetricsConfig{HTTPClientSettings: confighttp.HTTPClientSettings{Endpoint: proxy.URL}}, componenttest.NewNopTelemetrySettings(), "test")
+	require.NoError(t, err)
+	defer consumer.Close()
+	for i := 0; i < 3; i++ {
+		metric := newMetric("test.metric", pmetric.MetricTypeGauge)
+		addDataPoint(float64(i), 1631205001, nil, metric.Gauge().DataPoints())
+		require.NoError(t, consumer.Consume(context.Background(), constructMetrics(metric)))
+	}
+	assert.Equal(t, map[string]int{"test.metric": 3}, proxy.deliveredLines())
+	assert.Equal(t, int64(1), conns.Load())
+}
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/wavefronthq/wavefront-sdk-go/histogram"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
@@ -189,6 +190,387 @@ func TestEndToEndGaugeConsumerWithAppResAttrsExcluded(t *testing.T) {
 	)
 }
 
+func TestEndToEndGaugeConsumerWithUnitTag(t *testing.T) {
+	tests := []struct {
+		name           string
+		includeUnitTag bool
+		unitTagKey     string
+		unit           string
+		expectedTags   map[string]string
+	}{
+		{
+			name:           "enabled",
+			includeUnitTag: true,
+			unit:           "By",
+			expectedTags:   map[string]string{"env": "prod", "unit": "By"},
+		},
+		{
+			name:           "enabled with custom key",
+			includeUnitTag: true,
+			unitTagKey:     "metric.unit",
+			unit:           "By",
+			expectedTags:   map[string]string{"env": "prod", "metric.unit": "By"},
+		},
+		{
+			name:           "enabled without unit",
+			includeUnitTag: true,
+			expectedTags:   map[string]string{"env": "prod"},
+		},
+		{
+			name:         "disabled",
+			unit:         "By",
+			expectedTags: map[string]string{"env": "prod"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gauge := newMetric("gauge", pmetric.MetricTypeGauge)
+			gauge.SetUnit(tt.unit)
+			addDataPoint(432.25, 1640123456, map[string]interface{}{"env": "prod"}, gauge.Gauge().DataPoints())
+			exporterConfig := createDefaultConfig()
+			tobsConfig := exporterConfig.(*Config)
+			tobsConfig.Metrics.AppTagsExcluded = true
+			tobsConfig.Metrics.IncludeUnitTag = tt.includeUnitTag
+			if tt.unitTagKey != "" {
+				tobsConfig.Metrics.UnitTagKey = tt.unitTagKey
+			}
+			metrics := constructMetricsWithTags(map[string]string{"host.name": "my_source"}, gauge)
+			sender := &mockGaugeSender{}
+			gaugeConsumer := newGaugeConsumer(sender, componenttest.NewNopTelemetrySettings())
+			consumer := newMetricsConsumer(
+				[]typedMetricConsumer{gaugeConsumer}, &mockFlushCloser{}, false, tobsConfig.Metrics)
+			assert.NoError(t, consumer.Consume(context.Background(), metrics))
+
+			assert.Equal(t, []tobsMetric{
+				{
+					Name:   "gauge",
+					Ts:     1640123456,
+					Value:  432.25,
+					Tags:   tt.expectedTags,
+					Source: "my_source",
+				},
+			}, sender.metrics)
+		})
+	}
+}
+
+func TestEndToEndGaugeConsumerWithServiceHierarchy(t *testing.T) {
+	tests := []struct {
+		name               string
+		enabled            bool
+		appTagsExcluded    bool
+		applicationKey     string
+		resourceAttributes map[string]string
+		expectedTags       map[string]string
+	}{
+		{
+			name:               "enabled",
+			enabled:            true,
+			resourceAttributes: map[string]string{"host.name": "my_source", "service.namespace": "shop", "service.name": "checkout"},
+			expectedTags:       map[string]string{"env": "prod", "application": "shop", "service": "checkout"},
+		},
+		{
+			name:               "enabled with custom key",
+			enabled:            true,
+			applicationKey:     "k8s.namespace.name",
+			resourceAttributes: map[string]string{"host.name": "my_source", "k8s.namespace.name": "shop", "service.namespace": "store"},
+			expectedTags:       map[string]string{"env": "prod", "application": "shop"},
+		},
+		{
+			name:               "enabled with explicit application",
+			enabled:            true,
+			resourceAttributes: map[string]string{"host.name": "my_source", "service.namespace": "shop", "application": "store"},
+			expectedTags:       map[string]string{"env": "prod", "application": "store"},
+		},
+		{
+			name:               "enabled with app tags excluded",
+			enabled:            true,
+			appTagsExcluded:    true,
+			resourceAttributes: map[string]string{"host.name": "my_source", "service.namespace": "shop", "service.name": "checkout"},
+			expectedTags:       map[string]string{"env": "prod"},
+		},
+		{
+			name:               "disabled",
+			resourceAttributes: map[string]string{"host.name": "my_source", "service.namespace": "shop", "service.name": "checkout"},
+			expectedTags:       map[string]string{"env": "prod", "service": "checkout"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gauge := newMetric("gauge", pmetric.MetricTypeGauge)
+			addDataPoint(432.25, 1640123456, map[string]interface{}{"env": "prod"}, gauge.Gauge().DataPoints())
+			exporterConfig := createDefaultConfig()
+			tobsConfig := exporterConfig.(*Config)
+			tobsConfig.Metrics.AppTagsExcluded = tt.appTagsExcluded
+			tobsConfig.ServiceHierarchy.Enabled = tt.enabled
+			if tt.applicationKey != "" {
+				tobsConfig.ServiceHierarchy.ApplicationKey = tt.applicationKey
+			}
+			metrics := constructMetricsWithTags(tt.resourceAttributes, gauge)
+			sender := &mockGaugeSender{}
+			gaugeConsumer := newGaugeConsumer(sender, componenttest.NewNopTelemetrySettings())
+			consumer := newMetricsConsumer(
+				[]typedMetricConsumer{gaugeConsumer}, &mockFlushCloser{}, false, tobsConfig.Metrics)
+			consumer.serviceHierarchy = tobsConfig.ServiceHierarchy
+			assert.NoError(t, consumer.Consume(context.Background(), metrics))
+
+			assert.Equal(t, []tobsMetric{
+				{
+					Name:   "gauge",
+					Ts:     1640123456,
+					Value:  432.25,
+					Tags:   tt.expectedTags,
+					Source: "my_source",
+				},
+			}, sender.metrics)
+		})
+	}
+}
+
+func TestEndToEndGaugeConsumerWithTagMappings(t *testing.T) {
+	tests := []struct {
+		name                  string
+		resourceAttrsIncluded bool
+		pointAttributes       map[string]interface{}
+		expectedTags          map[string]string
+	}{
+		{
+			name:            "point and resource attributes",
+			pointAttributes: map[string]interface{}{"env": "prod", "http.method": "GET"},
+			expectedTags:    map[string]string{"env": "prod", "method": "GET", "cluster": "east", "namespace": "shop"},
+		},
+		{
+			name:                  "resource attributes included",
+			resourceAttrsIncluded: true,
+			pointAttributes:       map[string]interface{}{"env": "prod"},
+			expectedTags:          map[string]string{"env": "prod", "cluster": "east", "namespace": "shop", "k8s.pod.name": "pod-1"},
+		},
+		{
+			name:            "mapped to the key of another source",
+			pointAttributes: map[string]interface{}{"env": "prod", "k8s.cluster.name": "west"},
+			expectedTags:    map[string]string{"env": "prod", "cluster": "east", "namespace": "shop"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gauge := newMetric("gauge", pmetric.MetricTypeGauge)
+			addDataPoint(432.25, 1640123456, tt.pointAttributes, gauge.Gauge().DataPoints())
+			exporterConfig := createDefaultConfig()
+			tobsConfig := exporterConfig.(*Config)
+			tobsConfig.Metrics.ResourceAttrsIncluded = tt.resourceAttrsIncluded
+			tobsConfig.Metrics.TagMappings = []TagMapping{
+				{Source: "http.method", Target: "method"},
+				{Source: "k8s.cluster.name", Target: "cluster"},
+				{Source: "k8s.namespace.name", Target: "namespace"},
+			}
+			metrics := constructMetricsWithTags(map[string]string{
+				"host.name":          "my_source",
+				"k8s.cluster.name":   "east",
+				"k8s.namespace.name": "shop",
+				"k8s.pod.name":       "pod-1",
+			}, gauge)
+			sender := &mockGaugeSender{}
+			gaugeConsumer := newGaugeConsumer(sender, componenttest.NewNopTelemetrySettings())
+			consumer := newMetricsConsumer(
+				[]typedMetricConsumer{gaugeConsumer}, &mockFlushCloser{}, false, tobsConfig.Metrics)
+			assert.NoError(t, consumer.Consume(context.Background(), metrics))
+
+			assert.Equal(t, []tobsMetric{
+				{
+					Name:   "gauge",
+					Ts:     1640123456,
+					Value:  432.25,
+					Tags:   tt.expectedTags,
+					Source: "my_source",
+				},
+			}, sender.metrics)
+		})
+	}
+}
+
+func TestEndToEndGaugeConsumerWithTimestampGranularity(t *testing.T) {
+	tests := []struct {
+		granularity string
+		expectedTs  int64
+	}{
+		{granularity: "", expectedTs: 1640123456},
+		{granularity: "second", expectedTs: 1640123457},
+		{granularity: "minute", expectedTs: 1640123460},
+	}
+	for _, tt := range tests {
+		t.Run(tt.granularity, func(t *testing.T) {
+			gauge := newMetric("gauge", pmetric.MetricTypeGauge)
+			dataPoint := gauge.Gauge().DataPoints().AppendEmpty()
+			dataPoint.SetDoubleValue(432.25)
+			dataPoint.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(1640123456, 600_000_000)))
+			dataPoint.Attributes().PutStr("env", "prod")
+			tobsConfig := createDefaultConfig().(*Config)
+			tobsConfig.Metrics.TimestampGranularity = tt.granularity
+			metrics := constructMetricsWithTags(map[string]string{"host.name": "my_source"}, gauge)
+			sender := &mockGaugeSender{}
+			gaugeConsumer := newGaugeConsumer(sender, componenttest.NewNopTelemetrySettings())
+			consumer := newMetricsConsumer(
+				[]typedMetricConsumer{gaugeConsumer}, &mockFlushCloser{}, false, tobsConfig.Metrics)
+			assert.NoError(t, consumer.Consume(context.Background(), metrics))
+
+			assert.Equal(t, []tobsMetric{
+				{
+					Name:   "gauge",
+					Ts:     tt.expectedTs,
+					Value:  432.25,
+					Tags:   map[string]string{"env": "prod"},
+					Source: "my_source",
+				},
+			}, sender.metrics)
+		})
+	}
+}
+
+func TestTimestampGranularitySummaryAndHistogram(t *testing.T) {
+	ts := pcommon.NewTimestampFromTime(time.Unix(1640123456, 600_000_000))
+
+	summaryMetric := newMetric("test.summary", pmetric.MetricTypeSummary)
+	summaryPoint := summaryMetric.Summary().DataPoints().AppendEmpty()
+	summaryPoint.SetCount(10)
+	summaryPoint.SetSum(5000.0)
+	summaryPoint.SetTimestamp(ts)
+	summarySender := &mockGaugeSender{}
+	var errs []error
+	newSummaryConsumer(summarySender, componenttest.NewNopTelemetrySettings()).Consume(
+		metricInfo{Metric: summaryMetric, Source: "test_source", TimestampGranularity: time.Minute}, &errs)
+	assert.Empty(t, errs)
+	require.Len(t, summarySender.metrics, 2)
+	for _, m := range summarySender.metrics {
+		assert.Equal(t, int64(1640123460), m.Ts)
+	}
+
+	histogramMetric := newHistogramMetricWithDataPoints("test.histogram", pmetric.AggregationTemporalityCumulative, []int{3})
+	histogramMetric.Histogram().DataPoints().At(0).SetTimestamp(ts)
+	histogramSender := &mockGaugeSender{}
+	newHistogramConsumer(
+		newCumulativeHistogramDataPointConsumer(histogramSender),
+		&mockHistogramDataPointConsumer{},
+		histogramSender,
+		regularHistogram,
+		componenttest.NewNopTelemetrySettings()).Consume(
+		metricInfo{Metric: histogramMetric, Source: "test_source", TimestampGranularity: time.Second}, &errs)
+	assert.Empty(t, errs)
+	require.Len(t, histogramSender.metrics, 3)
+	for _, m := range histogramSender.metrics {
+		assert.Equal(t, int64(1640123457), m.Ts)
+	}
+}
+
+func TestEndToEndGaugeConsumerWithSourceFallbacks(t *testing.T) {
+	tests := []struct {
+		name               string
+		resourceAttributes map[string]string
+		expectedSource     string
+		expectedTags       map[string]string
+	}{
+		{
+			name:               "source attribute",
+			resourceAttributes: map[string]string{"source": "my_source", "host.name": "my_host", "k8s.node.name": "my_node"},
+			expectedSource:     "my_source",
+			expectedTags:       map[string]string{"host.name": "my_host", "k8s.node.name": "my_node"},
+		},
+		{
+			name:               "first fallback",
+			resourceAttributes: map[string]string{"host.name": "my_host", "k8s.node.name": "my_node"},
+			expectedSource:     "my_host",
+			expectedTags:       map[string]string{"k8s.node.name": "my_node"},
+		},
+		{
+			name:               "later fallback",
+			resourceAttributes: map[string]string{"host.id": "my_host_id", "k8s.node.name": "my_node"},
+			expectedSource:     "my_node",
+			expectedTags:       map[string]string{"host.id": "my_host_id"},
+		},
+		{
+			name:               "default",
+			resourceAttributes: map[string]string{"hostname": "my_hostname"},
+			expectedSource:     "my_default",
+			expectedTags:       map[string]string{"hostname": "my_hostname"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gauge := newMetric("gauge", pmetric.MetricTypeGauge)
+			addDataPoint(432.25, 1640123456, nil, gauge.Gauge().DataPoints())
+			exporterConfig := createDefaultConfig()
+			tobsConfig := exporterConfig.(*Config)
+			tobsConfig.Metrics.ResourceAttrsIncluded = true
+			tobsConfig.Metrics.SourceFallbacks = []string{"host.name", "k8s.node.name", "host.id"}
+			tobsConfig.Metrics.SourceDefault = "my_default"
+			metrics := constructMetricsWithTags(tt.resourceAttributes, gauge)
+			sender := &mockGaugeSender{}
+			gaugeConsumer := newGaugeConsumer(sender, componenttest.NewNopTelemetrySettings())
+			consumer := newMetricsConsumer(
+				[]typedMetricConsumer{gaugeConsumer}, &mockFlushCloser{}, false, tobsConfig.Metrics)
+			assert.NoError(t, consumer.Consume(context.Background(), metrics))
+
+			assert.Equal(t, []tobsMetric{
+				{
+					Name:   "gauge",
+					Ts:     1640123456,
+					Value:  432.25,
+					Tags:   tt.expectedTags,
+					Source: tt.expectedSource,
+				},
+			}, sender.metrics)
+		})
+	}
+}
+
+func TestEndToEndSummaryConsumer(t *testing.T) {
+	summaryMetric := newMetric("http.latency", pmetric.MetricTypeSummary)
+	dataPoint := summaryMetric.Summary().DataPoints().AppendEmpty()
+	setQuantileValues(dataPoint, 0.5, 12.5, 0.99, 80.0)
+	dataPoint.Attributes().PutStr("env", "prod")
+	dataPoint.SetCount(40)
+	dataPoint.SetSum(900.0)
+	setDataPointTimestamp(1640123456, dataPoint)
+	tobsConfig := createDefaultConfig().(*Config)
+	metrics := constructMetricsWithTags(map[string]string{"host.name": "my_source"}, summaryMetric)
+	sender := &mockGaugeSender{}
+	summaryConsumer := newSummaryConsumer(sender, componenttest.NewNopTelemetrySettings())
+	consumer := newMetricsConsumer(
+		[]typedMetricConsumer{summaryConsumer}, &mockFlushCloser{}, false, tobsConfig.Metrics)
+	assert.NoError(t, consumer.Consume(context.Background(), metrics))
+
+	// one line per quantile tagged with the quantile, plus the count and the sum
+	assert.ElementsMatch(t, []tobsMetric{
+		{
+			Name:   "http.latency",
+			Ts:     1640123456,
+			Value:  12.5,
+			Tags:   map[string]string{"env": "prod", "quantile": "0.5"},
+			Source: "my_source",
+		},
+		{
+			Name:   "http.latency",
+			Ts:     1640123456,
+			Value:  80.0,
+			Tags:   map[string]string{"env": "prod", "quantile": "0.99"},
+			Source: "my_source",
+		},
+		{
+			Name:   "http.latency_count",
+			Ts:     1640123456,
+			Value:  40.0,
+			Tags:   map[string]string{"env": "prod"},
+			Source: "my_source",
+		},
+		{
+			Name:   "http.latency_sum",
+			Ts:     1640123456,
+			Value:  900.0,
+			Tags:   map[string]string{"env": "prod"},
+			Source: "my_source",
+		},
+	}, sender.metrics)
+}
+
 func TestMetricsConsumerNormal(t *testing.T) {
 	gauge1 := newMetric("gauge1", pmetric.MetricTypeGauge)
 	sum1 := newMetric("sum1", pmetric.MetricTypeSum)
@@ -383,6 +765,101 @@ func TestMetricsConsumerRespectContext(t *testing.T) {
 	assert.Zero(t, mockGaugeConsumer.pushInternalMetricsCallCount)
 }
 
+func TestMetricsConsumerDisabledTypes(t *testing.T) {
+	gauge1 := newMetric("gauge1", pmetric.MetricTypeGauge)
+	histogram1 := newMetric("histogram1", pmetric.MetricTypeHistogram)
+	histogram2 := newMetric("histogram2", pmetric.MetricTypeHistogram)
+	summary1 := newMetric("summary1", pmetric.MetricTypeSummary)
+	exporterConfig := createDefaultConfig()
+	tobsConfig := exporterConfig.(*Config)
+	tobsConfig.Metrics.EnabledTypes = []string{"gauge"}
+
+	mockGaugeConsumer := &mockTypedMetricConsumer{typ: pmetric.MetricTypeGauge}
+	sender := &mockGaugeSender{}
+	settings := componenttest.NewNopTelemetrySettings()
+	consumer := newMetricsConsumer(
+		[]typedMetricConsumer{
+			mockGaugeConsumer,
+			newDisabledTypeConsumer(pmetric.MetricTypeHistogram, sender, settings),
+			newDisabledTypeConsumer(pmetric.MetricTypeSummary, sender, settings),
+		}, nil, true, tobsConfig.Metrics)
+
+	assert.NoError(t, consumer.Consume(context.Background(), constructMetrics(gauge1, histogram1, histogram2, summary1)))
+
+	assert.Equal(t, []string{"gauge1"}, mockGaugeConsumer.names)
+	assert.ElementsMatch(t, []tobsMetric{
+		{
+			Name:  droppedMetricName,
+			Value: 2.0,
+			Tags:  map[string]string{"type": "histogram", "reason": "type_disabled"},
+		},
+		{
+			Name:  droppedMetricName,
+			Value: 1.0,
+			Tags:  map[string]string{"type": "summary", "reason": "type_disabled"},
+		},
+	}, sender.metrics)
+}
+
+func TestMetricsConsumerDropNamePatterns(t *testing.T) {
+	gauge1 := newMetric("gauge1", pmetric.MetricTypeGauge)
+	gauge2 := newMetric("otel.sdk.internal.queue_size", pmetric.MetricTypeGauge)
+	gauge3 := newMetric("debug.gauge3", pmetric.MetricTypeGauge)
+	sum1 := newMetric("otel.sdk.internal.dropped", pmetric.MetricTypeSum)
+	sum2 := newMetric("sum2", pmetric.MetricTypeSum)
+	exporterConfig := createDefaultConfig()
+	tobsConfig := exporterConfig.(*Config)
+	tobsConfig.Metrics.DropNamePatterns = []string{`^otel\.sdk\.internal\.`, `^debug\.`}
+
+	mockGaugeConsumer := &mockTypedMetricConsumer{typ: pmetric.MetricTypeGauge}
+	mockSumConsumer := &mockTypedMetricConsumer{typ: pmetric.MetricTypeSum}
+	sender := &mockGaugeSender{}
+	consumer := newMetricsConsumer(
+		[]typedMetricConsumer{mockGaugeConsumer, mockSumConsumer}, nil, true, tobsConfig.Metrics)
+	consumer.internalMetricsSender = sender
+
+	assert.NoError(t, consumer.Consume(context.Background(), constructMetrics(gauge1, gauge2, gauge3, sum1, sum2)))
+
+	assert.Equal(t, []string{"gauge1"}, mockGaugeConsumer.names)
+	assert.Equal(t, []string{"sum2"}, mockSumConsumer.names)
+	assert.Equal(t, []tobsMetric{
+		{
+			Name:  droppedMetricName,
+			Value: 3.0,
+			Tags:  map[string]string{"reason": "name_filtered"},
+		},
+	}, sender.metrics)
+}
+
+func TestMetricsConsumerNoDropNamePatterns(t *testing.T) {
+	gauge1 := newMetric("otel.sdk.internal.queue_size", pmetric.MetricTypeGauge)
+	exporterConfig := createDefaultConfig()
+	tobsConfig := exporterConfig.(*Config)
+
+	mockGaugeConsumer := &mockTypedMetricConsumer{typ: pmetric.MetricTypeGauge}
+	sender := &mockGaugeSender{}
+	consumer := newMetricsConsumer(
+		[]typedMetricConsumer{mockGaugeConsumer}, nil, true, tobsConfig.Metrics)
+	consumer.internalMetricsSender = sender
+
+	assert.NoError(t, consumer.Consume(context.Background(), constructMetrics(gauge1)))
+
+	assert.Equal(t, []string{"otel.sdk.internal.queue_size"}, mockGaugeConsumer.names)
+	assert.Empty(t, sender.metrics)
+}
+
+func TestDisabledTypeConsumerErrorSending(t *testing.T) {
+	sender := &mockGaugeSender{errorOnSend: true}
+	consumer := newDisabledTypeConsumer(pmetric.MetricTypeSum, sender, componenttest.NewNopTelemetrySettings())
+	var errs []error
+
+	consumer.Consume(metricInfo{Metric: newMetric("sum1", pmetric.MetricTypeSum)}, &errs)
+	assert.Empty(t, errs)
+
+	consumer.PushInternalMetrics(&errs)
+	assert.Len(t, errs, 1)
+}
+
 func TestGaugeConsumerNormal(t *testing.T) {
 	verifyGaugeConsumer(t, false)
 }
@@ -926,7 +1403,7 @@ func TestDeltaHistogramDataPointConsumer(t *testing.T) {
 	histogramDataPoint.Attributes().PutStr("bar", "baz")
 	sender := &mockDistributionSender{}
 	report := newHistogramReporting(componenttest.NewNopTelemetrySettings())
-	consumer := newDeltaHistogramDataPointConsumer(sender)
+	consumer := newDeltaHistogramDataPointConsumer(sender, 0)
 	var errs []error
 
 	consumer.Consume(mi, fromOtelHistogramDataPoint(histogramDataPoint), &errs, report)
@@ -964,7 +1441,7 @@ func TestDeltaHistogramDataPointConsumer_OneBucket(t *testing.T) {
 	setDataPointTimestamp(1641234567, histogramDataPoint)
 	sender := &mockDistributionSender{}
 	report := newHistogramReporting(componenttest.NewNopTelemetrySettings())
-	consumer := newDeltaHistogramDataPointConsumer(sender)
+	consumer := newDeltaHistogramDataPointConsumer(sender, 0)
 	var errs []error
 
 	consumer.Consume(mi, fromOtelHistogramDataPoint(histogramDataPoint), &errs, report)
@@ -997,7 +1474,7 @@ func TestDeltaHistogramDataPointConsumerError(t *testing.T) {
 	histogramDataPoint.BucketCounts().FromRaw([]uint64{5, 1, 3, 2})
 	sender := &mockDistributionSender{errorOnSend: true}
 	report := newHistogramReporting(componenttest.NewNopTelemetrySettings())
-	consumer := newDeltaHistogramDataPointConsumer(sender)
+	consumer := newDeltaHistogramDataPointConsumer(sender, 0)
 	var errs []error
 
 	consumer.Consume(mi, fromOtelHistogramDataPoint(histogramDataPoint), &errs, report)
@@ -1011,7 +1488,7 @@ func TestDeltaHistogramDataPointConsumerMissingBuckets(t *testing.T) {
 	histogramDataPoint := pmetric.NewHistogramDataPoint()
 	sender := &mockDistributionSender{}
 	report := newHistogramReporting(componenttest.NewNopTelemetrySettings())
-	consumer := newDeltaHistogramDataPointConsumer(sender)
+	consumer := newDeltaHistogramDataPointConsumer(sender, 0)
 	var errs []error
 
 	consumer.Consume(mi, fromOtelHistogramDataPoint(histogramDataPoint), &errs, report)
@@ -1021,6 +1498,58 @@ func TestDeltaHistogramDataPointConsumerMissingBuckets(t *testing.T) {
 	assert.Equal(t, int64(1), report.Malformed())
 }
 
+func TestDeltaHistogramDataPointConsumerMaxBuckets(t *testing.T) {
+	metric := newMetric("a.delta.exponential.histogram", pmetric.MetricTypeExponentialHistogram)
+	mi := metricInfo{Metric: metric, Source: "test_source", SourceKey: "host.name"}
+	dataPoint := pmetric.NewExponentialHistogramDataPoint()
+	dataPoint.SetScale(5)
+	counts := make([]uint64, 1000)
+	var total int
+	for i := range counts {
+		counts[i] = uint64(i % 7)
+		total += i % 7
+	}
+	dataPoint.Positive().BucketCounts().FromRaw(counts)
+	dataPoint.SetZeroCount(3)
+	total += 3
+	sender := &mockDistributionSender{}
+	report := newHistogramReporting(componenttest.NewNopTelemetrySettings())
+	consumer := newDeltaHistogramDataPointConsumer(sender, 100)
+	var errs []error
+
+	consumer.Consume(mi, fromOtelExponentialHistogramDataPoint(dataPoint), &errs, report)
+
+	assert.Empty(t, errs)
+	require.Len(t, sender.distributions, 1)
+	centroids := sender.distributions[0].Centroids
+	assert.LessOrEqual(t, len(centroids), 100)
+	var count int
+	for i, centroid := range centroids {
+		count += centroid.Count
+		if i > 0 {
+			assert.Less(t, centroids[i-1].Value, centroid.Value)
+		}
+	}
+	assert.Equal(t, total, count)
+}
+
+func TestMergeCentroids(t *testing.T) {
+	centroids := []histogram.Centroid{
+		{Value: 1.0, Count: 1},
+		{Value: 2.0, Count: 3},
+		{Value: 3.0, Count: 0},
+		{Value: 4.0, Count: 0},
+		{Value: 5.0, Count: 2},
+	}
+	assert.Equal(t, []histogram.Centroid{
+		{Value: 1.75, Count: 4},
+		{Value: 3.5, Count: 0},
+		{Value: 5.0, Count: 2},
+	}, mergeCentroids(centroids, 3))
+	assert.Equal(t, centroids, mergeCentroids(centroids, 5))
+	assert.Equal(t, centroids, mergeCentroids(centroids, 0))
+}
+
 func TestSummaries(t *testing.T) {
 	summaryMetric := newMetric("test.summary", pmetric.MetricTypeSummary)
 	summary := summaryMetric.Summary()
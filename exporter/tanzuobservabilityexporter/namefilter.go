@@ -0,0 +1,68 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tanzuobservabilityexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/tanzuobservabilityexporter"
+
+import (
+	"regexp"
+
+	"go.uber.org/atomic"
+)
+
+// droppedReasonNameFiltered is the reason of the metrics dropped for matching metrics.drop_name_patterns
+const droppedReasonNameFiltered = "name_filtered"
+
+// nameFilter drops the metrics whose name matches one of the metrics.drop_name_patterns and counts how many it dropped.
+type nameFilter struct {
+	patterns []*regexp.Regexp
+	dropped  *atomic.Int64
+	tags     map[string]string
+}
+
+// newNameFilter returns the filter of the given patterns, which were validated with the config, or nil if there is none.
+func newNameFilter(patterns []string) *nameFilter {
+	if len(patterns) == 0 {
+		return nil
+	}
+	f := &nameFilter{
+		dropped: atomic.NewInt64(0),
+		tags:    map[string]string{"reason": droppedReasonNameFiltered},
+	}
+	for _, pattern := range patterns {
+		f.patterns = append(f.patterns, regexp.MustCompile(pattern))
+	}
+	return f
+}
+
+// drop returns true and counts the metric if its name matches one of the patterns. A nil nameFilter drops nothing.
+func (f *nameFilter) drop(name string) bool {
+	if f == nil {
+		return false
+	}
+	for _, pattern := range f.patterns {
+		if pattern.MatchString(name) {
+			f.dropped.Inc()
+			return true
+		}
+	}
+	return false
+}
+
+// pushInternalMetrics sends the number of metrics dropped by the filter. A nil nameFilter sends nothing.
+func (f *nameFilter) pushInternalMetrics(sender gaugeSender, errs *[]error) {
+	if f == nil {
+		return
+	}
+	report(f.dropped, droppedMetricName, f.tags, sender, errs)
+}
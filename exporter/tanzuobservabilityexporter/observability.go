@@ -0,0 +1,235 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tanzuobservabilityexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/tanzuobservabilityexporter"
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/atomic"
+)
+
+const (
+	// exporterKey used to identify exporters in metrics and traces.
+	exporterKey = "exporter"
+	nameSep     = "/"
+
+	signalTraces  = "traces"
+	signalMetrics = "metrics"
+	signalLogs    = "logs"
+
+	// droppedReasonRateLimited is the reason of spans dropped for exceeding traces.max_spans_per_second
+	droppedReasonRateLimited = "rate_limited"
+	// droppedReasonRejected is the reason of points dropped for being rejected by Tanzu Observability
+	droppedReasonRejected = "rejected"
+	// droppedReasonDuplicate is the reason of points dropped by metrics.dedup for being identical to a point of the same flush
+	droppedReasonDuplicate = "duplicate"
+
+	// internalMetricPrefix is prepended to the name of the measure of an internal metric sent by metrics.internal_metrics
+	internalMetricPrefix = "~sdk.otel.collector."
+)
+
+var (
+	exporterNameKey = tag.MustNewKey(exporterKey)
+	signalKey       = tag.MustNewKey("signal")
+	reasonKey       = tag.MustNewKey("reason")
+	typeKey         = tag.MustNewKey("type")
+
+	inflightRequests = stats.Int64("tanzu_inflight_requests", "Number of requests to Tanzu Observability that are in flight", stats.UnitDimensionless)
+	requestLatency   = stats.Float64("tanzu_request_latency", "Latency of the requests to Tanzu Observability", stats.UnitMilliseconds)
+	droppedSpans     = stats.Int64("tanzu_dropped_spans", "Number of spans dropped instead of being sent to Tanzu Observability", stats.UnitDimensionless)
+	droppedPoints    = stats.Int64("tanzu_dropped_points", "Number of points dropped instead of being delivered to Tanzu Observability", stats.UnitDimensionless)
+	busyWorkers      = stats.Int64("tanzu_busy_workers", "Number of workers busy sending data to Tanzu Observability", stats.UnitDimensionless)
+	tagOverflows     = stats.Int64("tanzu_tag_overflows", "Number of tag values replaced for exceeding the maximum number of distinct values of their tag", stats.UnitDimensionless)
+	conversionErrors = stats.Int64("tanzu_conversion_errors", "Number of metrics, data points and spans that failed to be converted for Tanzu Observability", stats.UnitDimensionless)
+
+	inflightRequestsView = fromMeasure(inflightRequests, view.LastValue())
+	requestLatencyView   = fromMeasure(requestLatency, view.Distribution(0, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000))
+	droppedSpansView     = fromMeasure(droppedSpans, view.Sum(), reasonKey)
+	droppedPointsView    = fromMeasure(droppedPoints, view.Sum(), reasonKey)
+	busyWorkersView      = fromMeasure(busyWorkers, view.LastValue())
+	tagOverflowsView     = fromMeasure(tagOverflows, view.Sum())
+	conversionErrorsView = fromMeasure(conversionErrors, view.Sum(), typeKey)
+
+	// internalMetricViews are the views whose rows are sent by metrics.internal_metrics
+	internalMetricViews = []*view.View{inflightRequestsView, requestLatencyView, droppedSpansView, droppedPointsView, busyWorkersView, tagOverflowsView, conversionErrorsView}
+
+	// the views are shared by all exporters, which are told apart by their tags,
+	// as a view with the same name cannot be registered twice
+	registerViewsOnce sync.Once
+	errRegisterViews  error
+)
+
+type opencensusMetrics struct {
+	tags     []tag.Mutator
+	inflight *atomic.Int64
+}
+
+// newOpenCensusMetrics registers the views of the internal telemetry recorded around the
+// requests sent to Tanzu Observability by the exporter of the given instance and signal
+func newOpenCensusMetrics(instanceName string, signal string) (*opencensusMetrics, error) {
+	registerViewsOnce.Do(func() {
+		errRegisterViews = view.Register(internalMetricViews...)
+	})
+	if errRegisterViews != nil {
+		return nil, errRegisterViews
+	}
+	return &opencensusMetrics{
+		tags: []tag.Mutator{
+			tag.Upsert(exporterNameKey, instanceName),
+			tag.Upsert(signalKey, signal),
+		},
+		inflight: atomic.NewInt64(0),
+	}, nil
+}
+
+func fromMeasure(measure stats.Measure, agg *view.Aggregation, tagKeys ...tag.Key) *view.View {
+	return &view.View{
+		Name:        buildExporterCustomMetricName(measure.Name()),
+		Description: measure.Description(),
+		Measure:     measure,
+		Aggregation: agg,
+		TagKeys:     append([]tag.Key{exporterNameKey, signalKey}, tagKeys...),
+	}
+}
+
+func buildExporterCustomMetricName(metric string) string {
+	return exporterKey + nameSep + exporterType + nameSep + metric
+}
+
+// recordRequest calls send, recording it as an in flight request while it runs and its latency once it returns.
+func (m *opencensusMetrics) recordRequest(send func() error) error {
+	m.recordInflightRequests(m.inflight.Inc())
+	start := time.Now()
+	err := send()
+	latency := float64(time.Since(start)) / float64(time.Millisecond)
+	m.recordInflightRequests(m.inflight.Dec())
+	_ = stats.RecordWithTags(context.Background(), m.tags, requestLatency.M(latency))
+	return err
+}
+
+func (m *opencensusMetrics) recordInflightRequests(count int64) {
+	_ = stats.RecordWithTags(context.Background(), m.tags, inflightRequests.M(count))
+}
+
+// recordBusyWorkers records the number of workers that are busy sending data.
+func (m *opencensusMetrics) recordBusyWorkers(count int64) {
+	_ = stats.RecordWithTags(context.Background(), m.tags, busyWorkers.M(count))
+}
+
+// recordTagOverflow increments the number of tag values replaced for exceeding the cardinality of their tag.
+func (m *opencensusMetrics) recordTagOverflow() {
+	_ = stats.RecordWithTags(context.Background(), m.tags, tagOverflows.M(1))
+}
+
+// recordConversionError increments the number of conversion failures of the given OTLP metric type or span kind.
+// A nil opencensusMetrics records nothing.
+func (m *opencensusMetrics) recordConversionError(dataType string) {
+	if m == nil {
+		return
+	}
+	mutators := append([]tag.Mutator{tag.Upsert(typeKey, dataType)}, m.tags...)
+	_ = stats.RecordWithTags(context.Background(), mutators, conversionErrors.M(1))
+}
+
+// recordDroppedSpan increments the number of spans dropped for the given reason.
+func (m *opencensusMetrics) recordDroppedSpan(reason string) {
+	mutators := append([]tag.Mutator{tag.Upsert(reasonKey, reason)}, m.tags...)
+	_ = stats.RecordWithTags(context.Background(), mutators, droppedSpans.M(1))
+}
+
+// recordDroppedPoint increments the number of points dropped for the given reason.
+func (m *opencensusMetrics) recordDroppedPoint(reason string) {
+	mutators := append([]tag.Mutator{tag.Upsert(reasonKey, reason)}, m.tags...)
+	_ = stats.RecordWithTags(context.Background(), mutators, droppedPoints.M(1))
+}
+
+// internalMetricPoint is a row of an internal metric view as a Wavefront point
+type internalMetricPoint struct {
+	name  string
+	value float64
+	tags  map[string]string
+}
+
+// internalMetricPoints returns the points of the internal metrics recorded by the exporters of the given instance,
+// whatever their signal, tagged with the tags of their rows. Distributions are sent as their count and sum. Tags
+// with an empty value, such as the exporter of an unnamed instance, are left out as Wavefront rejects them.
+func internalMetricPoints(instanceName string) []internalMetricPoint {
+	var points []internalMetricPoint
+	for _, v := range internalMetricViews {
+		rows, err := view.RetrieveData(v.Name)
+		if err != nil {
+			// the view is not registered if no exporter was created
+			continue
+		}
+		name := internalMetricPrefix + v.Measure.Name()
+		for _, row := range rows {
+			var instance string
+			tags := make(map[string]string, len(row.Tags))
+			for _, t := range row.Tags {
+				if t.Key == exporterNameKey {
+					instance = t.Value
+				}
+				if t.Value != "" {
+					tags[t.Key.Name()] = t.Value
+				}
+			}
+			if instance != instanceName {
+				continue
+			}
+			switch data := row.Data.(type) {
+			case *view.SumData:
+				points = append(points, internalMetricPoint{name: name, value: data.Value, tags: tags})
+			case *view.LastValueData:
+				points = append(points, internalMetricPoint{name: name, value: data.Value, tags: tags})
+			case *view.CountData:
+				points = append(points, internalMetricPoint{name: name, value: float64(data.Value), tags: tags})
+			case *view.DistributionData:
+				points = append(points,
+					internalMetricPoint{name: name + ".count", value: float64(data.Count), tags: tags},
+					internalMetricPoint{name: name + ".sum", value: data.Mean * float64(data.Count), tags: tags})
+			}
+		}
+	}
+	return points
+}
+
+// observedFlushCloser records every flush of the wrapped flushCloser, which sends
+// the buffered data to Tanzu Observability, as a request. The Wavefront SDK does not
+// expose its HTTP client, so the HTTP requests of a flush, one for each kind of data
+// buffered such as points and distributions, are observed together as one request.
+type observedFlushCloser struct {
+	flushCloser
+	metrics *opencensusMetrics
+}
+
+func (o *observedFlushCloser) Flush() error {
+	return o.metrics.recordRequest(o.flushCloser.Flush)
+}
+
+// observedSpanSender records every flush of the wrapped spanSender, which sends
+// the buffered spans and span logs to Tanzu Observability, as a request.
+type observedSpanSender struct {
+	spanSender
+	metrics *opencensusMetrics
+}
+
+func (o *observedSpanSender) Flush() error {
+	return o.metrics.recordRequest(o.spanSender.Flush)
+}
@@ -0,0 +1,157 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tanzuobservabilityexporter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+)
+
+func TestObservedFlushCloserRecordsRequests(t *testing.T) {
+	metrics, err := newOpenCensusMetrics(t.Name(), signalMetrics)
+	require.NoError(t, err)
+
+	flushing := make(chan struct{})
+	release := make(chan struct{})
+	sender := &observedFlushCloser{
+		flushCloser: &blockingFlushCloser{flushing: flushing, release: release},
+		metrics:     metrics,
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- sender.Flush()
+	}()
+	<-flushing
+	assert.Equal(t, float64(1), inflightRequestsValue(t, t.Name()))
+
+	close(release)
+	assert.NoError(t, <-done)
+	assert.Equal(t, float64(0), inflightRequestsValue(t, t.Name()))
+	assert.Equal(t, int64(1), requestLatencyCount(t, t.Name()))
+}
+
+func TestObservedSpanSenderRecordsFailedRequests(t *testing.T) {
+	metrics, err := newOpenCensusMetrics(t.Name(), signalTraces)
+	require.NoError(t, err)
+
+	sender := &observedSpanSender{
+		spanSender: &failingFlushSender{},
+		metrics:    metrics,
+	}
+	assert.Error(t, sender.Flush())
+	assert.Error(t, sender.Flush())
+
+	assert.Equal(t, float64(0), inflightRequestsValue(t, t.Name()))
+	assert.Equal(t, int64(2), requestLatencyCount(t, t.Name()))
+}
+
+type blockingFlushCloser struct {
+	flushing chan struct{}
+	release  chan struct{}
+}
+
+func (b *blockingFlushCloser) Flush() error {
+	close(b.flushing)
+	<-b.release
+	return nil
+}
+
+func (b *blockingFlushCloser) Close() {}
+
+type failingFlushSender struct {
+	mockSender
+}
+
+func (f *failingFlushSender) Flush() error {
+	return errors.New("flush failed")
+}
+
+func inflightRequestsValue(t *testing.T, instanceName string) float64 {
+	data := retrieveInstanceData(t, inflightRequestsView.Name, instanceName)
+	require.NotNil(t, data)
+	return data.(*view.LastValueData).Value
+}
+
+func busyWorkersValue(t *testing.T, instanceName string) float64 {
+	data := retrieveInstanceData(t, busyWorkersView.Name, instanceName)
+	require.NotNil(t, data)
+	return data.(*view.LastValueData).Value
+}
+
+func requestLatencyCount(t *testing.T, instanceName string) int64 {
+	data := retrieveInstanceData(t, requestLatencyView.Name, instanceName)
+	require.NotNil(t, data)
+	return data.(*view.DistributionData).Count
+}
+
+func droppedSpansValue(t *testing.T, instanceName string, reason string) float64 {
+	rows, err := view.RetrieveData(droppedSpansView.Name)
+	require.NoError(t, err)
+	for _, row := range rows {
+		var instance, rowReason string
+		for _, tag := range row.Tags {
+			switch tag.Key {
+			case exporterNameKey:
+				instance = tag.Value
+			case reasonKey:
+				rowReason = tag.Value
+			}
+		}
+		if instance == instanceName && rowReason == reason {
+			return row.Data.(*view.SumData).Value
+		}
+	}
+	return 0
+}
+
+func conversionErrorsValue(t *testing.T, instanceName string, signal string, dataType string) float64 {
+	rows, err := view.RetrieveData(conversionErrorsView.Name)
+	require.NoError(t, err)
+	for _, row := range rows {
+		var instance, rowSignal, rowType string
+		for _, tag := range row.Tags {
+			switch tag.Key {
+			case exporterNameKey:
+				instance = tag.Value
+			case signalKey:
+				rowSignal = tag.Value
+			case typeKey:
+				rowType = tag.Value
+			}
+		}
+		if instance == instanceName && rowSignal == signal && rowType == dataType {
+			return row.Data.(*view.SumData).Value
+		}
+	}
+	return 0
+}
+
+func retrieveInstanceData(t *testing.T, viewName string, instanceName string) view.AggregationData {
+	rows, err := view.RetrieveData(viewName)
+	require.NoError(t, err)
+	for _, row := range rows {
+		for _, tag := range row.Tags {
+			if tag.Key == exporterNameKey && tag.Value == instanceName {
+				return row.Data
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tanzuobservabilityexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/tanzuobservabilityexporter"
+
+import (
+	"errors"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wavefronthq/wavefront-sdk-go/histogram"
+	"github.com/wavefronthq/wavefront-sdk-go/senders"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+const (
+	deltaPrefix    = "∆"
+	altDeltaPrefix = "Δ"
+)
+
+// metricsSender is the part of the Wavefront sender that sends metrics
+type metricsSender interface {
+	senders.MetricSender
+	senders.DistributionSender
+	flushCloser
+}
+
+// sentPoint is a point that was sent since the last flush, formatted as a line in the Wavefront data format
+type sentPoint struct {
+	name string
+	line string
+}
+
+// partitioningSender buffers the points sent since the last flush and reports them itself so that a
+// batch rejected by the backend because of a malformed line is not lost as a whole. The Wavefront
+// protocol does not tell which line was rejected, so the batch is bisected and the halves are reported
+// on their own until the points that are rejected on their own are found, which are dropped while all
+// other points are delivered. The distributions are sent with the Wavefront sender.
+type partitioningSender struct {
+	sender   metricsSender
+	reporter *lineReporter
+	// defaultSource is the source of the points sent without one, the host name like in the Wavefront SDK
+	defaultSource string
+	points        []sentPoint
+	logger        *zap.Logger
+	metrics       *opencensusMetrics
+	// dedup drops the points that are identical to a point sent since the last flush
+	dedup bool
+	// sentKeys holds the keys of the points sent since the last flush, it is only kept if dedup is set
+	sentKeys map[string]struct{}
+}
+
+func newPartitioningSender(sender metricsSender, reporter *lineReporter, logger *zap.Logger) *partitioningSender {
+	defaultSource, err := os.Hostname()
+	if err != nil {
+		defaultSource = "wavefront_direct_sender"
+	}
+	return &partitioningSender{sender: sender, reporter: reporter, defaultSource: defaultSource, logger: logger}
+}
+
+func (p *partitioningSender) SendMetric(name string, value float64, ts int64, source string, tags map[string]string) error {
+	if p.dedup {
+		key := pointKey(name, value, ts, source, tags)
+		if _, ok := p.sentKeys[key]; ok {
+			p.metrics.recordDroppedPoint(droppedReasonDuplicate)
+			return nil
+		}
+		if p.sentKeys == nil {
+			p.sentKeys = map[string]struct{}{}
+		}
+		p.sentKeys[key] = struct{}{}
+	}
+	return p.add(name, value, ts, source, tags)
+}
+
+// SendDeltaCounter sends a delta counter like the Wavefront SDK does, prefixing its name with ∆
+// and leaving the timestamp to the backend. Delta counters that are not positive are not sent.
+func (p *partitioningSender) SendDeltaCounter(name string, value float64, source string, tags map[string]string) error {
+	if name == "" {
+		return errors.New("empty metric name")
+	}
+	if !strings.HasPrefix(name, deltaPrefix) && !strings.HasPrefix(name, altDeltaPrefix) {
+		name = deltaPrefix + name
+	}
+	if value <= 0 {
+		return nil
+	}
+	return p.add(name, value, 0, source, tags)
+}
+
+func (p *partitioningSender) SendDistribution(name string, centroids []histogram.Centroid, hgs map[histogram.Granularity]bool, ts int64, source string, tags map[string]string) error {
+	return p.sender.SendDistribution(name, centroids, hgs, ts, source, tags)
+}
+
+func (p *partitioningSender) add(name string, value float64, ts int64, source string, tags map[string]string) error {
+	line, err := metricLine(name, value, ts, source, tags, p.defaultSource)
+	if err != nil {
+		return err
+	}
+	p.points = append(p.points, sentPoint{name: name, line: line})
+	return nil
+}
+
+// Flush reports the points sent since the last flush in batches and flushes the Wavefront sender.
+// The points of a batch rejected by the backend are resent without the rejected ones. The points
+// of a batch that fails to be reported for any other reason are not kept, the flush failing so
+// that the metrics are retried by the pipeline.
+func (p *partitioningSender) Flush() error {
+	points := p.points
+	p.points = nil
+	p.sentKeys = nil
+	var errs error
+	for len(points) > 0 {
+		n := len(points)
+		if n > reportBatchSize {
+			n = reportBatchSize
+		}
+		errs = multierr.Append(errs, p.report(points[:n], true))
+		points = points[n:]
+	}
+	return multierr.Append(errs, p.sender.Flush())
+}
+
+func (p *partitioningSender) Close() {
+	p.sender.Close()
+}
+
+// report reports the points. If they are rejected they are split in halves that
+// are reported on their own, a point that is rejected on its own is dropped.
+func (p *partitioningSender) report(points []sentPoint, first bool) error {
+	lines := make([]string, len(points))
+	for i, point := range points {
+		lines[i] = point.line
+	}
+	err := p.reporter.report(lines)
+	if !errors.Is(err, errRejectedBatch) {
+		return err
+	}
+	if len(points) == 1 {
+		p.logger.Warn("Dropping point rejected by Tanzu Observability", zap.String(metricNameString, points[0].name))
+		p.metrics.recordDroppedPoint(droppedReasonRejected)
+		return nil
+	}
+	if first {
+		p.logger.Warn("Tanzu Observability rejected a batch of points, resending it without the rejected points", zap.Int("points", len(points)))
+	}
+	half := len(points) / 2
+	return multierr.Combine(p.report(points[:half], false), p.report(points[half:], false))
+}
+
+// pointKey identifies a point by its name, value, timestamp, source and tags. The tags are sorted by key
+// so that points with the same tags have the same key whatever the order the map is iterated in.
+func pointKey(name string, value float64, ts int64, source string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteByte(0)
+	b.WriteString(strconv.FormatUint(math.Float64bits(value), 16))
+	b.WriteByte(0)
+	b.WriteString(strconv.FormatInt(ts, 10))
+	b.WriteByte(0)
+	b.WriteString(source)
+	for _, key := range keys {
+		b.WriteByte(0)
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(tags[key])
+	}
+	return b.String()
+}
@@ -0,0 +1,282 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tanzuobservabilityexporter
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavefronthq/wavefront-sdk-go/senders"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+)
+
+func TestPartitioningSenderDeliversGoodPointsOfRejectedBatch(t *testing.T) {
+	proxy := newLineCountingProxy()
+	defer proxy.Close()
+	p := newTestPartitioningSender(t, proxy)
+
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("good.%d", i)
+		if i == 6 {
+			name = rejectedPointName
+		}
+		require.NoError(t, p.SendMetric(name, float64(i), 1000, "host", map[string]string{"env": "prod"}))
+	}
+	require.NoError(t, p.SendDeltaCounter("good.delta", 3, "host", nil))
+	require.NoError(t, p.Flush())
+
+	wantDelivered := map[string]int{
+		"good.0": 1, "good.1": 1, "good.2": 1, "good.3": 1, "good.4": 1,
+		"good.5": 1, "good.7": 1, "good.8": 1, "good.9": 1, "∆good.delta": 1,
+	}
+	assert.Equal(t, wantDelivered, proxy.deliveredLines())
+	assert.Equal(t, float64(1), droppedPointsValue(t, t.Name(), droppedReasonRejected))
+
+	// the rejected point is not retried with the points of the next flush
+	require.NoError(t, p.SendMetric("good.10", 10, 2000, "host", nil))
+	require.NoError(t, p.Flush())
+	wantDelivered["good.10"] = 1
+	assert.Equal(t, wantDelivered, proxy.deliveredLines())
+	assert.Equal(t, float64(1), droppedPointsValue(t, t.Name(), droppedReasonRejected))
+
+	// nothing is left buffered to be sent again on close
+	p.Close()
+	assert.Equal(t, wantDelivered, proxy.deliveredLines())
+}
+
+func TestPartitioningSenderReportsInBatches(t *testing.T) {
+	proxy := newLineCountingProxy()
+	defer proxy.Close()
+	p := newTestPartitioningSender(t, proxy)
+
+	want := map[string]int{}
+	for i := 0; i < reportBatchSize+10; i++ {
+		name := fmt.Sprintf("good.%d", i)
+		if i == reportBatchSize+5 {
+			name = rejectedPointName
+		} else {
+			want[name] = 1
+		}
+		require.NoError(t, p.SendMetric(name, float64(i), 1000, "host", nil))
+	}
+	require.NoError(t, p.Flush())
+	p.Close()
+
+	assert.Equal(t, want, proxy.deliveredLines())
+	assert.LessOrEqual(t, proxy.maxBatchLines(), reportBatchSize)
+	assert.Equal(t, float64(1), droppedPointsValue(t, t.Name(), droppedReasonRejected))
+}
+
+func TestPartitioningSenderDoesNotResendOtherErrors(t *testing.T) {
+	proxy := newLineCountingProxy()
+	defer proxy.Close()
+	proxy.status = http.StatusServiceUnavailable
+	p := newTestPartitioningSender(t, proxy)
+
+	require.NoError(t, p.SendMetric("good.0", 0, 1000, "host", nil))
+	assert.ErrorContains(t, p.Flush(), "status=503")
+	assert.Equal(t, 1, proxy.requestCount())
+	assert.Empty(t, proxy.deliveredLines())
+	assert.Equal(t, float64(0), droppedPointsValue(t, t.Name(), droppedReasonRejected))
+
+	// the points of the failed flush are retried by the pipeline, not by the sender
+	proxy.setStatus(http.StatusOK)
+	require.NoError(t, p.Flush())
+	p.Close()
+	assert.Empty(t, proxy.deliveredLines())
+}
+
+func TestPartitioningSenderSkipsDeltaCountersThatAreNotPositive(t *testing.T) {
+	proxy := newLineCountingProxy()
+	defer proxy.Close()
+	p := newTestPartitioningSender(t, proxy)
+
+	require.NoError(t, p.SendDeltaCounter("zero", 0, "host", nil))
+	require.NoError(t, p.SendDeltaCounter("∆prefixed", 1, "host", nil))
+	assert.Error(t, p.SendDeltaCounter("", 1, "host", nil))
+	require.NoError(t, p.Flush())
+	p.Close()
+	assert.Equal(t, map[string]int{"∆prefixed": 1}, proxy.deliveredLines())
+}
+
+func TestPartitioningSenderDedup(t *testing.T) {
+	proxy := newLineCountingProxy()
+	defer proxy.Close()
+	p := newTestPartitioningSender(t, proxy)
+	p.dedup = true
+
+	require.NoError(t, p.SendMetric("cpu", 1, 1000, "host", map[string]string{"env": "prod", "region": "us"}))
+	// identical to the first point, the tags being built in another order
+	require.NoError(t, p.SendMetric("cpu", 1, 1000, "host", map[string]string{"region": "us", "env": "prod"}))
+	// distinct in one of name, value, timestamp, source and tags each
+	require.NoError(t, p.SendMetric("mem", 1, 1000, "host", map[string]string{"env": "prod", "region": "us"}))
+	require.NoError(t, p.SendMetric("cpu", 2, 1000, "host", map[string]string{"env": "prod", "region": "us"}))
+	require.NoError(t, p.SendMetric("cpu", 1, 2000, "host", map[string]string{"env": "prod", "region": "us"}))
+	require.NoError(t, p.SendMetric("cpu", 1, 1000, "other", map[string]string{"env": "prod", "region": "us"}))
+	require.NoError(t, p.SendMetric("cpu", 1, 1000, "host", map[string]string{"env": "dev", "region": "us"}))
+	// delta counters add up, so they are never duplicates
+	require.NoError(t, p.SendDeltaCounter("requests", 1, "host", nil))
+	require.NoError(t, p.SendDeltaCounter("requests", 1, "host", nil))
+	require.NoError(t, p.Flush())
+
+	assert.Equal(t, map[string]int{"cpu": 5, "mem": 1, "∆requests": 2}, proxy.deliveredLines())
+	assert.Equal(t, float64(1), droppedPointsValue(t, t.Name(), droppedReasonDuplicate))
+
+	// points are only duplicates within the same flush
+	require.NoError(t, p.SendMetric("cpu", 1, 1000, "host", map[string]string{"env": "prod", "region": "us"}))
+	require.NoError(t, p.Flush())
+	p.Close()
+	assert.Equal(t, map[string]int{"cpu": 6, "mem": 1, "∆requests": 2}, proxy.deliveredLines())
+	assert.Equal(t, float64(1), droppedPointsValue(t, t.Name(), droppedReasonDuplicate))
+}
+
+func TestPartitioningSenderWithoutDedup(t *testing.T) {
+	proxy := newLineCountingProxy()
+	defer proxy.Close()
+	p := newTestPartitioningSender(t, proxy)
+
+	require.NoError(t, p.SendMetric("cpu", 1, 1000, "host", nil))
+	require.NoError(t, p.SendMetric("cpu", 1, 1000, "host", nil))
+	require.NoError(t, p.Flush())
+	p.Close()
+	assert.Equal(t, map[string]int{"cpu": 2}, proxy.deliveredLines())
+	assert.Equal(t, float64(0), droppedPointsValue(t, t.Name(), droppedReasonDuplicate))
+}
+
+func newTestPartitioningSender(t *testing.T, proxy *lineCountingProxy) *partitioningSender {
+	metrics, err := newOpenCensusMetrics(t.Name(), signalMetrics)
+	require.NoError(t, err)
+	reporter, err := newLineReporter(proxy.URL, proxy.Client())
+	require.NoError(t, err)
+	sender, err := senders.NewSender(proxy.URL, senders.FlushIntervalSeconds(3600))
+	require.NoError(t, err)
+	p := newPartitioningSender(sender, reporter, zap.NewNop())
+	p.metrics = metrics
+	return p
+}
+
+// rejectedPointName is the name of the points the lineCountingProxy rejects as malformed
+const rejectedPointName = "bad"
+
+// lineCountingProxy is a Wavefront proxy that counts every metric line it accepts by the name of the
+// metric. Like the Wavefront proxy, it rejects a whole batch with 400 Bad Request if a line of the batch
+// is malformed, which is a point named rejectedPointName here.
+type lineCountingProxy struct {
+	*httptest.Server
+	mu        sync.Mutex
+	status    int
+	requests  int
+	maxLines  int
+	delivered map[string]int
+}
+
+func newLineCountingProxy() *lineCountingProxy {
+	p := &lineCountingProxy{delivered: map[string]int{}}
+	p.Server = httptest.NewServer(http.HandlerFunc(p.handle))
+	return p
+}
+
+func (p *lineCountingProxy) handle(w http.ResponseWriter, r *http.Request) {
+	zr, err := gzip.NewReader(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var names []string
+	scanner := bufio.NewScanner(zr)
+	for scanner.Scan() {
+		name, _, _ := strings.Cut(strings.TrimPrefix(scanner.Text(), `"`), `"`)
+		names = append(names, name)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests++
+	if p.status != 0 && p.status != http.StatusOK {
+		w.WriteHeader(p.status)
+		return
+	}
+	for _, name := range names {
+		if name == rejectedPointName {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	if len(names) > p.maxLines {
+		p.maxLines = len(names)
+	}
+	for _, name := range names {
+		// the internal metrics of the Wavefront SDK are not counted
+		if !strings.HasPrefix(name, "~sdk") {
+			p.delivered[name]++
+		}
+	}
+}
+
+func (p *lineCountingProxy) setStatus(status int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status = status
+}
+
+func (p *lineCountingProxy) requestCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.requests
+}
+
+func (p *lineCountingProxy) maxBatchLines() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.maxLines
+}
+
+func (p *lineCountingProxy) deliveredLines() map[string]int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delivered := make(map[string]int, len(p.delivered))
+	for name, count := range p.delivered {
+		delivered[name] = count
+	}
+	return delivered
+}
+func droppedPointsValue(t *testing.T, instanceName string, reason string) float64 {
+	rows, err := view.RetrieveData(droppedPointsView.Name)
+	require.NoError(t, err)
+	for _, row := range rows {
+		var instance, rowReason string
+		for _, tag := range row.Tags {
+			switch tag.Key {
+			case exporterNameKey:
+				instance = tag.Value
+			case reasonKey:
+				rowReason = tag.Value
+			}
+		}
+		if instance == instanceName && rowReason == reason {
+			return row.Data.(*view.SumData).Value
+		}
+	}
+	return 0
+}
@@ -0,0 +1,177 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tanzuobservabilityexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/tanzuobservabilityexporter"
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// reportBatchSize is the maximum number of lines reported in one request, the same as the Wavefront SDK's
+const reportBatchSize = 10000
+
+// errRejectedBatch is returned by the lineReporter when the backend rejects a batch as malformed
+var errRejectedBatch = errors.New("batch rejected by Tanzu Observability")
+
+// lineReporter posts lines in the Wavefront data format to the report endpoint of a Wavefront proxy,
+// as the Wavefront SDK does, without buffering the lines of the batches that fail to be reported.
+type lineReporter struct {
+	reportURL string
+	token     string
+	client    *http.Client
+}
+
+func newLineReporter(endpoint string, client *http.Client) (*lineReporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+	default:
+		return nil, fmt.Errorf("invalid scheme %q in %q", u.Scheme, endpoint)
+	}
+	r := &lineReporter{client: client}
+	if u.User != nil {
+		r.token = u.User.String()
+		u.User = nil
+	}
+	u.Path = "/report"
+	u.RawQuery = url.Values{"f": []string{"wavefront"}}.Encode()
+	r.reportURL = u.String()
+	if r.client == nil {
+		r.client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return r, nil
+}
+
+// report posts the lines in a single request. It returns errRejectedBatch if the backend responds
+// with 400 Bad Request, which is how it rejects a batch holding a malformed line.
+func (r *lineReporter) report(lines []string) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	for _, line := range lines {
+		if _, err := io.WriteString(zw, line); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, r.reportURL, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "gzip")
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error reporting wavefront format data to Wavefront: %w", err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusBadRequest:
+		return errRejectedBatch
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("error reporting wavefront format data to Wavefront. status=%d", resp.StatusCode)
+	}
+	return nil
+}
+
+// metricLine formats a point as a line in the Wavefront data format like senders.MetricLine
+// does, except that the tags are written in sorted key order so that the same point is
+// always serialized to the same line.
+func metricLine(name string, value float64, ts int64, source string, tags map[string]string, defaultSource string) (string, error) {
+	if name == "" {
+		return "", errors.New("empty metric name")
+	}
+	if source == "" {
+		source = defaultSource
+	}
+	keys := make([]string, 0, len(tags))
+	for key, value := range tags {
+		if value == "" {
+			return "", fmt.Errorf("tag values cannot be empty: metric=%s tag=%s", name, key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(strconv.Quote(sanitizeName(name)))
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+	if ts != 0 {
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatInt(ts, 10))
+	}
+	b.WriteString(" source=")
+	b.WriteString(sanitizeValue(source))
+	for _, key := range keys {
+		b.WriteByte(' ')
+		b.WriteString(strconv.Quote(sanitizeName(key)))
+		b.WriteByte('=')
+		b.WriteString(sanitizeValue(tags[key]))
+	}
+	b.WriteByte('\n')
+	return b.String(), nil
+}
+
+// sanitizeName replaces the characters that are not allowed in metric names and tag keys with
+// a dash as the Wavefront SDK does, keeping the delta prefix and the tilde of internal metrics.
+func sanitizeName(name string) string {
+	var b strings.Builder
+	rest := name
+	for _, prefix := range []string{deltaPrefix, altDeltaPrefix} {
+		if strings.HasPrefix(rest, prefix) {
+			b.WriteString(prefix)
+			rest = rest[len(prefix):]
+			break
+		}
+	}
+	if strings.HasPrefix(rest, "~") {
+		b.WriteByte('~')
+		rest = rest[1:]
+	}
+	for i := 0; i < len(rest); i++ {
+		c := rest[i]
+		if (',' <= c && c <= '9') || ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || c == '_' {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// sanitizeValue quotes a source or tag value as the Wavefront SDK does, escaping its quotes and line breaks.
+func sanitizeValue(value string) string {
+	value = strings.TrimSpace(value)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + strings.ReplaceAll(value, "\n", `\n`) + `"`
+}
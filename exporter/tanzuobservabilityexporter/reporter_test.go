@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tanzuobservabilityexporter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavefronthq/wavefront-sdk-go/senders"
+)
+
+func TestLineReporterRequest(t *testing.T) {
+	var req *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+	}))
+	defer server.Close()
+
+	reporter, err := newLineReporter(strings.Replace(server.URL, "http://", "http://token@", 1), server.Client())
+	require.NoError(t, err)
+	require.NoError(t, reporter.report([]string{"\"cpu\" 1 source=\"host\"\n"}))
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, "/report", req.URL.Path)
+	assert.Equal(t, "wavefront", req.URL.Query().Get("f"))
+	assert.Equal(t, "gzip", req.Header.Get("Content-Encoding"))
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+
+	_, err = newLineReporter("tcp://localhost:2878", nil)
+	assert.Error(t, err)
+}
+
+func TestMetricLineIsDeterministic(t *testing.T) {
+	tags := map[string]string{"service": "checkout", "application": "shop", "env": "prod", "cluster": "none", "shard": "none"}
+	want := `"test.metric" 1 1000 source="test-source" "application"="shop" "cluster"="none" "env"="prod" "service"="checkout" "shard"="none"` + "\n"
+	for i := 0; i < 20; i++ {
+		line, err := metricLine("test.metric", 1, 1000, "test-source", tags, "")
+		require.NoError(t, err)
+		assert.Equal(t, want, line)
+	}
+}
+
+func TestMetricLineMatchesSDK(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  float64
+		ts     int64
+		source string
+		tags   map[string]string
+	}{
+		{name: "test.metric", value: 1.5, ts: 1000, source: "host"},
+		{name: "∆~delta counter", value: 2, source: "host", tags: map[string]string{"key with spaces": "value"}},
+		{name: "Δdelta", value: 2, source: "", tags: map[string]string{"k": "  quoted \"value\"\nwith a line break "}},
+		{name: "~internal/metric", value: -3e-7, ts: 2000, source: "host", tags: map[string]string{"émoji": "✓"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// with at most one tag the tag order of the SDK does not matter
+			want, err := senders.MetricLine(tt.name, tt.value, tt.ts, tt.source, tt.tags, "default")
+			require.NoError(t, err)
+			line, err := metricLine(tt.name, tt.value, tt.ts, tt.source, tt.tags, "default")
+			require.NoError(t, err)
+			assert.Equal(t, want, line)
+		})
+	}
+}
+
+func TestMetricLineErrors(t *testing.T) {
+	_, err := metricLine("", 1, 0, "host", nil, "")
+	assert.Error(t, err)
+	_, err = metricLine("test.metric", 1, 0, "host", map[string]string{"env": ""}, "")
+	assert.ErrorContains(t, err, "tag values cannot be empty")
+}
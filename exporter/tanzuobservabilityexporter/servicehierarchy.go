@@ -0,0 +1,40 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tanzuobservabilityexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/tanzuobservabilityexporter"
+
+import (
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// applyTags sets the `application` and `service` tags that are missing from the given tags to the values
+// of the ApplicationKey and ServiceKey resource attributes. The tags are left as they are if disabled.
+func (c ServiceHierarchyConfig) applyTags(resAttrs pcommon.Map, tags map[string]string) {
+	if !c.Enabled {
+		return
+	}
+	// the `service.name` tag becomes the `service` tag first, so that it is not sent as both tags
+	fixServiceTag(tags)
+	setTagFromAttribute(tags, labelApplication, resAttrs, c.ApplicationKey)
+	setTagFromAttribute(tags, labelService, resAttrs, c.ServiceKey)
+}
+
+func setTagFromAttribute(tags map[string]string, tagKey string, attrs pcommon.Map, attrKey string) {
+	if _, ok := tags[tagKey]; ok || attrKey == "" {
+		return
+	}
+	if value, ok := attrs.Get(attrKey); ok && value.AsString() != "" {
+		tags[tagKey] = value.AsString()
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tanzuobservabilityexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/tanzuobservabilityexporter"
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/wavefronthq/wavefront-sdk-go/senders"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+)
+
+// tagAllowlist limits the span attributes that become tags of the spans sent to Tanzu Observability
+// to the configured keys. The tags derived from the resource, the status and the kind of the span
+// are not affected.
+type tagAllowlist struct {
+	keys   map[string]struct{}
+	logger *zap.Logger
+}
+
+// newTagAllowlist returns the allowlist of the given keys, or nil if all span attributes are kept.
+func newTagAllowlist(keys []string, logger *zap.Logger) *tagAllowlist {
+	if len(keys) == 0 {
+		return nil
+	}
+	a := &tagAllowlist{keys: make(map[string]struct{}, len(keys)), logger: logger}
+	for _, key := range keys {
+		a.keys[key] = struct{}{}
+	}
+	return a
+}
+
+// filter returns the allowed attributes of a span. A nil tagAllowlist returns the attributes as they are.
+func (a *tagAllowlist) filter(attributes pcommon.Map) pcommon.Map {
+	if a == nil {
+		return attributes
+	}
+	allowed := pcommon.NewMap()
+	attributes.Range(func(k string, v pcommon.Value) bool {
+		if _, ok := a.keys[k]; ok {
+			v.CopyTo(allowed.PutEmpty(k))
+			return true
+		}
+		a.logger.Debug("Dropping span attribute that is not in the tag allowlist", zap.String("attribute", k))
+		return true
+	})
+	return allowed
+}
+
+// tagSizeBudget caps the total size of the tags of the spans sent to Tanzu Observability, which rejects
+// spans whose line is too long. The tags that do not fit are moved into a span log of the span instead
+// of being dropped. The tags derived from the resource, span.kind, the error tags and the otel.* tags
+// are never moved.
+type tagSizeBudget struct {
+	maxSize int
+	logger  *zap.Logger
+}
+
+// newTagSizeBudget returns the budget of the given size in bytes, or nil if the size of the tags is unlimited.
+func newTagSizeBudget(maxSize int, logger *zap.Logger) *tagSizeBudget {
+	if maxSize <= 0 {
+		return nil
+	}
+	return &tagSizeBudget{maxSize: maxSize, logger: logger}
+}
+
+// apply moves the tags of the span exceeding the budget, in the order of their keys, into a span log
+// at the start of the span. The tags whose keys are in resourceTags are kept. The size of a tag is the
+// length of its key and value. A nil tagSizeBudget leaves the span as it is.
+func (b *tagSizeBudget) apply(span *span, resourceTags map[string]struct{}) {
+	if b == nil {
+		return
+	}
+	size := 0
+	var movable []string
+	for key, value := range span.Tags {
+		if _, ok := resourceTags[key]; ok || isReservedSpanTag(key) {
+			size += len(key) + len(value)
+			continue
+		}
+		movable = append(movable, key)
+	}
+	sort.Strings(movable)
+
+	overflow := map[string]string{}
+	for _, key := range movable {
+		value := span.Tags[key]
+		if size+len(key)+len(value) <= b.maxSize {
+			size += len(key) + len(value)
+			continue
+		}
+		overflow[key] = value
+		delete(span.Tags, key)
+	}
+	if len(overflow) == 0 {
+		return
+	}
+	b.logger.Debug("Moving span tags exceeding the tag size budget into a span log",
+		zap.String("span", span.Name), zap.Int("count", len(overflow)), zap.Int("max_tags_size", b.maxSize))
+	span.SpanLogs = append(span.SpanLogs, senders.SpanLog{
+		Timestamp: span.StartMillis * 1000, // Timestamp is in microseconds
+		Fields:    overflow,
+	})
+}
+
+// isReservedSpanTag reports whether the tag is required by Tanzu Observability or set by the exporter
+func isReservedSpanTag(key string) bool {
+	switch key {
+	case labelApplication, labelService, labelCluster, labelShard, labelSpanKind, labelError:
+		return true
+	}
+	return strings.HasPrefix(key, "otel.")
+}
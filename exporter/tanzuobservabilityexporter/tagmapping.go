@@ -0,0 +1,78 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tanzuobservabilityexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/tanzuobservabilityexporter"
+
+import (
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// tagMapper holds the key of the point tag of every OTLP attribute of the metrics.tag_mappings by the key of the attribute
+type tagMapper map[string]string
+
+// newTagMapper returns the tagMapper of the given mappings, or nil if there are none.
+func newTagMapper(mappings []TagMapping) tagMapper {
+	if len(mappings) == 0 {
+		return nil
+	}
+	m := make(tagMapper, len(mappings))
+	for _, mapping := range mappings {
+		m[mapping.Source] = mapping.Target
+	}
+	return m
+}
+
+// mapAttributes returns the given point attributes with the mapped attributes renamed to their target key.
+// The attributes are returned as they are if none of them is mapped.
+func (m tagMapper) mapAttributes(attributes pcommon.Map) pcommon.Map {
+	mapped := false
+	attributes.Range(func(k string, _ pcommon.Value) bool {
+		_, mapped = m[k]
+		return !mapped
+	})
+	if !mapped {
+		return attributes
+	}
+	renamed := pcommon.NewMap()
+	renamed.EnsureCapacity(attributes.Len())
+	attributes.Range(func(k string, v pcommon.Value) bool {
+		if target, ok := m[k]; ok {
+			k = target
+		}
+		v.CopyTo(renamed.PutEmpty(k))
+		return true
+	})
+	return renamed
+}
+
+// mapResourceTags replaces the mapped resource attributes of the given resource tags, which are allocated if
+// nil, with tags of their target key. The mapped attributes are added even if the resource tags leave them out.
+func (m tagMapper) mapResourceTags(resAttrs pcommon.Map, tags map[string]string) map[string]string {
+	if len(m) == 0 {
+		return tags
+	}
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	// the sources are removed first, so that a tag mapped to the key of another source is kept
+	for source := range m {
+		delete(tags, source)
+	}
+	for source, target := range m {
+		if value, ok := resAttrs.Get(source); ok {
+			tags[target] = value.AsString()
+		}
+	}
+	return tags
+}
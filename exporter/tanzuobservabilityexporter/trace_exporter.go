@@ -18,6 +18,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/wavefronthq/wavefront-sdk-go/senders"
@@ -25,6 +28,7 @@ import (
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -44,6 +48,7 @@ const (
 	labelDroppedAttrsCount  = "otel.dropped_attributes_count"
 	labelOtelScopeName      = "otel.scope.name"
 	labelOtelScopeVersion   = "otel.scope.version"
+	labelCollectorInstance  = "otel.collector.instance"
 )
 
 // spanSender Interface for sending tracing spans to Tanzu Observability
@@ -58,9 +63,19 @@ type spanSender interface {
 }
 
 type tracesExporter struct {
-	cfg    *Config
-	sender spanSender
-	logger *zap.Logger
+	cfg     *Config
+	sender  spanSender
+	logger  *zap.Logger
+	metrics *opencensusMetrics
+	// limiter limits the rate of sent spans to traces.max_spans_per_second, nil if unlimited
+	limiter *rate.Limiter
+	// collectorInstance is the value of the otel.collector.instance tag of every span, the tag is not set if empty
+	collectorInstance string
+	// tagAllowlist limits the span attributes that become tags to traces.tag_allowlist, nil if all are kept
+	tagAllowlist *tagAllowlist
+	// tagSizeBudget moves the tags exceeding traces.max_tags_size into span logs, nil if unlimited
+	tagSizeBudget *tagSizeBudget
+	now           func() time.Time
 }
 
 func newTracesExporter(settings component.ExporterCreateSettings, c component.ExporterConfig) (*tracesExporter, error) {
@@ -75,6 +90,9 @@ func newTracesExporter(settings component.ExporterCreateSettings, c component.Ex
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse traces.endpoint: %w", err)
 	}
+	if unsupported := unsupportedHTTPClientSettings(cfg.Traces.HTTPClientSettings); len(unsupported) > 0 {
+		settings.Logger.Warn("traces http client settings are not supported by the Wavefront SDK and will be ignored", zap.Strings("settings", unsupported))
+	}
 	metricsPort := defaultMetricsPort
 	if cfg.hasMetricsEndpoint() {
 		_, metricsPort, err = cfg.parseMetricsEndpoint()
@@ -93,14 +111,33 @@ func newTracesExporter(settings component.ExporterCreateSettings, c component.Ex
 	if err != nil {
 		return nil, fmt.Errorf("failed to create proxy sender: %w", err)
 	}
+	metrics, err := newOpenCensusMetrics(cfg.ID().Name(), signalTraces)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register internal metrics: %w", err)
+	}
 
 	return &tracesExporter{
-		cfg:    cfg,
-		sender: s,
-		logger: settings.Logger,
+		cfg:               cfg,
+		sender:            &observedSpanSender{spanSender: s, metrics: metrics},
+		logger:            settings.Logger,
+		metrics:           metrics,
+		limiter:           newSpanLimiter(cfg.Traces.MaxSpansPerSecond),
+		collectorInstance: cfg.CollectorInstance.tagValue(),
+		tagAllowlist:      newTagAllowlist(cfg.Traces.TagAllowlist, settings.Logger),
+		tagSizeBudget:     newTagSizeBudget(cfg.Traces.MaxTagsSize, settings.Logger),
+		now:               time.Now,
 	}, nil
 }
 
+// newSpanLimiter returns a token bucket refilled at maxSpansPerSecond that holds one second
+// worth of spans, or nil if the rate of spans is unlimited.
+func newSpanLimiter(maxSpansPerSecond int) *rate.Limiter {
+	if maxSpansPerSecond <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(maxSpansPerSecond), maxSpansPerSecond)
+}
+
 func (e *tracesExporter) pushTraceData(ctx context.Context, td ptrace.Traces) error {
 	var errs error
 
@@ -109,7 +146,7 @@ func (e *tracesExporter) pushTraceData(ctx context.Context, td ptrace.Traces) er
 		resource := rspans.Resource()
 		for j := 0; j < rspans.ScopeSpans().Len(); j++ {
 			ispans := rspans.ScopeSpans().At(j)
-			transform := newTraceTransformer(resource)
+			transform := newTraceTransformer(resource, e.tagAllowlist, e.cfg.ServiceHierarchy)
 
 			libraryName := ispans.Scope().Name()
 			libraryVersion := ispans.Scope().Version()
@@ -119,8 +156,14 @@ func (e *tracesExporter) pushTraceData(ctx context.Context, td ptrace.Traces) er
 				case <-ctx.Done():
 					return multierr.Append(errs, errors.New("context canceled"))
 				default:
+					if e.limiter != nil && !e.limiter.AllowN(e.now(), 1) {
+						e.metrics.recordDroppedSpan(droppedReasonRateLimited)
+						continue
+					}
+
 					transformedSpan, err := transform.Span(ispans.Spans().At(k))
 					if err != nil {
+						e.metrics.recordConversionError(strings.ToLower(ispans.Spans().At(k).Kind().String()))
 						errs = multierr.Append(errs, err)
 						continue
 					}
@@ -133,6 +176,12 @@ func (e *tracesExporter) pushTraceData(ctx context.Context, td ptrace.Traces) er
 						transformedSpan.Tags[labelOtelScopeVersion] = libraryVersion
 					}
 
+					if e.collectorInstance != "" {
+						transformedSpan.Tags[labelCollectorInstance] = e.collectorInstance
+					}
+
+					e.tagSizeBudget.apply(&transformedSpan, transform.resourceTags)
+
 					if err := e.recordSpan(transformedSpan); err != nil {
 						errs = multierr.Append(errs, err)
 						continue
@@ -171,13 +220,17 @@ func (e *tracesExporter) shutdown(_ context.Context) error {
 	return nil
 }
 
+// mapToSpanTags returns the tags sorted by key, so that the same span is always sent as the same line.
 func mapToSpanTags(tags map[string]string) []senders.SpanTag {
-	var spanTags []senders.SpanTag
+	spanTags := make([]senders.SpanTag, 0, len(tags))
 	for k, v := range tags {
 		spanTags = append(spanTags, senders.SpanTag{
 			Key:   k,
 			Value: v,
 		})
 	}
+	sort.Slice(spanTags, func(i, j int) bool {
+		return spanTags[i].Key < spanTags[j].Key
+	})
 	return spanTags
 }
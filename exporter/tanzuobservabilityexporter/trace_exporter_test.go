@@ -18,6 +18,7 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -217,6 +218,185 @@ func TestExportTraceDataWithInstrumentationDetails(t *testing.T) {
 	validateTraces(t, expected, traces)
 }
 
+func TestExportTraceDataCollectorInstance(t *testing.T) {
+	for name, collectorInstance := range map[string]string{"enabled": "collector-1", "disabled": ""} {
+		t.Run(name, func(t *testing.T) {
+			traces := constructTraces([]ptrace.Span{createSpan(
+				"root",
+				pcommon.TraceID([16]byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}),
+				pcommon.SpanID([8]byte{9, 9, 9, 9, 9, 9, 9, 9}),
+				pcommon.SpanID{},
+			)})
+
+			sender := &mockSender{}
+			exp := tracesExporter{
+				cfg:               createDefaultConfig().(*Config),
+				sender:            sender,
+				logger:            zap.NewNop(),
+				collectorInstance: collectorInstance,
+			}
+			require.NoError(t, exp.pushTraceData(context.Background(), traces))
+			require.Len(t, sender.spans, 1)
+			value, ok := sender.spans[0].Tags[labelCollectorInstance]
+			assert.Equal(t, collectorInstance != "", ok)
+			assert.Equal(t, collectorInstance, value)
+		})
+	}
+}
+
+func TestExportTraceDataTagAllowlist(t *testing.T) {
+	for name, tc := range map[string]struct {
+		allowlist []string
+		expected  map[string]string
+	}{
+		"all attributes": {
+			expected: map[string]string{"http.method": "GET", "http.url": "/users/42", "user.id": "42"},
+		},
+		"allowlisted attributes": {
+			allowlist: []string{"http.method", "db.system"},
+			expected:  map[string]string{"http.method": "GET"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			span := createSpan(
+				"root",
+				pcommon.TraceID([16]byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}),
+				pcommon.SpanID([8]byte{9, 9, 9, 9, 9, 9, 9, 9}),
+				pcommon.SpanID{},
+			)
+			span.Attributes().PutStr("http.method", "GET")
+			span.Attributes().PutStr("http.url", "/users/42")
+			span.Attributes().PutStr("user.id", "42")
+			traces := constructTraces([]ptrace.Span{span})
+			traces.ResourceSpans().At(0).Resource().Attributes().PutStr(labelCluster, "us-west-2")
+
+			sender := &mockSender{}
+			exp := tracesExporter{
+				cfg:          createDefaultConfig().(*Config),
+				sender:       sender,
+				logger:       zap.NewNop(),
+				tagAllowlist: newTagAllowlist(tc.allowlist, zap.NewNop()),
+			}
+			require.NoError(t, exp.pushTraceData(context.Background(), traces))
+			require.Len(t, sender.spans, 1)
+			// the tags derived from the resource and the required tags are kept regardless of the allowlist
+			expected := map[string]string{
+				labelApplication: "defaultApp",
+				labelService:     "defaultService",
+				labelCluster:     "us-west-2",
+				labelSpanKind:    "unspecified",
+			}
+			for k, v := range tc.expected {
+				expected[k] = v
+			}
+			assert.Equal(t, expected, sender.spans[0].Tags)
+		})
+	}
+}
+
+func TestExportTraceDataMaxTagsSize(t *testing.T) {
+	span := createSpan(
+		"root",
+		pcommon.TraceID([16]byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}),
+		pcommon.SpanID([8]byte{9, 9, 9, 9, 9, 9, 9, 9}),
+		pcommon.SpanID{},
+	)
+	span.SetStartTimestamp(pcommon.NewTimestampFromTime(time.UnixMilli(1668000000123)))
+	span.Attributes().PutStr("http.method", "GET")
+	span.Attributes().PutStr("http.url", "/users/42")
+	span.Attributes().PutStr("user.id", "42")
+	traces := constructTraces([]ptrace.Span{span})
+
+	sender := &mockSender{}
+	exp := tracesExporter{
+		cfg:    createDefaultConfig().(*Config),
+		sender: sender,
+		logger: zap.NewNop(),
+		// the required tags take 62 bytes, leaving room for http.method and user.id but not http.url
+		tagSizeBudget: newTagSizeBudget(85, zap.NewNop()),
+	}
+	require.NoError(t, exp.pushTraceData(context.Background(), traces))
+	require.Len(t, sender.spans, 1)
+	assert.Equal(t, map[string]string{
+		labelApplication: "defaultApp",
+		labelService:     "defaultService",
+		labelSpanKind:    "unspecified",
+		"http.method":    "GET",
+		"user.id":        "42",
+	}, sender.spans[0].Tags)
+	assert.Equal(t, []senders.SpanLog{{
+		Timestamp: 1668000000123000,
+		Fields:    map[string]string{"http.url": "/users/42"},
+	}}, sender.spans[0].SpanLogs)
+}
+
+func TestExportTraceDataMaxTagsSizeKeepsRequiredTags(t *testing.T) {
+	span := createSpan(
+		"root",
+		pcommon.TraceID([16]byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}),
+		pcommon.SpanID([8]byte{9, 9, 9, 9, 9, 9, 9, 9}),
+		pcommon.SpanID{},
+	)
+	span.Attributes().PutStr("http.method", "GET")
+	event := span.Events().AppendEmpty()
+	event.SetName("retry")
+	traces := constructTraces([]ptrace.Span{span})
+
+	sender := &lineSender{}
+	exp := tracesExporter{
+		cfg:               createDefaultConfig().(*Config),
+		sender:            sender,
+		logger:            zap.NewNop(),
+		collectorInstance: "collector-0",
+		tagSizeBudget:     newTagSizeBudget(1, zap.NewNop()),
+	}
+	require.NoError(t, exp.pushTraceData(context.Background(), traces))
+	// the span is sent with its required tags even though they exceed the budget, the events are kept as span logs
+	require.Len(t, sender.lines, 1)
+	assert.Contains(t, sender.lines[0], `"application"="defaultApp" "otel.collector.instance"="collector-0" "service"="defaultService" "span.kind"="unspecified"`)
+	assert.NotContains(t, sender.lines[0], `"http.method"="GET"`)
+	assert.Contains(t, sender.lines[0], `"_spanLogs"="true"`)
+}
+
+func TestExportTraceDataMaxTagsSizeKeepsResourceTags(t *testing.T) {
+	span := createSpan(
+		"root",
+		pcommon.TraceID([16]byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}),
+		pcommon.SpanID([8]byte{9, 9, 9, 9, 9, 9, 9, 9}),
+		pcommon.SpanID{},
+	)
+	span.SetStartTimestamp(pcommon.NewTimestampFromTime(time.UnixMilli(1668000000123)))
+	span.Attributes().PutStr("http.method", "GET")
+	traces := constructTraces([]ptrace.Span{span})
+	resAttrs := traces.ResourceSpans().At(0).Resource().Attributes()
+	resAttrs.PutStr(conventions.AttributeHostName, "host-1")
+	resAttrs.PutStr(conventions.AttributeK8SPodName, "pod-1")
+	resAttrs.PutStr(conventions.AttributeK8SNamespaceName, "ns-1")
+
+	sender := &mockSender{}
+	exp := tracesExporter{
+		cfg:           createDefaultConfig().(*Config),
+		sender:        sender,
+		logger:        zap.NewNop(),
+		tagSizeBudget: newTagSizeBudget(1, zap.NewNop()),
+	}
+	require.NoError(t, exp.pushTraceData(context.Background(), traces))
+	require.Len(t, sender.spans, 1)
+	// the tags derived from the resource exceed the budget but are kept, the span attributes are moved
+	assert.Equal(t, "pod-1", sender.spans[0].Tags[conventions.AttributeK8SPodName])
+	assert.Equal(t, "ns-1", sender.spans[0].Tags[conventions.AttributeK8SNamespaceName])
+	assert.NotContains(t, sender.spans[0].Tags, "http.method")
+	assert.Equal(t, "host-1", sender.spans[0].Source)
+	assert.Equal(t, []senders.SpanLog{{
+		Timestamp: 1668000000123000,
+		Fields:    map[string]string{"http.method": "GET"},
+	}}, sender.spans[0].SpanLogs)
+}
+
+func TestNewTagSizeBudgetUnlimited(t *testing.T) {
+	assert.Nil(t, newTagSizeBudget(0, zap.NewNop()))
+}
+
 func TestExportTraceDataRespectsContext(t *testing.T) {
 	traces := constructTraces([]ptrace.Span{createSpan(
 		"root",
@@ -246,6 +426,112 @@ func TestExportTraceDataRespectsContext(t *testing.T) {
 	require.Error(t, mockOTelTracesExporter.ConsumeTraces(ctx, traces))
 }
 
+func TestExportTraceDataRateLimited(t *testing.T) {
+	metrics, err := newOpenCensusMetrics(t.Name(), signalTraces)
+	require.NoError(t, err)
+
+	now := time.Now()
+	sender := &mockSender{}
+	exp := tracesExporter{
+		cfg:     createDefaultConfig().(*Config),
+		sender:  sender,
+		logger:  zap.NewNop(),
+		metrics: metrics,
+		limiter: newSpanLimiter(10),
+		now:     func() time.Time { return now },
+	}
+	burst := func(count int) ptrace.Traces {
+		spans := make([]ptrace.Span, 0, count)
+		for i := 0; i < count; i++ {
+			spans = append(spans, createSpan(
+				"burst",
+				pcommon.TraceID([16]byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, byte(i)}),
+				pcommon.SpanID([8]byte{9, 9, 9, 9, 9, 9, 9, byte(i)}),
+				pcommon.SpanID{},
+			))
+		}
+		return constructTraces(spans)
+	}
+
+	// a burst of up to one second worth of spans is sent, the rest is dropped
+	require.NoError(t, exp.pushTraceData(context.Background(), burst(25)))
+	assert.Len(t, sender.spans, 10)
+	assert.Equal(t, float64(15), droppedSpansValue(t, t.Name(), droppedReasonRateLimited))
+
+	// the bucket refills at the configured rate
+	now = now.Add(500 * time.Millisecond)
+	require.NoError(t, exp.pushTraceData(context.Background(), burst(25)))
+	assert.Len(t, sender.spans, 15)
+	assert.Equal(t, float64(35), droppedSpansValue(t, t.Name(), droppedReasonRateLimited))
+
+	// spans within the rate are not dropped
+	now = now.Add(10 * time.Second)
+	require.NoError(t, exp.pushTraceData(context.Background(), burst(10)))
+	assert.Len(t, sender.spans, 25)
+	assert.Equal(t, float64(35), droppedSpansValue(t, t.Name(), droppedReasonRateLimited))
+}
+
+func TestExportTraceDataRecordsConversionErrors(t *testing.T) {
+	metrics, err := newOpenCensusMetrics(t.Name(), signalTraces)
+	require.NoError(t, err)
+
+	sender := &mockSender{}
+	exp := tracesExporter{
+		cfg:     createDefaultConfig().(*Config),
+		sender:  sender,
+		logger:  zap.NewNop(),
+		metrics: metrics,
+	}
+	valid := createSpan("valid", pcommon.TraceID([16]byte{1, 1}), pcommon.SpanID([8]byte{1}), pcommon.SpanID{})
+	invalidTraceID := createSpan("invalid-trace-id", pcommon.TraceID{}, pcommon.SpanID([8]byte{2}), pcommon.SpanID{})
+	invalidTraceID.SetKind(ptrace.SpanKindServer)
+	invalidSpanID := createSpan("invalid-span-id", pcommon.TraceID([16]byte{1, 1}), pcommon.SpanID{}, pcommon.SpanID{})
+	invalidSpanID.SetKind(ptrace.SpanKindClient)
+
+	assert.Error(t, exp.pushTraceData(context.Background(), constructTraces([]ptrace.Span{valid, invalidTraceID, invalidSpanID})))
+	assert.Len(t, sender.spans, 1)
+	assert.Equal(t, float64(1), conversionErrorsValue(t, t.Name(), signalTraces, "server"))
+	assert.Equal(t, float64(1), conversionErrorsValue(t, t.Name(), signalTraces, "client"))
+	assert.Equal(t, float64(0), conversionErrorsValue(t, t.Name(), signalTraces, "unspecified"))
+}
+
+func TestNewSpanLimiterUnlimited(t *testing.T) {
+	assert.Nil(t, newSpanLimiter(0))
+}
+
+func TestRecordSpanSortsTags(t *testing.T) {
+	sender := &lineSender{}
+	exp := tracesExporter{sender: sender, logger: zap.NewNop()}
+	s := span{
+		Name:    "test",
+		TraceID: uuid.New(),
+		SpanID:  uuid.New(),
+		Source:  "test-source",
+		Tags: map[string]string{
+			"service":     "checkout",
+			"application": "shop",
+			"span.kind":   "server",
+			"http.method": "GET",
+			"cluster":     "none",
+			"shard":       "none",
+			"error":       "true",
+		},
+	}
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, exp.recordSpan(s))
+	}
+	require.Len(t, sender.lines, 10)
+	for _, line := range sender.lines {
+		assert.Equal(t, sender.lines[0], line)
+	}
+	assert.Contains(t, sender.lines[0], `"application"="shop" "cluster"="none" "error"="true" "http.method"="GET" "service"="checkout" "shard"="none" "span.kind"="server"`)
+}
+
+func TestMapToSpanTagsEmpty(t *testing.T) {
+	assert.Empty(t, mapToSpanTags(nil))
+}
+
 func createSpan(
 	name string,
 	traceID pcommon.TraceID,
@@ -340,3 +626,25 @@ func (m *mockSender) SendSpan(
 }
 func (m *mockSender) Flush() error { return nil }
 func (m *mockSender) Close()       {}
+
+// lineSender implements the spanSender interface, recording the lines the Wavefront SDK sends
+type lineSender struct {
+	mockSender
+	lines []string
+}
+
+func (m *lineSender) SendSpan(
+	name string,
+	startMillis, durationMillis int64,
+	source, traceID, spanID string,
+	parents, followsFrom []string,
+	spanTags []senders.SpanTag,
+	spanLogs []senders.SpanLog,
+) error {
+	line, err := senders.SpanLine(name, startMillis, durationMillis, source, traceID, spanID, parents, followsFrom, spanTags, spanLogs, "")
+	if err != nil {
+		return err
+	}
+	m.lines = append(m.lines, line)
+	return nil
+}
@@ -30,11 +30,25 @@ import (
 
 type traceTransformer struct {
 	resAttrs pcommon.Map
+	// allowlist limits the span attributes that become tags, nil if all are kept
+	allowlist *tagAllowlist
+	// serviceHierarchy derives the application tags of the spans from the service resource attributes
+	serviceHierarchy ServiceHierarchyConfig
+	// resourceTags holds the keys of the tags of the spans that are derived from the resource attributes
+	resourceTags map[string]struct{}
 }
 
-func newTraceTransformer(resource pcommon.Resource) *traceTransformer {
+func newTraceTransformer(resource pcommon.Resource, allowlist *tagAllowlist, serviceHierarchy ServiceHierarchyConfig) *traceTransformer {
 	t := &traceTransformer{
-		resAttrs: resource.Attributes(),
+		resAttrs:         resource.Attributes(),
+		allowlist:        allowlist,
+		serviceHierarchy: serviceHierarchy,
+		resourceTags:     map[string]struct{}{},
+	}
+	_, attributesWithoutSource := getSourceAndResourceTags(t.resAttrs)
+	replaceSource(attributesWithoutSource)
+	for key := range attributesWithoutSource {
+		t.resourceTags[key] = struct{}{}
 	}
 	return t
 }
@@ -73,8 +87,9 @@ func (t *traceTransformer) Span(orig ptrace.Span) (span, error) {
 
 	source, attributesWithoutSource := getSourceAndResourceTags(t.resAttrs)
 	tags := attributesToTagsReplaceSource(
-		newMap(attributesWithoutSource), orig.Attributes())
+		newMap(attributesWithoutSource), t.allowlist.filter(orig.Attributes()))
 	fixServiceTag(tags)
+	t.serviceHierarchy.applyTags(t.resAttrs, tags)
 	t.setRequiredTags(tags)
 
 	tags[labelSpanKind] = spanKind(orig)
@@ -121,22 +136,31 @@ func getSourceAndResourceTagsAndSourceKey(attributes pcommon.Map) (
 		attributesWithoutSource[k] = v.AsString()
 		return true
 	})
-	candidateKeys := []string{labelSource, conventions.AttributeHostName, "hostname", conventions.AttributeHostID}
-	var source string
-	var sourceKey string
-	for _, key := range candidateKeys {
-		if value, isFound := attributesWithoutSource[key]; isFound {
-			source = value
-			sourceKey = key
-			delete(attributesWithoutSource, key)
-			break
-		}
+	source, sourceKey, found := findSource(attributesWithoutSource, defaultSourceFallbacks)
+	if found {
+		delete(attributesWithoutSource, sourceKey)
 	}
 
 	// returning an empty source is fine as wavefront.go.sdk will set it up to a default value(os.hostname())
 	return source, attributesWithoutSource, sourceKey
 }
 
+// defaultSourceFallbacks are the attributes the source is taken from, in order, when there is no source attribute.
+var defaultSourceFallbacks = []string{conventions.AttributeHostName, "hostname", conventions.AttributeHostID}
+
+// findSource returns the value and key of the source attribute, or of the first of the fallbacks present.
+func findSource(attributes map[string]string, fallbacks []string) (string, string, bool) {
+	if value, isFound := attributes[labelSource]; isFound {
+		return value, labelSource, true
+	}
+	for _, key := range fallbacks {
+		if value, isFound := attributes[key]; isFound {
+			return value, key, true
+		}
+	}
+	return "", "", false
+}
+
 func getSourceAndResourceTags(attributes pcommon.Map) (string, map[string]string) {
 	source, attributesWithoutSource, _ := getSourceAndResourceTagsAndSourceKey(attributes)
 	return source, attributesWithoutSource
@@ -147,6 +171,16 @@ func getSourceAndKey(attributes pcommon.Map) (string, string) {
 	return source, sourceKey
 }
 
+// getSourceAndKeyWithFallbacks returns the source and its key, taking the source from the fallbacks
+// when there is no source attribute. defaultSource is returned with an empty key if none is present.
+func getSourceAndKeyWithFallbacks(attributes pcommon.Map, fallbacks []string, defaultSource string) (string, string) {
+	source, sourceKey, found := findSource(attributesToTags(attributes), fallbacks)
+	if !found {
+		return defaultSource, ""
+	}
+	return source, sourceKey
+}
+
 func spanKind(span ptrace.Span) string {
 	switch span.Kind() {
 	case ptrace.SpanKindClient:
@@ -322,6 +322,70 @@ func TestSpanForDroppedCount(t *testing.T) {
 	assert.Equal(t, "789", actual.Tags["otel.dropped_attributes_count"])
 }
 
+func TestSpanForServiceHierarchy(t *testing.T) {
+	defaultHierarchy := createDefaultConfig().(*Config).ServiceHierarchy
+	tests := []struct {
+		name                string
+		hierarchy           ServiceHierarchyConfig
+		resAttrs            map[string]string
+		expectedApplication string
+		expectedService     string
+	}{
+		{
+			name:                "disabled",
+			hierarchy:           defaultHierarchy,
+			resAttrs:            map[string]string{"service.namespace": "shop", "service.name": "checkout"},
+			expectedApplication: defaultApplicationName,
+			expectedService:     "checkout",
+		},
+		{
+			name:                "enabled",
+			hierarchy:           ServiceHierarchyConfig{Enabled: true, ApplicationKey: defaultHierarchy.ApplicationKey, ServiceKey: defaultHierarchy.ServiceKey},
+			resAttrs:            map[string]string{"service.namespace": "shop", "service.name": "checkout"},
+			expectedApplication: "shop",
+			expectedService:     "checkout",
+		},
+		{
+			name:                "explicit tags win",
+			hierarchy:           ServiceHierarchyConfig{Enabled: true, ApplicationKey: defaultHierarchy.ApplicationKey, ServiceKey: defaultHierarchy.ServiceKey},
+			resAttrs:            map[string]string{"service.namespace": "shop", "service.name": "checkout", "application": "store", "service": "cart"},
+			expectedApplication: "store",
+			expectedService:     "cart",
+		},
+		{
+			name:                "custom keys",
+			hierarchy:           ServiceHierarchyConfig{Enabled: true, ApplicationKey: "k8s.namespace.name", ServiceKey: "k8s.deployment.name"},
+			resAttrs:            map[string]string{"k8s.namespace.name": "shop", "k8s.deployment.name": "checkout"},
+			expectedApplication: "shop",
+			expectedService:     "checkout",
+		},
+		{
+			name:                "missing attributes",
+			hierarchy:           ServiceHierarchyConfig{Enabled: true, ApplicationKey: defaultHierarchy.ApplicationKey, ServiceKey: defaultHierarchy.ServiceKey},
+			resAttrs:            map[string]string{},
+			expectedApplication: defaultApplicationName,
+			expectedService:     defaultServiceName,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := pcommon.NewResource()
+			for k, v := range tt.resAttrs {
+				resource.Attributes().PutStr(k, v)
+			}
+			transform := newTraceTransformer(resource, nil, tt.hierarchy)
+			span := ptrace.NewSpan()
+			span.SetSpanID([8]byte{0, 0, 0, 0, 0, 0, 0, 1})
+			span.SetTraceID([16]byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1})
+
+			actual, err := transform.Span(span)
+			require.NoError(t, err, "transforming span to wavefront format")
+			assert.Equal(t, tt.expectedApplication, actual.Tags[labelApplication])
+			assert.Equal(t, tt.expectedService, actual.Tags[labelService])
+		})
+	}
+}
+
 func TestGetSourceAndResourceTags(t *testing.T) {
 	resAttrs := pcommon.NewMap()
 	resAttrs.PutStr(labelSource, "test_source")
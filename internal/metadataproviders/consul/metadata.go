@@ -43,7 +43,9 @@ func NewProvider(client *api.Client, allowedLabels map[string]interface{}) Provi
 
 func (d *consulMetadataImpl) Metadata(ctx context.Context) (*Metadata, error) {
 	var metadata Metadata
-	self, err := d.consulClient.Agent().Self()
+	// the agent endpoint is queried through the raw client as Agent().Self() cannot be cancelled
+	var self map[string]map[string]interface{}
+	_, err := d.consulClient.Raw().Query("/v1/agent/self", &self, (&api.QueryOptions{}).WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get local agent information: %w", err)
 	}
@@ -60,3 +60,21 @@ func TestConsulHappyPath(t *testing.T) {
 	assert.Equal(t, "00000000-0000-0000-0000-000000000000", meta.NodeID)
 	assert.Equal(t, map[string]string{"test": "test"}, meta.HostMetadata)
 }
+
+func TestConsulCancelledContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	config := api.DefaultConfig()
+	config.Address = ts.URL
+
+	client, err := api.NewClient(config)
+	require.NoError(t, err)
+	provider := NewProvider(client, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = provider.Metadata(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
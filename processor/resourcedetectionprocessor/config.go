@@ -15,13 +15,22 @@
 package resourcedetectionprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor"
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/confighttp"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/aws/ec2"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/consul"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/k8spodlabels"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/socket"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/system"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/vsphere"
 )
 
 // Config defines configuration for Resource processor.
@@ -31,17 +40,78 @@ type Config struct {
 	// Detectors is an ordered list of named detectors that should be
 	// run to attempt to detect resource information.
 	Detectors []string `mapstructure:"detectors"`
+	// Priorities sets the priority of configured detectors, the resources of the detectors are merged in
+	// decreasing order of priority instead of the order of Detectors. Detectors without a priority have
+	// priority 0, detectors of the same priority are merged in the order of Detectors.
+	Priorities map[string]int `mapstructure:"priorities"`
+	// Conditions sets a precondition of configured detectors on the resource merged from the detectors run before
+	// them, a detector whose condition is not met is skipped. Detectors without a condition always run.
+	Conditions map[string]internal.DetectorCondition `mapstructure:"conditions"`
 	// Override indicates whether any existing resource attributes
 	// should be overridden or preserved. Defaults to true.
 	Override bool `mapstructure:"override"`
 	// DetectorConfig is a list of settings specific to all detectors
 	DetectorConfig DetectorConfig `mapstructure:",squash"`
+	// DetectorInstances contains the settings of named detector instances, e.g. "system/dns",
+	// which allow running a detector more than once with different settings. Instances
+	// without an entry use the settings of their detector type from DetectorConfig.
+	DetectorInstances map[string]DetectorConfig `mapstructure:"detector_instances"`
 	// HTTP client settings for the detector
 	// Timeout default is 5s
 	confighttp.HTTPClientSettings `mapstructure:",squash"`
+	// RequestTimeout bounds each request the detectors make to metadata services, so that a single hung
+	// request does not consume the whole timeout. Requests are only bounded by the timeout if not set.
+	RequestTimeout time.Duration `mapstructure:"request_timeout"`
 	// Attributes is an allowlist of attributes to add.
 	// If a supplied attribute is not a valid atrtibute of a supplied detector it will be ignored.
 	Attributes []string `mapstructure:"attributes"`
+	// DetectionMode controls how the results of the detectors are combined, either "merge"
+	// to merge the resources of all detectors or "first_match" to only use the resource of
+	// the first detector that returns a non-empty resource. Defaults to "merge".
+	DetectionMode internal.DetectionMode `mapstructure:"detection_mode"`
+	// ConflictPolicy determines which value is kept when multiple detectors detect the same
+	// attribute, one of "first", "last", "min" or "max". Defaults to "first".
+	ConflictPolicy internal.ConflictPolicy `mapstructure:"conflict_policy"`
+	// PreferNonEmpty keeps the non-empty value of an attribute that is an empty string in one resource
+	// and not in another, both when merging the detected resources and when merging the detected
+	// resource into the resource of the telemetry, whatever ConflictPolicy and Override.
+	PreferNonEmpty bool `mapstructure:"prefer_non_empty"`
+	// SchemaURLPolicy determines the schema URL of the incoming telemetry once the detected resource
+	// is applied, one of "keep_incoming", "keep_detected" or "merge". Defaults to "merge".
+	SchemaURLPolicy internal.SchemaURLPolicy `mapstructure:"schema_url_policy"`
+	// AttributeTemplates maps attribute keys to templates composing their value from the detected
+	// attributes, e.g. "${host.name}-${process.pid}". The templates are evaluated after the detected
+	// resources are merged, templates referencing attributes that were not detected are skipped.
+	AttributeTemplates map[string]string `mapstructure:"attribute_templates"`
+	// AttributeValues maps attribute keys to a filter of their values, e.g. to drop a "localhost"
+	// host.name. Attributes whose value is dropped by their filter are removed with the attributes
+	// that are not in the Attributes allowlist.
+	AttributeValues map[string]internal.AttributeValueFilter `mapstructure:"attribute_values"`
+	// Cache persists the detected resource in a storage extension, so that it is used instead of
+	// running the detectors when the collector restarts. Caching is disabled if not set.
+	Cache *CacheConfig `mapstructure:"cache"`
+	// Readiness retries the detection until the detected resource has the readiness attributes, so that the
+	// pipeline does not start with an incomplete resource. The detectors are run once if not set.
+	Readiness *ReadinessConfig `mapstructure:"readiness"`
+}
+
+// ReadinessConfig contains the settings of waiting for the detected resource to be complete
+type ReadinessConfig struct {
+	// Attributes are the attributes the detected resource must have, e.g. "cloud.instance.id".
+	Attributes []string `mapstructure:"attributes"`
+	// MaxWait is how long the detection is retried for, the last detected resource is used once it elapses.
+	MaxWait time.Duration `mapstructure:"max_wait"`
+	// RetryInterval is the interval at which the detection is retried. Defaults to 1s.
+	RetryInterval time.Duration `mapstructure:"retry_interval"`
+}
+
+// CacheConfig contains the settings of caching the detected resource across restarts
+type CacheConfig struct {
+	// StorageID is the ID of the storage extension the detected resource is persisted in.
+	StorageID *component.ID `mapstructure:"storage"`
+	// TTL is how long a persisted resource is used. The detectors still run in the background
+	// when a persisted resource is used, refreshing it for the next restart.
+	TTL time.Duration `mapstructure:"ttl"`
 }
 
 // DetectorConfig contains user-specified configurations unique to all individual detectors
@@ -54,22 +124,129 @@ type DetectorConfig struct {
 
 	// SystemConfig contains user-specified configurations for the System detector
 	SystemConfig system.Config `mapstructure:"system"`
+
+	// K8sPodLabelsConfig contains user-specified configurations for the k8spodlabels detector
+	K8sPodLabelsConfig k8spodlabels.Config `mapstructure:"k8spodlabels"`
+
+	// SocketConfig contains user-specified configurations for the socket detector
+	SocketConfig socket.Config `mapstructure:"socket"`
+
+	// VSphereConfig contains user-specified configurations for the vsphere detector
+	VSphereConfig vsphere.Config `mapstructure:"vsphere"`
 }
 
 func (d *DetectorConfig) GetConfigFromType(detectorType internal.DetectorType) internal.DetectorConfig {
-	switch detectorType {
+	switch detectorType.BaseType() {
 	case ec2.TypeStr:
 		return d.EC2Config
 	case consul.TypeStr:
 		return d.ConsulConfig
 	case system.TypeStr:
 		return d.SystemConfig
+	case k8spodlabels.TypeStr:
+		return d.K8sPodLabelsConfig
+	case socket.TypeStr:
+		return d.SocketConfig
+	case vsphere.TypeStr:
+		return d.VSphereConfig
 	default:
 		return nil
 	}
 }
 
+// detectorConfigs resolves the settings of the configured detectors, named detector
+// instances use their own settings when they are configured in DetectorInstances.
+type detectorConfigs struct {
+	DetectorConfig
+	instances map[string]DetectorConfig
+}
+
+func (d *detectorConfigs) GetConfigFromType(detectorType internal.DetectorType) internal.DetectorConfig {
+	if instance, ok := d.instances[string(detectorType)]; ok {
+		return instance.GetConfigFromType(detectorType)
+	}
+	return d.DetectorConfig.GetConfigFromType(detectorType)
+}
+
 // Validate config
 func (cfg *Config) Validate() error {
+	switch cfg.DetectionMode {
+	case internal.DetectionModeMerge, internal.DetectionModeFirstMatch:
+	default:
+		return fmt.Errorf("detection_mode contains invalid value: %q", cfg.DetectionMode)
+	}
+	switch cfg.ConflictPolicy {
+	case internal.ConflictPolicyFirst, internal.ConflictPolicyLast, internal.ConflictPolicyMin, internal.ConflictPolicyMax:
+	default:
+		return fmt.Errorf("conflict_policy contains invalid value: %q", cfg.ConflictPolicy)
+	}
+	switch cfg.SchemaURLPolicy {
+	case internal.SchemaURLPolicyKeepIncoming, internal.SchemaURLPolicyKeepDetected, internal.SchemaURLPolicyMerge:
+	default:
+		return fmt.Errorf("schema_url_policy contains invalid value: %q", cfg.SchemaURLPolicy)
+	}
+	for key := range cfg.AttributeTemplates {
+		if key == "" {
+			return errors.New("attribute_templates contains an empty attribute key")
+		}
+	}
+	if cfg.RequestTimeout < 0 {
+		return fmt.Errorf("request_timeout must not be negative: %s", cfg.RequestTimeout)
+	}
+	for key := range cfg.AttributeValues {
+		if key == "" {
+			return errors.New("attribute_values contains an empty attribute key")
+		}
+	}
+	if cfg.Cache != nil {
+		if cfg.Cache.StorageID == nil {
+			return errors.New("cache.storage must be set")
+		}
+		if cfg.Cache.TTL <= 0 {
+			return fmt.Errorf("cache.ttl must be positive: %s", cfg.Cache.TTL)
+		}
+	}
+	if cfg.Readiness != nil {
+		if len(cfg.Readiness.Attributes) == 0 {
+			return errors.New("readiness.attributes must not be empty")
+		}
+		if cfg.Readiness.MaxWait <= 0 {
+			return fmt.Errorf("readiness.max_wait must be positive: %s", cfg.Readiness.MaxWait)
+		}
+		if cfg.Readiness.RetryInterval < 0 {
+			return fmt.Errorf("readiness.retry_interval must not be negative: %s", cfg.Readiness.RetryInterval)
+		}
+	}
+	for name := range cfg.Priorities {
+		if !containsDetector(cfg.Detectors, name) {
+			return fmt.Errorf("priorities contains %q, which is not a configured detector", name)
+		}
+	}
+	for name, condition := range cfg.Conditions {
+		if !containsDetector(cfg.Detectors, name) {
+			return fmt.Errorf("conditions contains %q, which is not a configured detector", name)
+		}
+		if condition.Attribute == "" {
+			return fmt.Errorf("conditions.%s.attribute must not be empty", name)
+		}
+	}
+	for name, instance := range cfg.DetectorInstances {
+		if internal.DetectorType(name).BaseType() == internal.DetectorType(name) {
+			return fmt.Errorf("detector_instances contains invalid name %q, expected <type>/<name>", name)
+		}
+		if err := instance.SystemConfig.Validate(); err != nil {
+			return fmt.Errorf("detector_instances %q: %w", name, err)
+		}
+	}
 	return cfg.DetectorConfig.SystemConfig.Validate()
 }
+
+// containsDetector reports whether the detector is one of the configured detectors
+func containsDetector(detectors []string, name string) bool {
+	for _, detector := range detectors {
+		if strings.TrimSpace(detector) == name {
+			return true
+		}
+	}
+	return false
+}
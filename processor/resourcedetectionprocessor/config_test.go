@@ -36,6 +36,7 @@ func TestLoadConfig(t *testing.T) {
 
 	cfg := confighttp.NewDefaultHTTPClientSettings()
 	cfg.Timeout = 2 * time.Second
+	storageID := component.NewID("file_storage")
 
 	tests := []struct {
 		id           component.ID
@@ -49,6 +50,9 @@ func TestLoadConfig(t *testing.T) {
 				Detectors:          []string{"env", "gce"},
 				HTTPClientSettings: cfg,
 				Override:           false,
+				DetectionMode:      internal.DetectionModeMerge,
+				ConflictPolicy:     internal.ConflictPolicyFirst,
+				SchemaURLPolicy:    internal.SchemaURLPolicyMerge,
 			},
 		},
 		{
@@ -63,6 +67,9 @@ func TestLoadConfig(t *testing.T) {
 				},
 				HTTPClientSettings: cfg,
 				Override:           false,
+				DetectionMode:      internal.DetectionModeMerge,
+				ConflictPolicy:     internal.ConflictPolicyFirst,
+				SchemaURLPolicy:    internal.SchemaURLPolicyMerge,
 			},
 		},
 		{
@@ -78,12 +85,217 @@ func TestLoadConfig(t *testing.T) {
 				HTTPClientSettings: cfg,
 				Override:           false,
 				Attributes:         []string{"a", "b"},
+				DetectionMode:      internal.DetectionModeMerge,
+				ConflictPolicy:     internal.ConflictPolicyFirst,
+				SchemaURLPolicy:    internal.SchemaURLPolicyMerge,
+			},
+		},
+		{
+			id: component.NewIDWithName(typeStr, "first_match"),
+			expected: &Config{
+				ProcessorSettings:  config.NewProcessorSettings(component.NewID(typeStr)),
+				Detectors:          []string{"env", "ec2", "gce"},
+				HTTPClientSettings: cfg,
+				Override:           false,
+				DetectionMode:      internal.DetectionModeFirstMatch,
+				ConflictPolicy:     internal.ConflictPolicyFirst,
+				SchemaURLPolicy:    internal.SchemaURLPolicyMerge,
 			},
 		},
 		{
 			id:           component.NewIDWithName(typeStr, "invalid"),
 			errorMessage: "hostname_sources contains invalid value: \"invalid_source\"",
 		},
+		{
+			id: component.NewIDWithName(typeStr, "conflict_policy"),
+			expected: &Config{
+				ProcessorSettings:  config.NewProcessorSettings(component.NewID(typeStr)),
+				Detectors:          []string{"env", "system"},
+				HTTPClientSettings: cfg,
+				Override:           false,
+				DetectionMode:      internal.DetectionModeMerge,
+				ConflictPolicy:     internal.ConflictPolicyMax,
+				PreferNonEmpty:     true,
+				SchemaURLPolicy:    internal.SchemaURLPolicyMerge,
+			},
+		},
+		{
+			id:           component.NewIDWithName(typeStr, "invalid_conflict_policy"),
+			errorMessage: "conflict_policy contains invalid value: \"random\"",
+		},
+		{
+			id: component.NewIDWithName(typeStr, "schema_url_policy"),
+			expected: &Config{
+				ProcessorSettings:  config.NewProcessorSettings(component.NewID(typeStr)),
+				Detectors:          []string{"env"},
+				HTTPClientSettings: cfg,
+				Override:           false,
+				DetectionMode:      internal.DetectionModeMerge,
+				ConflictPolicy:     internal.ConflictPolicyFirst,
+				SchemaURLPolicy:    internal.SchemaURLPolicyKeepDetected,
+			},
+		},
+		{
+			id:           component.NewIDWithName(typeStr, "invalid_schema_url_policy"),
+			errorMessage: "schema_url_policy contains invalid value: \"newest\"",
+		},
+		{
+			id: component.NewIDWithName(typeStr, "instances"),
+			expected: &Config{
+				ProcessorSettings: config.NewProcessorSettings(component.NewID(typeStr)),
+				Detectors:         []string{"system/dns", "system/os"},
+				DetectorInstances: map[string]DetectorConfig{
+					"system/dns": {SystemConfig: system.Config{HostnameSources: []string{"dns"}}},
+					"system/os":  {SystemConfig: system.Config{HostnameSources: []string{"os"}}},
+				},
+				HTTPClientSettings: cfg,
+				Override:           false,
+				DetectionMode:      internal.DetectionModeMerge,
+				ConflictPolicy:     internal.ConflictPolicyFirst,
+				SchemaURLPolicy:    internal.SchemaURLPolicyMerge,
+			},
+		},
+		{
+			id:           component.NewIDWithName(typeStr, "invalid_instance"),
+			errorMessage: "detector_instances contains invalid name \"system\", expected <type>/<name>",
+		},
+		{
+			id: component.NewIDWithName(typeStr, "attribute_templates"),
+			expected: &Config{
+				ProcessorSettings:  config.NewProcessorSettings(component.NewID(typeStr)),
+				Detectors:          []string{"env", "system"},
+				HTTPClientSettings: cfg,
+				Override:           false,
+				DetectionMode:      internal.DetectionModeMerge,
+				ConflictPolicy:     internal.ConflictPolicyFirst,
+				SchemaURLPolicy:    internal.SchemaURLPolicyMerge,
+				AttributeTemplates: map[string]string{
+					"service.instance.id": "${host.name}-${process.pid}",
+				},
+			},
+		},
+		{
+			id:           component.NewIDWithName(typeStr, "invalid_attribute_templates"),
+			errorMessage: "attribute_templates contains an empty attribute key",
+		},
+		{
+			id: component.NewIDWithName(typeStr, "attribute_values"),
+			expected: &Config{
+				ProcessorSettings:  config.NewProcessorSettings(component.NewID(typeStr)),
+				Detectors:          []string{"env", "system"},
+				HTTPClientSettings: cfg,
+				Override:           false,
+				DetectionMode:      internal.DetectionModeMerge,
+				ConflictPolicy:     internal.ConflictPolicyFirst,
+				SchemaURLPolicy:    internal.SchemaURLPolicyMerge,
+				AttributeValues: map[string]internal.AttributeValueFilter{
+					"host.name": {Exclude: []string{"localhost", "unknown"}},
+					"os.type":   {Include: []string{"linux", "windows"}},
+				},
+			},
+		},
+		{
+			id:           component.NewIDWithName(typeStr, "invalid_attribute_values"),
+			errorMessage: "attribute_values contains an empty attribute key",
+		},
+		{
+			id: component.NewIDWithName(typeStr, "request_timeout"),
+			expected: &Config{
+				ProcessorSettings:  config.NewProcessorSettings(component.NewID(typeStr)),
+				Detectors:          []string{"env", "ec2"},
+				HTTPClientSettings: cfg,
+				RequestTimeout:     time.Second,
+				Override:           false,
+				DetectionMode:      internal.DetectionModeMerge,
+				ConflictPolicy:     internal.ConflictPolicyFirst,
+				SchemaURLPolicy:    internal.SchemaURLPolicyMerge,
+			},
+		},
+		{
+			id:           component.NewIDWithName(typeStr, "invalid_request_timeout"),
+			errorMessage: "request_timeout must not be negative: -1s",
+		},
+		{
+			id: component.NewIDWithName(typeStr, "cache"),
+			expected: &Config{
+				ProcessorSettings:  config.NewProcessorSettings(component.NewID(typeStr)),
+				Detectors:          []string{"env", "system"},
+				HTTPClientSettings: cfg,
+				Override:           false,
+				DetectionMode:      internal.DetectionModeMerge,
+				ConflictPolicy:     internal.ConflictPolicyFirst,
+				SchemaURLPolicy:    internal.SchemaURLPolicyMerge,
+				Cache: &CacheConfig{
+					StorageID: &storageID,
+					TTL:       24 * time.Hour,
+				},
+			},
+		},
+		{
+			id:           component.NewIDWithName(typeStr, "invalid_cache"),
+			errorMessage: "cache.storage must be set",
+		},
+		{
+			id: component.NewIDWithName(typeStr, "priorities"),
+			expected: &Config{
+				ProcessorSettings:  config.NewProcessorSettings(component.NewID(typeStr)),
+				Detectors:          []string{"env", "ec2", "system"},
+				Priorities:         map[string]int{"ec2": 10, "env": 5},
+				HTTPClientSettings: cfg,
+				Override:           false,
+				DetectionMode:      internal.DetectionModeMerge,
+				ConflictPolicy:     internal.ConflictPolicyFirst,
+				SchemaURLPolicy:    internal.SchemaURLPolicyMerge,
+			},
+		},
+		{
+			id: component.NewIDWithName(typeStr, "conditions"),
+			expected: &Config{
+				ProcessorSettings: config.NewProcessorSettings(component.NewID(typeStr)),
+				Detectors:         []string{"azure", "aks"},
+				Conditions: map[string]internal.DetectorCondition{
+					"aks": {Attribute: "cloud.provider", Values: []string{"azure"}},
+				},
+				HTTPClientSettings: cfg,
+				Override:           false,
+				DetectionMode:      internal.DetectionModeMerge,
+				ConflictPolicy:     internal.ConflictPolicyFirst,
+				SchemaURLPolicy:    internal.SchemaURLPolicyMerge,
+			},
+		},
+		{
+			id:           component.NewIDWithName(typeStr, "invalid_conditions"),
+			errorMessage: "conditions.aks.attribute must not be empty",
+		},
+		{
+			id: component.NewIDWithName(typeStr, "readiness"),
+			expected: &Config{
+				ProcessorSettings:  config.NewProcessorSettings(component.NewID(typeStr)),
+				Detectors:          []string{"ec2", "system"},
+				HTTPClientSettings: cfg,
+				Override:           false,
+				DetectionMode:      internal.DetectionModeMerge,
+				ConflictPolicy:     internal.ConflictPolicyFirst,
+				SchemaURLPolicy:    internal.SchemaURLPolicyMerge,
+				Readiness: &ReadinessConfig{
+					Attributes:    []string{"cloud.instance.id"},
+					MaxWait:       time.Minute,
+					RetryInterval: 5 * time.Second,
+				},
+			},
+		},
+		{
+			id:           component.NewIDWithName(typeStr, "invalid_readiness"),
+			errorMessage: "readiness.max_wait must be positive: 0s",
+		},
+		{
+			id:           component.NewIDWithName(typeStr, "invalid_priorities"),
+			errorMessage: "priorities contains \"ec2\", which is not a configured detector",
+		},
+		{
+			id:           component.NewIDWithName(typeStr, "invalid_detection_mode"),
+			errorMessage: "detection_mode contains invalid value: \"all\"",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.id.String(), func(t *testing.T) {
@@ -148,6 +360,18 @@ func TestGetConfigFromType(t *testing.T) {
 				HostnameSources: []string{"os"},
 			},
 		},
+		{
+			name:         "Get Named Instance Config",
+			detectorType: internal.DetectorType("system/other"),
+			inputDetectorConfig: DetectorConfig{
+				SystemConfig: system.Config{
+					HostnameSources: []string{"os"},
+				},
+			},
+			expectedConfig: system.Config{
+				HostnameSources: []string{"os"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -157,3 +381,17 @@ func TestGetConfigFromType(t *testing.T) {
 		})
 	}
 }
+
+func TestGetConfigFromTypeWithInstances(t *testing.T) {
+	configs := &detectorConfigs{
+		DetectorConfig: DetectorConfig{
+			SystemConfig: system.Config{HostnameSources: []string{"os"}},
+		},
+		instances: map[string]DetectorConfig{
+			"system/dns": {SystemConfig: system.Config{HostnameSources: []string{"dns"}}},
+		},
+	}
+	assert.Equal(t, system.Config{HostnameSources: []string{"dns"}}, configs.GetConfigFromType("system/dns"))
+	assert.Equal(t, system.Config{HostnameSources: []string{"os"}}, configs.GetConfigFromType("system/other"))
+	assert.Equal(t, system.Config{HostnameSources: []string{"os"}}, configs.GetConfigFromType(system.TypeStr))
+}
@@ -27,17 +27,30 @@ import (
 	"go.opentelemetry.io/collector/processor/processorhelper"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/alibaba"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/aws/ec2"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/aws/ecs"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/aws/eks"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/aws/elasticbeanstalk"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/azure"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/azure/aks"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/cloudfoundry"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/consul"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/docker"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/env"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/flyio"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/gcp"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/k8spodlabels"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/k8ssatoken"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/machineid"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/nomad"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/openstack"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/railway"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/render"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/socket"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/system"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/tencent"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/vsphere"
 )
 
 const (
@@ -45,6 +58,8 @@ const (
 	typeStr = "resourcedetection"
 	// The stability level of the processor.
 	stability = component.StabilityLevelBeta
+	// The interval at which the detection is retried until the resource is ready, if not configured.
+	defaultReadinessRetryInterval = time.Second
 )
 
 var consumerCapabilities = consumer.Capabilities{MutatesData: true}
@@ -62,7 +77,9 @@ type factory struct {
 func NewFactory() component.ProcessorFactory {
 	resourceProviderFactory := internal.NewProviderFactory(map[internal.DetectorType]internal.DetectorFactory{
 		aks.TypeStr:              aks.NewDetector,
+		alibaba.TypeStr:          alibaba.NewDetector,
 		azure.TypeStr:            azure.NewDetector,
+		cloudfoundry.TypeStr:     cloudfoundry.NewDetector,
 		consul.TypeStr:           consul.NewDetector,
 		docker.TypeStr:           docker.NewDetector,
 		ec2.TypeStr:              ec2.NewDetector,
@@ -70,11 +87,22 @@ func NewFactory() component.ProcessorFactory {
 		eks.TypeStr:              eks.NewDetector,
 		elasticbeanstalk.TypeStr: elasticbeanstalk.NewDetector,
 		env.TypeStr:              env.NewDetector,
+		flyio.TypeStr:            flyio.NewDetector,
 		gcp.TypeStr:              gcp.NewDetector,
 		// TODO(#10348): Remove GKE and GCE after the v0.54.0 release.
 		gcp.DeprecatedGKETypeStr: gcp.NewDetector,
 		gcp.DeprecatedGCETypeStr: gcp.NewDetector,
+		k8spodlabels.TypeStr:     k8spodlabels.NewDetector,
+		k8ssatoken.TypeStr:       k8ssatoken.NewDetector,
+		machineid.TypeStr:        machineid.NewDetector,
+		nomad.TypeStr:            nomad.NewDetector,
+		openstack.TypeStr:        openstack.NewDetector,
+		railway.TypeStr:          railway.NewDetector,
+		render.TypeStr:           render.NewDetector,
+		socket.TypeStr:           socket.NewDetector,
 		system.TypeStr:           system.NewDetector,
+		tencent.TypeStr:          tencent.NewDetector,
+		vsphere.TypeStr:          vsphere.NewDetector,
 	})
 
 	f := &factory{
@@ -102,6 +130,9 @@ func createDefaultConfig() component.ProcessorConfig {
 		HTTPClientSettings: defaultHTTPClientSettings(),
 		Override:           true,
 		Attributes:         nil,
+		DetectionMode:      internal.DetectionModeMerge,
+		ConflictPolicy:     internal.ConflictPolicyFirst,
+		SchemaURLPolicy:    internal.SchemaURLPolicyMerge,
 		// TODO: Once issue(https://github.com/open-telemetry/opentelemetry-collector/issues/4001) gets resolved,
 		// 		 Set the default value of 'hostname_source' here instead of 'system' detector
 	}
@@ -131,7 +162,8 @@ func (f *factory) createTracesProcessor(
 		nextConsumer,
 		rdp.processTraces,
 		processorhelper.WithCapabilities(consumerCapabilities),
-		processorhelper.WithStart(rdp.Start))
+		processorhelper.WithStart(rdp.Start),
+		processorhelper.WithShutdown(rdp.Shutdown))
 }
 
 func (f *factory) createMetricsProcessor(
@@ -152,7 +184,8 @@ func (f *factory) createMetricsProcessor(
 		nextConsumer,
 		rdp.processMetrics,
 		processorhelper.WithCapabilities(consumerCapabilities),
-		processorhelper.WithStart(rdp.Start))
+		processorhelper.WithStart(rdp.Start),
+		processorhelper.WithShutdown(rdp.Shutdown))
 }
 
 func (f *factory) createLogsProcessor(
@@ -173,7 +206,8 @@ func (f *factory) createLogsProcessor(
 		nextConsumer,
 		rdp.processLogs,
 		processorhelper.WithCapabilities(consumerCapabilities),
-		processorhelper.WithStart(rdp.Start))
+		processorhelper.WithStart(rdp.Start),
+		processorhelper.WithShutdown(rdp.Shutdown))
 }
 
 func (f *factory) getResourceDetectionProcessor(
@@ -182,7 +216,7 @@ func (f *factory) getResourceDetectionProcessor(
 ) (*resourceDetectionProcessor, error) {
 	oCfg := cfg.(*Config)
 
-	provider, err := f.getResourceProvider(params, cfg.ID(), oCfg.HTTPClientSettings.Timeout, oCfg.Detectors, oCfg.DetectorConfig, oCfg.Attributes)
+	provider, err := f.getResourceProvider(params, cfg.ID(), oCfg)
 	if err != nil {
 		return nil, err
 	}
@@ -190,6 +224,8 @@ func (f *factory) getResourceDetectionProcessor(
 	return &resourceDetectionProcessor{
 		provider:           provider,
 		override:           oCfg.Override,
+		preferNonEmpty:     oCfg.PreferNonEmpty,
+		schemaURLPolicy:    oCfg.SchemaURLPolicy,
 		httpClientSettings: oCfg.HTTPClientSettings,
 		telemetrySettings:  params.TelemetrySettings,
 	}, nil
@@ -198,10 +234,7 @@ func (f *factory) getResourceDetectionProcessor(
 func (f *factory) getResourceProvider(
 	params component.ProcessorCreateSettings,
 	processorName component.ID,
-	timeout time.Duration,
-	configuredDetectors []string,
-	detectorConfigs DetectorConfig,
-	attributes []string,
+	cfg *Config,
 ) (*internal.ResourceProvider, error) {
 	f.lock.Lock()
 	defer f.lock.Unlock()
@@ -211,14 +244,15 @@ func (f *factory) getResourceProvider(
 	}
 
 	// TODO(#10348): Remove this after the v0.54.0 release.
-	configuredDetectors = gcp.DeduplicateDetectors(params, configuredDetectors)
+	configuredDetectors := gcp.DeduplicateDetectors(params, cfg.Detectors)
 
 	detectorTypes := make([]internal.DetectorType, 0, len(configuredDetectors))
 	for _, key := range configuredDetectors {
 		detectorTypes = append(detectorTypes, internal.DetectorType(strings.TrimSpace(key)))
 	}
 
-	provider, err := f.resourceProviderFactory.CreateResourceProvider(params, timeout, attributes, &detectorConfigs, detectorTypes...)
+	configs := &detectorConfigs{DetectorConfig: cfg.DetectorConfig, instances: cfg.DetectorInstances}
+	provider, err := f.resourceProviderFactory.CreateResourceProvider(params, cfg.HTTPClientSettings.Timeout, cfg.Attributes, providerSettings(processorName, cfg, detectorTypes), configs, detectorTypes...)
 	if err != nil {
 		return nil, err
 	}
@@ -226,3 +260,46 @@ func (f *factory) getResourceProvider(
 	f.providers[processorName] = provider
 	return provider, nil
 }
+
+// providerSettings returns the settings of the resource provider of the processor, the priorities and
+// conditions of the detectors are in the order of the detector types.
+func providerSettings(processorName component.ID, cfg *Config, detectorTypes []internal.DetectorType) internal.ProviderSettings {
+	settings := internal.ProviderSettings{
+		Mode:               cfg.DetectionMode,
+		ConflictPolicy:     cfg.ConflictPolicy,
+		AttributeTemplates: cfg.AttributeTemplates,
+		AttributeValues:    cfg.AttributeValues,
+		RequestTimeout:     cfg.RequestTimeout,
+		PreferNonEmpty:     cfg.PreferNonEmpty,
+	}
+
+	if len(cfg.Priorities) > 0 {
+		settings.Priorities = make([]int, len(detectorTypes))
+		for i, detectorType := range detectorTypes {
+			settings.Priorities[i] = cfg.Priorities[string(detectorType)]
+		}
+	}
+
+	if len(cfg.Conditions) > 0 {
+		settings.Conditions = make([]*internal.DetectorCondition, len(detectorTypes))
+		for i, detectorType := range detectorTypes {
+			if condition, ok := cfg.Conditions[string(detectorType)]; ok {
+				settings.Conditions[i] = &condition
+			}
+		}
+	}
+
+	if cfg.Readiness != nil {
+		settings.ReadinessAttributes = cfg.Readiness.Attributes
+		settings.ReadinessMaxWait = cfg.Readiness.MaxWait
+		settings.ReadinessInterval = cfg.Readiness.RetryInterval
+		if settings.ReadinessInterval == 0 {
+			settings.ReadinessInterval = defaultReadinessRetryInterval
+		}
+	}
+
+	if cfg.Cache != nil && cfg.Cache.StorageID != nil {
+		settings.Cache = internal.NewResourceCache(*cfg.Cache.StorageID, processorName, cfg.Cache.TTL)
+	}
+	return settings
+}
@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package alibaba provides a detector that loads resource information from
+// the metadata service of Alibaba Cloud ECS instances.
+package alibaba // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/alibaba"
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+const (
+	// TypeStr is type of detector.
+	TypeStr = "alibaba"
+
+	defaultEndpoint = "http://100.100.100.200"
+	instanceIDPath  = "/latest/meta-data/instance-id"
+	regionPath      = "/latest/meta-data/region-id"
+	zonePath        = "/latest/meta-data/zone-id"
+)
+
+var _ internal.Detector = (*Detector)(nil)
+
+// Detector is an Alibaba Cloud ECS metadata detector
+type Detector struct {
+	// endpoint is the base URL of the metadata service
+	endpoint string
+	logger   *zap.Logger
+}
+
+// NewDetector creates a new Alibaba Cloud ECS metadata detector
+func NewDetector(p component.ProcessorCreateSettings, _ internal.DetectorConfig) (internal.Detector, error) {
+	return &Detector{
+		endpoint: defaultEndpoint,
+		logger:   p.Logger,
+	}, nil
+}
+
+// Detect detects Alibaba Cloud ECS instance metadata and returns a resource with the available ones
+func (d *Detector) Detect(ctx context.Context) (resource pcommon.Resource, schemaURL string, err error) {
+	res := pcommon.NewResource()
+
+	client, err := internal.ClientFromContext(ctx)
+	if err != nil {
+		client = http.DefaultClient
+		d.logger.Debug("Error retrieving client from context thus creating default", zap.Error(err))
+	}
+
+	instanceID, err := d.get(ctx, client, instanceIDPath)
+	if err != nil {
+		d.logger.Debug("Alibaba Cloud metadata unavailable", zap.Error(err))
+		// return an empty Resource and no error
+		return res, "", nil
+	}
+	region, err := d.get(ctx, client, regionPath)
+	if err != nil {
+		return res, "", fmt.Errorf("failed getting region: %w", err)
+	}
+	zone, err := d.get(ctx, client, zonePath)
+	if err != nil {
+		return res, "", fmt.Errorf("failed getting zone: %w", err)
+	}
+
+	attrs := res.Attributes()
+	attrs.PutStr(conventions.AttributeCloudProvider, conventions.AttributeCloudProviderAlibabaCloud)
+	attrs.PutStr(conventions.AttributeCloudRegion, region)
+	attrs.PutStr(conventions.AttributeCloudAvailabilityZone, zone)
+	attrs.PutStr(conventions.AttributeHostID, instanceID)
+
+	return res, conventions.SchemaURL, nil
+}
+
+// get returns the value of the metadata item at the given path, every request is bounded
+// individually by the request timeout, if any
+func (d *Detector) get(ctx context.Context, client *http.Client, path string) (string, error) {
+	reqCtx, cancel := internal.RequestContext(ctx)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, d.endpoint+path, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata service responded to %s with status %d", path, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSpace(string(body))
+	if value == "" {
+		return "", fmt.Errorf("metadata service responded to %s with an empty value", path)
+	}
+	return value, nil
+}
@@ -49,16 +49,12 @@ type Detector struct {
 
 func NewDetector(set component.ProcessorCreateSettings, dcfg internal.DetectorConfig) (internal.Detector, error) {
 	cfg := dcfg.(Config)
-	sess, err := session.NewSession()
-	if err != nil {
-		return nil, err
-	}
 	tagKeyRegexes, err := compileRegexes(cfg)
 	if err != nil {
 		return nil, err
 	}
 	return &Detector{
-		metadataProvider: ec2provider.NewProvider(sess),
+		metadataProvider: newIMDSProvider(set.Logger),
 		tagKeyRegexes:    tagKeyRegexes,
 		logger:           set.Logger,
 	}, nil
@@ -66,17 +62,25 @@ func NewDetector(set component.ProcessorCreateSettings, dcfg internal.DetectorCo
 
 func (d *Detector) Detect(ctx context.Context) (resource pcommon.Resource, schemaURL string, err error) {
 	res := pcommon.NewResource()
-	if _, err = d.metadataProvider.InstanceID(ctx); err != nil {
+	// every metadata request is bounded individually by the request timeout, if any
+	reqCtx, cancel := internal.RequestContext(ctx)
+	_, err = d.metadataProvider.InstanceID(reqCtx)
+	cancel()
+	if err != nil {
 		d.logger.Debug("EC2 metadata unavailable", zap.Error(err))
 		return res, "", nil
 	}
 
-	meta, err := d.metadataProvider.Get(ctx)
+	reqCtx, cancel = internal.RequestContext(ctx)
+	meta, err := d.metadataProvider.Get(reqCtx)
+	cancel()
 	if err != nil {
 		return res, "", fmt.Errorf("failed getting identity document: %w", err)
 	}
 
-	hostname, err := d.metadataProvider.Hostname(ctx)
+	reqCtx, cancel = internal.RequestContext(ctx)
+	hostname, err := d.metadataProvider.Hostname(reqCtx)
+	cancel()
 	if err != nil {
 		return res, "", fmt.Errorf("failed getting hostname: %w", err)
 	}
@@ -94,7 +98,9 @@ func (d *Detector) Detect(ctx context.Context) (resource pcommon.Resource, schem
 
 	if len(d.tagKeyRegexes) != 0 {
 		client := getHTTPClientSettings(ctx, d.logger)
-		tags, err := connectAndFetchEc2Tags(meta.Region, meta.InstanceID, d.tagKeyRegexes, client)
+		reqCtx, cancel = internal.RequestContext(ctx)
+		tags, err := connectAndFetchEc2Tags(reqCtx, meta.Region, meta.InstanceID, d.tagKeyRegexes, client)
+		cancel()
 		if err != nil {
 			return res, "", fmt.Errorf("failed fetching ec2 instance tags: %w", err)
 		}
@@ -115,7 +121,7 @@ func getHTTPClientSettings(ctx context.Context, logger *zap.Logger) *http.Client
 	return client
 }
 
-func connectAndFetchEc2Tags(region string, instanceID string, tagKeyRegexes []*regexp.Regexp, client *http.Client) (map[string]string, error) {
+func connectAndFetchEc2Tags(ctx context.Context, region string, instanceID string, tagKeyRegexes []*regexp.Regexp, client *http.Client) (map[string]string, error) {
 	sess, err := session.NewSession(&aws.Config{
 		Region:     aws.String(region),
 		HTTPClient: client},
@@ -125,11 +131,11 @@ func connectAndFetchEc2Tags(region string, instanceID string, tagKeyRegexes []*r
 	}
 	e := ec2.New(sess)
 
-	return fetchEC2Tags(e, instanceID, tagKeyRegexes)
+	return fetchEC2Tags(ctx, e, instanceID, tagKeyRegexes)
 }
 
-func fetchEC2Tags(svc ec2iface.EC2API, instanceID string, tagKeyRegexes []*regexp.Regexp) (map[string]string, error) {
-	ec2Tags, err := svc.DescribeTags(&ec2.DescribeTagsInput{
+func fetchEC2Tags(ctx context.Context, svc ec2iface.EC2API, instanceID string, tagKeyRegexes []*regexp.Regexp) (map[string]string, error) {
+	ec2Tags, err := svc.DescribeTagsWithContext(ctx, &ec2.DescribeTagsInput{
 		Filters: []*ec2.Filter{{
 			Name: aws.String("resource-id"),
 			Values: []*string{
@@ -19,8 +19,11 @@ import (
 	"errors"
 	"regexp"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	"github.com/stretchr/testify/assert"
@@ -201,13 +204,55 @@ func TestDetector_Detect(t *testing.T) {
 	}
 }
 
+func TestDetector_DetectRequestTimeout(t *testing.T) {
+	metadata := &deadlineMetadata{}
+	d := &Detector{metadataProvider: metadata, logger: zap.NewNop()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	start := time.Now()
+	_, _, err := d.Detect(internal.ContextWithRequestTimeout(ctx, time.Second))
+	require.NoError(t, err)
+
+	// every metadata request gets its own deadline from the request timeout, not the overall one
+	require.Len(t, metadata.deadlines, 3)
+	for _, deadline := range metadata.deadlines {
+		assert.WithinDuration(t, start.Add(time.Second), deadline, 500*time.Millisecond)
+	}
+}
+
+// deadlineMetadata records the deadline of the context of every metadata request
+type deadlineMetadata struct {
+	deadlines []time.Time
+}
+
+func (dm *deadlineMetadata) record(ctx context.Context) {
+	deadline, _ := ctx.Deadline()
+	dm.deadlines = append(dm.deadlines, deadline)
+}
+
+func (dm *deadlineMetadata) InstanceID(ctx context.Context) (string, error) {
+	dm.record(ctx)
+	return "i-abcd1234", nil
+}
+
+func (dm *deadlineMetadata) Get(ctx context.Context) (ec2metadata.EC2InstanceIdentityDocument, error) {
+	dm.record(ctx)
+	return ec2metadata.EC2InstanceIdentityDocument{InstanceID: "i-abcd1234"}, nil
+}
+
+func (dm *deadlineMetadata) Hostname(ctx context.Context) (string, error) {
+	dm.record(ctx)
+	return "example-hostname", nil
+}
+
 // Define a mock client to mock connecting to an EC2 instance
 type mockEC2Client struct {
 	ec2iface.EC2API
 }
 
-// override the DescribeTags function to mock the output from an actual EC2 instance
-func (m *mockEC2Client) DescribeTags(input *ec2.DescribeTagsInput) (*ec2.DescribeTagsOutput, error) {
+// override the DescribeTagsWithContext function to mock the output from an actual EC2 instance
+func (m *mockEC2Client) DescribeTagsWithContext(_ aws.Context, input *ec2.DescribeTagsInput, _ ...request.Option) (*ec2.DescribeTagsOutput, error) {
 	if *input.Filters[0].Values[0] == "error" {
 		return nil, errors.New("error")
 	}
@@ -268,7 +313,7 @@ func TestEC2Tags(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := &mockEC2Client{}
-			output, err := fetchEC2Tags(m, tt.resourceID, tt.tagKeyRegexes)
+			output, err := fetchEC2Tags(context.Background(), m, tt.resourceID, tt.tagKeyRegexes)
 			if tt.shouldError {
 				assert.Error(t, err)
 				return
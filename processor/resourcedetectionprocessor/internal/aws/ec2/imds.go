@@ -0,0 +1,195 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ec2 // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/aws/ec2"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"go.uber.org/zap"
+
+	ec2provider "github.com/open-telemetry/opentelemetry-collector-contrib/internal/metadataproviders/aws/ec2"
+)
+
+const (
+	defaultIMDSEndpoint = "http://169.254.169.254"
+	// imdsEndpointEnvVar overrides the endpoint of IMDS, as it does for the AWS SDK
+	imdsEndpointEnvVar = "AWS_EC2_METADATA_SERVICE_ENDPOINT"
+
+	tokenPath      = "/latest/api/token"
+	tokenHeader    = "X-aws-ec2-metadata-token"
+	tokenTTLHeader = "X-aws-ec2-metadata-token-ttl-seconds"
+	tokenTTL       = 6 * time.Hour
+	// tokenExpiryWindow renews the token before it expires, so that it does not expire during a request
+	tokenExpiryWindow = time.Minute
+
+	instanceIDPath       = "/latest/meta-data/instance-id"
+	hostnamePath         = "/latest/meta-data/hostname"
+	identityDocumentPath = "/latest/dynamic/instance-identity/document"
+)
+
+var _ ec2provider.Provider = (*imdsProvider)(nil)
+
+// imdsProvider reads the metadata of the instance from IMDS with the HTTP client of the processor. It uses
+// IMDSv2, every metadata request carries a session token requested from IMDS first, and falls back to IMDSv1
+// requests without a token if IMDSv2 is disabled, that is if the token endpoint is not available or the token
+// request fails, as the AWS SDK does.
+type imdsProvider struct {
+	endpoint string
+	logger   *zap.Logger
+
+	mu         sync.Mutex
+	token      string
+	expiration time.Time
+	// v1 is set once the token endpoint is not available, until a request without a token is unauthorized
+	v1 bool
+}
+
+func newIMDSProvider(logger *zap.Logger) *imdsProvider {
+	endpoint := defaultIMDSEndpoint
+	if env := os.Getenv(imdsEndpointEnvVar); env != "" {
+		endpoint = env
+	}
+	return &imdsProvider{endpoint: endpoint, logger: logger}
+}
+
+func (p *imdsProvider) InstanceID(ctx context.Context) (string, error) {
+	body, err := p.get(ctx, instanceIDPath)
+	return string(body), err
+}
+
+func (p *imdsProvider) Hostname(ctx context.Context) (string, error) {
+	body, err := p.get(ctx, hostnamePath)
+	return string(body), err
+}
+
+func (p *imdsProvider) Get(ctx context.Context) (ec2metadata.EC2InstanceIdentityDocument, error) {
+	var document ec2metadata.EC2InstanceIdentityDocument
+	body, err := p.get(ctx, identityDocumentPath)
+	if err != nil {
+		return document, err
+	}
+	if err = json.Unmarshal(body, &document); err != nil {
+		return document, fmt.Errorf("failed to decode the instance identity document: %w", err)
+	}
+	return document, nil
+}
+
+// get requests the metadata at the given path. A request that is unauthorized is sent once more with a new
+// token, as the token may have been revoked or IMDSv2 enabled since the token endpoint was not available.
+func (p *imdsProvider) get(ctx context.Context, path string) ([]byte, error) {
+	client := getHTTPClientSettings(ctx, p.logger)
+	var status int
+	for attempt := 0; attempt < 2; attempt++ {
+		token, err := p.sessionToken(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.endpoint+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			req.Header.Set(tokenHeader, token)
+		}
+		var body []byte
+		body, status, err = send(client, req)
+		if err != nil {
+			return nil, err
+		}
+		if status == http.StatusOK {
+			return body, nil
+		}
+		if status != http.StatusUnauthorized {
+			break
+		}
+		p.resetToken()
+	}
+	return nil, fmt.Errorf("IMDS responded to %s with status %d", path, status)
+}
+
+// sessionToken returns the IMDSv2 token the metadata requests carry, or an empty string if IMDSv2 is disabled
+func (p *imdsProvider) sessionToken(ctx context.Context, client *http.Client) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.v1 {
+		return "", nil
+	}
+	if p.token != "" && time.Now().Before(p.expiration) {
+		return p.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, p.endpoint+tokenPath, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set(tokenTTLHeader, strconv.Itoa(int(tokenTTL/time.Second)))
+	body, status, err := send(client, req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("failed requesting the IMDSv2 token: %w", err)
+		}
+		// like the AWS SDK, IMDSv2 is considered disabled if the token request fails, such as when
+		// it times out because the hop limit of the instance does not let the response reach it
+		p.logger.Debug("IMDSv2 token request failed, falling back to IMDSv1", zap.Error(err))
+		p.v1 = true
+		return "", nil
+	}
+	switch status {
+	case http.StatusOK:
+		p.token = string(body)
+		p.expiration = time.Now().Add(tokenTTL - tokenExpiryWindow)
+		return p.token, nil
+	case http.StatusForbidden, http.StatusNotFound, http.StatusMethodNotAllowed:
+		p.logger.Debug("IMDSv2 is not available, falling back to IMDSv1", zap.Int("status", status))
+		p.v1 = true
+		return "", nil
+	case http.StatusBadRequest:
+		return "", fmt.Errorf("IMDS responded to the IMDSv2 token request with status %d", status)
+	default:
+		// like the AWS SDK, the request is sent without a token, IMDSv2 being requested again by the next one
+		p.logger.Debug("IMDSv2 token request failed, sending the request without a token", zap.Int("status", status))
+		return "", nil
+	}
+}
+
+// resetToken discards the token, and enables IMDSv2 again if it fell back to IMDSv1
+func (p *imdsProvider) resetToken() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.token = ""
+	p.v1 = false
+}
+
+func send(client *http.Client, req *http.Request) ([]byte, int, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, resp.StatusCode, nil
+}
@@ -0,0 +1,231 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ec2
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+const testIdentityDocument = `{
+	"region": "us-west-2",
+	"accountId": "123456789012",
+	"availabilityZone": "us-west-2a",
+	"instanceId": "i-abcd1234",
+	"imageId": "ami-abcd1234",
+	"instanceType": "c5.large"
+}`
+
+// mockIMDS serves the metadata of an instance, requiring the IMDSv2 token on every metadata request unless v1Only
+// is set, in which case the token endpoint is not available as for an instance with IMDSv2 disabled
+type mockIMDS struct {
+	v1Only bool
+	// tokenStatus is the status the token endpoint responds with instead of a token, if set
+	tokenStatus int
+	// tokenHang blocks the token requests until it is closed, if set
+	tokenHang chan struct{}
+
+	mu            sync.Mutex
+	tokenRequests int
+	// tokens are the tokens the metadata requests carried, in order
+	tokens []string
+}
+
+func (m *mockIMDS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == tokenPath && m.tokenHang != nil {
+		<-m.tokenHang
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if r.URL.Path == tokenPath {
+		m.tokenRequests++
+		if m.tokenStatus != 0 {
+			w.WriteHeader(m.tokenStatus)
+			return
+		}
+		if m.v1Only {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.Method != http.MethodPut || r.Header.Get(tokenTTLHeader) == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte("test-token"))
+		return
+	}
+
+	token := r.Header.Get(tokenHeader)
+	m.tokens = append(m.tokens, token)
+	if !m.v1Only && token != "test-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch r.URL.Path {
+	case instanceIDPath:
+		_, _ = w.Write([]byte("i-abcd1234"))
+	case hostnamePath:
+		_, _ = w.Write([]byte("example-hostname"))
+	case identityDocumentPath:
+		_, _ = w.Write([]byte(testIdentityDocument))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestIMDSProvider(t *testing.T) {
+	tests := []struct {
+		name           string
+		v1Only         bool
+		expectedTokens []string
+	}{
+		{
+			name:           "IMDSv2",
+			expectedTokens: []string{"test-token", "test-token", "test-token"},
+		},
+		{
+			name:           "fallback to IMDSv1",
+			v1Only:         true,
+			expectedTokens: []string{"", "", ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imds := &mockIMDS{v1Only: tt.v1Only}
+			server := httptest.NewServer(imds)
+			defer server.Close()
+
+			d := &Detector{metadataProvider: &imdsProvider{endpoint: server.URL, logger: zap.NewNop()}, logger: zap.NewNop()}
+			ctx := internal.ContextWithClient(context.Background(), server.Client())
+			res, _, err := d.Detect(ctx)
+			require.NoError(t, err)
+
+			assert.Equal(t, map[string]interface{}{
+				"cloud.provider":          "aws",
+				"cloud.platform":          "aws_ec2",
+				"cloud.region":            "us-west-2",
+				"cloud.account.id":        "123456789012",
+				"cloud.availability_zone": "us-west-2a",
+				"host.id":                 "i-abcd1234",
+				"host.image.id":           "ami-abcd1234",
+				"host.type":               "c5.large",
+				"host.name":               "example-hostname",
+			}, res.Attributes().AsRaw())
+			// the token, or the unavailability of the token endpoint, is requested once for all metadata requests
+			assert.Equal(t, 1, imds.tokenRequests)
+			assert.Equal(t, tt.expectedTokens, imds.tokens)
+		})
+	}
+}
+
+func TestIMDSProviderRenewsRevokedToken(t *testing.T) {
+	imds := &mockIMDS{}
+	server := httptest.NewServer(imds)
+	defer server.Close()
+
+	provider := &imdsProvider{endpoint: server.URL, logger: zap.NewNop()}
+	ctx := internal.ContextWithClient(context.Background(), server.Client())
+	provider.token = "revoked-token"
+	provider.expiration = time.Now().Add(time.Hour)
+
+	instanceID, err := provider.InstanceID(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "i-abcd1234", instanceID)
+	assert.Equal(t, 1, imds.tokenRequests)
+	assert.Equal(t, []string{"revoked-token", "test-token"}, imds.tokens)
+}
+
+func TestIMDSProviderTokenError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	provider := &imdsProvider{endpoint: server.URL, logger: zap.NewNop()}
+	_, err := provider.InstanceID(internal.ContextWithClient(context.Background(), server.Client()))
+	assert.ErrorContains(t, err, "status 400")
+}
+
+func TestIMDSProviderFallsBackWhenTokenRequestHangs(t *testing.T) {
+	imds := &mockIMDS{v1Only: true, tokenHang: make(chan struct{})}
+	server := httptest.NewServer(imds)
+	defer server.Close()
+	defer close(imds.tokenHang)
+
+	client := server.Client()
+	client.Timeout = 50 * time.Millisecond
+	ctx := internal.ContextWithClient(context.Background(), client)
+	provider := &imdsProvider{endpoint: server.URL, logger: zap.NewNop()}
+	instanceID, err := provider.InstanceID(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "i-abcd1234", instanceID)
+	assert.True(t, provider.v1)
+
+	// the token is not requested again once IMDSv2 is disabled
+	hostname, err := provider.Hostname(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "example-hostname", hostname)
+	assert.Equal(t, []string{"", ""}, imds.tokens)
+}
+
+func TestIMDSProviderTokenServerError(t *testing.T) {
+	imds := &mockIMDS{v1Only: true, tokenStatus: http.StatusInternalServerError}
+	server := httptest.NewServer(imds)
+	defer server.Close()
+
+	provider := &imdsProvider{endpoint: server.URL, logger: zap.NewNop()}
+	ctx := internal.ContextWithClient(context.Background(), server.Client())
+	instanceID, err := provider.InstanceID(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "i-abcd1234", instanceID)
+	// the request is sent without a token, the token being requested again by the next request
+	assert.False(t, provider.v1)
+	_, err = provider.Hostname(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, imds.tokenRequests)
+	assert.Equal(t, []string{"", ""}, imds.tokens)
+}
+
+func TestIMDSProviderTokenRequestCanceled(t *testing.T) {
+	imds := &mockIMDS{tokenHang: make(chan struct{})}
+	server := httptest.NewServer(imds)
+	defer server.Close()
+	defer close(imds.tokenHang)
+
+	provider := &imdsProvider{endpoint: server.URL, logger: zap.NewNop()}
+	ctx, cancel := context.WithTimeout(internal.ContextWithClient(context.Background(), server.Client()), 50*time.Millisecond)
+	defer cancel()
+	_, err := provider.InstanceID(ctx)
+	assert.ErrorContains(t, err, "failed requesting the IMDSv2 token")
+	// the request context ending does not tell whether IMDSv2 is available
+	assert.False(t, provider.v1)
+}
+
+func TestNewIMDSProviderEndpoint(t *testing.T) {
+	t.Setenv(imdsEndpointEnvVar, "")
+	assert.Equal(t, defaultIMDSEndpoint, newIMDSProvider(zap.NewNop()).endpoint)
+	t.Setenv(imdsEndpointEnvVar, "http://localhost:1338")
+	assert.Equal(t, "http://localhost:1338", newIMDSProvider(zap.NewNop()).endpoint)
+}
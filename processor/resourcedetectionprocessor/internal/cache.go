@@ -0,0 +1,155 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// cacheKey is the key the detected resource is stored with in the storage of the processor.
+const cacheKey = "detected_resource"
+
+// ResourceCache persists the detected resource in a storage extension, so that it can be
+// used instead of running the detectors again when the collector restarts.
+type ResourceCache struct {
+	storageID   component.ID
+	componentID component.ID
+	ttl         time.Duration
+	now         func() time.Time
+
+	lock   sync.Mutex
+	client storage.Client
+}
+
+// cacheEntry is the stored representation of a detected resource. The resource is stored
+// as the OTLP JSON encoding of logs holding the resource, which keeps the types of its
+// attributes and its schema URL.
+type cacheEntry struct {
+	DetectedAt time.Time       `json:"detected_at"`
+	Resource   json.RawMessage `json:"resource"`
+}
+
+// NewResourceCache returns a cache storing the resource detected by the processor with the
+// componentID in the storage extension with the storageID. A stored resource is used for ttl.
+func NewResourceCache(storageID component.ID, componentID component.ID, ttl time.Duration) *ResourceCache {
+	return &ResourceCache{
+		storageID:   storageID,
+		componentID: componentID,
+		ttl:         ttl,
+		now:         time.Now,
+	}
+}
+
+// Start creates the client of the storage extension, it is a no-op if the client was already created.
+func (c *ResourceCache) Start(ctx context.Context, host component.Host) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.client != nil {
+		return nil
+	}
+
+	extension, ok := host.GetExtensions()[c.storageID]
+	if !ok {
+		return fmt.Errorf("storage extension %q not found", c.storageID)
+	}
+	storageExtension, ok := extension.(storage.Extension)
+	if !ok {
+		return fmt.Errorf("non-storage extension %q found", c.storageID)
+	}
+	client, err := storageExtension.GetClient(ctx, component.KindProcessor, c.componentID, "")
+	if err != nil {
+		return fmt.Errorf("failed to get storage client: %w", err)
+	}
+	c.client = client
+	return nil
+}
+
+// Shutdown closes the client of the storage extension.
+func (c *ResourceCache) Shutdown(ctx context.Context) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.client == nil {
+		return nil
+	}
+	err := c.client.Close(ctx)
+	c.client = nil
+	return err
+}
+
+// load returns the stored resource and its schema URL. ok is false if no resource is stored
+// or the stored resource is older than the ttl.
+func (c *ResourceCache) load(ctx context.Context) (res pcommon.Resource, schemaURL string, ok bool, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.client == nil {
+		return pcommon.Resource{}, "", false, nil
+	}
+
+	data, err := c.client.Get(ctx, cacheKey)
+	if err != nil || data == nil {
+		return pcommon.Resource{}, "", false, err
+	}
+	var entry cacheEntry
+	if err = json.Unmarshal(data, &entry); err != nil {
+		return pcommon.Resource{}, "", false, fmt.Errorf("failed to decode cached resource: %w", err)
+	}
+	if c.now().Sub(entry.DetectedAt) >= c.ttl {
+		return pcommon.Resource{}, "", false, nil
+	}
+
+	unmarshaler := plog.JSONUnmarshaler{}
+	logs, err := unmarshaler.UnmarshalLogs(entry.Resource)
+	if err != nil {
+		return pcommon.Resource{}, "", false, fmt.Errorf("failed to decode cached resource: %w", err)
+	}
+	if logs.ResourceLogs().Len() != 1 {
+		return pcommon.Resource{}, "", false, fmt.Errorf("cached resource contains %d resources", logs.ResourceLogs().Len())
+	}
+	rl := logs.ResourceLogs().At(0)
+	return rl.Resource(), rl.SchemaUrl(), true, nil
+}
+
+// store persists the resource and its schema URL, detected now.
+func (c *ResourceCache) store(ctx context.Context, res pcommon.Resource, schemaURL string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.client == nil {
+		return nil
+	}
+
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	res.CopyTo(rl.Resource())
+	rl.SetSchemaUrl(schemaURL)
+	marshaler := plog.JSONMarshaler{}
+	resource, err := marshaler.MarshalLogs(logs)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(cacheEntry{DetectedAt: c.now(), Resource: resource})
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, cacheKey, data)
+}
@@ -0,0 +1,203 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.uber.org/zap"
+)
+
+var (
+	testStorageID   = component.NewID("file_storage")
+	testProcessorID = component.NewID("resourcedetection")
+)
+
+func TestResourceProviderValidCache(t *testing.T) {
+	now := time.Now()
+	host := newStorageHost()
+	cache := startTestCache(t, host, now)
+	require.NoError(t, cache.store(context.Background(), NewResource(map[string]interface{}{"host.name": "cached", "process.pid": int64(42)}), "https://example.com/cached"))
+
+	release := make(chan time.Time)
+	md := &MockDetector{}
+	md.On("Detect").WaitUntil(release).Return(NewResource(map[string]interface{}{"host.name": "detected"}), nil)
+
+	p := NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{Cache: NewResourceCache(testStorageID, testProcessorID, time.Hour)}, md)
+	p.cache.now = func() time.Time { return now }
+	require.NoError(t, p.Start(context.Background(), host))
+
+	// the cached resource is used without waiting for the detectors
+	res, schemaURL, err := p.Get(context.Background(), &http.Client{Timeout: time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"host.name": "cached", "process.pid": int64(42)}, AttributesToMap(res.Attributes()))
+	assert.Equal(t, "https://example.com/cached", schemaURL)
+
+	// the detectors run in the background, refreshing the cache for the next start
+	close(release)
+	require.NoError(t, p.Shutdown(context.Background()))
+	md.AssertNumberOfCalls(t, "Detect", 1)
+
+	cache = startTestCache(t, host, now)
+	res, _, ok, err := cache.load(context.Background())
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"host.name": "detected"}, AttributesToMap(res.Attributes()))
+}
+
+func TestResourceProviderExpiredCache(t *testing.T) {
+	now := time.Now()
+	host := newStorageHost()
+	cache := startTestCache(t, host, now.Add(-2*time.Hour))
+	require.NoError(t, cache.store(context.Background(), NewResource(map[string]interface{}{"host.name": "cached"}), ""))
+
+	md := &MockDetector{}
+	md.On("Detect").Return(NewResource(map[string]interface{}{"host.name": "detected"}), nil)
+
+	p := NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{Cache: NewResourceCache(testStorageID, testProcessorID, time.Hour)}, md)
+	p.cache.now = func() time.Time { return now }
+	require.NoError(t, p.Start(context.Background(), host))
+
+	res, _, err := p.Get(context.Background(), &http.Client{Timeout: time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"host.name": "detected"}, AttributesToMap(res.Attributes()))
+	md.AssertNumberOfCalls(t, "Detect", 1)
+
+	// the detected resource replaces the expired one
+	res, _, ok, err := p.cache.load(context.Background())
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"host.name": "detected"}, AttributesToMap(res.Attributes()))
+	require.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestResourceProviderEmptyCache(t *testing.T) {
+	md := &MockDetector{}
+	md.On("Detect").Return(NewResource(map[string]interface{}{"host.name": "detected"}), nil)
+
+	p := NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{Cache: NewResourceCache(testStorageID, testProcessorID, time.Hour)}, md)
+	require.NoError(t, p.Start(context.Background(), newStorageHost()))
+
+	res, _, err := p.Get(context.Background(), &http.Client{Timeout: time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"host.name": "detected"}, AttributesToMap(res.Attributes()))
+	md.AssertNumberOfCalls(t, "Detect", 1)
+	require.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestResourceCacheStartErrors(t *testing.T) {
+	cache := NewResourceCache(testStorageID, testProcessorID, time.Hour)
+	assert.EqualError(t, cache.Start(context.Background(), componenttest.NewNopHost()), `storage extension "file_storage" not found`)
+
+	host := &storageHost{Host: componenttest.NewNopHost(), extensions: map[component.ID]component.Extension{
+		testStorageID: &nopExtension{},
+	}}
+	assert.EqualError(t, cache.Start(context.Background(), host), `non-storage extension "file_storage" found`)
+}
+
+func startTestCache(t *testing.T, host component.Host, now time.Time) *ResourceCache {
+	cache := NewResourceCache(testStorageID, testProcessorID, time.Hour)
+	cache.now = func() time.Time { return now }
+	require.NoError(t, cache.Start(context.Background(), host))
+	return cache
+}
+
+type storageHost struct {
+	component.Host
+	extensions map[component.ID]component.Extension
+}
+
+func newStorageHost() *storageHost {
+	return &storageHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Extension{testStorageID: &memoryStorage{data: map[string][]byte{}}},
+	}
+}
+
+func (h *storageHost) GetExtensions() map[component.ID]component.Extension {
+	return h.extensions
+}
+
+type nopExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+// memoryStorage is a storage extension keeping the data of all its clients in memory.
+type memoryStorage struct {
+	component.StartFunc
+	component.ShutdownFunc
+	lock sync.Mutex
+	data map[string][]byte
+}
+
+func (s *memoryStorage) GetClient(context.Context, component.Kind, component.ID, string) (storage.Client, error) {
+	return &memoryClient{storage: s}, nil
+}
+
+type memoryClient struct {
+	storage *memoryStorage
+}
+
+func (c *memoryClient) Get(_ context.Context, key string) ([]byte, error) {
+	c.storage.lock.Lock()
+	defer c.storage.lock.Unlock()
+	return c.storage.data[key], nil
+}
+
+func (c *memoryClient) Set(_ context.Context, key string, value []byte) error {
+	c.storage.lock.Lock()
+	defer c.storage.lock.Unlock()
+	c.storage.data[key] = value
+	return nil
+}
+
+func (c *memoryClient) Delete(_ context.Context, key string) error {
+	c.storage.lock.Lock()
+	defer c.storage.lock.Unlock()
+	delete(c.storage.data, key)
+	return nil
+}
+
+func (c *memoryClient) Batch(ctx context.Context, ops ...storage.Operation) error {
+	for _, op := range ops {
+		var err error
+		switch op.Type {
+		case storage.Get:
+			op.Value, err = c.Get(ctx, op.Key)
+		case storage.Set:
+			err = c.Set(ctx, op.Key, op.Value)
+		case storage.Delete:
+			err = c.Delete(ctx, op.Key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *memoryClient) Close(context.Context) error {
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cloudfoundry provides a detector that loads resource information from
+// the VCAP_APPLICATION environment variable that Cloud Foundry injects into every application instance.
+package cloudfoundry // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/cloudfoundry"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+const (
+	// TypeStr is type of detector.
+	TypeStr = "cloudfoundry"
+
+	// vcapApplicationEnvVar holds the metadata of the application as JSON
+	vcapApplicationEnvVar = "VCAP_APPLICATION"
+
+	attributeCloudFoundryAppName   = "cloudfoundry.app.name"
+	attributeCloudFoundryAppID     = "cloudfoundry.app.id"
+	attributeCloudFoundryOrgName   = "cloudfoundry.org.name"
+	attributeCloudFoundrySpaceName = "cloudfoundry.space.name"
+)
+
+var _ internal.Detector = (*Detector)(nil)
+
+type Detector struct {
+	getenv func(string) string
+}
+
+// vcapApplication holds the fields of VCAP_APPLICATION that are detected
+type vcapApplication struct {
+	ApplicationID    string `json:"application_id"`
+	ApplicationName  string `json:"application_name"`
+	OrganizationName string `json:"organization_name"`
+	SpaceName        string `json:"space_name"`
+}
+
+// NewDetector creates a new Cloud Foundry detector
+func NewDetector(component.ProcessorCreateSettings, internal.DetectorConfig) (internal.Detector, error) {
+	return &Detector{getenv: os.Getenv}, nil
+}
+
+func (d *Detector) Detect(context.Context) (resource pcommon.Resource, schemaURL string, err error) {
+	res := pcommon.NewResource()
+
+	// The variable is not set when not running on Cloud Foundry
+	value := d.getenv(vcapApplicationEnvVar)
+	if value == "" {
+		return res, "", nil
+	}
+
+	var app vcapApplication
+	if err = json.Unmarshal([]byte(value), &app); err != nil {
+		return res, "", fmt.Errorf("failed to parse %s: %w", vcapApplicationEnvVar, err)
+	}
+
+	attrs := res.Attributes()
+	putIfNotEmpty(attrs, attributeCloudFoundryAppName, app.ApplicationName)
+	putIfNotEmpty(attrs, attributeCloudFoundryAppID, app.ApplicationID)
+	putIfNotEmpty(attrs, attributeCloudFoundryOrgName, app.OrganizationName)
+	putIfNotEmpty(attrs, attributeCloudFoundrySpaceName, app.SpaceName)
+
+	return res, conventions.SchemaURL, nil
+}
+
+func putIfNotEmpty(attrs pcommon.Map, key string, value string) {
+	if value != "" {
+		attrs.PutStr(key, value)
+	}
+}
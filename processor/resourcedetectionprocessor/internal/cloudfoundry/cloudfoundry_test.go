@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudfoundry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+// testVCAPApplication is the VCAP_APPLICATION of an application instance as injected by Cloud Foundry
+const testVCAPApplication = `{
+  "application_id": "fa05c1a9-0fc1-4fbd-bae1-139850dec7a3",
+  "application_name": "checkout",
+  "application_uris": ["checkout.apps.example.com"],
+  "application_version": "fb8fbcc6-8d58-479e-bcc7-3b4ce5a7f0ca",
+  "cf_api": "https://api.example.com",
+  "limits": {"disk": 1024, "fds": 16384, "mem": 256},
+  "name": "checkout",
+  "organization_id": "c0134da1-1a8b-45e2-a1c8-3bf1e7d0bd5c",
+  "organization_name": "payments",
+  "process_id": "fa05c1a9-0fc1-4fbd-bae1-139850dec7a3",
+  "process_type": "web",
+  "space_id": "06450c72-4669-4dc6-8096-45f9777db68a",
+  "space_name": "production",
+  "uris": ["checkout.apps.example.com"],
+  "version": "fb8fbcc6-8d58-479e-bcc7-3b4ce5a7f0ca"
+}`
+
+func mockEnv(env map[string]string) func(string) string {
+	return func(key string) string {
+		return env[key]
+	}
+}
+
+func TestNewDetector(t *testing.T) {
+	d, err := NewDetector(componenttest.NewNopProcessorCreateSettings(), nil)
+	assert.NotNil(t, d)
+	assert.NoError(t, err)
+}
+
+func TestDetectFull(t *testing.T) {
+	detector := &Detector{getenv: mockEnv(map[string]string{
+		vcapApplicationEnvVar: testVCAPApplication,
+	})}
+	res, schemaURL, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, conventions.SchemaURL, schemaURL)
+	res.Attributes().Sort()
+
+	expected := internal.NewResource(map[string]interface{}{
+		attributeCloudFoundryAppName:   "checkout",
+		attributeCloudFoundryAppID:     "fa05c1a9-0fc1-4fbd-bae1-139850dec7a3",
+		attributeCloudFoundryOrgName:   "payments",
+		attributeCloudFoundrySpaceName: "production",
+	})
+	expected.Attributes().Sort()
+
+	assert.Equal(t, expected, res)
+}
+
+func TestDetectPartial(t *testing.T) {
+	detector := &Detector{getenv: mockEnv(map[string]string{
+		vcapApplicationEnvVar: `{"application_id": "fa05c1a9-0fc1-4fbd-bae1-139850dec7a3", "application_name": "checkout"}`,
+	})}
+	res, schemaURL, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, conventions.SchemaURL, schemaURL)
+	res.Attributes().Sort()
+
+	expected := internal.NewResource(map[string]interface{}{
+		attributeCloudFoundryAppName: "checkout",
+		attributeCloudFoundryAppID:   "fa05c1a9-0fc1-4fbd-bae1-139850dec7a3",
+	})
+	expected.Attributes().Sort()
+
+	assert.Equal(t, expected, res)
+}
+
+func TestDetectNotCloudFoundry(t *testing.T) {
+	detector := &Detector{getenv: mockEnv(map[string]string{})}
+	res, schemaURL, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "", schemaURL)
+	assert.True(t, internal.IsEmptyResource(res))
+}
+
+func TestDetectInvalidVCAPApplication(t *testing.T) {
+	detector := &Detector{getenv: mockEnv(map[string]string{
+		vcapApplicationEnvVar: `{"application_name": "checkout"`,
+	})}
+	res, schemaURL, err := detector.Detect(context.Background())
+	assert.ErrorContains(t, err, "failed to parse VCAP_APPLICATION")
+	assert.Equal(t, "", schemaURL)
+	assert.True(t, internal.IsEmptyResource(res))
+}
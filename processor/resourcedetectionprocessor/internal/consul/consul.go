@@ -16,7 +16,9 @@ package consul // import "github.com/open-telemetry/opentelemetry-collector-cont
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 
 	"github.com/hashicorp/consul/api"
 	"go.opentelemetry.io/collector/component"
@@ -59,7 +61,7 @@ func NewDetector(p component.ProcessorCreateSettings, dcfg internal.DetectorConf
 		cfg.Token = userCfg.Token
 	}
 	if userCfg.TokenFile != "" {
-		cfg.Token = userCfg.TokenFile
+		cfg.TokenFile = userCfg.TokenFile
 	}
 
 	client, err := api.NewClient(cfg)
@@ -76,7 +78,15 @@ func (d *Detector) Detect(ctx context.Context) (resource pcommon.Resource, schem
 	res := pcommon.NewResource()
 	attrs := res.Attributes()
 
-	metadata, err := d.provider.Metadata(ctx)
+	reqCtx, cancel := internal.RequestContext(ctx)
+	defer cancel()
+	metadata, err := d.provider.Metadata(reqCtx)
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		// the collector is not running next to a consul agent
+		d.logger.Debug("Consul agent is unreachable, no consul metadata is detected", zap.Error(err))
+		return pcommon.NewResource(), "", nil
+	}
 	if err != nil {
 		return res, "", fmt.Errorf("failed to get consul metadata: %w", err)
 	}
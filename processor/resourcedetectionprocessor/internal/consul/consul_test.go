@@ -16,11 +16,15 @@ package consul
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
 	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
 	"go.uber.org/zap"
 
@@ -72,3 +76,56 @@ func TestDetect(t *testing.T) {
 
 	assert.Equal(t, expected, res)
 }
+
+func TestDetectFromAgent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/agent/self", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("X-Consul-Token"))
+		fmt.Fprintln(w, `{
+			"Config": {
+				"Datacenter": "dc1",
+				"NodeName": "hostname",
+				"NodeID": "00000000-0000-0000-0000-000000000000"
+			},
+			"Meta": {
+				"test": "test",
+				"environment": "prod"
+			}
+		}`)
+	}))
+	defer ts.Close()
+
+	detector, err := NewDetector(componenttest.NewNopProcessorCreateSettings(), Config{
+		Address:    ts.URL,
+		Token:      "secret",
+		MetaLabels: map[string]interface{}{"test": nil},
+	})
+	require.NoError(t, err)
+	res, schemaURL, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, conventions.SchemaURL, schemaURL)
+	res.Attributes().Sort()
+
+	expected := internal.NewResource(map[string]interface{}{
+		conventions.AttributeHostName:    "hostname",
+		conventions.AttributeCloudRegion: "dc1",
+		conventions.AttributeHostID:      "00000000-0000-0000-0000-000000000000",
+		"test":                           "test",
+	})
+	expected.Attributes().Sort()
+
+	assert.Equal(t, expected, res)
+}
+
+func TestDetectUnreachableAgent(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	address := ts.URL
+	ts.Close()
+
+	detector, err := NewDetector(componenttest.NewNopProcessorCreateSettings(), Config{Address: address})
+	require.NoError(t, err)
+	res, schemaURL, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "", schemaURL)
+	assert.Equal(t, 0, res.Attributes().Len())
+}
@@ -18,11 +18,15 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 type contextKey int
 
-const clientContextKey contextKey = iota
+const (
+	clientContextKey contextKey = iota
+	requestTimeoutContextKey
+)
 
 // ContextWithClient returns a new context.Context with the provided *http.Client stored as a value.
 func ContextWithClient(ctx context.Context, client *http.Client) context.Context {
@@ -43,3 +47,18 @@ func ClientFromContext(ctx context.Context) (*http.Client, error) {
 
 	return c, nil
 }
+
+// ContextWithRequestTimeout returns a new context.Context with the timeout of the individual requests of detectors stored as a value.
+func ContextWithRequestTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, requestTimeoutContextKey, timeout)
+}
+
+// RequestContext returns the context.Context for a single request of a detector, which is bounded by the request
+// timeout stored in ctx. Without a request timeout the request is only bounded by ctx itself.
+func RequestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout, ok := ctx.Value(requestTimeoutContextKey).(time.Duration)
+	if !ok || timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
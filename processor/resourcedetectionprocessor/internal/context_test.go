@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+)
+
+func TestClientFromContext(t *testing.T) {
+	_, err := ClientFromContext(context.Background())
+	assert.Error(t, err)
+
+	client, err := ClientFromContext(ContextWithClient(context.Background(), http.DefaultClient))
+	require.NoError(t, err)
+	assert.Equal(t, http.DefaultClient, client)
+}
+
+func TestRequestContextWithoutTimeout(t *testing.T) {
+	ctx, cancel := RequestContext(context.Background())
+	defer cancel()
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+}
+
+func TestRequestContextBoundedByParent(t *testing.T) {
+	parent, cancelParent := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelParent()
+
+	ctx, cancel := RequestContext(ContextWithRequestTimeout(parent, time.Minute))
+	defer cancel()
+	parentDeadline, _ := parent.Deadline()
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	assert.Equal(t, parentDeadline, deadline)
+}
+
+func TestDetectResource_RequestTimeout(t *testing.T) {
+	// each request hangs until its context is done, so the first one must not consume the time of the second one
+	d := &requestingDetector{requests: 2}
+	p := NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{RequestTimeout: 50 * time.Millisecond}, d)
+
+	start := time.Now()
+	detected, _, err := p.Get(context.Background(), &http.Client{Timeout: 10 * time.Second})
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	require.Len(t, d.errs, 2)
+	for _, err := range d.errs {
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+	require.Len(t, d.durations, 2)
+	for _, duration := range d.durations {
+		assert.GreaterOrEqual(t, duration, 50*time.Millisecond)
+		assert.Less(t, duration, time.Second)
+	}
+	assert.Equal(t, map[string]interface{}{"requests": int64(2)}, detected.Attributes().AsRaw())
+}
+
+// requestingDetector makes a number of requests that only return once their context is done
+type requestingDetector struct {
+	requests  int
+	errs      []error
+	durations []time.Duration
+}
+
+func (d *requestingDetector) Detect(ctx context.Context) (pcommon.Resource, string, error) {
+	for i := 0; i < d.requests; i++ {
+		reqCtx, cancel := RequestContext(ctx)
+		start := time.Now()
+		<-reqCtx.Done()
+		d.durations = append(d.durations, time.Since(start))
+		d.errs = append(d.errs, reqCtx.Err())
+		cancel()
+	}
+	res := pcommon.NewResource()
+	res.Attributes().PutInt("requests", int64(d.requests))
+	return res, "", nil
+}
@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package flyio provides a detector that loads resource information from
+// the environment variables that Fly.io injects into every machine of an app.
+package flyio // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/flyio"
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+const (
+	// TypeStr is type of detector.
+	TypeStr = "flyio"
+
+	// cloudProviderFlyIO is the cloud.provider of Fly.io, which is not defined by the semantic conventions
+	cloudProviderFlyIO = "fly_io"
+
+	// Environment variables that are set by Fly.io for every machine
+	appNameEnvVar   = "FLY_APP_NAME"
+	regionEnvVar    = "FLY_REGION"
+	machineIDEnvVar = "FLY_MACHINE_ID"
+	allocIDEnvVar   = "FLY_ALLOC_ID"
+)
+
+var _ internal.Detector = (*Detector)(nil)
+
+type Detector struct {
+	getenv func(string) string
+}
+
+// NewDetector creates a new Fly.io detector
+func NewDetector(component.ProcessorCreateSettings, internal.DetectorConfig) (internal.Detector, error) {
+	return &Detector{getenv: os.Getenv}, nil
+}
+
+func (d *Detector) Detect(context.Context) (resource pcommon.Resource, schemaURL string, err error) {
+	res := pcommon.NewResource()
+
+	// The app name is always set when running on Fly.io
+	appName := d.getenv(appNameEnvVar)
+	if appName == "" {
+		return res, "", nil
+	}
+
+	attrs := res.Attributes()
+	attrs.PutStr(conventions.AttributeCloudProvider, cloudProviderFlyIO)
+	attrs.PutStr(conventions.AttributeServiceName, appName)
+	putIfNotEmpty(attrs, conventions.AttributeCloudRegion, d.getenv(regionEnvVar))
+	// FLY_ALLOC_ID is the former name of the machine ID, kept by Fly.io for compatibility
+	instanceID := d.getenv(machineIDEnvVar)
+	if instanceID == "" {
+		instanceID = d.getenv(allocIDEnvVar)
+	}
+	putIfNotEmpty(attrs, conventions.AttributeServiceInstanceID, instanceID)
+
+	return res, conventions.SchemaURL, nil
+}
+
+func putIfNotEmpty(attrs pcommon.Map, key string, value string) {
+	if value != "" {
+		attrs.PutStr(key, value)
+	}
+}
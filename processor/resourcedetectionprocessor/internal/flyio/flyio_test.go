@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flyio
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+func mockEnv(env map[string]string) func(string) string {
+	return func(key string) string {
+		return env[key]
+	}
+}
+
+func TestNewDetector(t *testing.T) {
+	d, err := NewDetector(componenttest.NewNopProcessorCreateSettings(), nil)
+	assert.NotNil(t, d)
+	assert.NoError(t, err)
+}
+
+func TestDetectFull(t *testing.T) {
+	detector := &Detector{getenv: mockEnv(map[string]string{
+		appNameEnvVar:   "checkout",
+		regionEnvVar:    "ams",
+		machineIDEnvVar: "148ed193b95789",
+		allocIDEnvVar:   "148ed193b95789",
+	})}
+	res, schemaURL, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, conventions.SchemaURL, schemaURL)
+	res.Attributes().Sort()
+
+	expected := internal.NewResource(map[string]interface{}{
+		conventions.AttributeCloudProvider:     cloudProviderFlyIO,
+		conventions.AttributeCloudRegion:       "ams",
+		conventions.AttributeServiceName:       "checkout",
+		conventions.AttributeServiceInstanceID: "148ed193b95789",
+	})
+	expected.Attributes().Sort()
+
+	assert.Equal(t, expected, res)
+}
+
+func TestDetectAllocIDFallback(t *testing.T) {
+	detector := &Detector{getenv: mockEnv(map[string]string{
+		appNameEnvVar: "checkout",
+		allocIDEnvVar: "b6a7d9ac-2c1b-4e42-8f4e-2c5a4a3e8f1d",
+	})}
+	res, _, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	res.Attributes().Sort()
+
+	expected := internal.NewResource(map[string]interface{}{
+		conventions.AttributeCloudProvider:     cloudProviderFlyIO,
+		conventions.AttributeServiceName:       "checkout",
+		conventions.AttributeServiceInstanceID: "b6a7d9ac-2c1b-4e42-8f4e-2c5a4a3e8f1d",
+	})
+	expected.Attributes().Sort()
+
+	assert.Equal(t, expected, res)
+}
+
+func TestDetectNotFlyIO(t *testing.T) {
+	detector := &Detector{getenv: mockEnv(map[string]string{
+		// the region alone does not identify Fly.io
+		regionEnvVar: "ams",
+	})}
+	res, schemaURL, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "", schemaURL)
+	assert.True(t, internal.IsEmptyResource(res))
+}
@@ -17,6 +17,9 @@ package gcp // import "github.com/open-telemetry/opentelemetry-collector-contrib
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
 
 	"cloud.google.com/go/compute/metadata"
 	"github.com/GoogleCloudPlatform/opentelemetry-operations-go/detectors/gcp"
@@ -36,6 +39,11 @@ const (
 	// TODO(#10348): Remove these after the v0.54.0 release.
 	DeprecatedGKETypeStr = "gke"
 	DeprecatedGCETypeStr = "gce"
+
+	defaultMetadataEndpoint = "http://169.254.169.254"
+	clusterNamePath         = "/computeMetadata/v1/instance/attributes/cluster-name"
+	clusterLocationPath     = "/computeMetadata/v1/instance/attributes/cluster-location"
+	zonePath                = "/computeMetadata/v1/instance/zone"
 )
 
 // NewDetector returns a detector which can detect resource attributes on:
@@ -48,15 +56,18 @@ func NewDetector(set component.ProcessorCreateSettings, _ internal.DetectorConfi
 	return &detector{
 		logger:   set.Logger,
 		detector: gcp.NewDetector(),
+		endpoint: defaultMetadataEndpoint,
 	}, nil
 }
 
 type detector struct {
 	logger   *zap.Logger
 	detector gcpDetector
+	// endpoint is the base URL of the metadata server the cluster metadata is read from
+	endpoint string
 }
 
-func (d *detector) Detect(context.Context) (resource pcommon.Resource, schemaURL string, err error) {
+func (d *detector) Detect(ctx context.Context) (resource pcommon.Resource, schemaURL string, err error) {
 	res := pcommon.NewResource()
 	if !metadata.OnGCE() {
 		return res, "", nil
@@ -73,6 +84,7 @@ func (d *detector) Detect(context.Context) (resource pcommon.Resource, schemaURL
 		b.add(conventions.AttributeHostID, d.detector.GKEHostID)
 		// GCEHostname is fallible on GKE, since it's not available when using workload identity.
 		b.addFallible(conventions.AttributeHostName, d.detector.GCEHostName)
+		d.addClusterMetadata(ctx, b)
 	case gcp.CloudRun:
 		b.attrs.PutStr(conventions.AttributeCloudPlatform, conventions.AttributeCloudPlatformGCPCloudRun)
 		b.add(conventions.AttributeFaaSName, d.detector.FaaSName)
@@ -104,12 +116,96 @@ func (d *detector) Detect(context.Context) (resource pcommon.Resource, schemaURL
 		b.add(conventions.AttributeHostType, d.detector.GCEHostType)
 		b.add(conventions.AttributeHostID, d.detector.GCEHostID)
 		b.add(conventions.AttributeHostName, d.detector.GCEHostName)
+		// the node of a GKE cluster is a GCE instance, e.g. when the collector runs outside of kubernetes
+		d.addClusterMetadata(ctx, b)
 	default:
 		// We don't support this platform yet, so just return with what we have
 	}
 	return res, conventions.SchemaURL, multierr.Combine(b.errs...)
 }
 
+// clusterMetadata are the attributes of the GKE cluster the instance is a node of, they are empty if it is not.
+type clusterMetadata struct {
+	name     string
+	location string
+	zone     string
+}
+
+// addClusterMetadata completes the attributes of a GKE node with the cluster metadata of the metadata server.
+// The cluster location is either the zone or the region of the cluster, so the zone of the node and the region
+// of the zone are added if they are missing. Instances that are not GKE nodes are left without a cluster name.
+func (d *detector) addClusterMetadata(ctx context.Context, b *resourceBuilder) {
+	meta, err := d.clusterMetadata(ctx)
+	if err != nil {
+		d.logger.Info("Failed to read the cluster metadata, these attributes will not be available.", zap.Error(err))
+		return
+	}
+	if meta.name == "" {
+		return
+	}
+	putIfAbsent(b.attrs, conventions.AttributeK8SClusterName, meta.name)
+	zone := meta.zone
+	if zone == "" && strings.Count(meta.location, "-") == 2 {
+		zone = meta.location
+	}
+	if zone != "" {
+		putIfAbsent(b.attrs, conventions.AttributeCloudAvailabilityZone, zone)
+		// the region is the zone without its suffix, e.g. us-central1 for us-central1-c
+		if i := strings.LastIndex(zone, "-"); i > 0 {
+			putIfAbsent(b.attrs, conventions.AttributeCloudRegion, zone[:i])
+		}
+	} else if strings.Count(meta.location, "-") == 1 {
+		putIfAbsent(b.attrs, conventions.AttributeCloudRegion, meta.location)
+	}
+}
+
+func (d *detector) clusterMetadata(ctx context.Context) (clusterMetadata, error) {
+	client, err := internal.ClientFromContext(ctx)
+	if err != nil {
+		client = http.DefaultClient
+		d.logger.Debug("Error retrieving client from context thus creating default", zap.Error(err))
+	}
+	var meta clusterMetadata
+	if meta.name, err = d.metadataValue(ctx, client, clusterNamePath); err != nil || meta.name == "" {
+		return meta, err
+	}
+	if meta.location, err = d.metadataValue(ctx, client, clusterLocationPath); err != nil {
+		return meta, err
+	}
+	zone, err := d.metadataValue(ctx, client, zonePath)
+	// the zone has the format projects/<project number>/zones/<zone>
+	meta.zone = zone[strings.LastIndex(zone, "/")+1:]
+	return meta, err
+}
+
+// metadataValue returns the value of the metadata server at the given path, an empty value if there is none.
+func (d *detector) metadataValue(ctx context.Context, client *http.Client, path string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.endpoint+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata server responded with status %d for %s", resp.StatusCode, path)
+	}
+	value, err := io.ReadAll(resp.Body)
+	return strings.TrimSpace(string(value)), err
+}
+
+func putIfAbsent(attrs pcommon.Map, key string, value string) {
+	if _, ok := attrs.Get(key); !ok {
+		attrs.PutStr(key, value)
+	}
+}
+
 // resourceBuilder simplifies constructing resources using GCP detection
 // library functions.
 type resourceBuilder struct {
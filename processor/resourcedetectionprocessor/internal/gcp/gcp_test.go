@@ -17,6 +17,8 @@ package gcp // import "github.com/open-telemetry/opentelemetry-collector-contrib
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/GoogleCloudPlatform/opentelemetry-operations-go/detectors/gcp"
@@ -41,7 +43,7 @@ func TestDetect(t *testing.T) {
 	}{
 		{
 			desc: "zonal GKE cluster",
-			detector: newTestDetector(&fakeGCPDetector{
+			detector: newTestDetector(t, &fakeGCPDetector{
 				projectID:           "my-project",
 				cloudPlatform:       gcp.GKE,
 				gceHostName:         "my-gke-node-1234",
@@ -61,7 +63,7 @@ func TestDetect(t *testing.T) {
 		},
 		{
 			desc: "regional GKE cluster",
-			detector: newTestDetector(&fakeGCPDetector{
+			detector: newTestDetector(t, &fakeGCPDetector{
 				projectID:      "my-project",
 				cloudPlatform:  gcp.GKE,
 				gceHostName:    "my-gke-node-1234",
@@ -81,7 +83,7 @@ func TestDetect(t *testing.T) {
 		},
 		{
 			desc: "regional GKE cluster with workload identity",
-			detector: newTestDetector(&fakeGCPDetector{
+			detector: newTestDetector(t, &fakeGCPDetector{
 				projectID:      "my-project",
 				cloudPlatform:  gcp.GKE,
 				gceHostNameErr: fmt.Errorf("metadata endpoint is concealed"),
@@ -100,7 +102,7 @@ func TestDetect(t *testing.T) {
 		},
 		{
 			desc: "GCE",
-			detector: newTestDetector(&fakeGCPDetector{
+			detector: newTestDetector(t, &fakeGCPDetector{
 				projectID:           "my-project",
 				cloudPlatform:       gcp.GCE,
 				gceHostID:           "1472385723456792345",
@@ -122,7 +124,7 @@ func TestDetect(t *testing.T) {
 		},
 		{
 			desc: "Cloud Run",
-			detector: newTestDetector(&fakeGCPDetector{
+			detector: newTestDetector(t, &fakeGCPDetector{
 				projectID:       "my-project",
 				cloudPlatform:   gcp.CloudRun,
 				faaSID:          "1472385723456792345",
@@ -142,7 +144,7 @@ func TestDetect(t *testing.T) {
 		},
 		{
 			desc: "Cloud Functions",
-			detector: newTestDetector(&fakeGCPDetector{
+			detector: newTestDetector(t, &fakeGCPDetector{
 				projectID:       "my-project",
 				cloudPlatform:   gcp.CloudFunctions,
 				faaSID:          "1472385723456792345",
@@ -162,7 +164,7 @@ func TestDetect(t *testing.T) {
 		},
 		{
 			desc: "App Engine Standard",
-			detector: newTestDetector(&fakeGCPDetector{
+			detector: newTestDetector(t, &fakeGCPDetector{
 				projectID:                 "my-project",
 				cloudPlatform:             gcp.AppEngineStandard,
 				appEngineServiceInstance:  "1472385723456792345",
@@ -184,7 +186,7 @@ func TestDetect(t *testing.T) {
 		},
 		{
 			desc: "App Engine Flex",
-			detector: newTestDetector(&fakeGCPDetector{
+			detector: newTestDetector(t, &fakeGCPDetector{
 				projectID:                 "my-project",
 				cloudPlatform:             gcp.AppEngineFlex,
 				appEngineServiceInstance:  "1472385723456792345",
@@ -206,7 +208,7 @@ func TestDetect(t *testing.T) {
 		},
 		{
 			desc: "Unknown Platform",
-			detector: newTestDetector(&fakeGCPDetector{
+			detector: newTestDetector(t, &fakeGCPDetector{
 				projectID:     "my-project",
 				cloudPlatform: gcp.UnknownPlatform,
 			}),
@@ -217,7 +219,7 @@ func TestDetect(t *testing.T) {
 		},
 		{
 			desc: "error",
-			detector: newTestDetector(&fakeGCPDetector{
+			detector: newTestDetector(t, &fakeGCPDetector{
 				err: fmt.Errorf("failed to get metadata"),
 			}),
 			expectErr: true,
@@ -241,13 +243,184 @@ func TestDetect(t *testing.T) {
 	}
 }
 
-func newTestDetector(gcpDetector *fakeGCPDetector) *detector {
+func TestDetectClusterMetadata(t *testing.T) {
+	t.Setenv("GCE_METADATA_HOST", "169.254.169.254")
+
+	for _, tc := range []struct {
+		desc             string
+		gcpDetector      *fakeGCPDetector
+		metadata         map[string]string
+		expectedResource pcommon.Resource
+	}{
+		{
+			desc: "regional GKE cluster",
+			gcpDetector: &fakeGCPDetector{
+				projectID:      "my-project",
+				cloudPlatform:  gcp.GKE,
+				gceHostName:    "my-gke-node-1234",
+				gkeHostID:      "1472385723456792345",
+				gkeClusterName: "my-cluster",
+				gkeRegion:      "us-central1",
+			},
+			metadata: map[string]string{
+				clusterNamePath:     "my-cluster",
+				clusterLocationPath: "us-central1",
+				zonePath:            "projects/123456789/zones/us-central1-c",
+			},
+			expectedResource: internal.NewResource(map[string]interface{}{
+				conventions.AttributeCloudProvider:         conventions.AttributeCloudProviderGCP,
+				conventions.AttributeCloudAccountID:        "my-project",
+				conventions.AttributeCloudPlatform:         conventions.AttributeCloudPlatformGCPKubernetesEngine,
+				conventions.AttributeK8SClusterName:        "my-cluster",
+				conventions.AttributeCloudRegion:           "us-central1",
+				conventions.AttributeCloudAvailabilityZone: "us-central1-c",
+				conventions.AttributeHostID:                "1472385723456792345",
+				conventions.AttributeHostName:              "my-gke-node-1234",
+			}),
+		},
+		{
+			desc: "zonal GKE cluster",
+			gcpDetector: &fakeGCPDetector{
+				projectID:           "my-project",
+				cloudPlatform:       gcp.GKE,
+				gceHostName:         "my-gke-node-1234",
+				gkeHostID:           "1472385723456792345",
+				gkeClusterName:      "my-cluster",
+				gkeAvailabilityZone: "us-central1-c",
+			},
+			metadata: map[string]string{
+				clusterNamePath:     "my-cluster",
+				clusterLocationPath: "us-central1-c",
+			},
+			expectedResource: internal.NewResource(map[string]interface{}{
+				conventions.AttributeCloudProvider:         conventions.AttributeCloudProviderGCP,
+				conventions.AttributeCloudAccountID:        "my-project",
+				conventions.AttributeCloudPlatform:         conventions.AttributeCloudPlatformGCPKubernetesEngine,
+				conventions.AttributeK8SClusterName:        "my-cluster",
+				conventions.AttributeCloudRegion:           "us-central1",
+				conventions.AttributeCloudAvailabilityZone: "us-central1-c",
+				conventions.AttributeHostID:                "1472385723456792345",
+				conventions.AttributeHostName:              "my-gke-node-1234",
+			}),
+		},
+		{
+			desc: "GKE node outside of kubernetes",
+			gcpDetector: &fakeGCPDetector{
+				projectID:           "my-project",
+				cloudPlatform:       gcp.GCE,
+				gceHostType:         "n1-standard1",
+				gceAvailabilityZone: "us-central1-c",
+				gceRegion:           "us-central1",
+				gceHostID:           "1472385723456792345",
+				gceHostName:         "my-gke-node-1234",
+			},
+			metadata: map[string]string{
+				clusterNamePath:     "my-cluster",
+				clusterLocationPath: "us-central1",
+				zonePath:            "projects/123456789/zones/us-central1-c",
+			},
+			expectedResource: internal.NewResource(map[string]interface{}{
+				conventions.AttributeCloudProvider:         conventions.AttributeCloudProviderGCP,
+				conventions.AttributeCloudAccountID:        "my-project",
+				conventions.AttributeCloudPlatform:         conventions.AttributeCloudPlatformGCPComputeEngine,
+				conventions.AttributeK8SClusterName:        "my-cluster",
+				conventions.AttributeHostType:              "n1-standard1",
+				conventions.AttributeCloudRegion:           "us-central1",
+				conventions.AttributeCloudAvailabilityZone: "us-central1-c",
+				conventions.AttributeHostID:                "1472385723456792345",
+				conventions.AttributeHostName:              "my-gke-node-1234",
+			}),
+		},
+		{
+			desc: "plain GCE instance",
+			gcpDetector: &fakeGCPDetector{
+				projectID:           "my-project",
+				cloudPlatform:       gcp.GCE,
+				gceHostType:         "n1-standard1",
+				gceAvailabilityZone: "us-central1-c",
+				gceRegion:           "us-central1",
+				gceHostID:           "1472385723456792345",
+				gceHostName:         "my-instance",
+			},
+			metadata: map[string]string{
+				zonePath: "projects/123456789/zones/us-central1-c",
+			},
+			expectedResource: internal.NewResource(map[string]interface{}{
+				conventions.AttributeCloudProvider:         conventions.AttributeCloudProviderGCP,
+				conventions.AttributeCloudAccountID:        "my-project",
+				conventions.AttributeCloudPlatform:         conventions.AttributeCloudPlatformGCPComputeEngine,
+				conventions.AttributeHostType:              "n1-standard1",
+				conventions.AttributeCloudRegion:           "us-central1",
+				conventions.AttributeCloudAvailabilityZone: "us-central1-c",
+				conventions.AttributeHostID:                "1472385723456792345",
+				conventions.AttributeHostName:              "my-instance",
+			}),
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			d := newTestDetector(t, tc.gcpDetector)
+			d.endpoint = newTestMetadataServer(t, tc.metadata).URL
+			// the cluster metadata is read with the client of the processor
+			ctx := internal.ContextWithClient(context.Background(), &http.Client{})
+			res, _, err := d.Detect(ctx)
+			assert.NoError(t, err)
+			tc.expectedResource.Attributes().Sort()
+			res.Attributes().Sort()
+			assert.Equal(t, tc.expectedResource, res, "Resource object returned is incorrect")
+		})
+	}
+}
+
+func TestDetectClusterMetadataError(t *testing.T) {
+	t.Setenv("GCE_METADATA_HOST", "169.254.169.254")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	d := newTestDetector(t, &fakeGCPDetector{
+		projectID:      "my-project",
+		cloudPlatform:  gcp.GKE,
+		gceHostName:    "my-gke-node-1234",
+		gkeHostID:      "1472385723456792345",
+		gkeClusterName: "my-cluster",
+		gkeRegion:      "us-central1",
+	})
+	d.endpoint = server.URL
+
+	// the attributes of the GCP detection library are kept if the cluster metadata cannot be read
+	res, _, err := d.Detect(context.Background())
+	assert.NoError(t, err)
+	_, ok := res.Attributes().Get(conventions.AttributeCloudAvailabilityZone)
+	assert.False(t, ok)
+	clusterName, ok := res.Attributes().Get(conventions.AttributeK8SClusterName)
+	assert.True(t, ok)
+	assert.Equal(t, "my-cluster", clusterName.Str())
+}
+
+func newTestDetector(t *testing.T, gcpDetector *fakeGCPDetector) *detector {
 	return &detector{
 		logger:   zap.NewNop(),
 		detector: gcpDetector,
+		endpoint: newTestMetadataServer(t, nil).URL,
 	}
 }
 
+// newTestMetadataServer returns a metadata server responding with the given values by path, and not found otherwise.
+func newTestMetadataServer(t *testing.T, values map[string]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+		value, ok := values[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(value))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
 // fakeGCPDetector implements gcpDetector and uses fake values.
 type fakeGCPDetector struct {
 	err                       error
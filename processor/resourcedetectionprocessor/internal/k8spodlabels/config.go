@@ -0,0 +1,28 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8spodlabels // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/k8spodlabels"
+
+// Config defines user-specified configurations unique to the k8spodlabels detector
+type Config struct {
+	// Directory is the mount path of the downward API volume holding the `labels` and `annotations`
+	// files of the pod. (**default**: `/etc/podinfo`)
+	Directory string `mapstructure:"directory"`
+
+	// Labels are the keys of the pod labels that are set as `k8s.pod.labels.<key>` resource attributes.
+	Labels []string `mapstructure:"labels"`
+
+	// Annotations are the keys of the pod annotations that are set as `k8s.pod.annotations.<key>` resource attributes.
+	Annotations []string `mapstructure:"annotations"`
+}
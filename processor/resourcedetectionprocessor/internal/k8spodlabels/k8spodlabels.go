@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package k8spodlabels provides a detector that loads the labels and annotations of a Kubernetes pod
+// from the files of a downward API volume.
+package k8spodlabels // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/k8spodlabels"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+const (
+	// TypeStr is type of detector.
+	TypeStr = "k8spodlabels"
+
+	defaultDirectory = "/etc/podinfo"
+
+	// labelsFile and annotationsFile are the paths of the downward API volume holding
+	// the metadata.labels and metadata.annotations of the pod
+	labelsFile      = "labels"
+	annotationsFile = "annotations"
+
+	labelAttributePrefix      = "k8s.pod.labels."
+	annotationAttributePrefix = "k8s.pod.annotations."
+)
+
+var _ internal.Detector = (*Detector)(nil)
+
+type Detector struct {
+	dir         string
+	labels      []string
+	annotations []string
+	logger      *zap.Logger
+}
+
+// NewDetector creates a new Kubernetes pod labels detector
+func NewDetector(p component.ProcessorCreateSettings, dcfg internal.DetectorConfig) (internal.Detector, error) {
+	cfg := dcfg.(Config)
+	if cfg.Directory == "" {
+		cfg.Directory = defaultDirectory
+	}
+	return &Detector{dir: cfg.Directory, labels: cfg.Labels, annotations: cfg.Annotations, logger: p.Logger}, nil
+}
+
+func (d *Detector) Detect(context.Context) (resource pcommon.Resource, schemaURL string, err error) {
+	res := pcommon.NewResource()
+
+	labels, err := d.readFile(labelsFile)
+	if err != nil {
+		return res, "", err
+	}
+	annotations, err := d.readFile(annotationsFile)
+	if err != nil {
+		return res, "", err
+	}
+	// The volume is not mounted when not running in a pod, or the pod does not project its metadata
+	if labels == nil && annotations == nil {
+		return res, "", nil
+	}
+
+	attrs := res.Attributes()
+	putSelected(attrs, labelAttributePrefix, d.labels, labels)
+	putSelected(attrs, annotationAttributePrefix, d.annotations, annotations)
+
+	return res, conventions.SchemaURL, nil
+}
+
+// readFile parses a file of the downward API volume, it returns nil if the file does not exist
+func (d *Detector) readFile(name string) (map[string]string, error) {
+	path := filepath.Join(d.dir, name)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		d.logger.Debug("Downward API file does not exist", zap.String("path", path))
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	values, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// parse parses the lines of a downward API file, which are formatted as key="value" with the value quoted.
+// The lines are not scanned as annotations such as kubectl's last applied configuration can be very long.
+func parse(data []byte) (map[string]string, error) {
+	values := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, quoted, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %q is not a key=value pair", line)
+		}
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, fmt.Errorf("value of %q is not quoted: %w", key, err)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+func putSelected(attrs pcommon.Map, prefix string, keys []string, values map[string]string) {
+	for _, key := range keys {
+		if value, ok := values[key]; ok {
+			attrs.PutStr(prefix+key, value)
+		}
+	}
+}
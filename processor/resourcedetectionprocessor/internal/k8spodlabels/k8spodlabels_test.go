@@ -0,0 +1,71 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8spodlabels
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+)
+
+func TestDetect(t *testing.T) {
+	d, err := NewDetector(componenttest.NewNopProcessorCreateSettings(), Config{
+		Directory:   filepath.Join("testdata", "podinfo"),
+		Labels:      []string{"app", "team", "missing"},
+		Annotations: []string{"owner"},
+	})
+	require.NoError(t, err)
+	res, schemaURL, err := d.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, conventions.SchemaURL, schemaURL)
+	assert.Equal(t, map[string]interface{}{
+		"k8s.pod.labels.app":        "checkout",
+		"k8s.pod.labels.team":       "payments",
+		"k8s.pod.annotations.owner": `payments "core" team`,
+	}, res.Attributes().AsRaw())
+}
+
+func TestDetectMissingDirectory(t *testing.T) {
+	d, err := NewDetector(componenttest.NewNopProcessorCreateSettings(), Config{
+		Directory: filepath.Join(t.TempDir(), "podinfo"),
+		Labels:    []string{"app"},
+	})
+	require.NoError(t, err)
+	res, schemaURL, err := d.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "", schemaURL)
+	assert.Equal(t, 0, res.Attributes().Len())
+}
+
+func TestDetectMalformedFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, labelsFile), []byte("app=checkout\n"), 0600))
+	d, err := NewDetector(componenttest.NewNopProcessorCreateSettings(), Config{Directory: dir, Labels: []string{"app"}})
+	require.NoError(t, err)
+	_, _, err = d.Detect(context.Background())
+	assert.ErrorContains(t, err, "failed to parse")
+}
+
+func TestDefaultDirectory(t *testing.T) {
+	d, err := NewDetector(componenttest.NewNopProcessorCreateSettings(), Config{})
+	require.NoError(t, err)
+	assert.Equal(t, defaultDirectory, d.(*Detector).dir)
+}
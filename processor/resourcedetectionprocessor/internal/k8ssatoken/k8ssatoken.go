@@ -0,0 +1,132 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package k8ssatoken provides a detector that loads the namespace and the service account of a Kubernetes
+// pod from the claims of its mounted service account token.
+package k8ssatoken // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/k8ssatoken"
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+const (
+	// TypeStr is type of detector.
+	TypeStr = "k8ssatoken"
+
+	// tokenPath is the service account token that Kubernetes mounts into the containers of a pod
+	tokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+	// attributeK8SServiceAccountName is the name of the service account of the pod, it is not in the semantic conventions yet
+	attributeK8SServiceAccountName = "k8s.serviceaccount.name"
+)
+
+var _ internal.Detector = (*Detector)(nil)
+
+type Detector struct {
+	path string
+}
+
+// NewDetector creates a new Kubernetes service account token detector
+func NewDetector(component.ProcessorCreateSettings, internal.DetectorConfig) (internal.Detector, error) {
+	return &Detector{path: tokenPath}, nil
+}
+
+// Detect decodes the claims of the service account token. The signature of the token is not verified, the
+// token is only read from the filesystem of the pod and nothing is authorized with it.
+func (d *Detector) Detect(context.Context) (resource pcommon.Resource, schemaURL string, err error) {
+	res := pcommon.NewResource()
+
+	data, err := os.ReadFile(d.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		// The token is not mounted when not running in a pod, or the pod disables automountServiceAccountToken
+		return res, "", nil
+	}
+	if err != nil {
+		return res, "", fmt.Errorf("failed reading service account token from %s: %w", d.path, err)
+	}
+	c, err := parseClaims(strings.TrimSpace(string(data)))
+	if err != nil {
+		return res, "", fmt.Errorf("failed parsing service account token from %s: %w", d.path, err)
+	}
+	namespace, serviceAccount := c.namespace(), c.serviceAccount()
+	if namespace == "" && serviceAccount == "" {
+		return res, "", nil
+	}
+
+	attrs := res.Attributes()
+	if namespace != "" {
+		attrs.PutStr(conventions.AttributeK8SNamespaceName, namespace)
+	}
+	if serviceAccount != "" {
+		attrs.PutStr(attributeK8SServiceAccountName, serviceAccount)
+	}
+	return res, conventions.SchemaURL, nil
+}
+
+// claims are the claims of a service account token. The bound tokens projected since Kubernetes 1.21 hold them
+// in the kubernetes.io claim, the legacy tokens of service account secrets in the kubernetes.io/serviceaccount/ claims.
+type claims struct {
+	Kubernetes *struct {
+		Namespace      string `json:"namespace"`
+		ServiceAccount struct {
+			Name string `json:"name"`
+		} `json:"serviceaccount"`
+	} `json:"kubernetes.io"`
+	LegacyNamespace      string `json:"kubernetes.io/serviceaccount/namespace"`
+	LegacyServiceAccount string `json:"kubernetes.io/serviceaccount/service-account.name"`
+}
+
+func (c claims) namespace() string {
+	if c.Kubernetes != nil && c.Kubernetes.Namespace != "" {
+		return c.Kubernetes.Namespace
+	}
+	return c.LegacyNamespace
+}
+
+func (c claims) serviceAccount() string {
+	if c.Kubernetes != nil && c.Kubernetes.ServiceAccount.Name != "" {
+		return c.Kubernetes.ServiceAccount.Name
+	}
+	return c.LegacyServiceAccount
+}
+
+// parseClaims decodes the payload of a JWT, which is the base64url encoded JSON between the header and the signature
+func parseClaims(token string) (claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims{}, errors.New("token is not a JWT of three parts")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return claims{}, fmt.Errorf("token payload is not base64url encoded: %w", err)
+	}
+	var c claims
+	if err = json.Unmarshal(payload, &c); err != nil {
+		return claims{}, fmt.Errorf("token payload is not a JSON object: %w", err)
+	}
+	return c, nil
+}
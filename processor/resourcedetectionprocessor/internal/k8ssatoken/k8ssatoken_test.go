@@ -0,0 +1,133 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8ssatoken
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+// newToken returns a JWT with the given payload, its header and signature are not read by the detector
+func newToken(payload string) string {
+	return "eyJhbGciOiJSUzI1NiIsImtpZCI6InRlc3QifQ." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".c2lnbmF0dXJl"
+}
+
+// newTestDetector returns a detector reading the token from a temporary directory, the token is written if not empty
+func newTestDetector(t *testing.T, token string) *Detector {
+	path := filepath.Join(t.TempDir(), "token")
+	if token != "" {
+		require.NoError(t, os.WriteFile(path, []byte(token), 0600))
+	}
+	return &Detector{path: path}
+}
+
+func TestNewDetector(t *testing.T) {
+	d, err := NewDetector(componenttest.NewNopProcessorCreateSettings(), nil)
+	assert.NotNil(t, d)
+	assert.NoError(t, err)
+	assert.Equal(t, tokenPath, d.(*Detector).path)
+}
+
+func TestDetectClaims(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		want  map[string]interface{}
+	}{
+		{
+			name:  "bound token",
+			token: newToken(`{"aud":["https://kubernetes.default.svc"],"kubernetes.io":{"namespace":"shop","pod":{"name":"checkout-7d9f","uid":"1b2c"},"serviceaccount":{"name":"checkout","uid":"3d4e"}},"sub":"system:serviceaccount:shop:checkout"}`),
+			want: map[string]interface{}{
+				conventions.AttributeK8SNamespaceName: "shop",
+				attributeK8SServiceAccountName:        "checkout",
+			},
+		},
+		{
+			name:  "legacy token",
+			token: newToken(`{"iss":"kubernetes/serviceaccount","kubernetes.io/serviceaccount/namespace":"shop","kubernetes.io/serviceaccount/service-account.name":"checkout"}`) + "\n",
+			want: map[string]interface{}{
+				conventions.AttributeK8SNamespaceName: "shop",
+				attributeK8SServiceAccountName:        "checkout",
+			},
+		},
+		{
+			name:  "namespace only",
+			token: newToken(`{"kubernetes.io":{"namespace":"shop"}}`),
+			want: map[string]interface{}{
+				conventions.AttributeK8SNamespaceName: "shop",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, schemaURL, err := newTestDetector(t, tt.token).Detect(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, conventions.SchemaURL, schemaURL)
+			assert.Equal(t, tt.want, res.Attributes().AsRaw())
+		})
+	}
+}
+
+func TestDetectNoClaims(t *testing.T) {
+	res, schemaURL, err := newTestDetector(t, newToken(`{"sub":"someone"}`)).Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "", schemaURL)
+	assert.True(t, internal.IsEmptyResource(res))
+}
+
+func TestDetectNoToken(t *testing.T) {
+	res, schemaURL, err := newTestDetector(t, "").Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "", schemaURL)
+	assert.True(t, internal.IsEmptyResource(res))
+}
+
+func TestDetectMalformedToken(t *testing.T) {
+	tests := map[string]string{
+		"not a jwt":      "not-a-token",
+		"not base64":     "eyJhbGciOiJSUzI1NiJ9.!!!.c2lnbmF0dXJl",
+		"not json":       newToken("namespace=shop"),
+		"not an object":  newToken(`["shop"]`),
+		"too many parts": newToken(`{}`) + ".extra",
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, schemaURL, err := newTestDetector(t, token).Detect(context.Background())
+			assert.ErrorContains(t, err, "failed parsing service account token")
+			assert.Equal(t, "", schemaURL)
+			assert.True(t, internal.IsEmptyResource(res))
+		})
+	}
+}
+
+func TestDetectReadError(t *testing.T) {
+	// the token path is a directory, which cannot be read as a file
+	detector := &Detector{path: t.TempDir()}
+
+	res, schemaURL, err := detector.Detect(context.Background())
+	assert.ErrorContains(t, err, "failed reading service account token")
+	assert.Equal(t, "", schemaURL)
+	assert.True(t, internal.IsEmptyResource(res))
+}
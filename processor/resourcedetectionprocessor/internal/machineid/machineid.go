@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package machineid provides a detector that loads the host id from the
+// machine id of systemd or D-Bus.
+package machineid // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/machineid"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+const (
+	// TypeStr is type of detector.
+	TypeStr = "machineid"
+
+	// machineIDPath is the machine id written by systemd
+	machineIDPath = "/etc/machine-id"
+	// dbusMachineIDPath is the machine id written by D-Bus, used on systems without systemd
+	dbusMachineIDPath = "/var/lib/dbus/machine-id"
+)
+
+var _ internal.Detector = (*Detector)(nil)
+
+type Detector struct {
+	// paths are the files read in order, the first one containing a machine id is used
+	paths    []string
+	readFile func(string) ([]byte, error)
+}
+
+// NewDetector creates a new machine id detector
+func NewDetector(component.ProcessorCreateSettings, internal.DetectorConfig) (internal.Detector, error) {
+	return &Detector{paths: []string{machineIDPath, dbusMachineIDPath}, readFile: os.ReadFile}, nil
+}
+
+func (d *Detector) Detect(context.Context) (resource pcommon.Resource, schemaURL string, err error) {
+	res := pcommon.NewResource()
+
+	for _, path := range d.paths {
+		data, err := d.readFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return res, "", fmt.Errorf("failed reading machine id from %s: %w", path, err)
+		}
+		// the file holds the id followed by a newline, it is empty on images that have not been booted yet
+		if id := strings.TrimSpace(string(data)); id != "" {
+			res.Attributes().PutStr(conventions.AttributeHostID, id)
+			return res, conventions.SchemaURL, nil
+		}
+	}
+
+	// None of the files exist when the host has neither systemd nor D-Bus
+	return res, "", nil
+}
@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package machineid
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+const testMachineID = "4f9a2c7e1b3d4e5f8a6b0c1d2e3f4a5b"
+
+// newTestDetector returns a detector reading the machine id and the D-Bus machine id from a temporary directory
+func newTestDetector(t *testing.T) (*Detector, string, string) {
+	dir := t.TempDir()
+	machineID := filepath.Join(dir, "machine-id")
+	dbusMachineID := filepath.Join(dir, "dbus", "machine-id")
+	return &Detector{paths: []string{machineID, dbusMachineID}, readFile: os.ReadFile}, machineID, dbusMachineID
+}
+
+func writeMachineID(t *testing.T, path string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+func TestNewDetector(t *testing.T) {
+	d, err := NewDetector(componenttest.NewNopProcessorCreateSettings(), nil)
+	assert.NotNil(t, d)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{machineIDPath, dbusMachineIDPath}, d.(*Detector).paths)
+}
+
+func TestDetectMachineID(t *testing.T) {
+	detector, machineID, dbusMachineID := newTestDetector(t)
+	writeMachineID(t, machineID, testMachineID+"\n")
+	writeMachineID(t, dbusMachineID, "ffffffffffffffffffffffffffffffff\n")
+
+	res, schemaURL, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, conventions.SchemaURL, schemaURL)
+	assert.Equal(t, internal.NewResource(map[string]interface{}{
+		conventions.AttributeHostID: testMachineID,
+	}), res)
+}
+
+func TestDetectDBusFallback(t *testing.T) {
+	for name, setup := range map[string]func(t *testing.T, machineID string){
+		"missing machine id": func(*testing.T, string) {},
+		"empty machine id": func(t *testing.T, machineID string) {
+			writeMachineID(t, machineID, "\n")
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			detector, machineID, dbusMachineID := newTestDetector(t)
+			setup(t, machineID)
+			writeMachineID(t, dbusMachineID, testMachineID+"\n")
+
+			res, schemaURL, err := detector.Detect(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, conventions.SchemaURL, schemaURL)
+			assert.Equal(t, internal.NewResource(map[string]interface{}{
+				conventions.AttributeHostID: testMachineID,
+			}), res)
+		})
+	}
+}
+
+func TestDetectNoMachineID(t *testing.T) {
+	detector, _, _ := newTestDetector(t)
+
+	res, schemaURL, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "", schemaURL)
+	assert.True(t, internal.IsEmptyResource(res))
+}
+
+func TestDetectReadError(t *testing.T) {
+	detector, _, _ := newTestDetector(t)
+	detector.readFile = func(string) ([]byte, error) {
+		return nil, errors.New("permission denied")
+	}
+
+	res, schemaURL, err := detector.Detect(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, "", schemaURL)
+	assert.True(t, internal.IsEmptyResource(res))
+}
@@ -0,0 +1,80 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nomad provides a detector that loads resource information from
+// the environment variables that HashiCorp Nomad injects into every task.
+package nomad // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/nomad"
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+const (
+	// TypeStr is type of detector.
+	TypeStr = "nomad"
+
+	// Environment variables that are set by Nomad for every task
+	allocIDEnvVar  = "NOMAD_ALLOC_ID"
+	jobNameEnvVar  = "NOMAD_JOB_NAME"
+	taskNameEnvVar = "NOMAD_TASK_NAME"
+
+	attributeNomadAllocID  = "nomad.alloc.id"
+	attributeNomadJobName  = "nomad.job.name"
+	attributeNomadTaskName = "nomad.task.name"
+)
+
+var _ internal.Detector = (*Detector)(nil)
+
+type Detector struct {
+	getenv func(string) string
+}
+
+// NewDetector creates a new Nomad detector
+func NewDetector(component.ProcessorCreateSettings, internal.DetectorConfig) (internal.Detector, error) {
+	return &Detector{getenv: os.Getenv}, nil
+}
+
+func (d *Detector) Detect(context.Context) (resource pcommon.Resource, schemaURL string, err error) {
+	res := pcommon.NewResource()
+
+	allocID := d.getenv(allocIDEnvVar)
+	jobName := d.getenv(jobNameEnvVar)
+	taskName := d.getenv(taskNameEnvVar)
+
+	// None of the variables are set when not running as a Nomad task
+	if allocID == "" && jobName == "" && taskName == "" {
+		return res, "", nil
+	}
+
+	attrs := res.Attributes()
+	if allocID != "" {
+		attrs.PutStr(attributeNomadAllocID, allocID)
+	}
+	if jobName != "" {
+		attrs.PutStr(attributeNomadJobName, jobName)
+		attrs.PutStr(conventions.AttributeServiceName, jobName)
+	}
+	if taskName != "" {
+		attrs.PutStr(attributeNomadTaskName, taskName)
+	}
+
+	return res, conventions.SchemaURL, nil
+}
@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nomad
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+func mockEnv(env map[string]string) func(string) string {
+	return func(key string) string {
+		return env[key]
+	}
+}
+
+func TestNewDetector(t *testing.T) {
+	d, err := NewDetector(componenttest.NewNopProcessorCreateSettings(), nil)
+	assert.NotNil(t, d)
+	assert.NoError(t, err)
+}
+
+func TestDetectFull(t *testing.T) {
+	detector := &Detector{getenv: mockEnv(map[string]string{
+		allocIDEnvVar:  "5a7e9b1c-8f3d-4c2e-9a6b-1d2f3e4a5b6c",
+		jobNameEnvVar:  "checkout",
+		taskNameEnvVar: "server",
+	})}
+	res, schemaURL, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, conventions.SchemaURL, schemaURL)
+	res.Attributes().Sort()
+
+	expected := internal.NewResource(map[string]interface{}{
+		attributeNomadAllocID:            "5a7e9b1c-8f3d-4c2e-9a6b-1d2f3e4a5b6c",
+		attributeNomadJobName:            "checkout",
+		attributeNomadTaskName:           "server",
+		conventions.AttributeServiceName: "checkout",
+	})
+	expected.Attributes().Sort()
+
+	assert.Equal(t, expected, res)
+}
+
+func TestDetectPartial(t *testing.T) {
+	detector := &Detector{getenv: mockEnv(map[string]string{
+		allocIDEnvVar: "5a7e9b1c-8f3d-4c2e-9a6b-1d2f3e4a5b6c",
+	})}
+	res, schemaURL, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, conventions.SchemaURL, schemaURL)
+	assert.Equal(t, internal.NewResource(map[string]interface{}{
+		attributeNomadAllocID: "5a7e9b1c-8f3d-4c2e-9a6b-1d2f3e4a5b6c",
+	}), res)
+}
+
+func TestDetectNotNomad(t *testing.T) {
+	detector := &Detector{getenv: mockEnv(map[string]string{})}
+	res, schemaURL, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "", schemaURL)
+	assert.True(t, internal.IsEmptyResource(res))
+}
@@ -0,0 +1,124 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package openstack provides a detector that loads resource information from
+// the metadata service of OpenStack instances.
+package openstack // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/openstack"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+const (
+	// TypeStr is type of detector.
+	TypeStr = "openstack"
+
+	cloudProviderOpenStack = "openstack"
+
+	defaultEndpoint = "http://169.254.169.254"
+	metadataPath    = "/openstack/latest/meta_data.json"
+)
+
+var _ internal.Detector = (*Detector)(nil)
+
+// Detector is an OpenStack metadata detector
+type Detector struct {
+	// endpoint is the base URL of the metadata service
+	endpoint string
+	logger   *zap.Logger
+}
+
+// metadata holds the fields of the metadata of an instance used by the detector
+type metadata struct {
+	UUID             string `json:"uuid"`
+	Name             string `json:"name"`
+	Hostname         string `json:"hostname"`
+	AvailabilityZone string `json:"availability_zone"`
+}
+
+// NewDetector creates a new OpenStack metadata detector
+func NewDetector(p component.ProcessorCreateSettings, _ internal.DetectorConfig) (internal.Detector, error) {
+	return &Detector{
+		endpoint: defaultEndpoint,
+		logger:   p.Logger,
+	}, nil
+}
+
+// Detect detects OpenStack instance metadata and returns a resource with the available ones
+func (d *Detector) Detect(ctx context.Context) (resource pcommon.Resource, schemaURL string, err error) {
+	res := pcommon.NewResource()
+
+	meta, err := d.metadata(ctx)
+	if err != nil {
+		d.logger.Debug("OpenStack detector metadata retrieval failed", zap.Error(err))
+		// return an empty Resource and no error
+		return res, "", nil
+	}
+
+	hostname := meta.Hostname
+	if hostname == "" {
+		hostname = meta.Name
+	}
+
+	attrs := res.Attributes()
+	attrs.PutStr(conventions.AttributeCloudProvider, cloudProviderOpenStack)
+	attrs.PutStr(conventions.AttributeHostID, meta.UUID)
+	attrs.PutStr(conventions.AttributeHostName, hostname)
+	if meta.AvailabilityZone != "" {
+		attrs.PutStr(conventions.AttributeCloudAvailabilityZone, meta.AvailabilityZone)
+	}
+
+	return res, conventions.SchemaURL, nil
+}
+
+func (d *Detector) metadata(ctx context.Context) (*metadata, error) {
+	client, err := internal.ClientFromContext(ctx)
+	if err != nil {
+		client = http.DefaultClient
+		d.logger.Debug("Error retrieving client from context thus creating default", zap.Error(err))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.endpoint+metadataPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metadata service responded with status %d", resp.StatusCode)
+	}
+
+	var meta metadata
+	if err = json.NewDecoder(resp.Body).Decode(&meta); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	if meta.UUID == "" {
+		return nil, errors.New("metadata does not contain the uuid of the instance")
+	}
+	return &meta, nil
+}
@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openstack
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+func TestNewDetector(t *testing.T) {
+	d, err := NewDetector(componenttest.NewNopProcessorCreateSettings(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, defaultEndpoint, d.(*Detector).endpoint)
+}
+
+func TestDetectFull(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "meta_data.json"))
+	require.NoError(t, err)
+	server := newMetadataServer(t, http.StatusOK, body)
+
+	client := &http.Client{}
+	detector := &Detector{endpoint: server.URL, logger: zap.NewNop()}
+	res, schemaURL, err := detector.Detect(internal.ContextWithClient(context.Background(), client))
+	require.NoError(t, err)
+	assert.Equal(t, conventions.SchemaURL, schemaURL)
+	res.Attributes().Sort()
+
+	expected := internal.NewResource(map[string]interface{}{
+		conventions.AttributeCloudProvider:         "openstack",
+		conventions.AttributeHostID:                "d8e02d56-2648-49a3-bf97-6be8f1204f38",
+		conventions.AttributeHostName:              "test-instance.novalocal",
+		conventions.AttributeCloudAvailabilityZone: "nova",
+	})
+	expected.Attributes().Sort()
+
+	assert.Equal(t, expected, res)
+}
+
+func TestDetectHostnameFallback(t *testing.T) {
+	server := newMetadataServer(t, http.StatusOK, []byte(`{"uuid": "d8e02d56-2648-49a3-bf97-6be8f1204f38", "name": "test-instance"}`))
+
+	detector := &Detector{endpoint: server.URL, logger: zap.NewNop()}
+	res, _, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+
+	hostname, ok := res.Attributes().Get(conventions.AttributeHostName)
+	require.True(t, ok)
+	assert.Equal(t, "test-instance", hostname.Str())
+	_, ok = res.Attributes().Get(conventions.AttributeCloudAvailabilityZone)
+	assert.False(t, ok)
+}
+
+func TestDetectMetadataUnavailable(t *testing.T) {
+	for name, server := range map[string]*httptest.Server{
+		"not found":    newMetadataServer(t, http.StatusNotFound, nil),
+		"invalid json": newMetadataServer(t, http.StatusOK, []byte(`<html></html>`)),
+		"no uuid":      newMetadataServer(t, http.StatusOK, []byte(`{"name": "test-instance"}`)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			detector := &Detector{endpoint: server.URL, logger: zap.NewNop()}
+			res, schemaURL, err := detector.Detect(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, "", schemaURL)
+			assert.Equal(t, 0, res.Attributes().Len())
+		})
+	}
+}
+
+func newMetadataServer(t *testing.T, status int, body []byte) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != metadataPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
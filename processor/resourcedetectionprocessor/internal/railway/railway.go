@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package railway provides a detector that loads resource information from
+// the environment variables that Railway injects into every deployment.
+package railway // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/railway"
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+const (
+	// TypeStr is type of detector.
+	TypeStr = "railway"
+
+	// cloudProviderRailway is the cloud.provider of Railway, which is not defined by the semantic conventions
+	cloudProviderRailway = "railway"
+
+	// Environment variables that are set by Railway for every deployment
+	projectIDEnvVar       = "RAILWAY_PROJECT_ID"
+	serviceNameEnvVar     = "RAILWAY_SERVICE_NAME"
+	environmentNameEnvVar = "RAILWAY_ENVIRONMENT_NAME"
+	replicaIDEnvVar       = "RAILWAY_REPLICA_ID"
+	replicaRegionEnvVar   = "RAILWAY_REPLICA_REGION"
+
+	attributeRailwayProjectID = "railway.project.id"
+)
+
+var _ internal.Detector = (*Detector)(nil)
+
+type Detector struct {
+	getenv func(string) string
+}
+
+// NewDetector creates a new Railway detector
+func NewDetector(component.ProcessorCreateSettings, internal.DetectorConfig) (internal.Detector, error) {
+	return &Detector{getenv: os.Getenv}, nil
+}
+
+func (d *Detector) Detect(context.Context) (resource pcommon.Resource, schemaURL string, err error) {
+	res := pcommon.NewResource()
+
+	// The project ID is always set when running on Railway
+	projectID := d.getenv(projectIDEnvVar)
+	if projectID == "" {
+		return res, "", nil
+	}
+
+	attrs := res.Attributes()
+	attrs.PutStr(conventions.AttributeCloudProvider, cloudProviderRailway)
+	attrs.PutStr(attributeRailwayProjectID, projectID)
+	putIfNotEmpty(attrs, conventions.AttributeServiceName, d.getenv(serviceNameEnvVar))
+	putIfNotEmpty(attrs, conventions.AttributeCloudRegion, d.getenv(replicaRegionEnvVar))
+	putIfNotEmpty(attrs, conventions.AttributeDeploymentEnvironment, d.getenv(environmentNameEnvVar))
+	putIfNotEmpty(attrs, conventions.AttributeServiceInstanceID, d.getenv(replicaIDEnvVar))
+
+	return res, conventions.SchemaURL, nil
+}
+
+func putIfNotEmpty(attrs pcommon.Map, key string, value string) {
+	if value != "" {
+		attrs.PutStr(key, value)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package railway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+func mockEnv(env map[string]string) func(string) string {
+	return func(key string) string {
+		return env[key]
+	}
+}
+
+func TestNewDetector(t *testing.T) {
+	d, err := NewDetector(componenttest.NewNopProcessorCreateSettings(), nil)
+	assert.NotNil(t, d)
+	assert.NoError(t, err)
+}
+
+func TestDetectFull(t *testing.T) {
+	detector := &Detector{getenv: mockEnv(map[string]string{
+		projectIDEnvVar:       "2f5a9c1e-7d3b-4e8a-9b6c-1a2b3c4d5e6f",
+		serviceNameEnvVar:     "checkout",
+		environmentNameEnvVar: "production",
+		replicaIDEnvVar:       "9d8c7b6a-5e4f-4a3b-8c2d-1e0f9a8b7c6d",
+		replicaRegionEnvVar:   "us-west2",
+	})}
+	res, schemaURL, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, conventions.SchemaURL, schemaURL)
+	res.Attributes().Sort()
+
+	expected := internal.NewResource(map[string]interface{}{
+		conventions.AttributeCloudProvider:         cloudProviderRailway,
+		conventions.AttributeCloudRegion:           "us-west2",
+		conventions.AttributeServiceName:           "checkout",
+		conventions.AttributeServiceInstanceID:     "9d8c7b6a-5e4f-4a3b-8c2d-1e0f9a8b7c6d",
+		conventions.AttributeDeploymentEnvironment: "production",
+		attributeRailwayProjectID:                  "2f5a9c1e-7d3b-4e8a-9b6c-1a2b3c4d5e6f",
+	})
+	expected.Attributes().Sort()
+
+	assert.Equal(t, expected, res)
+}
+
+func TestDetectPartial(t *testing.T) {
+	detector := &Detector{getenv: mockEnv(map[string]string{
+		projectIDEnvVar: "2f5a9c1e-7d3b-4e8a-9b6c-1a2b3c4d5e6f",
+	})}
+	res, _, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	res.Attributes().Sort()
+
+	expected := internal.NewResource(map[string]interface{}{
+		conventions.AttributeCloudProvider: cloudProviderRailway,
+		attributeRailwayProjectID:          "2f5a9c1e-7d3b-4e8a-9b6c-1a2b3c4d5e6f",
+	})
+	expected.Attributes().Sort()
+
+	assert.Equal(t, expected, res)
+}
+
+func TestDetectNotRailway(t *testing.T) {
+	detector := &Detector{getenv: mockEnv(map[string]string{
+		// the service name alone does not identify Railway
+		serviceNameEnvVar: "checkout",
+	})}
+	res, schemaURL, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "", schemaURL)
+	assert.True(t, internal.IsEmptyResource(res))
+}
@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package render provides a detector that loads resource information from
+// the environment variables that Render injects into every service instance.
+package render // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/render"
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+const (
+	// TypeStr is type of detector.
+	TypeStr = "render"
+
+	// cloudProviderRender is the cloud.provider of Render, which is not defined by the semantic conventions
+	cloudProviderRender = "render"
+
+	// Environment variables that are set by Render for every service instance.
+	// Render does not expose the region of a service to its instances.
+	serviceNameEnvVar = "RENDER_SERVICE_NAME"
+	serviceIDEnvVar   = "RENDER_SERVICE_ID"
+	instanceIDEnvVar  = "RENDER_INSTANCE_ID"
+
+	attributeRenderServiceID = "render.service.id"
+)
+
+var _ internal.Detector = (*Detector)(nil)
+
+type Detector struct {
+	getenv func(string) string
+}
+
+// NewDetector creates a new Render detector
+func NewDetector(component.ProcessorCreateSettings, internal.DetectorConfig) (internal.Detector, error) {
+	return &Detector{getenv: os.Getenv}, nil
+}
+
+func (d *Detector) Detect(context.Context) (resource pcommon.Resource, schemaURL string, err error) {
+	res := pcommon.NewResource()
+
+	// The service name is always set when running on Render
+	serviceName := d.getenv(serviceNameEnvVar)
+	if serviceName == "" {
+		return res, "", nil
+	}
+
+	attrs := res.Attributes()
+	attrs.PutStr(conventions.AttributeCloudProvider, cloudProviderRender)
+	attrs.PutStr(conventions.AttributeServiceName, serviceName)
+	putIfNotEmpty(attrs, attributeRenderServiceID, d.getenv(serviceIDEnvVar))
+	putIfNotEmpty(attrs, conventions.AttributeServiceInstanceID, d.getenv(instanceIDEnvVar))
+
+	return res, conventions.SchemaURL, nil
+}
+
+func putIfNotEmpty(attrs pcommon.Map, key string, value string) {
+	if value != "" {
+		attrs.PutStr(key, value)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+func mockEnv(env map[string]string) func(string) string {
+	return func(key string) string {
+		return env[key]
+	}
+}
+
+func TestNewDetector(t *testing.T) {
+	d, err := NewDetector(componenttest.NewNopProcessorCreateSettings(), nil)
+	assert.NotNil(t, d)
+	assert.NoError(t, err)
+}
+
+func TestDetectFull(t *testing.T) {
+	detector := &Detector{getenv: mockEnv(map[string]string{
+		serviceNameEnvVar: "checkout",
+		serviceIDEnvVar:   "srv-cf2ktbarrk0f0bd3wq1g",
+		instanceIDEnvVar:  "srv-cf2ktbarrk0f0bd3wq1g-5d8b7c6f4-x2j9q",
+	})}
+	res, schemaURL, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, conventions.SchemaURL, schemaURL)
+	res.Attributes().Sort()
+
+	expected := internal.NewResource(map[string]interface{}{
+		conventions.AttributeCloudProvider:     cloudProviderRender,
+		conventions.AttributeServiceName:       "checkout",
+		conventions.AttributeServiceInstanceID: "srv-cf2ktbarrk0f0bd3wq1g-5d8b7c6f4-x2j9q",
+		attributeRenderServiceID:               "srv-cf2ktbarrk0f0bd3wq1g",
+	})
+	expected.Attributes().Sort()
+
+	assert.Equal(t, expected, res)
+}
+
+func TestDetectPartial(t *testing.T) {
+	detector := &Detector{getenv: mockEnv(map[string]string{
+		serviceNameEnvVar: "checkout",
+	})}
+	res, _, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	res.Attributes().Sort()
+
+	expected := internal.NewResource(map[string]interface{}{
+		conventions.AttributeCloudProvider: cloudProviderRender,
+		conventions.AttributeServiceName:   "checkout",
+	})
+	expected.Attributes().Sort()
+
+	assert.Equal(t, expected, res)
+}
+
+func TestDetectNotRender(t *testing.T) {
+	detector := &Detector{getenv: mockEnv(map[string]string{
+		"FLY_APP_NAME": "checkout",
+	})}
+	res, schemaURL, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "", schemaURL)
+	assert.True(t, internal.IsEmptyResource(res))
+}
@@ -20,6 +20,9 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -30,12 +33,107 @@ import (
 
 type DetectorType string
 
+// detectorInstanceSep separates the type of a detector from the name of one of its instances, e.g. "env/app".
+const detectorInstanceSep = "/"
+
+// BaseType returns the type of the detector without the name of the instance, if any.
+func (d DetectorType) BaseType() DetectorType {
+	if idx := strings.Index(string(d), detectorInstanceSep); idx >= 0 {
+		return d[:idx]
+	}
+	return d
+}
+
 type Detector interface {
 	Detect(ctx context.Context) (resource pcommon.Resource, schemaURL string, err error)
 }
 
 type DetectorConfig interface{}
 
+// DetectionMode controls how the resources returned by the configured detectors are combined.
+type DetectionMode string
+
+const (
+	// DetectionModeMerge runs every detector and merges the detected resources,
+	// attributes of earlier detectors take precedence.
+	DetectionModeMerge DetectionMode = "merge"
+	// DetectionModeFirstMatch stops at the first detector that returns a non-empty resource.
+	DetectionModeFirstMatch DetectionMode = "first_match"
+)
+
+// ConflictPolicy determines which value is kept when merged resources contain the same attribute.
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyFirst keeps the value of the resource that was merged first.
+	ConflictPolicyFirst ConflictPolicy = "first"
+	// ConflictPolicyLast keeps the value of the resource that was merged last.
+	ConflictPolicyLast ConflictPolicy = "last"
+	// ConflictPolicyMin keeps the smallest value, independently of the merge order.
+	ConflictPolicyMin ConflictPolicy = "min"
+	// ConflictPolicyMax keeps the largest value, independently of the merge order.
+	ConflictPolicyMax ConflictPolicy = "max"
+)
+
+// SchemaURLPolicy determines the schema URL of incoming telemetry once the detected resource is applied.
+type SchemaURLPolicy string
+
+const (
+	// SchemaURLPolicyKeepIncoming leaves the schema URL of the incoming telemetry as is.
+	SchemaURLPolicyKeepIncoming SchemaURLPolicy = "keep_incoming"
+	// SchemaURLPolicyKeepDetected replaces the schema URL of the incoming telemetry with the detected one, if any.
+	SchemaURLPolicyKeepDetected SchemaURLPolicy = "keep_detected"
+	// SchemaURLPolicyMerge merges the incoming and detected schema URLs with MergeSchemaURL.
+	SchemaURLPolicyMerge SchemaURLPolicy = "merge"
+)
+
+// AttributeValueFilter filters a detected attribute by its value. The attribute is dropped when its
+// value is one of Exclude, or when Include is set and its value is not one of Include.
+type AttributeValueFilter struct {
+	Include []string `mapstructure:"include"`
+	Exclude []string `mapstructure:"exclude"`
+}
+
+func (f AttributeValueFilter) keep(value string) bool {
+	for _, excluded := range f.Exclude {
+		if value == excluded {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, included := range f.Include {
+		if value == included {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectorCondition is the precondition of a detector on the resource merged from the detectors run before it. The
+// detector only runs when the merged resource has Attribute, with one of Values if Values is set.
+type DetectorCondition struct {
+	Attribute string   `mapstructure:"attribute"`
+	Values    []string `mapstructure:"values"`
+}
+
+func (c DetectorCondition) met(am pcommon.Map) bool {
+	v, ok := am.Get(c.Attribute)
+	if !ok {
+		return false
+	}
+	if len(c.Values) == 0 {
+		return true
+	}
+	for _, value := range c.Values {
+		if v.AsString() == value {
+			return true
+		}
+	}
+	return false
+}
+
 type ResourceDetectorConfig interface {
 	GetConfigFromType(DetectorType) DetectorConfig
 }
@@ -55,6 +153,7 @@ func (f *ResourceProviderFactory) CreateResourceProvider(
 	params component.ProcessorCreateSettings,
 	timeout time.Duration,
 	attributes []string,
+	settings ProviderSettings,
 	detectorConfigs ResourceDetectorConfig,
 	detectorTypes ...DetectorType) (*ResourceProvider, error) {
 	detectors, err := f.getDetectors(params, detectorConfigs, detectorTypes)
@@ -69,14 +168,15 @@ func (f *ResourceProviderFactory) CreateResourceProvider(
 		}
 	}
 
-	provider := NewResourceProvider(params.Logger, timeout, attributesToKeep, detectors...)
+	provider := NewResourceProvider(params.Logger, timeout, attributesToKeep, settings, detectors...)
 	return provider, nil
 }
 
 func (f *ResourceProviderFactory) getDetectors(params component.ProcessorCreateSettings, detectorConfigs ResourceDetectorConfig, detectorTypes []DetectorType) ([]Detector, error) {
 	detectors := make([]Detector, 0, len(detectorTypes))
 	for _, detectorType := range detectorTypes {
-		detectorFactory, ok := f.detectors[detectorType]
+		// named instances of a detector, e.g. "env/app", are created by the factory of their type
+		detectorFactory, ok := f.detectors[detectorType.BaseType()]
 		if !ok {
 			return nil, fmt.Errorf("invalid detector key: %v", detectorType)
 		}
@@ -92,6 +192,39 @@ func (f *ResourceProviderFactory) getDetectors(params component.ProcessorCreateS
 	return detectors, nil
 }
 
+// ProviderSettings holds the settings of a ResourceProvider besides its timeout, its attributes to keep and its detectors.
+// The zero value runs every detector in the configured order and keeps the first value of conflicting attributes.
+type ProviderSettings struct {
+	Mode           DetectionMode
+	ConflictPolicy ConflictPolicy
+	// AttributeTemplates maps attribute keys to templates referencing detected attributes, e.g. "${host.name}"
+	AttributeTemplates map[string]string
+	// AttributeValues maps attribute keys to the filter of their values
+	AttributeValues map[string]AttributeValueFilter
+	// Cache persists the detected resource across restarts, detection is not cached if nil
+	Cache *ResourceCache
+	// RequestTimeout bounds each request of the detectors, so that a detector making several requests is not stuck
+	// on a single one for the whole timeout. Detectors honor it through RequestContext. Unbounded if 0.
+	RequestTimeout time.Duration
+	// PreferNonEmpty keeps the non-empty string value of an attribute that a detector detects as an empty
+	// string and another one as a non-empty string, whatever the conflict policy.
+	PreferNonEmpty bool
+	// Priorities holds the priority of every detector, by the index of the detector. The resources of the detectors
+	// are merged in decreasing order of priority instead of the configured order, detectors of the same priority keep
+	// their configured order.
+	Priorities []int
+	// Conditions holds the precondition of every detector, by the index of the detector. A detector whose
+	// precondition is not met by the resource merged from the detectors run before it is skipped, detectors with
+	// a nil condition always run.
+	Conditions []*DetectorCondition
+	// ReadinessAttributes retries the detection every ReadinessInterval until the detected resource has all of them,
+	// so that telemetry is not processed with an incomplete resource while a metadata service is not available yet.
+	// The resource of the last detection is used once ReadinessMaxWait elapsed.
+	ReadinessAttributes []string
+	ReadinessMaxWait    time.Duration
+	ReadinessInterval   time.Duration
+}
+
 type ResourceProvider struct {
 	logger           *zap.Logger
 	timeout          time.Duration
@@ -99,6 +232,29 @@ type ResourceProvider struct {
 	detectedResource *resourceResult
 	once             sync.Once
 	attributesToKeep map[string]struct{}
+	mode             DetectionMode
+	conflictPolicy   ConflictPolicy
+	// attributeTemplates maps attribute keys to templates referencing detected attributes, e.g. "${host.name}"
+	attributeTemplates map[string]string
+	// attributeValues maps attribute keys to the filter of their values
+	attributeValues map[string]AttributeValueFilter
+	// cache persists the detected resource across restarts, it is nil if caching is disabled
+	cache *ResourceCache
+	// requestTimeout bounds each request of the detectors, 0 if requests are only bounded by the timeout
+	requestTimeout time.Duration
+	// preferNonEmpty replaces the empty string values detected by a detector with the non-empty values of the other detectors
+	preferNonEmpty bool
+	// priorities holds the priority of every detector by its index, nil if the detectors run in the configured order
+	priorities []int
+	// conditions holds the precondition of every detector by its index, detectors with a nil condition always run
+	conditions []*DetectorCondition
+	// readinessAttributes are the attributes the detected resource must have for the detection to succeed, the
+	// detection is retried every readinessInterval until they are detected or readinessMaxWait elapses
+	readinessAttributes []string
+	readinessMaxWait    time.Duration
+	readinessInterval   time.Duration
+	// refreshes tracks the detection refreshing the cache when a cached resource was used
+	refreshes sync.WaitGroup
 }
 
 type resourceResult struct {
@@ -107,53 +263,235 @@ type resourceResult struct {
 	err       error
 }
 
-func NewResourceProvider(logger *zap.Logger, timeout time.Duration, attributesToKeep map[string]struct{}, detectors ...Detector) *ResourceProvider {
+func NewResourceProvider(logger *zap.Logger, timeout time.Duration, attributesToKeep map[string]struct{}, settings ProviderSettings, detectors ...Detector) *ResourceProvider {
 	return &ResourceProvider{
-		logger:           logger,
-		timeout:          timeout,
-		detectors:        detectors,
-		attributesToKeep: attributesToKeep,
+		logger:              logger,
+		timeout:             timeout,
+		detectors:           detectors,
+		attributesToKeep:    attributesToKeep,
+		mode:                settings.Mode,
+		conflictPolicy:      settings.ConflictPolicy,
+		attributeTemplates:  settings.AttributeTemplates,
+		attributeValues:     settings.AttributeValues,
+		cache:               settings.Cache,
+		requestTimeout:      settings.RequestTimeout,
+		preferNonEmpty:      settings.PreferNonEmpty,
+		priorities:          settings.Priorities,
+		conditions:          settings.Conditions,
+		readinessAttributes: settings.ReadinessAttributes,
+		readinessMaxWait:    settings.ReadinessMaxWait,
+		readinessInterval:   settings.ReadinessInterval,
+	}
+}
+
+// detectionOrder returns the indexes of the detectors in the order their resources are merged in.
+func (p *ResourceProvider) detectionOrder() []int {
+	order := make([]int, len(p.detectors))
+	for i := range order {
+		order[i] = i
+	}
+	if len(p.priorities) != len(p.detectors) {
+		return order
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return p.priorities[order[i]] > p.priorities[order[j]]
+	})
+	return order
+}
+
+// Start starts the cache of the provider, if any.
+func (p *ResourceProvider) Start(ctx context.Context, host component.Host) error {
+	if p.cache == nil {
+		return nil
+	}
+	return p.cache.Start(ctx, host)
+}
+
+// Shutdown waits for a refresh of the cache to finish and shuts down the cache of the provider, if any.
+func (p *ResourceProvider) Shutdown(ctx context.Context) error {
+	if p.cache == nil {
+		return nil
 	}
+	p.refreshes.Wait()
+	return p.cache.Shutdown(ctx)
 }
 
 func (p *ResourceProvider) Get(ctx context.Context, client *http.Client) (resource pcommon.Resource, schemaURL string, err error) {
 	p.once.Do(func() {
+		if p.loadCachedResource(ctx, client) {
+			return
+		}
+		p.detectedResource = p.detectUntilReady(ctx, client.Timeout)
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, client.Timeout)
 		defer cancel()
-		p.detectResource(ctx)
+		p.storeResource(ctx, p.detectedResource)
 	})
 
 	return p.detectedResource.resource, p.detectedResource.schemaURL, p.detectedResource.err
 }
 
-func (p *ResourceProvider) detectResource(ctx context.Context) {
-	p.detectedResource = &resourceResult{}
+// loadCachedResource uses the cached resource if the cache holds one that has not expired yet,
+// the detectors are then run in the background to refresh the cache for the next start.
+func (p *ResourceProvider) loadCachedResource(ctx context.Context, client *http.Client) bool {
+	if p.cache == nil {
+		return false
+	}
+	res, schemaURL, ok, err := p.cache.load(ctx)
+	if err != nil {
+		p.logger.Warn("failed to load cached resource, detecting resource information", zap.Error(err))
+		return false
+	}
+	if !ok {
+		return false
+	}
+
+	p.logger.Info("using cached resource information", zap.Any("resource", AttributesToMap(res.Attributes())))
+	p.detectedResource = &resourceResult{resource: res, schemaURL: schemaURL}
+
+	p.refreshes.Add(1)
+	go func() {
+		defer p.refreshes.Done()
+		result := p.detectUntilReady(ctx, client.Timeout)
+		refreshCtx, cancel := context.WithTimeout(ctx, client.Timeout)
+		defer cancel()
+		p.storeResource(refreshCtx, result)
+	}()
+	return true
+}
+
+// storeResource stores the detected resource in the cache, if any.
+func (p *ResourceProvider) storeResource(ctx context.Context, result *resourceResult) {
+	if p.cache == nil || result.err != nil {
+		return
+	}
+	if err := p.cache.store(ctx, result.resource, result.schemaURL); err != nil {
+		p.logger.Warn("failed to cache detected resource information", zap.Error(err))
+	}
+}
+
+// detectUntilReady runs the detectors, bounded by the timeout, until the detected resource has all readiness
+// attributes or the readiness max wait elapses. Without readiness attributes the detectors are run once.
+func (p *ResourceProvider) detectUntilReady(ctx context.Context, timeout time.Duration) *resourceResult {
+	deadline := time.Now().Add(p.readinessMaxWait)
+	for {
+		detectCtx, cancel := context.WithTimeout(ctx, timeout)
+		result := p.detectResource(detectCtx)
+		cancel()
+		missing := missingAttributes(result.resource.Attributes(), p.readinessAttributes)
+		if len(missing) == 0 {
+			return result
+		}
+		if !time.Now().Add(p.readinessInterval).Before(deadline) {
+			p.logger.Warn("resource is not ready once the readiness max wait elapsed, using the detected resource",
+				zap.Strings("missing attributes", missing), zap.Duration("max wait", p.readinessMaxWait))
+			return result
+		}
+		p.logger.Info("resource is not ready, retrying detection",
+			zap.Strings("missing attributes", missing), zap.Duration("retry interval", p.readinessInterval))
+		select {
+		case <-time.After(p.readinessInterval):
+		case <-ctx.Done():
+			return result
+		}
+	}
+}
+
+// missingAttributes returns the keys that the attributes do not have.
+func missingAttributes(am pcommon.Map, keys []string) []string {
+	var missing []string
+	for _, key := range keys {
+		if _, ok := am.Get(key); !ok {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+// detectResource runs the detectors and returns the detected resource.
+func (p *ResourceProvider) detectResource(ctx context.Context) *resourceResult {
+	result := &resourceResult{}
 
 	res := pcommon.NewResource()
 	mergedSchemaURL := ""
 
 	p.logger.Info("began detecting resource information")
 
-	for _, detector := range p.detectors {
-		r, schemaURL, err := detector.Detect(ctx)
+	if p.requestTimeout > 0 {
+		ctx = ContextWithRequestTimeout(ctx, p.requestTimeout)
+	}
+
+	for _, i := range p.detectionOrder() {
+		if i < len(p.conditions) && p.conditions[i] != nil && !p.conditions[i].met(res.Attributes()) {
+			p.logger.Debug("skipping detector whose condition is not met",
+				zap.Int("detector", i), zap.String("condition attribute", p.conditions[i].Attribute))
+			continue
+		}
+		r, schemaURL, err := p.detectors[i].Detect(ctx)
 		if err != nil {
 			p.logger.Warn("failed to detect resource", zap.Error(err))
-		} else {
-			mergedSchemaURL = MergeSchemaURL(mergedSchemaURL, schemaURL)
-			MergeResource(res, r, false)
+			continue
+		}
+		if p.mode == DetectionModeFirstMatch && r.Attributes().Len() == 0 {
+			continue
+		}
+
+		mergedSchemaURL = MergeSchemaURL(mergedSchemaURL, schemaURL)
+		MergeResourceWithOptions(res, r, p.conflictPolicy, p.preferNonEmpty)
+
+		if p.mode == DetectionModeFirstMatch {
+			break
 		}
 	}
 
-	droppedAttributes := filterAttributes(res.Attributes(), p.attributesToKeep)
+	// templates are evaluated before filtering, so they can reference attributes that are not kept
+	templatedAttributes := p.evaluateAttributeTemplates(res.Attributes())
+	droppedAttributes := filterAttributes(res.Attributes(), p.attributesToKeep, p.attributeValues)
+	for _, key := range sortedKeys(templatedAttributes) {
+		res.Attributes().PutStr(key, templatedAttributes[key])
+	}
 
 	p.logger.Info("detected resource information", zap.Any("resource", AttributesToMap(res.Attributes())))
 	if len(droppedAttributes) > 0 {
 		p.logger.Info("dropped resource information", zap.Strings("resource keys", droppedAttributes))
 	}
 
-	p.detectedResource.resource = res
-	p.detectedResource.schemaURL = mergedSchemaURL
+	result.resource = res
+	result.schemaURL = mergedSchemaURL
+	return result
+}
+
+// evaluateAttributeTemplates expands the ${<attribute>} references of every attribute template with the
+// values of the detected attributes. Templates referencing an attribute that was not detected are skipped.
+func (p *ResourceProvider) evaluateAttributeTemplates(am pcommon.Map) map[string]string {
+	values := make(map[string]string, len(p.attributeTemplates))
+	for _, key := range sortedKeys(p.attributeTemplates) {
+		var missing []string
+		value := os.Expand(p.attributeTemplates[key], func(ref string) string {
+			v, ok := am.Get(ref)
+			if !ok {
+				missing = append(missing, ref)
+				return ""
+			}
+			return v.AsString()
+		})
+		if len(missing) > 0 {
+			p.logger.Warn("skipping attribute template referencing attributes that were not detected",
+				zap.String("attribute", key), zap.Strings("missing attributes", missing))
+			continue
+		}
+		values[key] = value
+	}
+	return values
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 func AttributesToMap(am pcommon.Map) map[string]interface{} {
@@ -193,6 +531,22 @@ func getSerializableArray(inArr pcommon.Slice) []interface{} {
 	return outArr
 }
 
+// ApplySchemaURL returns the schema URL of telemetry with the incoming schema URL once the resource with
+// the detected schema URL is applied to it, according to the policy.
+func ApplySchemaURL(policy SchemaURLPolicy, incomingSchemaURL string, detectedSchemaURL string) string {
+	switch policy {
+	case SchemaURLPolicyKeepIncoming:
+		return incomingSchemaURL
+	case SchemaURLPolicyKeepDetected:
+		if detectedSchemaURL == "" {
+			return incomingSchemaURL
+		}
+		return detectedSchemaURL
+	default:
+		return MergeSchemaURL(incomingSchemaURL, detectedSchemaURL)
+	}
+}
+
 func MergeSchemaURL(currentSchemaURL string, newSchemaURL string) string {
 	if currentSchemaURL == "" {
 		return newSchemaURL
@@ -208,39 +562,117 @@ func MergeSchemaURL(currentSchemaURL string, newSchemaURL string) string {
 	return currentSchemaURL
 }
 
-func filterAttributes(am pcommon.Map, attributesToKeep map[string]struct{}) []string {
-	if len(attributesToKeep) > 0 {
-		var droppedAttributes []string
-		am.RemoveIf(func(k string, v pcommon.Value) bool {
-			_, keep := attributesToKeep[k]
-			if !keep {
-				droppedAttributes = append(droppedAttributes, k)
-			}
-			return !keep
-		})
-		return droppedAttributes
+// filterAttributes removes the attributes that are not in attributesToKeep, if it is set, and the
+// attributes whose value is dropped by their filter in attributeValues. It returns the removed keys.
+func filterAttributes(am pcommon.Map, attributesToKeep map[string]struct{}, attributeValues map[string]AttributeValueFilter) []string {
+	if len(attributesToKeep) == 0 && len(attributeValues) == 0 {
+		return nil
+	}
+	var droppedAttributes []string
+	am.RemoveIf(func(k string, v pcommon.Value) bool {
+		keep := true
+		if len(attributesToKeep) > 0 {
+			_, keep = attributesToKeep[k]
+		}
+		if filter, ok := attributeValues[k]; ok && keep {
+			keep = filter.keep(v.AsString())
+		}
+		if !keep {
+			droppedAttributes = append(droppedAttributes, k)
+		}
+		return !keep
+	})
+	return droppedAttributes
+}
+
+// MergeResource merges the attributes of from into to, overriding the attributes of to if overrideTo is set.
+// If preferNonEmpty is set, an empty string value is replaced by a non-empty string value and never replaces one.
+func MergeResource(to, from pcommon.Resource, overrideTo bool, preferNonEmpty bool) {
+	policy := ConflictPolicyFirst
+	if overrideTo {
+		policy = ConflictPolicyLast
 	}
-	return nil
+	MergeResourceWithOptions(to, from, policy, preferNonEmpty)
+}
+
+// MergeResourceWithPolicy merges the attributes of from into to, using policy to
+// pick the value of the attributes that are present in both resources.
+func MergeResourceWithPolicy(to, from pcommon.Resource, policy ConflictPolicy) {
+	MergeResourceWithOptions(to, from, policy, false)
 }
 
-func MergeResource(to, from pcommon.Resource, overrideTo bool) {
+// MergeResourceWithOptions merges the attributes of from into to like MergeResourceWithPolicy. If preferNonEmpty
+// is set, an attribute whose value is an empty string in one resource and a non-empty string in the other keeps
+// the non-empty value, whatever the policy.
+func MergeResourceWithOptions(to, from pcommon.Resource, policy ConflictPolicy, preferNonEmpty bool) {
 	if IsEmptyResource(from) {
 		return
 	}
 
 	toAttr := to.Attributes()
 	from.Attributes().Range(func(k string, v pcommon.Value) bool {
-		if overrideTo {
+		current, found := toAttr.Get(k)
+		if !found {
 			v.CopyTo(toAttr.PutEmpty(k))
-		} else {
-			if _, found := toAttr.Get(k); !found {
+			return true
+		}
+		if preferNonEmpty && isEmptyString(current) != isEmptyString(v) {
+			if isEmptyString(current) {
 				v.CopyTo(toAttr.PutEmpty(k))
 			}
+			return true
+		}
+		if keepNewValue(policy, current, v) {
+			v.CopyTo(toAttr.PutEmpty(k))
 		}
 		return true
 	})
 }
 
+// isEmptyString returns true if the value is a string without any character
+func isEmptyString(v pcommon.Value) bool {
+	return v.Type() == pcommon.ValueTypeStr && v.Str() == ""
+}
+
+func keepNewValue(policy ConflictPolicy, current, v pcommon.Value) bool {
+	switch policy {
+	case ConflictPolicyLast:
+		return true
+	case ConflictPolicyMin:
+		return compareValues(v, current) < 0
+	case ConflictPolicyMax:
+		return compareValues(v, current) > 0
+	default:
+		return false
+	}
+}
+
+// compareValues orders numeric values by their value and all other values by their string representation.
+func compareValues(a, b pcommon.Value) int {
+	if isNumeric(a) && isNumeric(b) {
+		af, bf := toFloat(a), toFloat(b)
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a.AsString(), b.AsString())
+}
+
+func isNumeric(v pcommon.Value) bool {
+	return v.Type() == pcommon.ValueTypeInt || v.Type() == pcommon.ValueTypeDouble
+}
+
+func toFloat(v pcommon.Value) float64 {
+	if v.Type() == pcommon.ValueTypeInt {
+		return float64(v.Int())
+	}
+	return v.Double()
+}
+
 func IsEmptyResource(res pcommon.Resource) bool {
 	return res.Attributes().Len() == 0
 }
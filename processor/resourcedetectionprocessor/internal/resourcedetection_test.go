@@ -30,6 +30,7 @@ import (
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 type MockDetector struct {
@@ -110,7 +111,7 @@ func TestDetect(t *testing.T) {
 			}
 
 			f := NewProviderFactory(mockDetectors)
-			p, err := f.CreateResourceProvider(componenttest.NewNopProcessorCreateSettings(), time.Second, tt.attributes, &mockDetectorConfig{}, mockDetectorTypes...)
+			p, err := f.CreateResourceProvider(componenttest.NewNopProcessorCreateSettings(), time.Second, tt.attributes, ProviderSettings{}, &mockDetectorConfig{}, mockDetectorTypes...)
 			require.NoError(t, err)
 
 			got, _, err := p.Get(context.Background(), http.DefaultClient)
@@ -126,7 +127,7 @@ func TestDetect(t *testing.T) {
 func TestDetectResource_InvalidDetectorType(t *testing.T) {
 	mockDetectorKey := DetectorType("mock")
 	p := NewProviderFactory(map[DetectorType]DetectorFactory{})
-	_, err := p.CreateResourceProvider(componenttest.NewNopProcessorCreateSettings(), time.Second, nil, &mockDetectorConfig{}, mockDetectorKey)
+	_, err := p.CreateResourceProvider(componenttest.NewNopProcessorCreateSettings(), time.Second, nil, ProviderSettings{}, &mockDetectorConfig{}, mockDetectorKey)
 	require.EqualError(t, err, fmt.Sprintf("invalid detector key: %v", mockDetectorKey))
 }
 
@@ -137,10 +138,45 @@ func TestDetectResource_DetectoryFactoryError(t *testing.T) {
 			return nil, errors.New("creation failed")
 		},
 	})
-	_, err := p.CreateResourceProvider(componenttest.NewNopProcessorCreateSettings(), time.Second, nil, &mockDetectorConfig{}, mockDetectorKey)
+	_, err := p.CreateResourceProvider(componenttest.NewNopProcessorCreateSettings(), time.Second, nil, ProviderSettings{}, &mockDetectorConfig{}, mockDetectorKey)
 	require.EqualError(t, err, fmt.Sprintf("failed creating detector type %q: %v", mockDetectorKey, "creation failed"))
 }
 
+type instanceDetectorConfig map[DetectorType]DetectorConfig
+
+func (d instanceDetectorConfig) GetConfigFromType(detectorType DetectorType) DetectorConfig {
+	return d[detectorType]
+}
+
+func TestDetectResource_NamedInstances(t *testing.T) {
+	var created []DetectorConfig
+	p := NewProviderFactory(map[DetectorType]DetectorFactory{
+		"mock": func(_ component.ProcessorCreateSettings, cfg DetectorConfig) (Detector, error) {
+			created = append(created, cfg)
+			md := &MockDetector{}
+			md.On("Detect").Return(NewResource(map[string]interface{}{cfg.(string): "true"}), nil)
+			return md, nil
+		},
+	})
+	detectorConfigs := instanceDetectorConfig{"mock/app": "app", "mock/infra": "infra"}
+	provider, err := p.CreateResourceProvider(componenttest.NewNopProcessorCreateSettings(), time.Second, nil, ProviderSettings{}, detectorConfigs, "mock/app", "mock/infra")
+	require.NoError(t, err)
+	assert.Equal(t, []DetectorConfig{"app", "infra"}, created)
+
+	got, _, err := provider.Get(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	expected := NewResource(map[string]interface{}{"app": "true", "infra": "true"})
+	expected.Attributes().Sort()
+	got.Attributes().Sort()
+	assert.Equal(t, expected, got)
+}
+
+func TestDetectorTypeBaseType(t *testing.T) {
+	assert.Equal(t, DetectorType("env"), DetectorType("env").BaseType())
+	assert.Equal(t, DetectorType("env"), DetectorType("env/app").BaseType())
+	assert.Equal(t, DetectorType("env"), DetectorType("env/app/v2").BaseType())
+}
+
 func TestDetectResource_Error(t *testing.T) {
 	md1 := &MockDetector{}
 	md1.On("Detect").Return(NewResource(map[string]interface{}{"a": "1", "b": "2"}), nil)
@@ -148,18 +184,218 @@ func TestDetectResource_Error(t *testing.T) {
 	md2 := &MockDetector{}
 	md2.On("Detect").Return(pcommon.NewResource(), errors.New("err1"))
 
-	p := NewResourceProvider(zap.NewNop(), time.Second, nil, md1, md2)
+	p := NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{}, md1, md2)
 	_, _, err := p.Get(context.Background(), http.DefaultClient)
 	require.NoError(t, err)
 }
 
+func TestDetectResource_FirstMatch(t *testing.T) {
+	md1 := &MockDetector{}
+	md1.On("Detect").Return(pcommon.NewResource(), nil)
+
+	md2 := &MockDetector{}
+	md2.On("Detect").Return(NewResource(map[string]interface{}{"a": "1", "b": "2"}), nil)
+
+	md3 := &MockDetector{}
+	md3.On("Detect").Return(NewResource(map[string]interface{}{"a": "11", "c": "3"}), nil)
+
+	expectedResource := NewResource(map[string]interface{}{"a": "1", "b": "2"})
+	expectedResource.Attributes().Sort()
+
+	p := NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{Mode: DetectionModeFirstMatch}, md1, md2, md3)
+	detected, _, err := p.Get(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+
+	detected.Attributes().Sort()
+	assert.Equal(t, expectedResource, detected)
+	md1.AssertNumberOfCalls(t, "Detect", 1)
+	md2.AssertNumberOfCalls(t, "Detect", 1)
+	md3.AssertNotCalled(t, "Detect")
+}
+
+func TestDetectResource_Priorities(t *testing.T) {
+	md1 := &MockDetector{}
+	md1.On("Detect").Return(NewResource(map[string]interface{}{"host.name": "from-env", "a": "1"}), nil)
+
+	md2 := &MockDetector{}
+	md2.On("Detect").Return(NewResource(map[string]interface{}{"host.name": "from-ec2", "b": "2"}), nil)
+
+	md3 := &MockDetector{}
+	md3.On("Detect").Return(NewResource(map[string]interface{}{"host.name": "from-system", "b": "22", "c": "3"}), nil)
+
+	// the detectors are configured out of priority order, the values of the highest priority detector win
+	p := NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{Priorities: []int{0, 10, 5}}, md1, md2, md3)
+	detected, _, err := p.Get(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"host.name": "from-ec2", "a": "1", "b": "2", "c": "3"}, detected.Attributes().AsRaw())
+
+	// detectors of the same priority keep their configured order
+	p = NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{Priorities: []int{0, 5, 5}}, md1, md2, md3)
+	detected, _, err = p.Get(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"host.name": "from-ec2", "a": "1", "b": "2", "c": "3"}, detected.Attributes().AsRaw())
+
+	p = NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{Priorities: []int{0, 5, 10}}, md1, md2, md3)
+	detected, _, err = p.Get(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"host.name": "from-system", "a": "1", "b": "22", "c": "3"}, detected.Attributes().AsRaw())
+}
+
+func TestDetectResource_PrioritiesFirstMatch(t *testing.T) {
+	md1 := &MockDetector{}
+	md1.On("Detect").Return(NewResource(map[string]interface{}{"a": "1"}), nil)
+
+	md2 := &MockDetector{}
+	md2.On("Detect").Return(NewResource(map[string]interface{}{"a": "2"}), nil)
+
+	p := NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{Mode: DetectionModeFirstMatch, Priorities: []int{1, 2}}, md1, md2)
+	detected, _, err := p.Get(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": "2"}, detected.Attributes().AsRaw())
+	md1.AssertNotCalled(t, "Detect")
+}
+
+func TestDetectResource_Conditions(t *testing.T) {
+	gcp := &MockDetector{}
+	gcp.On("Detect").Return(NewResource(map[string]interface{}{"cloud.provider": "gcp"}), nil)
+
+	aws := &MockDetector{}
+	aws.On("Detect").Return(NewResource(map[string]interface{}{"cloud.provider": "aws"}), nil)
+
+	gke := &MockDetector{}
+	gke.On("Detect").Return(NewResource(map[string]interface{}{"k8s.cluster.name": "cluster"}), nil)
+
+	onGCP := &DetectorCondition{Attribute: "cloud.provider", Values: []string{"gcp"}}
+
+	// the conditional detector runs as the precondition attribute was detected before it
+	p := NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{Conditions: []*DetectorCondition{nil, onGCP}}, gcp, gke)
+	detected, _, err := p.Get(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"cloud.provider": "gcp", "k8s.cluster.name": "cluster"}, detected.Attributes().AsRaw())
+	gke.AssertNumberOfCalls(t, "Detect", 1)
+
+	// the conditional detector is skipped if the attribute has another value
+	p = NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{Conditions: []*DetectorCondition{nil, onGCP}}, aws, gke)
+	detected, _, err = p.Get(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"cloud.provider": "aws"}, detected.Attributes().AsRaw())
+	gke.AssertNumberOfCalls(t, "Detect", 1)
+
+	// the precondition is evaluated on the detectors run before, so a precondition detected later is not met
+	p = NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{Conditions: []*DetectorCondition{onGCP, nil}}, gke, gcp)
+	detected, _, err = p.Get(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"cloud.provider": "gcp"}, detected.Attributes().AsRaw())
+	gke.AssertNumberOfCalls(t, "Detect", 1)
+
+	// the priorities order the detectors the precondition is evaluated on, a condition without values only requires the attribute
+	p = NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{
+		Priorities: []int{0, 10},
+		Conditions: []*DetectorCondition{{Attribute: "cloud.provider"}, nil},
+	}, gke, aws)
+	detected, _, err = p.Get(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"cloud.provider": "aws", "k8s.cluster.name": "cluster"}, detected.Attributes().AsRaw())
+	gke.AssertNumberOfCalls(t, "Detect", 2)
+}
+
+func TestDetectResource_Readiness(t *testing.T) {
+	md1 := &MockDetector{}
+	md1.On("Detect").Return(NewResource(map[string]interface{}{"host.name": "host"}), nil)
+
+	// the metadata service of the second detector is only available on the third attempt
+	md2 := &MockDetector{}
+	md2.On("Detect").Return(pcommon.NewResource(), errors.New("metadata service not available")).Once()
+	md2.On("Detect").Return(pcommon.NewResource(), nil).Once()
+	md2.On("Detect").Return(NewResource(map[string]interface{}{"cloud.instance.id": "i-1234"}), nil)
+
+	p := NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{
+		ReadinessAttributes: []string{"cloud.instance.id"},
+		ReadinessMaxWait:    time.Minute,
+		ReadinessInterval:   time.Millisecond,
+	}, md1, md2)
+	detected, _, err := p.Get(context.Background(), &http.Client{Timeout: time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"host.name": "host", "cloud.instance.id": "i-1234"}, detected.Attributes().AsRaw())
+	md2.AssertNumberOfCalls(t, "Detect", 3)
+}
+
+func TestDetectResource_ReadinessMaxWait(t *testing.T) {
+	md := &MockDetector{}
+	md.On("Detect").Return(NewResource(map[string]interface{}{"host.name": "host"}), nil)
+
+	p := NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{
+		ReadinessAttributes: []string{"cloud.instance.id"},
+		ReadinessMaxWait:    50 * time.Millisecond,
+		ReadinessInterval:   10 * time.Millisecond,
+	}, md)
+	start := time.Now()
+	detected, _, err := p.Get(context.Background(), &http.Client{Timeout: time.Second})
+	require.NoError(t, err)
+	// the last detected resource is used once the max wait elapsed
+	assert.Equal(t, map[string]interface{}{"host.name": "host"}, detected.Attributes().AsRaw())
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Greater(t, len(md.Calls), 1)
+}
+
+func TestDetectResource_AttributeTemplates(t *testing.T) {
+	md1 := &MockDetector{}
+	md1.On("Detect").Return(NewResource(map[string]interface{}{"host.name": "node-1"}), nil)
+
+	md2 := &MockDetector{}
+	md2.On("Detect").Return(NewResource(map[string]interface{}{"process.pid": int64(42)}), nil)
+
+	templates := map[string]string{
+		"service.instance.id": "${host.name}-${process.pid}",
+		"host.id":             "${host.name}-${host.arch}",
+	}
+	attributesToKeep := map[string]struct{}{"host.name": {}}
+
+	expectedResource := NewResource(map[string]interface{}{"host.name": "node-1", "service.instance.id": "node-1-42"})
+	expectedResource.Attributes().Sort()
+
+	core, observed := observer.New(zap.WarnLevel)
+	p := NewResourceProvider(zap.New(core), time.Second, attributesToKeep, ProviderSettings{AttributeTemplates: templates}, md1, md2)
+	detected, _, err := p.Get(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+
+	detected.Attributes().Sort()
+	assert.Equal(t, expectedResource, detected)
+
+	warnings := observed.FilterMessage("skipping attribute template referencing attributes that were not detected").All()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "host.id", warnings[0].ContextMap()["attribute"])
+	assert.Equal(t, []interface{}{"host.arch"}, warnings[0].ContextMap()["missing attributes"])
+}
+
+func TestDetectResource_AttributeValues(t *testing.T) {
+	md1 := &MockDetector{}
+	md1.On("Detect").Return(NewResource(map[string]interface{}{"host.name": "localhost", "os.type": "linux"}), nil)
+
+	md2 := &MockDetector{}
+	md2.On("Detect").Return(NewResource(map[string]interface{}{"host.name": "node-1"}), nil)
+
+	values := map[string]AttributeValueFilter{"host.name": {Exclude: []string{"localhost"}}}
+
+	p := NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{AttributeValues: values}, md1)
+	detected, _, err := p.Get(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"os.type": "linux"}, detected.Attributes().AsRaw())
+
+	p = NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{AttributeValues: values}, md2)
+	detected, _, err = p.Get(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"host.name": "node-1"}, detected.Attributes().AsRaw())
+}
+
 func TestMergeResource(t *testing.T) {
 	for _, tt := range []struct {
-		name       string
-		res1       pcommon.Resource
-		res2       pcommon.Resource
-		overrideTo bool
-		expected   pcommon.Resource
+		name           string
+		res1           pcommon.Resource
+		res2           pcommon.Resource
+		overrideTo     bool
+		preferNonEmpty bool
+		expected       pcommon.Resource
 	}{
 		{
 			name:       "override non-empty resources",
@@ -173,12 +409,32 @@ func TestMergeResource(t *testing.T) {
 			res2:       NewResource(map[string]interface{}{"a": "1", "c": "3"}),
 			overrideTo: false,
 			expected:   NewResource(map[string]interface{}{"a": "1", "c": "3"}),
+		}, {
+			name:       "empty value without prefer non-empty",
+			res1:       NewResource(map[string]interface{}{"host.name": ""}),
+			res2:       NewResource(map[string]interface{}{"host.name": "node-1"}),
+			overrideTo: false,
+			expected:   NewResource(map[string]interface{}{"host.name": ""}),
+		}, {
+			name:           "empty value with prefer non-empty",
+			res1:           NewResource(map[string]interface{}{"host.name": "", "a": "1"}),
+			res2:           NewResource(map[string]interface{}{"host.name": "node-1", "a": "2"}),
+			overrideTo:     false,
+			preferNonEmpty: true,
+			expected:       NewResource(map[string]interface{}{"host.name": "node-1", "a": "1"}),
+		}, {
+			name:           "override with empty value with prefer non-empty",
+			res1:           NewResource(map[string]interface{}{"host.name": "node-1"}),
+			res2:           NewResource(map[string]interface{}{"host.name": ""}),
+			overrideTo:     true,
+			preferNonEmpty: true,
+			expected:       NewResource(map[string]interface{}{"host.name": "node-1"}),
 		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			out := pcommon.NewResource()
 			tt.res1.CopyTo(out)
-			MergeResource(out, tt.res2, tt.overrideTo)
+			MergeResource(out, tt.res2, tt.overrideTo, tt.preferNonEmpty)
 			tt.expected.Attributes().Sort()
 			out.Attributes().Sort()
 			assert.Equal(t, tt.expected, out)
@@ -186,6 +442,82 @@ func TestMergeResource(t *testing.T) {
 	}
 }
 
+func TestDetectResource_PreferNonEmpty(t *testing.T) {
+	md1 := &MockDetector{}
+	md1.On("Detect").Return(NewResource(map[string]interface{}{"host.name": "", "os.type": "linux"}), nil)
+
+	md2 := &MockDetector{}
+	md2.On("Detect").Return(NewResource(map[string]interface{}{"host.name": "node-1", "os.type": "windows"}), nil)
+
+	p := NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{}, md1, md2)
+	detected, _, err := p.Get(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"host.name": "", "os.type": "linux"}, detected.Attributes().AsRaw())
+
+	p = NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{PreferNonEmpty: true}, md1, md2)
+	detected, _, err = p.Get(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"host.name": "node-1", "os.type": "linux"}, detected.Attributes().AsRaw())
+}
+
+func TestMergeResourceWithPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		policy   ConflictPolicy
+		res1     pcommon.Resource
+		res2     pcommon.Resource
+		expected pcommon.Resource
+		reversed pcommon.Resource
+	}{
+		{
+			name:     "first",
+			policy:   ConflictPolicyFirst,
+			res1:     NewResource(map[string]interface{}{"a": "b", "b": "1"}),
+			res2:     NewResource(map[string]interface{}{"a": "a", "c": "2"}),
+			expected: NewResource(map[string]interface{}{"a": "b", "b": "1", "c": "2"}),
+			reversed: NewResource(map[string]interface{}{"a": "a", "b": "1", "c": "2"}),
+		}, {
+			name:     "last",
+			policy:   ConflictPolicyLast,
+			res1:     NewResource(map[string]interface{}{"a": "b", "b": "1"}),
+			res2:     NewResource(map[string]interface{}{"a": "a", "c": "2"}),
+			expected: NewResource(map[string]interface{}{"a": "a", "b": "1", "c": "2"}),
+			reversed: NewResource(map[string]interface{}{"a": "b", "b": "1", "c": "2"}),
+		}, {
+			name:     "min",
+			policy:   ConflictPolicyMin,
+			res1:     NewResource(map[string]interface{}{"a": "b", "b": int64(10), "c": 0.5}),
+			res2:     NewResource(map[string]interface{}{"a": "a", "b": int64(9), "c": int64(1)}),
+			expected: NewResource(map[string]interface{}{"a": "a", "b": int64(9), "c": 0.5}),
+			reversed: NewResource(map[string]interface{}{"a": "a", "b": int64(9), "c": 0.5}),
+		}, {
+			name:     "max",
+			policy:   ConflictPolicyMax,
+			res1:     NewResource(map[string]interface{}{"a": "b", "b": int64(10), "c": 0.5}),
+			res2:     NewResource(map[string]interface{}{"a": "a", "b": int64(9), "c": int64(1)}),
+			expected: NewResource(map[string]interface{}{"a": "b", "b": int64(10), "c": int64(1)}),
+			reversed: NewResource(map[string]interface{}{"a": "b", "b": int64(10), "c": int64(1)}),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expected.Attributes().Sort()
+			tt.reversed.Attributes().Sort()
+
+			out := pcommon.NewResource()
+			MergeResourceWithPolicy(out, tt.res1, tt.policy)
+			MergeResourceWithPolicy(out, tt.res2, tt.policy)
+			out.Attributes().Sort()
+			assert.Equal(t, tt.expected, out)
+
+			out = pcommon.NewResource()
+			MergeResourceWithPolicy(out, tt.res2, tt.policy)
+			MergeResourceWithPolicy(out, tt.res1, tt.policy)
+			out.Attributes().Sort()
+			assert.Equal(t, tt.reversed, out)
+		})
+	}
+}
+
 type MockParallelDetector struct {
 	mock.Mock
 	ch chan struct{}
@@ -218,7 +550,7 @@ func TestDetectResource_Parallel(t *testing.T) {
 	expectedResource := NewResource(map[string]interface{}{"a": "1", "b": "2", "c": "3"})
 	expectedResource.Attributes().Sort()
 
-	p := NewResourceProvider(zap.NewNop(), time.Second, nil, md1, md2, md3)
+	p := NewResourceProvider(zap.NewNop(), time.Second, nil, ProviderSettings{}, md1, md2, md3)
 
 	// call p.Get multiple times
 	wg := &sync.WaitGroup{}
@@ -261,7 +593,7 @@ func TestFilterAttributes_Match(t *testing.T) {
 	attr.PutStr("host.id", "test")
 	attr.PutStr("drop.this", "test")
 
-	droppedAttributes := filterAttributes(attr, m)
+	droppedAttributes := filterAttributes(attr, m, nil)
 
 	_, ok := attr.Get("host.name")
 	assert.True(t, ok)
@@ -283,7 +615,7 @@ func TestFilterAttributes_NoMatch(t *testing.T) {
 	attr.PutStr("host.name", "test")
 	attr.PutStr("host.id", "test")
 
-	droppedAttributes := filterAttributes(attr, m)
+	droppedAttributes := filterAttributes(attr, m, nil)
 
 	_, ok := attr.Get("host.name")
 	assert.False(t, ok)
@@ -300,7 +632,7 @@ func TestFilterAttributes_NilAttributes(t *testing.T) {
 	attr.PutStr("host.name", "test")
 	attr.PutStr("host.id", "test")
 
-	droppedAttributes := filterAttributes(attr, m)
+	droppedAttributes := filterAttributes(attr, m, nil)
 
 	_, ok := attr.Get("host.name")
 	assert.True(t, ok)
@@ -317,7 +649,7 @@ func TestFilterAttributes_NoAttributes(t *testing.T) {
 	attr.PutStr("host.name", "test")
 	attr.PutStr("host.id", "test")
 
-	droppedAttributes := filterAttributes(attr, m)
+	droppedAttributes := filterAttributes(attr, m, nil)
 
 	_, ok := attr.Get("host.name")
 	assert.True(t, ok)
@@ -328,6 +660,54 @@ func TestFilterAttributes_NoAttributes(t *testing.T) {
 	assert.Equal(t, len(droppedAttributes), 0)
 }
 
+func TestFilterAttributes_Values(t *testing.T) {
+	values := map[string]AttributeValueFilter{
+		"host.name": {Exclude: []string{"localhost", "unknown"}},
+		"os.type":   {Include: []string{"linux", "windows"}},
+	}
+	for _, tt := range []struct {
+		name     string
+		attrs    map[string]interface{}
+		expected map[string]interface{}
+		dropped  []string
+	}{
+		{
+			name:     "placeholder values are dropped",
+			attrs:    map[string]interface{}{"host.name": "localhost", "os.type": "plan9", "host.id": "localhost"},
+			expected: map[string]interface{}{"host.id": "localhost"},
+			dropped:  []string{"host.name", "os.type"},
+		},
+		{
+			name:     "real values are kept",
+			attrs:    map[string]interface{}{"host.name": "ip-10-0-0-1.ec2.internal", "os.type": "linux"},
+			expected: map[string]interface{}{"host.name": "ip-10-0-0-1.ec2.internal", "os.type": "linux"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			attr := pcommon.NewMap()
+			attr.FromRaw(tt.attrs)
+
+			droppedAttributes := filterAttributes(attr, nil, values)
+
+			assert.Equal(t, tt.expected, attr.AsRaw())
+			assert.ElementsMatch(t, tt.dropped, droppedAttributes)
+		})
+	}
+}
+
+func TestFilterAttributes_ValuesAndAllowlist(t *testing.T) {
+	m := map[string]struct{}{"host.name": {}}
+	values := map[string]AttributeValueFilter{"host.name": {Exclude: []string{"localhost"}}}
+	attr := pcommon.NewMap()
+	attr.PutStr("host.name", "localhost")
+	attr.PutStr("host.id", "test")
+
+	droppedAttributes := filterAttributes(attr, m, values)
+
+	assert.Equal(t, 0, attr.Len())
+	assert.ElementsMatch(t, []string{"host.name", "host.id"}, droppedAttributes)
+}
+
 func TestAttributesToMap(t *testing.T) {
 	m := map[string]interface{}{
 		"str":    "a",
@@ -0,0 +1,26 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package socket // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/socket"
+
+// Config defines user-specified configurations unique to the socket detector
+type Config struct {
+	// Path is the path of the Unix domain socket the metadata agent listens on.
+	// (**default**: `/var/run/metadata-agent.sock`)
+	Path string `mapstructure:"path"`
+
+	// Request is written to the socket once connected, the agent responds with a JSON object whose
+	// members are set as resource attributes. Nothing is written if empty. (**default**: `GET /metadata\n`)
+	Request *string `mapstructure:"request"`
+}
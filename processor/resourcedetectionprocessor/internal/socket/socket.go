@@ -0,0 +1,140 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package socket provides a detector that loads resource information from a local
+// metadata agent listening on a Unix domain socket.
+package socket // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/socket"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"syscall"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+const (
+	// TypeStr is type of detector.
+	TypeStr = "socket"
+
+	defaultPath    = "/var/run/metadata-agent.sock"
+	defaultRequest = "GET /metadata\n"
+)
+
+var _ internal.Detector = (*Detector)(nil)
+
+// Detector is a detector querying a metadata agent over a Unix domain socket
+type Detector struct {
+	path    string
+	request string
+	// dial connects to the socket, it is replaced in tests
+	dial   func(ctx context.Context, path string) (net.Conn, error)
+	logger *zap.Logger
+}
+
+// NewDetector creates a new Unix socket metadata agent detector
+func NewDetector(p component.ProcessorCreateSettings, dcfg internal.DetectorConfig) (internal.Detector, error) {
+	cfg := dcfg.(Config)
+	if cfg.Path == "" {
+		cfg.Path = defaultPath
+	}
+	request := defaultRequest
+	if cfg.Request != nil {
+		request = *cfg.Request
+	}
+	return &Detector{path: cfg.Path, request: request, dial: dialUnix, logger: p.Logger}, nil
+}
+
+func dialUnix(ctx context.Context, path string) (net.Conn, error) {
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "unix", path)
+}
+
+// Detect queries the metadata agent and returns a resource with the attributes of its response
+func (d *Detector) Detect(ctx context.Context) (resource pcommon.Resource, schemaURL string, err error) {
+	res := pcommon.NewResource()
+
+	metadata, err := d.query(ctx)
+	if isNotListening(err) {
+		d.logger.Debug("Metadata agent socket is not available", zap.String("path", d.path), zap.Error(err))
+		// return an empty Resource and no error
+		return res, "", nil
+	}
+	if err != nil {
+		return res, "", fmt.Errorf("failed to query the metadata agent at %s: %w", d.path, err)
+	}
+
+	attrs := res.Attributes()
+	for key, value := range metadata {
+		switch v := value.(type) {
+		case string:
+			attrs.PutStr(key, v)
+		case bool:
+			attrs.PutBool(key, v)
+		case json.Number:
+			if i, err := v.Int64(); err == nil {
+				attrs.PutInt(key, i)
+			} else if f, err := v.Float64(); err == nil {
+				attrs.PutDouble(key, f)
+			}
+		default:
+			d.logger.Debug("Ignoring metadata that is not a string, number or bool", zap.String("key", key))
+		}
+	}
+
+	return res, conventions.SchemaURL, nil
+}
+
+// query sends the request to the agent and decodes its response, the exchange is bounded by the request timeout, if any
+func (d *Detector) query(ctx context.Context) (map[string]interface{}, error) {
+	reqCtx, cancel := internal.RequestContext(ctx)
+	defer cancel()
+	conn, err := d.dial(reqCtx, d.path)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := reqCtx.Deadline(); ok {
+		if err = conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.request != "" {
+		if _, err = conn.Write([]byte(d.request)); err != nil {
+			return nil, fmt.Errorf("failed to send the request: %w", err)
+		}
+	}
+	decoder := json.NewDecoder(conn)
+	decoder.UseNumber()
+	var metadata map[string]interface{}
+	if err = decoder.Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode the response: %w", err)
+	}
+	return metadata, nil
+}
+
+// isNotListening returns true if the socket does not exist or no agent listens on it
+func isNotListening(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ECONNREFUSED)
+}
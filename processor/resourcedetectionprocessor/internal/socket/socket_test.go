@@ -0,0 +1,185 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package socket
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+func TestNewDetector(t *testing.T) {
+	d, err := NewDetector(componenttest.NewNopProcessorCreateSettings(), Config{})
+	require.NoError(t, err)
+	assert.Equal(t, defaultPath, d.(*Detector).path)
+	assert.Equal(t, defaultRequest, d.(*Detector).request)
+
+	empty := ""
+	d, err = NewDetector(componenttest.NewNopProcessorCreateSettings(), Config{Path: "/run/agent.sock", Request: &empty})
+	require.NoError(t, err)
+	assert.Equal(t, "/run/agent.sock", d.(*Detector).path)
+	assert.Equal(t, "", d.(*Detector).request)
+}
+
+func TestDetect(t *testing.T) {
+	path, requests := startAgent(t, `{"host.id": "i-1234", "deployment.environment": "prod", "host.cpu.count": 8, "ratio": 0.5, "spot": true, "tags": {"team": "a"}}`)
+
+	detector := &Detector{path: path, request: defaultRequest, dial: dialUnix, logger: zap.NewNop()}
+	res, schemaURL, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, conventions.SchemaURL, schemaURL)
+	assert.Equal(t, defaultRequest, <-requests)
+	res.Attributes().Sort()
+
+	expected := internal.NewResource(map[string]interface{}{
+		"host.id":                "i-1234",
+		"deployment.environment": "prod",
+		"host.cpu.count":         int64(8),
+		"ratio":                  0.5,
+		"spot":                   true,
+	})
+	expected.Attributes().Sort()
+
+	assert.Equal(t, expected, res)
+}
+
+func TestDetectCustomProtocol(t *testing.T) {
+	var dialedPath string
+	client, server := net.Pipe()
+	go func() {
+		line, _ := bufio.NewReader(server).ReadString('\n')
+		if line == "metadata\n" {
+			_, _ = server.Write([]byte(`{"host.name": "agent-host"}`))
+		}
+		server.Close()
+	}()
+
+	detector := &Detector{
+		path:    "/custom.sock",
+		request: "metadata\n",
+		dial: func(ctx context.Context, path string) (net.Conn, error) {
+			dialedPath = path
+			return client, nil
+		},
+		logger: zap.NewNop(),
+	}
+	res, _, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "/custom.sock", dialedPath)
+	assert.Equal(t, map[string]interface{}{"host.name": "agent-host"}, res.Attributes().AsRaw())
+}
+
+func TestDetectSocketNotPresent(t *testing.T) {
+	dir := t.TempDir()
+	// a socket file no agent listens on anymore
+	stale := filepath.Join(dir, "stale.sock")
+	listener, err := net.Listen("unix", stale)
+	require.NoError(t, err)
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, listener.Close())
+
+	for name, path := range map[string]string{
+		"missing": filepath.Join(dir, "missing.sock"),
+		"stale":   stale,
+	} {
+		t.Run(name, func(t *testing.T) {
+			detector := &Detector{path: path, request: defaultRequest, dial: dialUnix, logger: zap.NewNop()}
+			res, schemaURL, err := detector.Detect(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, "", schemaURL)
+			assert.Equal(t, 0, res.Attributes().Len())
+		})
+	}
+}
+
+func TestDetectInvalidResponse(t *testing.T) {
+	path, _ := startAgent(t, `not json`)
+
+	detector := &Detector{path: path, request: defaultRequest, dial: dialUnix, logger: zap.NewNop()}
+	_, _, err := detector.Detect(context.Background())
+	assert.ErrorContains(t, err, "failed to decode the response")
+}
+
+func TestDetectDialError(t *testing.T) {
+	detector := &Detector{
+		path: "/agent.sock",
+		dial: func(ctx context.Context, path string) (net.Conn, error) {
+			return nil, os.ErrPermission
+		},
+		logger: zap.NewNop(),
+	}
+	_, _, err := detector.Detect(context.Background())
+	assert.True(t, errors.Is(err, os.ErrPermission))
+}
+
+func TestDetectRequestTimeout(t *testing.T) {
+	// the agent accepts the connection but never responds
+	path, _ := startAgent(t, "")
+
+	detector := &Detector{path: path, request: defaultRequest, dial: dialUnix, logger: zap.NewNop()}
+	ctx := internal.ContextWithRequestTimeout(context.Background(), 10*time.Millisecond)
+	_, _, err := detector.Detect(ctx)
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+}
+
+// startAgent starts a metadata agent on a Unix socket that responds to the first line of every connection
+// with the given response, the lines it receives are sent on the returned channel. An empty response
+// keeps the connection open without responding.
+func startAgent(t *testing.T, response string) (string, <-chan string) {
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	requests := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				line, err := bufio.NewReader(conn).ReadString('\n')
+				if err != nil {
+					return
+				}
+				requests <- line
+				if response == "" {
+					// wait for the detector to give up
+					_, _ = conn.Read(make([]byte, 1))
+					return
+				}
+				_, _ = conn.Write([]byte(response))
+			}()
+		}
+	}()
+	return path, requests
+}
@@ -0,0 +1,107 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tencent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+func TestNewDetector(t *testing.T) {
+	d, err := NewDetector(componenttest.NewNopProcessorCreateSettings(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, defaultEndpoint, d.(*Detector).endpoint)
+}
+
+func TestDetect(t *testing.T) {
+	// testdata holds the responses recorded from the metadata service of an CVM instance
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	t.Cleanup(server.Close)
+
+	detector := &Detector{endpoint: server.URL, logger: zap.NewNop()}
+	res, schemaURL, err := detector.Detect(internal.ContextWithClient(context.Background(), &http.Client{}))
+	require.NoError(t, err)
+	assert.Equal(t, conventions.SchemaURL, schemaURL)
+	res.Attributes().Sort()
+
+	expected := internal.NewResource(map[string]interface{}{
+		conventions.AttributeCloudProvider:         "tencent_cloud",
+		conventions.AttributeCloudRegion:           "ap-guangzhou",
+		conventions.AttributeCloudAvailabilityZone: "ap-guangzhou-3",
+		conventions.AttributeHostID:                "ins-6ojmsp2x",
+	})
+	expected.Attributes().Sort()
+
+	assert.Equal(t, expected, res)
+}
+
+func TestDetectNotOnTencentCloud(t *testing.T) {
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(notFound.Close)
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	for name, endpoint := range map[string]string{
+		"not found":   notFound.URL,
+		"unreachable": unreachable.URL,
+	} {
+		t.Run(name, func(t *testing.T) {
+			detector := &Detector{endpoint: endpoint, logger: zap.NewNop()}
+			res, schemaURL, err := detector.Detect(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, "", schemaURL)
+			assert.Equal(t, 0, res.Attributes().Len())
+		})
+	}
+}
+
+func TestDetectPartialMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != instanceIDPath {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte("ins-6ojmsp2x"))
+	}))
+	t.Cleanup(server.Close)
+
+	detector := &Detector{endpoint: server.URL, logger: zap.NewNop()}
+	_, _, err := detector.Detect(context.Background())
+	assert.ErrorContains(t, err, "failed getting region")
+}
+
+func TestDetectRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	detector := &Detector{endpoint: server.URL, logger: zap.NewNop()}
+	ctx := internal.ContextWithRequestTimeout(context.Background(), 10*time.Millisecond)
+	res, _, err := detector.Detect(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.Attributes().Len())
+}
@@ -0,0 +1,23 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vsphere // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/vsphere"
+
+// Config defines user-specified configurations unique to the vsphere detector
+type Config struct {
+	// GuestInfoFile is the path of a file holding the guestinfo variables of the VM as `key = "value"` lines, as
+	// they are written in the advanced settings of the VM, such as a file mounted into a container. The variables
+	// are queried from VMware Tools with `vmtoolsd` if not set.
+	GuestInfoFile string `mapstructure:"guestinfo_file"`
+}
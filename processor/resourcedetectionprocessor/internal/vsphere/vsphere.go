@@ -0,0 +1,184 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vsphere provides a detector that loads the metadata of a VMware vSphere VM from
+// the guestinfo variables of the VM.
+package vsphere // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal/vsphere"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+const (
+	// TypeStr is type of detector.
+	TypeStr = "vsphere"
+
+	// vmtoolsdCommand is the daemon of VMware Tools, which reads the guestinfo variables from the hypervisor
+	vmtoolsdCommand = "vmtoolsd"
+	// noValueOutput is printed by vmtoolsd for a guestinfo variable that is not set
+	noValueOutput = "No value found"
+
+	attributeVSphereVMName         = "vsphere.vm.name"
+	attributeVSphereDatacenterName = "vsphere.datacenter.name"
+	attributeVSphereClusterName    = "vsphere.cluster.name"
+	attributeVSphereHostName       = "vsphere.host.name"
+)
+
+// guestInfoAttributes maps the guestinfo variables read by the detector to the resource attributes they are set as
+var guestInfoAttributes = []struct {
+	key       string
+	attribute string
+}{
+	{key: "guestinfo.vm.uuid", attribute: conventions.AttributeHostID},
+	{key: "guestinfo.vm.name", attribute: attributeVSphereVMName},
+	{key: "guestinfo.vm.datacenter", attribute: attributeVSphereDatacenterName},
+	{key: "guestinfo.vm.cluster", attribute: attributeVSphereClusterName},
+	{key: "guestinfo.vm.host", attribute: attributeVSphereHostName},
+}
+
+// errNoGuestInfo is returned by a guestInfoSource that cannot read guestinfo, as when not running on vSphere
+var errNoGuestInfo = errors.New("guestinfo is not available")
+
+// guestInfoSource reads the guestinfo variables of the VM
+type guestInfoSource interface {
+	// get returns the value of the guestinfo variable, or an empty string if the variable is not set
+	get(ctx context.Context, key string) (string, error)
+}
+
+var _ internal.Detector = (*Detector)(nil)
+
+// Detector is a vSphere guestinfo detector
+type Detector struct {
+	source guestInfoSource
+	logger *zap.Logger
+}
+
+// NewDetector creates a new vSphere guestinfo detector
+func NewDetector(p component.ProcessorCreateSettings, dcfg internal.DetectorConfig) (internal.Detector, error) {
+	cfg := dcfg.(Config)
+	var source guestInfoSource = &vmtoolsdSource{run: runCommand}
+	if cfg.GuestInfoFile != "" {
+		source = &fileSource{path: cfg.GuestInfoFile}
+	}
+	return &Detector{source: source, logger: p.Logger}, nil
+}
+
+// Detect detects the metadata of the vSphere VM and returns a resource with the available ones
+func (d *Detector) Detect(ctx context.Context) (resource pcommon.Resource, schemaURL string, err error) {
+	res := pcommon.NewResource()
+
+	for _, ga := range guestInfoAttributes {
+		value, err := d.source.get(ctx, ga.key)
+		if errors.Is(err, errNoGuestInfo) {
+			// guestinfo cannot be read when not running on vSphere or without VMware Tools
+			d.logger.Debug("vSphere detector guestinfo retrieval failed", zap.String("key", ga.key), zap.Error(err))
+			return pcommon.NewResource(), "", nil
+		}
+		if err != nil {
+			return pcommon.NewResource(), "", err
+		}
+		if value != "" {
+			res.Attributes().PutStr(ga.attribute, value)
+		}
+	}
+
+	if res.Attributes().Len() == 0 {
+		return res, "", nil
+	}
+	return res, conventions.SchemaURL, nil
+}
+
+// vmtoolsdSource queries the guestinfo variables from VMware Tools
+type vmtoolsdSource struct {
+	run func(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).CombinedOutput()
+}
+
+func (s *vmtoolsdSource) get(ctx context.Context, key string) (string, error) {
+	output, err := s.run(ctx, vmtoolsdCommand, "--cmd", "info-get "+key)
+	if errors.Is(err, exec.ErrNotFound) {
+		return "", fmt.Errorf("%w: %s is not installed", errNoGuestInfo, vmtoolsdCommand)
+	}
+	if err != nil {
+		if strings.Contains(string(output), noValueOutput) {
+			return "", nil
+		}
+		// vmtoolsd fails outside of a VMware VM
+		return "", fmt.Errorf("%w: %s failed: %v: %s", errNoGuestInfo, vmtoolsdCommand, err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// fileSource reads the guestinfo variables from a file formatted as the advanced settings of a VM
+type fileSource struct {
+	path string
+	// values are the variables of the file, which is read once
+	values map[string]string
+}
+
+func (s *fileSource) get(_ context.Context, key string) (string, error) {
+	if s.values == nil {
+		values, err := readGuestInfoFile(s.path)
+		if err != nil {
+			return "", err
+		}
+		s.values = values
+	}
+	return s.values[key], nil
+}
+
+// readGuestInfoFile parses the `key = "value"` lines of the file, blank lines and lines starting with # are skipped
+func readGuestInfoFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s does not exist", errNoGuestInfo, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	values := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("failed to parse %s: line %q is not a key = value pair", path, line)
+		}
+		value = strings.TrimSpace(value)
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+	return values, nil
+}
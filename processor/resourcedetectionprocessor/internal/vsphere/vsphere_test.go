@@ -0,0 +1,150 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor/internal"
+)
+
+// mockSource is a guestinfo source holding the variables of the VM, or failing with err
+type mockSource struct {
+	values map[string]string
+	err    error
+}
+
+func (s *mockSource) get(_ context.Context, key string) (string, error) {
+	return s.values[key], s.err
+}
+
+func TestNewDetector(t *testing.T) {
+	d, err := NewDetector(componenttest.NewNopProcessorCreateSettings(), Config{})
+	require.NoError(t, err)
+	assert.IsType(t, &vmtoolsdSource{}, d.(*Detector).source)
+
+	d, err = NewDetector(componenttest.NewNopProcessorCreateSettings(), Config{GuestInfoFile: "testdata/guestinfo"})
+	require.NoError(t, err)
+	assert.Equal(t, &fileSource{path: "testdata/guestinfo"}, d.(*Detector).source)
+}
+
+func TestDetect(t *testing.T) {
+	detector := &Detector{source: &mockSource{values: map[string]string{
+		"guestinfo.vm.uuid":       "4211b6c5-7e2a-9d3f-1c4b-8a6e0f2d5b17",
+		"guestinfo.vm.name":       "checkout-01",
+		"guestinfo.vm.datacenter": "dc-east",
+		"guestinfo.vm.cluster":    "cluster-a",
+		"guestinfo.vm.host":       "esxi-07.example.com",
+		"guestinfo.other":         "ignored",
+	}}, logger: zap.NewNop()}
+
+	res, schemaURL, err := detector.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, conventions.SchemaURL, schemaURL)
+	assert.Equal(t, map[string]interface{}{
+		conventions.AttributeHostID:    "4211b6c5-7e2a-9d3f-1c4b-8a6e0f2d5b17",
+		attributeVSphereVMName:         "checkout-01",
+		attributeVSphereDatacenterName: "dc-east",
+		attributeVSphereClusterName:    "cluster-a",
+		attributeVSphereHostName:       "esxi-07.example.com",
+	}, res.Attributes().AsRaw())
+}
+
+func TestDetectNoGuestInfo(t *testing.T) {
+	for name, source := range map[string]guestInfoSource{
+		"not on vSphere": &mockSource{err: errNoGuestInfo},
+		"no variables":   &mockSource{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			detector := &Detector{source: source, logger: zap.NewNop()}
+
+			res, schemaURL, err := detector.Detect(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, "", schemaURL)
+			assert.True(t, internal.IsEmptyResource(res))
+		})
+	}
+}
+
+func TestDetectSourceError(t *testing.T) {
+	detector := &Detector{source: &mockSource{err: errors.New("permission denied")}, logger: zap.NewNop()}
+
+	res, _, err := detector.Detect(context.Background())
+	assert.Error(t, err)
+	assert.True(t, internal.IsEmptyResource(res))
+}
+
+func TestVmtoolsdSource(t *testing.T) {
+	var args []string
+	source := &vmtoolsdSource{run: func(_ context.Context, name string, a ...string) ([]byte, error) {
+		args = append([]string{name}, a...)
+		switch a[1] {
+		case "info-get guestinfo.vm.name":
+			return []byte("checkout-01\n"), nil
+		case "info-get guestinfo.vm.host":
+			return []byte("No value found\n"), errors.New("exit status 1")
+		default:
+			return []byte("vmtoolsd must be run inside a virtual machine\n"), errors.New("exit status 1")
+		}
+	}}
+
+	value, err := source.get(context.Background(), "guestinfo.vm.name")
+	require.NoError(t, err)
+	assert.Equal(t, "checkout-01", value)
+	assert.Equal(t, []string{"vmtoolsd", "--cmd", "info-get guestinfo.vm.name"}, args)
+
+	value, err = source.get(context.Background(), "guestinfo.vm.host")
+	require.NoError(t, err)
+	assert.Equal(t, "", value)
+
+	_, err = source.get(context.Background(), "guestinfo.vm.uuid")
+	assert.ErrorIs(t, err, errNoGuestInfo)
+
+	source.run = func(context.Context, string, ...string) ([]byte, error) {
+		return nil, &exec.Error{Name: vmtoolsdCommand, Err: exec.ErrNotFound}
+	}
+	_, err = source.get(context.Background(), "guestinfo.vm.name")
+	assert.ErrorIs(t, err, errNoGuestInfo)
+}
+
+func TestFileSource(t *testing.T) {
+	d, err := NewDetector(componenttest.NewNopProcessorCreateSettings(), Config{GuestInfoFile: "testdata/guestinfo"})
+	require.NoError(t, err)
+
+	res, schemaURL, err := d.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, conventions.SchemaURL, schemaURL)
+	assert.Equal(t, map[string]interface{}{
+		conventions.AttributeHostID:    "4211b6c5-7e2a-9d3f-1c4b-8a6e0f2d5b17",
+		attributeVSphereVMName:         "checkout-01",
+		attributeVSphereDatacenterName: "dc-east",
+		attributeVSphereClusterName:    "cluster-a",
+	}, res.Attributes().AsRaw())
+
+	_, err = (&fileSource{path: "testdata/missing"}).get(context.Background(), "guestinfo.vm.name")
+	assert.ErrorIs(t, err, errNoGuestInfo)
+
+	_, err = (&fileSource{path: "testdata/malformed"}).get(context.Background(), "guestinfo.vm.name")
+	assert.ErrorContains(t, err, "is not a key = value pair")
+}
@@ -31,7 +31,9 @@ type resourceDetectionProcessor struct {
 	provider           *internal.ResourceProvider
 	resource           pcommon.Resource
 	schemaURL          string
+	schemaURLPolicy    internal.SchemaURLPolicy
 	override           bool
+	preferNonEmpty     bool
 	httpClientSettings confighttp.HTTPClientSettings
 	telemetrySettings  component.TelemetrySettings
 }
@@ -40,19 +42,27 @@ type resourceDetectionProcessor struct {
 func (rdp *resourceDetectionProcessor) Start(ctx context.Context, host component.Host) error {
 	client, _ := rdp.httpClientSettings.ToClient(host, rdp.telemetrySettings)
 	ctx = internal.ContextWithClient(ctx, client)
+	if err := rdp.provider.Start(ctx, host); err != nil {
+		return err
+	}
 	var err error
 	rdp.resource, rdp.schemaURL, err = rdp.provider.Get(ctx, client)
 	return err
 }
 
+// Shutdown is invoked during service shutdown.
+func (rdp *resourceDetectionProcessor) Shutdown(ctx context.Context) error {
+	return rdp.provider.Shutdown(ctx)
+}
+
 // processTraces implements the ProcessTracesFunc type.
 func (rdp *resourceDetectionProcessor) processTraces(_ context.Context, td ptrace.Traces) (ptrace.Traces, error) {
 	rs := td.ResourceSpans()
 	for i := 0; i < rs.Len(); i++ {
 		rss := rs.At(i)
-		rss.SetSchemaUrl(internal.MergeSchemaURL(rss.SchemaUrl(), rdp.schemaURL))
+		rss.SetSchemaUrl(internal.ApplySchemaURL(rdp.schemaURLPolicy, rss.SchemaUrl(), rdp.schemaURL))
 		res := rss.Resource()
-		internal.MergeResource(res, rdp.resource, rdp.override)
+		internal.MergeResource(res, rdp.resource, rdp.override, rdp.preferNonEmpty)
 	}
 	return td, nil
 }
@@ -62,9 +72,9 @@ func (rdp *resourceDetectionProcessor) processMetrics(_ context.Context, md pmet
 	rm := md.ResourceMetrics()
 	for i := 0; i < rm.Len(); i++ {
 		rss := rm.At(i)
-		rss.SetSchemaUrl(internal.MergeSchemaURL(rss.SchemaUrl(), rdp.schemaURL))
+		rss.SetSchemaUrl(internal.ApplySchemaURL(rdp.schemaURLPolicy, rss.SchemaUrl(), rdp.schemaURL))
 		res := rss.Resource()
-		internal.MergeResource(res, rdp.resource, rdp.override)
+		internal.MergeResource(res, rdp.resource, rdp.override, rdp.preferNonEmpty)
 	}
 	return md, nil
 }
@@ -74,9 +84,9 @@ func (rdp *resourceDetectionProcessor) processLogs(_ context.Context, ld plog.Lo
 	rl := ld.ResourceLogs()
 	for i := 0; i < rl.Len(); i++ {
 		rss := rl.At(i)
-		rss.SetSchemaUrl(internal.MergeSchemaURL(rss.SchemaUrl(), rdp.schemaURL))
+		rss.SetSchemaUrl(internal.ApplySchemaURL(rdp.schemaURLPolicy, rss.SchemaUrl(), rdp.schemaURL))
 		res := rss.Resource()
-		internal.MergeResource(res, rdp.resource, rdp.override)
+		internal.MergeResource(res, rdp.resource, rdp.override, rdp.preferNonEmpty)
 	}
 	return ld, nil
 }
@@ -278,6 +278,87 @@ func TestResourceProcessor(t *testing.T) {
 	}
 }
 
+func TestSchemaURLPolicy(t *testing.T) {
+	const (
+		incoming = "https://opentelemetry.io/schemas/1.5.0"
+		detected = "https://opentelemetry.io/schemas/1.6.1"
+	)
+	tests := []struct {
+		name              string
+		policy            internal.SchemaURLPolicy
+		incomingSchemaURL string
+		detectedSchemaURL string
+		expectedSchemaURL string
+	}{
+		{
+			name:              "keep incoming",
+			policy:            internal.SchemaURLPolicyKeepIncoming,
+			incomingSchemaURL: incoming,
+			detectedSchemaURL: detected,
+			expectedSchemaURL: incoming,
+		},
+		{
+			name:              "keep incoming without incoming schema URL",
+			policy:            internal.SchemaURLPolicyKeepIncoming,
+			detectedSchemaURL: detected,
+			expectedSchemaURL: "",
+		},
+		{
+			name:              "keep detected",
+			policy:            internal.SchemaURLPolicyKeepDetected,
+			incomingSchemaURL: incoming,
+			detectedSchemaURL: detected,
+			expectedSchemaURL: detected,
+		},
+		{
+			name:              "keep detected without detected schema URL",
+			policy:            internal.SchemaURLPolicyKeepDetected,
+			incomingSchemaURL: incoming,
+			expectedSchemaURL: incoming,
+		},
+		{
+			name:              "merge",
+			policy:            internal.SchemaURLPolicyMerge,
+			incomingSchemaURL: incoming,
+			detectedSchemaURL: detected,
+			expectedSchemaURL: incoming,
+		},
+		{
+			name:              "merge without incoming schema URL",
+			policy:            internal.SchemaURLPolicyMerge,
+			detectedSchemaURL: detected,
+			expectedSchemaURL: detected,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rdp := &resourceDetectionProcessor{
+				resource:        pcommon.NewResource(),
+				schemaURL:       tt.detectedSchemaURL,
+				schemaURLPolicy: tt.policy,
+			}
+
+			td := ptrace.NewTraces()
+			td.ResourceSpans().AppendEmpty().SetSchemaUrl(tt.incomingSchemaURL)
+			td, err := rdp.processTraces(context.Background(), td)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedSchemaURL, td.ResourceSpans().At(0).SchemaUrl())
+
+			md := pmetric.NewMetrics()
+			md.ResourceMetrics().AppendEmpty().SetSchemaUrl(tt.incomingSchemaURL)
+			md, err = rdp.processMetrics(context.Background(), md)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedSchemaURL, md.ResourceMetrics().At(0).SchemaUrl())
+
+			ld := plog.NewLogs()
+			ld.ResourceLogs().AppendEmpty().SetSchemaUrl(tt.incomingSchemaURL)
+			ld, err = rdp.processLogs(context.Background(), ld)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedSchemaURL, ld.ResourceLogs().At(0).SchemaUrl())
+		})
+	}
+}
+
 func oCensusResource(res pcommon.Resource) *resourcepb.Resource {
 	if res.Attributes().Len() == 0 {
 		return &resourcepb.Resource{}
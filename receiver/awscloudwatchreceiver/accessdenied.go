@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+// accessDeniedCode is the error code of the requests the IAM role is not allowed to make,
+// it is also returned when the role may not decrypt a log group encrypted with a KMS key
+const accessDeniedCode = "AccessDeniedException"
+
+var (
+	accessDenied = stats.Int64(
+		"awscloudwatchreceiver/cloudwatch_access_denied",
+		"Number of polls of a log group denied by AWS, such as for lacking decrypt permissions on the KMS key of the log group",
+		stats.UnitDimensionless)
+
+	accessDeniedView = &view.View{
+		Name:        "receiver/" + typeStr + "/cloudwatch_access_denied",
+		Description: accessDenied.Description(),
+		Measure:     accessDenied,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{receiverNameKey, logGroupKey},
+	}
+)
+
+// accessDeniedError is returned by the poll of a log group that AWS denied access to. Retrying does not help
+// until the permissions of the IAM role are fixed, so it is reported apart from the other poll errors.
+type accessDeniedError struct {
+	group string
+	// kms is true if access to the KMS key encrypting the log group was denied
+	kms bool
+	err error
+}
+
+func (e *accessDeniedError) Error() string {
+	if e.kms {
+		return fmt.Sprintf("access denied to the KMS key of log group %q, the IAM role requires kms:Decrypt on the key: %v", e.group, e.err)
+	}
+	return fmt.Sprintf("access denied to log group %q, the IAM role requires logs:FilterLogEvents on the log group: %v", e.group, e.err)
+}
+
+func (e *accessDeniedError) Unwrap() error {
+	return e.err
+}
+
+// asAccessDenied returns an accessDeniedError if err denies access to the log group, nil otherwise
+func asAccessDenied(group string, err error) *accessDeniedError {
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) || awsErr.Code() != accessDeniedCode {
+		return nil
+	}
+	return &accessDeniedError{
+		group: group,
+		kms:   strings.Contains(strings.ToLower(awsErr.Message()), "kms"),
+		err:   err,
+	}
+}
+
+// recordAccessDenied increments the number of polls of the log group that were denied
+func (l *logsReceiver) recordAccessDenied(group string) {
+	_ = stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{tag.Upsert(receiverNameKey, l.id.String()), tag.Upsert(logGroupKey, group)},
+		accessDenied.M(1),
+	)
+}
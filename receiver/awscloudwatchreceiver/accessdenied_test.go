@@ -0,0 +1,131 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+func TestPollAccessDenied(t *testing.T) {
+	cases := []struct {
+		name      string
+		err       error
+		expectKMS bool
+	}{
+		{
+			name: "kms",
+			err: awserr.New(accessDeniedCode, "User: arn:aws:sts::123456789012:assumed-role/collector is not authorized to perform: kms:Decrypt "+
+				"on resource: arn:aws:kms:us-west-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab", nil),
+			expectKMS: true,
+		},
+		{
+			name: "iam",
+			err: awserr.New(accessDeniedCode, "User: arn:aws:sts::123456789012:assumed-role/collector is not authorized to perform: logs:FilterLogEvents "+
+				"on resource: arn:aws:logs:us-west-1:123456789012:log-group:encrypted", nil),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.ReceiverSettings.SetIDName(t.Name())
+			cfg.Region = "us-west-1"
+			cfg.Logs.Groups = GroupConfig{
+				NamedConfigs: map[string]StreamConfig{
+					"encrypted": {},
+					"healthy":   {},
+				},
+			}
+			sink := &consumertest.LogsSink{}
+			logsRcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+			mc := &mockClient{}
+			mc.On("FilterLogEventsWithContext", mock.Anything, groupInput("encrypted"), mock.Anything).Return(
+				(*cloudwatchlogs.FilterLogEventsOutput)(nil), tc.err)
+			mc.On("FilterLogEventsWithContext", mock.Anything, groupInput("healthy"), mock.Anything).Return(
+				&cloudwatchlogs.FilterLogEventsOutput{
+					Events: []*cloudwatchlogs.FilteredLogEvent{
+						{
+							EventId:       aws.String(testEventID),
+							LogStreamName: aws.String(testLogStreamName),
+							Message:       aws.String(testLogStreamMessage),
+							Timestamp:     aws.Int64(testTimeStamp),
+						},
+					},
+				}, nil)
+			logsRcvr.client = mc
+
+			err := logsRcvr.poll(context.Background())
+			var denied *accessDeniedError
+			require.ErrorAs(t, err, &denied)
+			require.Equal(t, "encrypted", denied.group)
+			require.Equal(t, tc.expectKMS, denied.kms)
+			require.ErrorIs(t, err, tc.err)
+			// the other groups are still polled
+			require.Equal(t, 1, sink.LogRecordCount())
+			require.Equal(t, int64(1), accessDeniedCount(t, cfg.ID().String(), "encrypted"))
+
+			require.Error(t, logsRcvr.poll(context.Background()))
+			require.Equal(t, int64(2), accessDeniedCount(t, cfg.ID().String(), "encrypted"))
+		})
+	}
+}
+
+func TestPollOtherErrorsAreNotAccessDenied(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ReceiverSettings.SetIDName(t.Name())
+	cfg.Region = "us-west-1"
+	cfg.Logs.Groups = GroupConfig{
+		NamedConfigs: map[string]StreamConfig{
+			"throttled": {},
+		},
+	}
+	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), &consumertest.LogsSink{})
+	mc := &mockClient{}
+	mc.On("FilterLogEventsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		(*cloudwatchlogs.FilterLogEventsOutput)(nil), awserr.New(cloudwatchlogs.ErrCodeServiceUnavailableException, "try again", nil))
+	logsRcvr.client = mc
+
+	err := logsRcvr.poll(context.Background())
+	require.Error(t, err)
+	var denied *accessDeniedError
+	require.False(t, errors.As(err, &denied))
+	require.Equal(t, int64(0), accessDeniedCount(t, cfg.ID().String(), "throttled"))
+}
+
+// accessDeniedCount returns the number of denied polls of the log group, 0 if none was recorded
+func accessDeniedCount(t *testing.T, receiverName string, group string) int64 {
+	rows, err := view.RetrieveData(accessDeniedView.Name)
+	require.NoError(t, err)
+	for _, row := range rows {
+		tags := map[string]string{}
+		for _, tag := range row.Tags {
+			tags[tag.Key.Name()] = tag.Value
+		}
+		if tags[receiverNameKey.Name()] == receiverName && tags[logGroupKey.Name()] == group {
+			return row.Data.(*view.CountData).Value
+		}
+	}
+	return 0
+}
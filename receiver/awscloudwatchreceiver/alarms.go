@@ -0,0 +1,162 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+const (
+	alarmNameAttribute     = "cloudwatch.alarm.name"
+	alarmTypeAttribute     = "cloudwatch.alarm.type"
+	alarmOldStateAttribute = "cloudwatch.alarm.old_state"
+	alarmNewStateAttribute = "cloudwatch.alarm.new_state"
+	alarmReasonAttribute   = "cloudwatch.alarm.reason"
+)
+
+type alarmsClient interface {
+	DescribeAlarmHistoryWithContext(ctx context.Context, input *cloudwatch.DescribeAlarmHistoryInput, opts ...request.Option) (*cloudwatch.DescribeAlarmHistoryOutput, error)
+}
+
+// alarmHistoryData is the part of the history data of a state update that is emitted
+type alarmHistoryData struct {
+	OldState alarmState `json:"oldState"`
+	NewState alarmState `json:"newState"`
+}
+
+type alarmState struct {
+	StateValue  string `json:"stateValue"`
+	StateReason string `json:"stateReason"`
+}
+
+// pollAlarms emits every state change of the configured alarms, or of all metric alarms if none is configured,
+// since the last successful poll as a log record. The alarms that failed are polled over the same time range
+// again by the next poll.
+func (l *logsReceiver) pollAlarms(ctx context.Context) error {
+	err := l.ensureAlarmsSession()
+	if err != nil {
+		return err
+	}
+
+	// the timestamps of the history items are in milliseconds and both ends of the time range are
+	// included, so the range of the next poll starts in the millisecond after the end of this one
+	startTime, endTime := l.nextStartTime, l.now().Truncate(time.Millisecond)
+	if startTime.After(endTime) {
+		return nil
+	}
+	names := []string{""}
+	if l.alarms != nil && len(l.alarms.Names) > 0 {
+		names = l.alarms.Names
+	}
+
+	var errs error
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	l.putCloudAttributes(rl.Resource().Attributes())
+	records := rl.ScopeLogs().AppendEmpty().LogRecords()
+	for _, name := range names {
+		if err = l.pollAlarmHistory(ctx, name, startTime, endTime, records); err != nil {
+			errs = multierr.Append(errs, err)
+		}
+	}
+	if errs != nil {
+		return errs
+	}
+
+	if records.Len() > 0 {
+		if err = l.consumer.ConsumeLogs(ctx, logs); err != nil {
+			return fmt.Errorf("unable to consume the alarm state changes: %w", err)
+		}
+	}
+	l.nextStartTime = endTime.Add(time.Millisecond)
+	return nil
+}
+
+// pollAlarmHistory appends the state changes of the alarm with the given name, or of all alarms if the name
+// is empty, within the time range to the log records.
+func (l *logsReceiver) pollAlarmHistory(ctx context.Context, name string, startTime, endTime time.Time, records plog.LogRecordSlice) error {
+	input := &cloudwatch.DescribeAlarmHistoryInput{
+		AlarmTypes:      aws.StringSlice([]string{cloudwatch.AlarmTypeMetricAlarm}),
+		HistoryItemType: aws.String(cloudwatch.HistoryItemTypeStateUpdate),
+		StartDate:       aws.Time(startTime),
+		EndDate:         aws.Time(endTime),
+		ScanBy:          aws.String(cloudwatch.ScanByTimestampAscending),
+	}
+	if name != "" {
+		input.AlarmName = aws.String(name)
+	}
+
+	observedTime := pcommon.NewTimestampFromTime(l.now())
+	for {
+		resp, err := l.alarmsClient.DescribeAlarmHistoryWithContext(ctx, input)
+		if err != nil {
+			if name == "" {
+				return fmt.Errorf("unable to describe the alarm history: %w", err)
+			}
+			return fmt.Errorf("unable to describe the history of alarm %s: %w", name, err)
+		}
+		for _, item := range resp.AlarmHistoryItems {
+			l.appendAlarmRecord(observedTime, item, records)
+		}
+		if resp.NextToken == nil {
+			return nil
+		}
+		input.NextToken = resp.NextToken
+	}
+}
+
+func (l *logsReceiver) appendAlarmRecord(observedTime pcommon.Timestamp, item *cloudwatch.AlarmHistoryItem, records plog.LogRecordSlice) {
+	logRecord := records.AppendEmpty()
+	logRecord.SetObservedTimestamp(observedTime)
+	if item.Timestamp != nil {
+		logRecord.SetTimestamp(pcommon.NewTimestampFromTime(*item.Timestamp))
+	}
+	logRecord.Body().SetStr(aws.StringValue(item.HistorySummary))
+
+	attrs := logRecord.Attributes()
+	attrs.PutStr(alarmNameAttribute, aws.StringValue(item.AlarmName))
+	attrs.PutStr(alarmTypeAttribute, aws.StringValue(item.AlarmType))
+	var data alarmHistoryData
+	if err := json.Unmarshal([]byte(aws.StringValue(item.HistoryData)), &data); err != nil {
+		l.logger.Debug("unable to parse the history data of an alarm state change", zap.String("alarm", aws.StringValue(item.AlarmName)), zap.Error(err))
+		return
+	}
+	attrs.PutStr(alarmOldStateAttribute, data.OldState.StateValue)
+	attrs.PutStr(alarmNewStateAttribute, data.NewState.StateValue)
+	attrs.PutStr(alarmReasonAttribute, data.NewState.StateReason)
+}
+
+func (l *logsReceiver) ensureAlarmsSession() error {
+	if l.alarmsClient != nil {
+		return nil
+	}
+	s, err := l.newSession()
+	if err != nil {
+		return err
+	}
+	l.alarmsClient = cloudwatch.New(s)
+	return nil
+}
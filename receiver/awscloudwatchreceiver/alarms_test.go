@@ -0,0 +1,217 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+)
+
+const (
+	testAlarmName        = "checkout-latency-high"
+	testAlarmSummary     = "Alarm updated from OK to ALARM"
+	testAlarmHistoryData = `{"version":"1.0","oldState":{"stateValue":"OK","stateReason":"Threshold Crossed: 1 datapoint [0.2 (07/10/22 18:05:00)] was not greater than the threshold (0.5)."},` +
+		`"newState":{"stateValue":"ALARM","stateReason":"Threshold Crossed: 1 datapoint [0.9 (07/10/22 18:10:00)] was greater than the threshold (0.5)."}}`
+)
+
+func TestAlarmsStateChanges(t *testing.T) {
+	logsRcvr, sink := newAlarmsTestReceiver()
+	mc := &mockAlarmsClient{}
+	mc.On("DescribeAlarmHistoryWithContext", mock.Anything, mock.MatchedBy(func(input *cloudwatch.DescribeAlarmHistoryInput) bool {
+		return input.AlarmName == nil &&
+			aws.StringValue(input.HistoryItemType) == cloudwatch.HistoryItemTypeStateUpdate &&
+			!aws.TimeValue(input.StartDate).After(aws.TimeValue(input.EndDate))
+	}), mock.Anything).Return(&cloudwatch.DescribeAlarmHistoryOutput{
+		AlarmHistoryItems: []*cloudwatch.AlarmHistoryItem{testAlarmHistoryItem(testAlarmName, testAlarmHistoryData)},
+	}, nil)
+	logsRcvr.alarmsClient = mc
+
+	startTime := logsRcvr.nextStartTime
+	require.NoError(t, logsRcvr.pollAlarms(context.Background()))
+	require.True(t, logsRcvr.nextStartTime.After(startTime))
+
+	require.Len(t, sink.AllLogs(), 1)
+	require.Equal(t, 1, sink.LogRecordCount())
+	rl := sink.AllLogs()[0].ResourceLogs().At(0)
+	region, ok := rl.Resource().Attributes().Get("aws.region")
+	require.True(t, ok)
+	require.Equal(t, "us-west-1", region.Str())
+
+	record := rl.ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, pcommon.NewTimestampFromTime(time.UnixMilli(testTimeStamp)), record.Timestamp())
+	require.Equal(t, testAlarmSummary, record.Body().Str())
+	require.Equal(t, map[string]interface{}{
+		"cloudwatch.alarm.name":      testAlarmName,
+		"cloudwatch.alarm.type":      cloudwatch.AlarmTypeMetricAlarm,
+		"cloudwatch.alarm.old_state": "OK",
+		"cloudwatch.alarm.new_state": "ALARM",
+		"cloudwatch.alarm.reason":    "Threshold Crossed: 1 datapoint [0.9 (07/10/22 18:10:00)] was greater than the threshold (0.5).",
+	}, record.Attributes().AsRaw())
+}
+
+func TestAlarmsNamedAlarmsAndPages(t *testing.T) {
+	logsRcvr, sink := newAlarmsTestReceiver()
+	logsRcvr.alarms = &AlarmsConfig{Names: []string{testAlarmName, "checkout-errors"}}
+	mc := &mockAlarmsClient{}
+	mc.On("DescribeAlarmHistoryWithContext", mock.Anything, mock.MatchedBy(func(input *cloudwatch.DescribeAlarmHistoryInput) bool {
+		return aws.StringValue(input.AlarmName) == testAlarmName && input.NextToken == nil
+	}), mock.Anything).Return(&cloudwatch.DescribeAlarmHistoryOutput{
+		AlarmHistoryItems: []*cloudwatch.AlarmHistoryItem{testAlarmHistoryItem(testAlarmName, testAlarmHistoryData)},
+		NextToken:         aws.String("next"),
+	}, nil).Once()
+	mc.On("DescribeAlarmHistoryWithContext", mock.Anything, mock.MatchedBy(func(input *cloudwatch.DescribeAlarmHistoryInput) bool {
+		return aws.StringValue(input.AlarmName) == testAlarmName && aws.StringValue(input.NextToken) == "next"
+	}), mock.Anything).Return(&cloudwatch.DescribeAlarmHistoryOutput{
+		AlarmHistoryItems: []*cloudwatch.AlarmHistoryItem{testAlarmHistoryItem(testAlarmName, testAlarmHistoryData)},
+	}, nil).Once()
+	mc.On("DescribeAlarmHistoryWithContext", mock.Anything, mock.MatchedBy(func(input *cloudwatch.DescribeAlarmHistoryInput) bool {
+		return aws.StringValue(input.AlarmName) == "checkout-errors"
+	}), mock.Anything).Return(&cloudwatch.DescribeAlarmHistoryOutput{
+		AlarmHistoryItems: []*cloudwatch.AlarmHistoryItem{testAlarmHistoryItem("checkout-errors", testAlarmHistoryData)},
+	}, nil).Once()
+	logsRcvr.alarmsClient = mc
+
+	require.NoError(t, logsRcvr.pollAlarms(context.Background()))
+	mc.AssertNumberOfCalls(t, "DescribeAlarmHistoryWithContext", 3)
+	require.Equal(t, 3, sink.LogRecordCount())
+	records := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	name, _ := records.At(2).Attributes().Get("cloudwatch.alarm.name")
+	require.Equal(t, "checkout-errors", name.Str())
+}
+
+func TestAlarmsTimeRanges(t *testing.T) {
+	logsRcvr, _ := newAlarmsTestReceiver()
+	now := time.UnixMilli(testTimeStamp).Add(time.Minute)
+	logsRcvr.now = func() time.Time { return now }
+	logsRcvr.nextStartTime = now.Add(-time.Minute)
+	mc := &mockAlarmsClient{}
+	var input *cloudwatch.DescribeAlarmHistoryInput
+	mc.On("DescribeAlarmHistoryWithContext", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		input = args.Get(1).(*cloudwatch.DescribeAlarmHistoryInput)
+	}).Return(&cloudwatch.DescribeAlarmHistoryOutput{}, nil)
+	logsRcvr.alarmsClient = mc
+
+	require.NoError(t, logsRcvr.pollAlarms(context.Background()))
+	require.True(t, aws.TimeValue(input.EndDate).Equal(now))
+	// both ends of the range are included, so the next range starts in the following millisecond
+	require.True(t, logsRcvr.nextStartTime.Equal(now.Add(time.Millisecond)))
+
+	// no alarm history is described until the next range has begun
+	require.NoError(t, logsRcvr.pollAlarms(context.Background()))
+	mc.AssertNumberOfCalls(t, "DescribeAlarmHistoryWithContext", 1)
+}
+
+func TestAlarmsUnparsableHistoryData(t *testing.T) {
+	logsRcvr, sink := newAlarmsTestReceiver()
+	mc := &mockAlarmsClient{}
+	mc.On("DescribeAlarmHistoryWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatch.DescribeAlarmHistoryOutput{
+		AlarmHistoryItems: []*cloudwatch.AlarmHistoryItem{testAlarmHistoryItem(testAlarmName, "not json")},
+	}, nil)
+	logsRcvr.alarmsClient = mc
+
+	require.NoError(t, logsRcvr.pollAlarms(context.Background()))
+	require.Equal(t, 1, sink.LogRecordCount())
+	record := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, testAlarmSummary, record.Body().Str())
+	require.Equal(t, map[string]interface{}{
+		"cloudwatch.alarm.name": testAlarmName,
+		"cloudwatch.alarm.type": cloudwatch.AlarmTypeMetricAlarm,
+	}, record.Attributes().AsRaw())
+}
+
+func TestAlarmsDescribeError(t *testing.T) {
+	logsRcvr, sink := newAlarmsTestReceiver()
+	logsRcvr.alarms = &AlarmsConfig{Names: []string{testAlarmName, "checkout-errors"}}
+	mc := &mockAlarmsClient{}
+	mc.On("DescribeAlarmHistoryWithContext", mock.Anything, mock.MatchedBy(func(input *cloudwatch.DescribeAlarmHistoryInput) bool {
+		return aws.StringValue(input.AlarmName) == testAlarmName
+	}), mock.Anything).Return(&cloudwatch.DescribeAlarmHistoryOutput{
+		AlarmHistoryItems: []*cloudwatch.AlarmHistoryItem{testAlarmHistoryItem(testAlarmName, testAlarmHistoryData)},
+	}, nil)
+	mc.On("DescribeAlarmHistoryWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		(*cloudwatch.DescribeAlarmHistoryOutput)(nil), errors.New("ThrottlingException"))
+	logsRcvr.alarmsClient = mc
+
+	// the whole time range is polled again, so that no state change is emitted twice
+	startTime := logsRcvr.nextStartTime
+	require.ErrorContains(t, logsRcvr.pollAlarms(context.Background()), "unable to describe the history of alarm checkout-errors")
+	require.Equal(t, startTime, logsRcvr.nextStartTime)
+	require.Empty(t, sink.AllLogs())
+}
+
+func TestAlarmsMode(t *testing.T) {
+	logsRcvr, sink := newAlarmsTestReceiver()
+	mc := &mockAlarmsClient{}
+	mc.On("DescribeAlarmHistoryWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatch.DescribeAlarmHistoryOutput{
+		AlarmHistoryItems: []*cloudwatch.AlarmHistoryItem{testAlarmHistoryItem(testAlarmName, testAlarmHistoryData)},
+	}, nil)
+	logsRcvr.alarmsClient = mc
+	logsRcvr.stsClient = defaultMockSTSClient()
+
+	require.NoError(t, logsRcvr.Start(context.Background(), componenttest.NewNopHost()))
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() > 0
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, logsRcvr.Shutdown(context.Background()))
+
+	rl := sink.AllLogs()[0].ResourceLogs().At(0)
+	accountID, ok := rl.Resource().Attributes().Get("cloud.account.id")
+	require.True(t, ok)
+	require.Equal(t, testAccountID, accountID.Str())
+	require.Equal(t, testAlarmSummary, rl.ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+}
+
+func newAlarmsTestReceiver() (*logsReceiver, *consumertest.LogsSink) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.PollInterval = 100 * time.Millisecond
+	cfg.Logs.Mode = modeAlarms
+
+	sink := &consumertest.LogsSink{}
+	return newLogsReceiver(cfg, zap.NewNop(), sink), sink
+}
+
+// testAlarmHistoryItem returns a state update of a metric alarm, as returned by DescribeAlarmHistory
+func testAlarmHistoryItem(name, data string) *cloudwatch.AlarmHistoryItem {
+	return &cloudwatch.AlarmHistoryItem{
+		AlarmName:       aws.String(name),
+		AlarmType:       aws.String(cloudwatch.AlarmTypeMetricAlarm),
+		HistoryData:     aws.String(data),
+		HistoryItemType: aws.String(cloudwatch.HistoryItemTypeStateUpdate),
+		HistorySummary:  aws.String(testAlarmSummary),
+		Timestamp:       aws.Time(time.UnixMilli(testTimeStamp)),
+	}
+}
+
+type mockAlarmsClient struct {
+	mock.Mock
+}
+
+func (mc *mockAlarmsClient) DescribeAlarmHistoryWithContext(ctx context.Context, input *cloudwatch.DescribeAlarmHistoryInput, opts ...request.Option) (*cloudwatch.DescribeAlarmHistoryOutput, error) {
+	args := mc.Called(ctx, input, opts)
+	return args.Get(0).(*cloudwatch.DescribeAlarmHistoryOutput), args.Error(1)
+}
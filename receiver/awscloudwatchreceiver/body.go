@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"encoding/json"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	// bodyFormatRaw keeps the message as the string body of the log record
+	bodyFormatRaw = "raw"
+	// bodyFormatParsed replaces the body of messages that are JSON objects with the parsed object
+	bodyFormatParsed = "parsed"
+	// bodyFormatBoth keeps the message as the string body and adds the fields of JSON objects as attributes
+	bodyFormatBoth = "both"
+)
+
+// setBody sets the body of the log record from the message in the given body format, the names of the fields
+// of JSON objects are prefixed with the given prefix. Messages that are not JSON objects are always kept as the
+// string body.
+func setBody(record plog.LogRecord, message string, format string, prefix string) {
+	if format == "" || format == bodyFormatRaw {
+		record.Body().SetStr(message)
+		return
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(message), &fields); err != nil || fields == nil {
+		record.Body().SetStr(message)
+		return
+	}
+	if prefix != "" {
+		fields = prefixFields(fields, prefix)
+	}
+	if format == bodyFormatParsed {
+		record.Body().SetEmptyMap().FromRaw(fields)
+		return
+	}
+	record.Body().SetStr(message)
+	attributes := record.Attributes()
+	for key, value := range fields {
+		attributes.PutEmpty(key).FromRaw(value)
+	}
+}
+
+// prefixFields returns the fields with the prefix prepended to their names, the nested fields keep their names
+func prefixFields(fields map[string]interface{}, prefix string) map[string]interface{} {
+	prefixed := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		prefixed[prefix+key] = value
+	}
+	return prefixed
+}
@@ -0,0 +1,151 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+)
+
+func TestProcessEventsBodyFormat(t *testing.T) {
+	const message = `{"level":"error","msg":"payment declined","id":"order-7","request":{"amount":42}}`
+	cases := []struct {
+		name               string
+		bodyFormat         string
+		attributePrefix    string
+		message            string
+		expectedBody       interface{}
+		expectedAttributes map[string]interface{}
+	}{
+		{
+			name:               "raw",
+			bodyFormat:         bodyFormatRaw,
+			message:            message,
+			expectedBody:       message,
+			expectedAttributes: map[string]interface{}{"id": testEventID},
+		},
+		{
+			name:       "parsed",
+			bodyFormat: bodyFormatParsed,
+			message:    message,
+			expectedBody: map[string]interface{}{
+				"level":   "error",
+				"msg":     "payment declined",
+				"id":      "order-7",
+				"request": map[string]interface{}{"amount": float64(42)},
+			},
+			expectedAttributes: map[string]interface{}{"id": testEventID},
+		},
+		{
+			name:         "both",
+			bodyFormat:   bodyFormatBoth,
+			message:      message,
+			expectedBody: message,
+			// the id of the event is kept over the field of the message with the same name
+			expectedAttributes: map[string]interface{}{
+				"id":      testEventID,
+				"level":   "error",
+				"msg":     "payment declined",
+				"request": map[string]interface{}{"amount": float64(42)},
+			},
+		},
+		{
+			name:            "parsed with prefix",
+			bodyFormat:      bodyFormatParsed,
+			attributePrefix: "cw.",
+			message:         message,
+			expectedBody: map[string]interface{}{
+				"cw.level":   "error",
+				"cw.msg":     "payment declined",
+				"cw.id":      "order-7",
+				"cw.request": map[string]interface{}{"amount": float64(42)},
+			},
+			expectedAttributes: map[string]interface{}{"id": testEventID},
+		},
+		{
+			name:            "both with prefix",
+			bodyFormat:      bodyFormatBoth,
+			attributePrefix: "cw.",
+			message:         message,
+			expectedBody:    message,
+			// the field of the message named like the id of the event no longer collides with it
+			expectedAttributes: map[string]interface{}{
+				"id":         testEventID,
+				"cw.id":      "order-7",
+				"cw.level":   "error",
+				"cw.msg":     "payment declined",
+				"cw.request": map[string]interface{}{"amount": float64(42)},
+			},
+		},
+		{
+			name:               "raw with prefix",
+			bodyFormat:         bodyFormatRaw,
+			attributePrefix:    "cw.",
+			message:            message,
+			expectedBody:       message,
+			expectedAttributes: map[string]interface{}{"id": testEventID},
+		},
+		{
+			name:               "parsed plain text",
+			bodyFormat:         bodyFormatParsed,
+			message:            "payment declined",
+			expectedBody:       "payment declined",
+			expectedAttributes: map[string]interface{}{"id": testEventID},
+		},
+		{
+			name:               "both json array",
+			bodyFormat:         bodyFormatBoth,
+			message:            `["payment", "declined"]`,
+			expectedBody:       `["payment", "declined"]`,
+			expectedAttributes: map[string]interface{}{"id": testEventID},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Region = "us-west-1"
+			cfg.Logs.BodyFormat = tc.bodyFormat
+			cfg.Logs.AttributePrefix = tc.attributePrefix
+
+			logsRcvr := newLogsReceiver(cfg, zap.NewNop(), &consumertest.LogsSink{})
+			output := &cloudwatchlogs.FilterLogEventsOutput{
+				Events: []*cloudwatchlogs.FilteredLogEvent{
+					{
+						EventId:       &testEventID,
+						LogStreamName: aws.String(testLogStreamName),
+						Message:       aws.String(tc.message),
+						Timestamp:     aws.Int64(testTimeStamp),
+					},
+				},
+			}
+
+			logs, _ := logsRcvr.processEvents(pcommon.NewTimestampFromTime(time.Now()), testLogGroupName, output)
+			record := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+			require.Equal(t, tc.expectedBody, record.Body().AsRaw())
+			require.Equal(t, tc.expectedAttributes, record.Attributes().AsRaw())
+			// the resource attributes of the receiver are never prefixed
+			groupName, ok := logs.ResourceLogs().At(0).Resource().Attributes().Get("cloudwatch.log.group.name")
+			require.True(t, ok)
+			require.Equal(t, testLogGroupName, groupName.Str())
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+)
+
+// checkpointKey is the key of the cursor state of the poll in the storage
+const checkpointKey = "cloudwatch_logs_cursor"
+
+// pollCheckpoint is the cursor state of the poll that is persisted in the storage after every poll,
+// so that a restarted receiver continues where it stopped instead of from the current time.
+type pollCheckpoint struct {
+	NextStartTime   time.Time            `json:"next_start_time"`
+	GroupStartTimes map[string]time.Time `json:"group_start_times,omitempty"`
+	Resume          *resumeCheckpoint    `json:"resume,omitempty"`
+}
+
+// resumeCheckpoint is the persisted pollResume. The name of the group is kept to check
+// that the index still refers to the same group once the receiver is restarted.
+type resumeCheckpoint struct {
+	GroupIndex int       `json:"group_index"`
+	Group      string    `json:"group"`
+	NextToken  string    `json:"next_token,omitempty"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+}
+
+// startToken is the configured position a log group is read from on the first poll after the start. The token
+// is only used by the first request for the group, the start time holds until the first time window is read.
+type startToken struct {
+	nextToken string
+	startTime time.Time
+}
+
+// newStartTokens returns the start tokens of the groups by their name, or nil if none is configured.
+func newStartTokens(cfgs map[string]StartTokenConfig) map[string]*startToken {
+	if len(cfgs) == 0 {
+		return nil
+	}
+	tokens := make(map[string]*startToken, len(cfgs))
+	for group, cfg := range cfgs {
+		st := &startToken{nextToken: cfg.NextToken}
+		if cfg.StartTime != "" {
+			// the start time was validated with the config
+			st.startTime, _ = time.Parse(time.RFC3339, cfg.StartTime)
+		}
+		tokens[group] = st
+	}
+	return tokens
+}
+
+// getStorageClient returns a client of the storage extension with the given ID, or a client
+// that does not store anything if no storage is configured.
+func getStorageClient(ctx context.Context, host component.Host, storageID *component.ID, componentID component.ID) (storage.Client, error) {
+	if storageID == nil {
+		return storage.NewNopClient(), nil
+	}
+	extension, ok := host.GetExtensions()[*storageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension '%s' not found", storageID)
+	}
+	storageExtension, ok := extension.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("non-storage extension '%s' found", storageID)
+	}
+	return storageExtension.GetClient(ctx, component.KindReceiver, componentID, "")
+}
+
+// loadCheckpoint restores the cursor state of the poll from the storage, the state is left
+// as is if nothing was stored.
+func (l *logsReceiver) loadCheckpoint(ctx context.Context) error {
+	data, err := l.storageClient.Get(ctx, checkpointKey)
+	if err != nil {
+		return fmt.Errorf("unable to read the checkpoint: %w", err)
+	}
+	if data == nil {
+		return nil
+	}
+	var checkpoint pollCheckpoint
+	if err = json.Unmarshal(data, &checkpoint); err != nil {
+		return fmt.Errorf("unable to decode the checkpoint: %w", err)
+	}
+	l.nextStartTime = checkpoint.NextStartTime
+	if checkpoint.GroupStartTimes != nil {
+		l.groupStartTimes = checkpoint.GroupStartTimes
+	}
+	if r := checkpoint.Resume; r != nil {
+		l.resume = &pollResume{groupIndex: r.GroupIndex, group: r.Group, nextToken: r.NextToken, startTime: r.StartTime, endTime: r.EndTime}
+	}
+	return nil
+}
+
+// writeCheckpoint persists the cursor state of the poll to the storage.
+func (l *logsReceiver) writeCheckpoint(ctx context.Context) error {
+	checkpoint := pollCheckpoint{
+		NextStartTime:   l.nextStartTime,
+		GroupStartTimes: l.groupStartTimes,
+	}
+	if r := l.resume; r != nil {
+		checkpoint.Resume = &resumeCheckpoint{GroupIndex: r.groupIndex, Group: r.group, NextToken: r.nextToken, StartTime: r.startTime, EndTime: r.endTime}
+	}
+	data, err := json.Marshal(&checkpoint)
+	if err != nil {
+		return fmt.Errorf("unable to encode the checkpoint: %w", err)
+	}
+	return l.storageClient.Set(ctx, checkpointKey, data)
+}
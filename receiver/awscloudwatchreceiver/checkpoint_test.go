@@ -0,0 +1,175 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+)
+
+func TestCheckpointSurvivesRestart(t *testing.T) {
+	storageID := storagetest.NewStorageID("test")
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.StorageID = &storageID
+	cfg.Logs.PollInterval = time.Hour
+	cfg.Logs.MaxEventsPerRequest = 10
+	cfg.Logs.MaxEventsPerPoll = 15
+	cfg.Logs.Groups = GroupConfig{
+		NamedConfigs: map[string]StreamConfig{
+			testLogGroupName: {
+				Names: []*string{&testLogStreamName},
+			},
+		},
+	}
+	pc := &pagedClient{totalEvents: 25}
+	sink := &consumertest.LogsSink{}
+
+	// the first receiver stops in the middle of the time window once it reached the cap
+	first := newLogsReceiver(cfg, zap.NewNop(), sink)
+	first.client = pc
+	first.stsClient = defaultMockSTSClient()
+	require.NoError(t, first.Start(context.Background(), host))
+	require.NoError(t, first.poll(context.Background()))
+	require.NoError(t, first.writeCheckpoint(context.Background()))
+	require.NoError(t, first.Shutdown(context.Background()))
+	require.Equal(t, 15, sink.LogRecordCount())
+
+	// the restarted receiver continues from the persisted token within the same time window
+	second := newLogsReceiver(cfg, zap.NewNop(), sink)
+	second.client = pc
+	second.stsClient = defaultMockSTSClient()
+	require.NoError(t, second.Start(context.Background(), host))
+	require.NotNil(t, second.resume)
+	require.NoError(t, second.poll(context.Background()))
+	require.NoError(t, second.writeCheckpoint(context.Background()))
+	require.NoError(t, second.Shutdown(context.Background()))
+	require.Equal(t, 25, sink.LogRecordCount())
+	require.Len(t, pc.requests, 3)
+	require.Equal(t, "15", aws.StringValue(pc.requests[2].NextToken))
+	require.Equal(t, pc.requests[0].StartTime, pc.requests[2].StartTime)
+	require.Equal(t, pc.requests[0].EndTime, pc.requests[2].EndTime)
+
+	// the next time window starts where the restarted receiver stopped, not at the time of the restart
+	third := newLogsReceiver(cfg, zap.NewNop(), sink)
+	third.client = pc
+	third.stsClient = defaultMockSTSClient()
+	require.NoError(t, third.Start(context.Background(), host))
+	require.Nil(t, third.resume)
+	require.True(t, third.nextStartTime.Equal(second.nextStartTime))
+	require.NoError(t, third.Shutdown(context.Background()))
+}
+
+func TestCheckpointResumeOfChangedGroups(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.Groups = GroupConfig{
+		NamedConfigs: map[string]StreamConfig{
+			testLogGroupName: {},
+		},
+	}
+	client := storagetest.NewInMemoryClient(component.KindReceiver, cfg.ID(), "")
+	startTime := time.UnixMilli(testTimeStamp)
+	endTime := startTime.Add(time.Minute)
+
+	first := newLogsReceiver(cfg, zap.NewNop(), &consumertest.LogsSink{})
+	first.storageClient = client
+	first.resume = &pollResume{groupIndex: 0, group: "removed", nextToken: "15", startTime: startTime, endTime: endTime}
+	require.NoError(t, first.writeCheckpoint(context.Background()))
+
+	// the group the poll stopped at is no longer configured, so the time window is read again from the start
+	second := newLogsReceiver(cfg, zap.NewNop(), &consumertest.LogsSink{})
+	second.storageClient = client
+	require.NoError(t, second.loadCheckpoint(context.Background()))
+	pc := &pagedClient{totalEvents: 5}
+	second.client = pc
+	require.NoError(t, second.poll(context.Background()))
+	require.Len(t, pc.requests, 1)
+	require.Nil(t, pc.requests[0].NextToken)
+	require.Equal(t, startTime.UnixMilli(), aws.Int64Value(pc.requests[0].StartTime))
+	require.Equal(t, endTime.UnixMilli(), aws.Int64Value(pc.requests[0].EndTime))
+}
+
+func TestStartTokenOverridesCheckpoint(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.MaxEventsPerRequest = 10
+	cfg.Logs.MaxEventsPerPoll = 10
+	cfg.Logs.Groups = GroupConfig{
+		NamedConfigs: map[string]StreamConfig{
+			testLogGroupName: {},
+		},
+		StartTokens: map[string]StartTokenConfig{
+			testLogGroupName: {NextToken: "5", StartTime: "2022-11-01T00:00:00Z"},
+		},
+	}
+	client := storagetest.NewInMemoryClient(component.KindReceiver, cfg.ID(), "")
+	persistedStart := time.UnixMilli(testTimeStamp)
+
+	first := newLogsReceiver(cfg, zap.NewNop(), &consumertest.LogsSink{})
+	first.storageClient = client
+	first.resume = &pollResume{groupIndex: 0, group: testLogGroupName, nextToken: "20", startTime: persistedStart, endTime: persistedStart.Add(time.Minute)}
+	require.NoError(t, first.writeCheckpoint(context.Background()))
+
+	sink := &consumertest.LogsSink{}
+	second := newLogsReceiver(cfg, zap.NewNop(), sink)
+	second.storageClient = client
+	require.NoError(t, second.loadCheckpoint(context.Background()))
+	pc := &pagedClient{totalEvents: 25}
+	second.client = pc
+	startTime := time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+
+	// the first poll reads from the configured token instead of the persisted one and stops at the cap
+	require.NoError(t, second.poll(context.Background()))
+	require.Len(t, pc.requests, 1)
+	require.Equal(t, "5", aws.StringValue(pc.requests[0].NextToken))
+	require.Equal(t, startTime, aws.Int64Value(pc.requests[0].StartTime))
+	require.Equal(t, 10, sink.LogRecordCount())
+
+	// the time window is resumed with the token of the next page and keeps the configured start time
+	require.NoError(t, second.poll(context.Background()))
+	require.Len(t, pc.requests, 2)
+	require.Equal(t, "15", aws.StringValue(pc.requests[1].NextToken))
+	require.Equal(t, startTime, aws.Int64Value(pc.requests[1].StartTime))
+	require.Equal(t, 20, sink.LogRecordCount())
+	require.Nil(t, second.startTokens)
+
+	// the next time window starts where the first one ended
+	require.NoError(t, second.poll(context.Background()))
+	require.Len(t, pc.requests, 3)
+	require.Nil(t, pc.requests[2].NextToken)
+	require.Equal(t, aws.Int64Value(pc.requests[1].EndTime), aws.Int64Value(pc.requests[2].StartTime))
+}
+
+func TestStartWithMissingStorage(t *testing.T) {
+	storageID := storagetest.NewStorageID("missing")
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.StorageID = &storageID
+
+	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), &consumertest.LogsSink{})
+	err := logsRcvr.Start(context.Background(), storagetest.NewStorageHost())
+	require.ErrorContains(t, err, "storage extension 'test_storage/missing' not found")
+}
@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var (
+	receiverNameKey = tag.MustNewKey("receiver")
+	logGroupKey     = tag.MustNewKey("log_group")
+
+	circuitBreakerOpen = stats.Int64(
+		"awscloudwatchreceiver/log_group_circuit_breaker_open",
+		"Indicates with value 1 that the polling of the log group is paused after consecutive failures",
+		stats.UnitDimensionless)
+
+	circuitBreakerOpenView = &view.View{
+		Name:        "receiver/" + typeStr + "/log_group_circuit_breaker_open",
+		Description: circuitBreakerOpen.Description(),
+		Measure:     circuitBreakerOpen,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{receiverNameKey, logGroupKey},
+	}
+
+	// the views are shared by all receivers, which are told apart by their tags,
+	// as a view with the same name cannot be registered twice
+	registerViewsOnce sync.Once
+	errRegisterViews  error
+)
+
+// circuitBreaker pauses the polling of a log group for a cooldown once polling it failed
+// a number of consecutive times, so that a failing log group does not hammer the API.
+type circuitBreaker struct {
+	threshold    int
+	cooldown     time.Duration
+	receiverName string
+	groups       map[string]*groupCircuit
+	now          func() time.Time
+}
+
+type groupCircuit struct {
+	failures  int
+	open      bool
+	openUntil time.Time
+}
+
+// registerViews registers the views of the internal telemetry of the receivers
+func registerViews() error {
+	registerViewsOnce.Do(func() {
+		errRegisterViews = view.Register(circuitBreakerOpenView, accessDeniedView, ingestionLagView, emptyPollsView, excludedEventsView)
+	})
+	return errRegisterViews
+}
+
+func newCircuitBreaker(cfg *CircuitBreakerConfig, receiverName string) (*circuitBreaker, error) {
+	if err := registerViews(); err != nil {
+		return nil, err
+	}
+	return &circuitBreaker{
+		threshold:    cfg.FailureThreshold,
+		cooldown:     cfg.Cooldown,
+		receiverName: receiverName,
+		groups:       map[string]*groupCircuit{},
+		now:          time.Now,
+	}, nil
+}
+
+// allow returns false while the circuit of the log group is open. Once the cooldown has passed
+// the log group is polled again, closing the circuit if it succeeds.
+func (c *circuitBreaker) allow(group string) bool {
+	if c == nil {
+		return true
+	}
+	circuit, ok := c.groups[group]
+	if !ok || !circuit.open {
+		return true
+	}
+	return !c.now().Before(circuit.openUntil)
+}
+
+// recordResult records the result of polling the log group, returning true if the circuit was opened.
+func (c *circuitBreaker) recordResult(group string, err error) bool {
+	if c == nil {
+		return false
+	}
+	circuit, ok := c.groups[group]
+	if !ok {
+		circuit = &groupCircuit{}
+		c.groups[group] = circuit
+	}
+
+	if err == nil {
+		circuit.failures = 0
+		if circuit.open {
+			circuit.open = false
+			c.recordState(group, 0)
+		}
+		return false
+	}
+
+	circuit.failures++
+	// a failure after the cooldown opens the circuit again right away
+	if !circuit.open && circuit.failures < c.threshold {
+		return false
+	}
+	circuit.failures = 0
+	circuit.open = true
+	circuit.openUntil = c.now().Add(c.cooldown)
+	c.recordState(group, 1)
+	return true
+}
+
+func (c *circuitBreaker) recordState(group string, open int64) {
+	_ = stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{tag.Upsert(receiverNameKey, c.receiverName), tag.Upsert(logGroupKey, group)},
+		circuitBreakerOpen.M(open),
+	)
+}
@@ -0,0 +1,183 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+func TestCircuitBreakerSkipsFailingGroup(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ReceiverSettings.SetIDName(t.Name())
+	cfg.Region = "us-west-1"
+	cfg.Logs.CircuitBreaker = &CircuitBreakerConfig{FailureThreshold: 2, Cooldown: time.Minute}
+	cfg.Logs.Groups = GroupConfig{
+		NamedConfigs: map[string]StreamConfig{
+			"failing": {},
+			"healthy": {},
+		},
+	}
+
+	sink := &consumertest.LogsSink{}
+	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+	now := time.Now()
+	logsRcvr.circuitBreaker.now = func() time.Time { return now }
+
+	mc := &mockClient{}
+	mc.On("FilterLogEventsWithContext", mock.Anything, groupInput("failing"), mock.Anything).Return(
+		(*cloudwatchlogs.FilterLogEventsOutput)(nil), errors.New("throttled"))
+	mc.On("FilterLogEventsWithContext", mock.Anything, groupInput("healthy"), mock.Anything).Return(
+		&cloudwatchlogs.FilterLogEventsOutput{
+			Events: []*cloudwatchlogs.FilteredLogEvent{
+				{
+					EventId:       aws.String(testEventID),
+					LogStreamName: aws.String(testLogStreamName),
+					Message:       aws.String(testLogStreamMessage),
+					Timestamp:     aws.Int64(testTimeStamp),
+				},
+			},
+		}, nil)
+	logsRcvr.client = mc
+
+	// the failing group is polled until it fails consecutively as many times as the threshold
+	require.Error(t, logsRcvr.poll(context.Background()))
+	require.Error(t, logsRcvr.poll(context.Background()))
+	require.Equal(t, int64(1), circuitBreakerState(t, cfg.ID().String(), "failing"))
+
+	// while its circuit is open only the healthy group is polled
+	require.NoError(t, logsRcvr.poll(context.Background()))
+	mc.AssertNumberOfCalls(t, "FilterLogEventsWithContext", 5)
+	require.Equal(t, 3, sink.LogRecordCount())
+
+	// once the cooldown has passed the group is polled again, a success closes its circuit
+	now = now.Add(time.Minute)
+	mc.ExpectedCalls = nil
+	mc.On("FilterLogEventsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&cloudwatchlogs.FilterLogEventsOutput{}, nil)
+	require.NoError(t, logsRcvr.poll(context.Background()))
+	mc.AssertNumberOfCalls(t, "FilterLogEventsWithContext", 7)
+	require.Equal(t, int64(0), circuitBreakerState(t, cfg.ID().String(), "failing"))
+}
+
+func TestCircuitBreakerReadsPausedTimeWindow(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ReceiverSettings.SetIDName(t.Name())
+	cfg.Region = "us-west-1"
+	cfg.Logs.CircuitBreaker = &CircuitBreakerConfig{FailureThreshold: 1, Cooldown: time.Minute}
+	cfg.Logs.Groups = GroupConfig{
+		NamedConfigs: map[string]StreamConfig{
+			"failing": {},
+			"healthy": {},
+		},
+	}
+
+	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), &consumertest.LogsSink{})
+	now := time.Now()
+	logsRcvr.circuitBreaker.now = func() time.Time { return now }
+
+	startTimes := map[string][]int64{}
+	recordStartTime := func(args mock.Arguments) {
+		input := args.Get(1).(*cloudwatchlogs.FilterLogEventsInput)
+		group := aws.StringValue(input.LogGroupName)
+		startTimes[group] = append(startTimes[group], aws.Int64Value(input.StartTime))
+	}
+	mc := &mockClient{}
+	mc.On("FilterLogEventsWithContext", mock.Anything, groupInput("failing"), mock.Anything).Run(recordStartTime).Return(
+		(*cloudwatchlogs.FilterLogEventsOutput)(nil), errors.New("throttled")).Once()
+	mc.On("FilterLogEventsWithContext", mock.Anything, mock.Anything, mock.Anything).Run(recordStartTime).Return(
+		&cloudwatchlogs.FilterLogEventsOutput{}, nil)
+	logsRcvr.client = mc
+
+	// the failure opens the circuit, the group is then paused for a poll
+	require.Error(t, logsRcvr.poll(context.Background()))
+	require.NoError(t, logsRcvr.poll(context.Background()))
+	require.Len(t, startTimes["failing"], 1)
+
+	// once the circuit closes the group is polled from the start of the window it failed to read
+	now = now.Add(time.Minute)
+	require.NoError(t, logsRcvr.poll(context.Background()))
+	require.Len(t, startTimes["failing"], 2)
+	require.Equal(t, startTimes["failing"][0], startTimes["failing"][1])
+	require.Len(t, startTimes["healthy"], 3)
+	require.Greater(t, startTimes["healthy"][2], startTimes["healthy"][0])
+
+	// after a successful poll the group continues with the window of the other groups
+	require.NoError(t, logsRcvr.poll(context.Background()))
+	require.Equal(t, startTimes["healthy"][3], startTimes["failing"][2])
+}
+
+func TestCircuitBreakerReopensAfterFailedRetry(t *testing.T) {
+	breaker, err := newCircuitBreaker(&CircuitBreakerConfig{FailureThreshold: 3, Cooldown: time.Minute}, t.Name())
+	require.NoError(t, err)
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	failure := errors.New("throttled")
+	require.False(t, breaker.recordResult("group", failure))
+	require.False(t, breaker.recordResult("group", failure))
+	// a success resets the consecutive failures
+	require.False(t, breaker.recordResult("group", nil))
+	require.False(t, breaker.recordResult("group", failure))
+	require.False(t, breaker.recordResult("group", failure))
+	require.True(t, breaker.allow("group"))
+	require.True(t, breaker.recordResult("group", failure))
+	require.False(t, breaker.allow("group"))
+	require.True(t, breaker.allow("other"))
+
+	// the first failure after the cooldown opens the circuit again
+	now = now.Add(time.Minute)
+	require.True(t, breaker.allow("group"))
+	require.True(t, breaker.recordResult("group", failure))
+	require.False(t, breaker.allow("group"))
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	var breaker *circuitBreaker
+	require.True(t, breaker.allow("group"))
+	require.False(t, breaker.recordResult("group", errors.New("throttled")))
+}
+
+func groupInput(group string) interface{} {
+	return mock.MatchedBy(func(input *cloudwatchlogs.FilterLogEventsInput) bool {
+		return aws.StringValue(input.LogGroupName) == group
+	})
+}
+
+func circuitBreakerState(t *testing.T, receiverName string, group string) int64 {
+	rows, err := view.RetrieveData(circuitBreakerOpenView.Name)
+	require.NoError(t, err)
+	for _, row := range rows {
+		tags := map[string]string{}
+		for _, tag := range row.Tags {
+			tags[tag.Key.Name()] = tag.Value
+		}
+		if tags[receiverNameKey.Name()] == receiverName && tags[logGroupKey.Name()] == group {
+			return int64(row.Data.(*view.LastValueData).Value)
+		}
+	}
+	require.Failf(t, "no circuit breaker state", "log group %q of receiver %q", group, receiverName)
+	return -1
+}
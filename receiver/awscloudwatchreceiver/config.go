@@ -18,8 +18,11 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"regexp"
+	"strings"
 	"time"
 
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/confmap"
 	"go.uber.org/multierr"
@@ -29,28 +32,181 @@ var (
 	defaultPollInterval  = time.Minute
 	defaultEventLimit    = 1000
 	defaultLogGroupLimit = 50
+	// defaultInsightsTimeout and defaultInsightsStatusInterval are used when an insights query does not configure them
+	defaultInsightsTimeout        = time.Minute
+	defaultInsightsStatusInterval = time.Second
+	// defaultInsightsLimit and maxInsightsLimit are the default and the largest number of rows returned by an insights query
+	defaultInsightsLimit = 1000
+	maxInsightsLimit     = 10000
+	// defaultMaxDecompressedSize is 64 MiB, far more than the 256 KB an event is limited to and
+	// enough for the objects of log exports
+	defaultMaxDecompressedSize int64 = 64 * 1024 * 1024
 )
 
 // Config is the overall config structure for the awscloudwatchreceiver
 type Config struct {
 	config.ReceiverSettings `mapstructure:",squash"`
-	Region                  string      `mapstructure:"region"`
-	Profile                 string      `mapstructure:"profile"`
-	IMDSEndpoint            string      `mapstructure:"imds_endpoint"`
-	Logs                    *LogsConfig `mapstructure:"logs"`
+	Region                  string        `mapstructure:"region"`
+	Profile                 string        `mapstructure:"profile"`
+	IMDSEndpoint            string        `mapstructure:"imds_endpoint"`
+	Logs                    *LogsConfig   `mapstructure:"logs"`
+	StorageID               *component.ID `mapstructure:"storage"`
+	AWS                     *AWSConfig    `mapstructure:"aws,omitempty"`
 }
 
+// AWSConfig is the configuration of the clients of the AWS SDK
+type AWSConfig struct {
+	HTTP HTTPConfig `mapstructure:"http"`
+}
+
+// HTTPConfig is the configuration of the HTTP client the AWS SDK sends its requests with, the defaults of the
+// SDK are used for the settings that are not set
+type HTTPConfig struct {
+	// Timeout is the longest a request may take, including its connection and the read of its response, 0 means no timeout
+	Timeout time.Duration `mapstructure:"timeout"`
+	// MaxRetries is the number of times a failed request is retried, the default of each AWS service if not set
+	MaxRetries *int `mapstructure:"max_retries"`
+	// MaxIdleConns is the maximum number of idle connections to all hosts, 0 means the default of the Go HTTP client
+	MaxIdleConns int `mapstructure:"max_idle_conns"`
+	// MaxIdleConnsPerHost is the maximum number of idle connections to every host, 0 means the default of the Go HTTP client
+	MaxIdleConnsPerHost int `mapstructure:"max_idle_conns_per_host"`
+	// IdleConnTimeout is the longest an idle connection is kept open, 0 means the default of the Go HTTP client
+	IdleConnTimeout time.Duration `mapstructure:"idle_conn_timeout"`
+}
+
+const (
+	// modePoll polls the Cloudwatch Logs API for new events
+	modePoll = "poll"
+	// modeS3 reads log exports that Cloudwatch Logs has written to S3
+	modeS3 = "s3"
+	// modeInsights runs a Cloudwatch Logs Insights query and emits its results
+	modeInsights = "insights"
+	// modeAlarms polls the history of Cloudwatch metric alarms and emits their state changes
+	modeAlarms = "alarms"
+	// modeLogGroupMetrics periodically describes the log groups and emits their stored bytes and retention as metrics
+	modeLogGroupMetrics = "log_group_metrics"
+)
+
 // LogsConfig is the configuration for the logs portion of this receiver
 type LogsConfig struct {
-	PollInterval        time.Duration `mapstructure:"poll_interval"`
-	MaxEventsPerRequest int           `mapstructure:"max_events_per_request"`
-	Groups              GroupConfig   `mapstructure:"groups"`
+	Mode                string                `mapstructure:"mode"`
+	PollInterval        time.Duration         `mapstructure:"poll_interval"`
+	MaxEventsPerRequest int                   `mapstructure:"max_events_per_request"`
+	MaxEventsPerPoll    int                   `mapstructure:"max_events_per_poll"`
+	Groups              GroupConfig           `mapstructure:"groups"`
+	S3                  *S3Config             `mapstructure:"s3,omitempty"`
+	Insights            *InsightsConfig       `mapstructure:"insights,omitempty"`
+	Alarms              *AlarmsConfig         `mapstructure:"alarms,omitempty"`
+	Severity            *SeverityConfig       `mapstructure:"severity,omitempty"`
+	EMF                 *EMFConfig            `mapstructure:"emf,omitempty"`
+	CircuitBreaker      *CircuitBreakerConfig `mapstructure:"circuit_breaker,omitempty"`
+	Services            *ServiceConfig        `mapstructure:"services,omitempty"`
+	// StreamAttributes extracts resource attributes from the log stream names following a naming convention
+	StreamAttributes *StreamAttributesConfig `mapstructure:"stream_attributes,omitempty"`
+	// MaxDecompressedSize is the largest size in bytes that gzip compressed events and export objects
+	// are decompressed to, anything larger is rejected. 0 means there is no limit.
+	MaxDecompressedSize int64 `mapstructure:"max_decompressed_size"`
+	// BodyFormat is the body of the log records of messages that are JSON objects, one of raw, parsed or both
+	BodyFormat string `mapstructure:"body_format"`
+	// AttributePrefix is prepended to the names of the fields of the JSON objects parsed with body_format parsed
+	// or both, so that they do not collide with the attributes set by the receiver. The names are kept if empty.
+	AttributePrefix string `mapstructure:"attribute_prefix"`
+	// FailOnEmpty fails the start if no log group matches the configured groups, which are checked at start in poll mode
+	FailOnEmpty bool `mapstructure:"fail_on_empty"`
+	// MinEventTimestamp discards the events older than an RFC 3339 time, or than a duration before each poll,
+	// whatever the time window of the poll. No event is discarded if empty.
+	MinEventTimestamp string `mapstructure:"min_event_timestamp"`
+	// EmptyPollWarnThreshold is the number of consecutive polls of a log group that read no events after which
+	// a warning is logged, as the configuration of the log group may be wrong. No warning is logged if 0.
+	EmptyPollWarnThreshold int `mapstructure:"empty_poll_warn_threshold"`
+	// ExcludePatterns are regular expressions matched against the messages of the polled events, matching
+	// events are dropped before they are emitted. No event is dropped if empty.
+	ExcludePatterns []string `mapstructure:"exclude_patterns"`
+}
+
+// CircuitBreakerConfig is the configuration for pausing the polling of log groups that repeatedly fail
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures of a log group after which its polling is paused
+	FailureThreshold int `mapstructure:"failure_threshold"`
+	// Cooldown is the duration the polling of a log group is paused for
+	Cooldown time.Duration `mapstructure:"cooldown"`
+}
+
+// ServiceConfig is the configuration for classifying the AWS service that wrote the events of a log group
+type ServiceConfig struct {
+	// Mappings are evaluated in order before the default mappings, the first matching pattern sets the service
+	Mappings []ServiceMapping `mapstructure:"mappings"`
+	// DisableDefaults only evaluates the configured mappings
+	DisableDefaults bool `mapstructure:"disable_defaults"`
+}
+
+// ServiceMapping classifies the log groups whose name matches the regular expression as the given service
+type ServiceMapping struct {
+	Pattern string `mapstructure:"pattern"`
+	Service string `mapstructure:"service"`
+}
+
+// StreamAttributesConfig is the configuration for extracting resource attributes from the log stream names
+type StreamAttributesConfig struct {
+	// Pattern is a regular expression matched against the log stream name whose named groups are extracted,
+	// the naming convention of the log streams of Lambda functions if empty
+	Pattern string `mapstructure:"pattern"`
+	// Attributes maps the named groups of the pattern to the resource attributes they are set as
+	Attributes map[string]string `mapstructure:"attributes"`
+}
+
+// EMFConfig is the configuration for extracting the metrics of events in the Cloudwatch embedded metric format
+type EMFConfig struct {
+	// KeepLogs emits the log records of events in the embedded metric format in addition to their metrics
+	KeepLogs bool `mapstructure:"keep_logs"`
+}
+
+// SeverityConfig is the configuration for parsing the severity of events from their message
+type SeverityConfig struct {
+	Regex     string `mapstructure:"regex"`
+	JSONField string `mapstructure:"json_field"`
+}
+
+// S3Config is the configuration for reading Cloudwatch Logs exports from S3
+type S3Config struct {
+	Bucket string `mapstructure:"bucket"`
+	Prefix string `mapstructure:"prefix"`
+}
+
+// InsightsConfig is the configuration for running a Cloudwatch Logs Insights query
+type InsightsConfig struct {
+	Query     string   `mapstructure:"query"`
+	LogGroups []string `mapstructure:"log_groups"`
+	// Timeout is the longest a query may run before it is stopped, 0 means the default of one minute
+	Timeout time.Duration `mapstructure:"timeout"`
+	// StatusInterval is the interval at which the status of a running query is checked, 0 means the default of one second
+	StatusInterval time.Duration `mapstructure:"status_interval"`
+	// Limit is the maximum number of rows returned by a query, 0 means the default of 1000
+	Limit int `mapstructure:"limit"`
+}
+
+// AlarmsConfig is the configuration for polling the state changes of Cloudwatch metric alarms
+type AlarmsConfig struct {
+	// Names are the alarms whose state changes are emitted, all alarms if empty
+	Names []string `mapstructure:"names"`
 }
 
 // GroupConfig is the configuration for log group collection
 type GroupConfig struct {
 	AutodiscoverConfig *AutodiscoverConfig     `mapstructure:"autodiscover,omitempty"`
 	NamedConfigs       map[string]StreamConfig `mapstructure:"named"`
+	// StartTokens are the positions the log groups, by name, are read from on the first poll after the start,
+	// they take precedence over the state persisted in the storage
+	StartTokens map[string]StartTokenConfig `mapstructure:"start_token"`
+}
+
+// StartTokenConfig is the position a log group is read from on the first poll after the start
+type StartTokenConfig struct {
+	// NextToken is the token of the page of events the first request for the log group starts at
+	NextToken string `mapstructure:"next_token"`
+	// StartTime is the RFC 3339 time the time window of the log group starts at, the start of the time window
+	// of the poll if empty
+	StartTime string `mapstructure:"start_time"`
 }
 
 // AutodiscoverConfig is the configuration for the autodiscovery functionality of log groups
@@ -64,15 +220,43 @@ type AutodiscoverConfig struct {
 type StreamConfig struct {
 	Prefixes []*string `mapstructure:"prefixes"`
 	Names    []*string `mapstructure:"names"`
+	// Include are regular expressions of which the log stream of an event must match at least one, if any are set
+	Include []string `mapstructure:"stream_include"`
+	// Exclude are regular expressions the log stream of an event must not match
+	Exclude []string `mapstructure:"stream_exclude"`
 }
 
 var (
 	errNoRegion                       = errors.New("no region was specified")
 	errNoLogsConfigured               = errors.New("no logs configured")
 	errInvalidEventLimit              = errors.New("event limit is improperly configured, value must be greater than 0")
+	errInvalidPollEventLimit          = errors.New("event limit per poll is improperly configured, value must not be negative")
 	errInvalidPollInterval            = errors.New("poll interval is incorrect, it must be a duration greater than one second")
 	errInvalidAutodiscoverLimit       = errors.New("the limit of autodiscovery of log groups is improperly configured, value must be greater than 0")
 	errAutodiscoverAndNamedConfigured = errors.New("both autodiscover and named configs are configured, Only one or the other is permitted")
+	errInvalidStartToken              = errors.New("start token is improperly configured, a log group name and at least one of next_token or start_time must be specified")
+	errInvalidStartTime               = errors.New("start token is improperly configured, start_time must be an RFC 3339 time")
+	errInvalidMode                    = errors.New("mode is improperly configured, value must be one of 'poll', 's3', 'insights', 'alarms' or 'log_group_metrics'")
+	errNoS3Bucket                     = errors.New("no s3 bucket was specified, a bucket is required when mode is 's3'")
+	errNoInsightsQuery                = errors.New("no insights query was specified, a query is required when mode is 'insights'")
+	errNoInsightsLogGroups            = errors.New("no log groups were specified, at least one log group is required when mode is 'insights'")
+	errInvalidInsightsTimeout         = errors.New("insights timeout is improperly configured, value must not be negative")
+	errInvalidInsightsStatusInterval  = errors.New("insights status interval is improperly configured, value must not be negative")
+	errInvalidInsightsLimit           = errors.New("insights limit is improperly configured, value must be between 0 and 10000")
+	errInvalidSeverityConfig          = errors.New("severity is improperly configured, exactly one of regex or json_field must be specified")
+	errInvalidFailureThreshold        = errors.New("circuit breaker failure threshold is improperly configured, value must be greater than 0")
+	errInvalidCooldown                = errors.New("circuit breaker cooldown is improperly configured, value must be greater than 0")
+	errInvalidMaxDecompressedSize     = errors.New("max decompressed size is improperly configured, value must not be negative")
+	errInvalidEmptyPollThreshold      = errors.New("empty poll warn threshold is improperly configured, value must not be negative")
+	errInvalidServiceMapping          = errors.New("service mapping is improperly configured, both pattern and service must be specified")
+	errInvalidBodyFormat              = errors.New("body format is improperly configured, value must be one of 'raw', 'parsed' or 'both'")
+	errInvalidMinEventTimestamp       = errors.New("min event timestamp is improperly configured, value must be an RFC 3339 time or a positive duration")
+	errNoStreamPatternGroups          = errors.New("stream pattern is improperly configured, it must have at least one named group")
+	errEmptyStreamAttribute           = errors.New("stream attributes are improperly configured, attribute names must not be empty")
+	errEmptyAlarmName                 = errors.New("alarm names are improperly configured, names must not be empty")
+	errInvalidHTTPTimeout             = errors.New("aws http timeout is improperly configured, value must not be negative")
+	errInvalidHTTPMaxRetries          = errors.New("aws http max retries is improperly configured, value must not be negative")
+	errInvalidHTTPIdleConns           = errors.New("aws http idle connections are improperly configured, values must not be negative")
 )
 
 // Validate validates all portions of the relevant config
@@ -88,6 +272,12 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if c.AWS != nil {
+		if err := c.AWS.HTTP.validate(); err != nil {
+			return err
+		}
+	}
+
 	var errs error
 	errs = multierr.Append(errs, c.validateLogsConfig())
 	return errs
@@ -118,18 +308,181 @@ func (c *Config) validateLogsConfig() error {
 	if c.Logs.MaxEventsPerRequest <= 0 {
 		return errInvalidEventLimit
 	}
+	if c.Logs.MaxEventsPerPoll < 0 {
+		return errInvalidPollEventLimit
+	}
 	if c.Logs.PollInterval < time.Second {
 		return errInvalidPollInterval
 	}
+	if c.Logs.MaxDecompressedSize < 0 {
+		return errInvalidMaxDecompressedSize
+	}
+	if c.Logs.EmptyPollWarnThreshold < 0 {
+		return errInvalidEmptyPollThreshold
+	}
+	switch c.Logs.BodyFormat {
+	case "", bodyFormatRaw, bodyFormatParsed, bodyFormatBoth:
+	default:
+		return errInvalidBodyFormat
+	}
+	if _, err := parseMinTimestamp(c.Logs.MinEventTimestamp); err != nil {
+		return err
+	}
+	if _, err := newMessageExcluder(c.Logs.ExcludePatterns); err != nil {
+		return err
+	}
+
+	if c.Logs.Severity != nil {
+		if err := c.Logs.Severity.validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Logs.CircuitBreaker != nil {
+		if err := c.Logs.CircuitBreaker.validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Logs.Services != nil {
+		if err := c.Logs.Services.validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Logs.StreamAttributes != nil {
+		if err := c.Logs.StreamAttributes.validate(); err != nil {
+			return err
+		}
+	}
+
+	switch c.Logs.Mode {
+	case "", modePoll, modeLogGroupMetrics:
+	case modeS3:
+		return c.Logs.S3.validate()
+	case modeInsights:
+		return c.Logs.Insights.validate()
+	case modeAlarms:
+		return c.Logs.Alarms.validate()
+	default:
+		return errInvalidMode
+	}
 
 	return c.Logs.Groups.validate()
 }
 
+func (c *HTTPConfig) validate() error {
+	if c.Timeout < 0 {
+		return errInvalidHTTPTimeout
+	}
+	if c.MaxRetries != nil && *c.MaxRetries < 0 {
+		return errInvalidHTTPMaxRetries
+	}
+	if c.MaxIdleConns < 0 || c.MaxIdleConnsPerHost < 0 || c.IdleConnTimeout < 0 {
+		return errInvalidHTTPIdleConns
+	}
+	return nil
+}
+
+func (c *SeverityConfig) validate() error {
+	if (c.Regex == "") == (c.JSONField == "") {
+		return errInvalidSeverityConfig
+	}
+	if c.Regex != "" {
+		if _, err := regexp.Compile(c.Regex); err != nil {
+			return fmt.Errorf("unable to compile severity regex: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *CircuitBreakerConfig) validate() error {
+	if c.FailureThreshold <= 0 {
+		return errInvalidFailureThreshold
+	}
+	if c.Cooldown <= 0 {
+		return errInvalidCooldown
+	}
+	return nil
+}
+
+func (c *ServiceConfig) validate() error {
+	for _, mapping := range c.Mappings {
+		if mapping.Pattern == "" || mapping.Service == "" {
+			return errInvalidServiceMapping
+		}
+		if _, err := regexp.Compile(mapping.Pattern); err != nil {
+			return fmt.Errorf("unable to compile service pattern: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *StreamAttributesConfig) validate() error {
+	for _, attribute := range c.Attributes {
+		if attribute == "" {
+			return errEmptyStreamAttribute
+		}
+	}
+	_, err := newStreamAttributeParser(c)
+	return err
+}
+
+func (c *S3Config) validate() error {
+	if c == nil || c.Bucket == "" {
+		return errNoS3Bucket
+	}
+	return nil
+}
+
+func (c *InsightsConfig) validate() error {
+	if c == nil || strings.TrimSpace(c.Query) == "" {
+		return errNoInsightsQuery
+	}
+	if len(c.LogGroups) == 0 {
+		return errNoInsightsLogGroups
+	}
+	if c.Timeout < 0 {
+		return errInvalidInsightsTimeout
+	}
+	if c.StatusInterval < 0 {
+		return errInvalidInsightsStatusInterval
+	}
+	if c.Limit < 0 || c.Limit > maxInsightsLimit {
+		return errInvalidInsightsLimit
+	}
+	return nil
+}
+
+func (c *AlarmsConfig) validate() error {
+	if c == nil {
+		return nil
+	}
+	for _, name := range c.Names {
+		if name == "" {
+			return errEmptyAlarmName
+		}
+	}
+	return nil
+}
+
 func (c *GroupConfig) validate() error {
 	if c.AutodiscoverConfig != nil && len(c.NamedConfigs) > 0 {
 		return errAutodiscoverAndNamedConfigured
 	}
 
+	for _, sc := range c.NamedConfigs {
+		if _, err := newStreamFilter(sc); err != nil {
+			return err
+		}
+	}
+
+	for group, st := range c.StartTokens {
+		if err := st.validate(group); err != nil {
+			return err
+		}
+	}
+
 	if c.AutodiscoverConfig != nil {
 		return validateAutodiscover(*c.AutodiscoverConfig)
 	}
@@ -137,9 +490,22 @@ func (c *GroupConfig) validate() error {
 	return nil
 }
 
+func (c *StartTokenConfig) validate(group string) error {
+	if group == "" || (c.NextToken == "" && c.StartTime == "") {
+		return errInvalidStartToken
+	}
+	if c.StartTime != "" {
+		if _, err := time.Parse(time.RFC3339, c.StartTime); err != nil {
+			return errInvalidStartTime
+		}
+	}
+	return nil
+}
+
 func validateAutodiscover(cfg AutodiscoverConfig) error {
 	if cfg.Limit <= 0 {
 		return errInvalidAutodiscoverLimit
 	}
-	return nil
+	_, err := newStreamFilter(cfg.Streams)
+	return err
 }
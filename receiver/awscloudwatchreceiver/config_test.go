@@ -72,6 +72,18 @@ func TestValidate(t *testing.T) {
 			},
 			expectedErr: errInvalidEventLimit,
 		},
+		{
+			name: "Invalid Poll Event Limit",
+			config: Config{
+				Region: "us-west-2",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					MaxEventsPerPoll:    -1,
+					PollInterval:        defaultPollInterval,
+				},
+			},
+			expectedErr: errInvalidPollEventLimit,
+		},
 		{
 			name: "Invalid Poll Interval",
 			config: Config{
@@ -137,6 +149,468 @@ func TestValidate(t *testing.T) {
 			},
 			expectedErr: errAutodiscoverAndNamedConfigured,
 		},
+		{
+			name: "Invalid Mode",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					Mode:                "stream",
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+				},
+			},
+			expectedErr: errInvalidMode,
+		},
+		{
+			name: "Negative Max Decompressed Size",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					MaxDecompressedSize: -1,
+				},
+			},
+			expectedErr: errInvalidMaxDecompressedSize,
+		},
+		{
+			name: "Negative Empty Poll Warn Threshold",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest:    defaultEventLimit,
+					PollInterval:           defaultPollInterval,
+					EmptyPollWarnThreshold: -1,
+				},
+			},
+			expectedErr: errInvalidEmptyPollThreshold,
+		},
+		{
+			name: "Invalid Body Format",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					BodyFormat:          "structured",
+				},
+			},
+			expectedErr: errInvalidBodyFormat,
+		},
+		{
+			name: "Invalid Min Event Timestamp",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					MinEventTimestamp:   "-24h",
+				},
+			},
+			expectedErr: errInvalidMinEventTimestamp,
+		},
+		{
+			name: "Invalid Exclude Pattern",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					ExcludePatterns:     []string{"ELB-HealthChecker/(", "^GET /health"},
+				},
+			},
+			expectedErr: errors.New(`unable to compile exclude pattern "ELB-HealthChecker/("`),
+		},
+		{
+			name: "S3 Mode Without Bucket",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					Mode:                modeS3,
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					S3:                  &S3Config{Prefix: "exports"},
+				},
+			},
+			expectedErr: errNoS3Bucket,
+		},
+		{
+			name: "Insights Mode Without Query",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					Mode:                modeInsights,
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					Insights:            &InsightsConfig{LogGroups: []string{"/aws/lambda/checkout"}},
+				},
+			},
+			expectedErr: errNoInsightsQuery,
+		},
+		{
+			name: "Insights Mode Without Log Groups",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					Mode:                modeInsights,
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					Insights:            &InsightsConfig{Query: "fields @message"},
+				},
+			},
+			expectedErr: errNoInsightsLogGroups,
+		},
+		{
+			name: "Insights Mode Negative Timeout",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					Mode:                modeInsights,
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					Insights: &InsightsConfig{
+						Query:     "fields @message",
+						LogGroups: []string{"/aws/lambda/checkout"},
+						Timeout:   -time.Second,
+					},
+				},
+			},
+			expectedErr: errInvalidInsightsTimeout,
+		},
+		{
+			name: "Insights Mode Negative Status Interval",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					Mode:                modeInsights,
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					Insights: &InsightsConfig{
+						Query:          "fields @message",
+						LogGroups:      []string{"/aws/lambda/checkout"},
+						StatusInterval: -time.Second,
+					},
+				},
+			},
+			expectedErr: errInvalidInsightsStatusInterval,
+		},
+		{
+			name: "Insights Mode Limit Too Large",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					Mode:                modeInsights,
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					Insights: &InsightsConfig{
+						Query:     "fields @message",
+						LogGroups: []string{"/aws/lambda/checkout"},
+						Limit:     maxInsightsLimit + 1,
+					},
+				},
+			},
+			expectedErr: errInvalidInsightsLimit,
+		},
+		{
+			name: "Alarms Mode Empty Alarm Name",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					Mode:                modeAlarms,
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					Alarms:              &AlarmsConfig{Names: []string{"checkout-latency-high", ""}},
+				},
+			},
+			expectedErr: errEmptyAlarmName,
+		},
+		{
+			name: "Severity Regex And JSON Field",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					Severity:            &SeverityConfig{Regex: `level=(\w+)`, JSONField: "level"},
+				},
+			},
+			expectedErr: errInvalidSeverityConfig,
+		},
+		{
+			name: "Severity Empty",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					Severity:            &SeverityConfig{},
+				},
+			},
+			expectedErr: errInvalidSeverityConfig,
+		},
+		{
+			name: "Severity Invalid Regex",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					Severity:            &SeverityConfig{Regex: `level=(\w+`},
+				},
+			},
+			expectedErr: errors.New("unable to compile severity regex"),
+		},
+		{
+			name: "Named Group Invalid Stream Include",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					Groups: GroupConfig{
+						NamedConfigs: map[string]StreamConfig{
+							"/aws/eks/dev-0/cluster": {Include: []string{`kube-(api`}},
+						},
+					},
+				},
+			},
+			expectedErr: errors.New("unable to compile stream_include regex"),
+		},
+		{
+			name: "Autodiscover Invalid Stream Exclude",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					Groups: GroupConfig{
+						AutodiscoverConfig: &AutodiscoverConfig{
+							Limit:   defaultLogGroupLimit,
+							Streams: StreamConfig{Exclude: []string{`[test`}},
+						},
+					},
+				},
+			},
+			expectedErr: errors.New("unable to compile stream_exclude regex"),
+		},
+		{
+			name: "Circuit Breaker Invalid Failure Threshold",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					CircuitBreaker:      &CircuitBreakerConfig{Cooldown: time.Minute},
+				},
+			},
+			expectedErr: errInvalidFailureThreshold,
+		},
+		{
+			name: "Circuit Breaker Invalid Cooldown",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					CircuitBreaker:      &CircuitBreakerConfig{FailureThreshold: 3},
+				},
+			},
+			expectedErr: errInvalidCooldown,
+		},
+		{
+			name: "Service Mapping Without Service",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					Services:            &ServiceConfig{Mappings: []ServiceMapping{{Pattern: "^/payments/"}}},
+				},
+			},
+			expectedErr: errInvalidServiceMapping,
+		},
+		{
+			name: "Service Mapping Invalid Pattern",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					Services:            &ServiceConfig{Mappings: []ServiceMapping{{Pattern: "^/payments/(", Service: "payments"}}},
+				},
+			},
+			expectedErr: errors.New("unable to compile service pattern"),
+		},
+		{
+			name: "Stream Attributes Without Named Group",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					StreamAttributes:    &StreamAttributesConfig{Pattern: `^(\d+)/(\w+)$`},
+				},
+			},
+			expectedErr: errNoStreamPatternGroups,
+		},
+		{
+			name: "Stream Attributes Empty Attribute",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					StreamAttributes:    &StreamAttributesConfig{Attributes: map[string]string{"instance": ""}},
+				},
+			},
+			expectedErr: errEmptyStreamAttribute,
+		},
+		{
+			name: "Valid Start Token",
+			config: Config{
+				Region: "us-west-2",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					Groups: GroupConfig{
+						NamedConfigs: map[string]StreamConfig{testLogGroupName: {}},
+						StartTokens: map[string]StartTokenConfig{
+							testLogGroupName: {NextToken: "f/3718", StartTime: "2022-11-01T00:00:00Z"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid Empty Start Token",
+			config: Config{
+				Region: "us-west-2",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					Groups: GroupConfig{
+						StartTokens: map[string]StartTokenConfig{testLogGroupName: {}},
+					},
+				},
+			},
+			expectedErr: errInvalidStartToken,
+		},
+		{
+			name: "Invalid Start Token Start Time",
+			config: Config{
+				Region: "us-west-2",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					Groups: GroupConfig{
+						StartTokens: map[string]StartTokenConfig{testLogGroupName: {StartTime: "yesterday"}},
+					},
+				},
+			},
+			expectedErr: errInvalidStartTime,
+		},
+		{
+			name: "S3 Mode Valid",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					Mode:                modeS3,
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					S3:                  &S3Config{Bucket: "my-bucket"},
+				},
+			},
+		},
+		{
+			name: "Insights Mode Valid",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					Mode:                modeInsights,
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					Insights: &InsightsConfig{
+						Query:     "fields @message",
+						LogGroups: []string{"/aws/lambda/checkout"},
+					},
+				},
+			},
+		},
+		{
+			name: "AWS HTTP Negative Timeout",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+				},
+				AWS: &AWSConfig{HTTP: HTTPConfig{Timeout: -time.Second}},
+			},
+			expectedErr: errInvalidHTTPTimeout,
+		},
+		{
+			name: "AWS HTTP Negative Max Retries",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+				},
+				AWS: &AWSConfig{HTTP: HTTPConfig{MaxRetries: aws.Int(-1)}},
+			},
+			expectedErr: errInvalidHTTPMaxRetries,
+		},
+		{
+			name: "AWS HTTP Negative Idle Connections",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+				},
+				AWS: &AWSConfig{HTTP: HTTPConfig{MaxIdleConnsPerHost: -1}},
+			},
+			expectedErr: errInvalidHTTPIdleConns,
+		},
+		{
+			name: "AWS HTTP Zero Max Retries",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+				},
+				AWS: &AWSConfig{HTTP: HTTPConfig{MaxRetries: aws.Int(0)}},
+			},
+		},
+		{
+			name: "Alarms Mode Valid Without Names",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					Mode:                modeAlarms,
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+				},
+			},
+		},
+		{
+			name: "Log Group Metrics Mode Valid",
+			config: Config{
+				Region: "us-east-1",
+				Logs: &LogsConfig{
+					Mode:                modeLogGroupMetrics,
+					MaxEventsPerRequest: defaultEventLimit,
+					PollInterval:        defaultPollInterval,
+					Groups: GroupConfig{
+						AutodiscoverConfig: &AutodiscoverConfig{
+							Limit:  defaultLogGroupLimit,
+							Prefix: "/aws/lambda",
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -154,6 +628,7 @@ func TestValidate(t *testing.T) {
 func TestLoadConfig(t *testing.T) {
 	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
 	require.NoError(t, err)
+	storageID := component.NewID("file_storage")
 
 	cases := []struct {
 		name           string
@@ -165,8 +640,11 @@ func TestLoadConfig(t *testing.T) {
 				ReceiverSettings: config.NewReceiverSettings(component.NewID(typeStr)),
 				Region:           "us-west-1",
 				Logs: &LogsConfig{
+					Mode:                modePoll,
 					PollInterval:        time.Minute,
 					MaxEventsPerRequest: defaultEventLimit,
+					MaxDecompressedSize: defaultMaxDecompressedSize,
+					BodyFormat:          bodyFormatRaw,
 					Groups: GroupConfig{
 						AutodiscoverConfig: &AutodiscoverConfig{
 							Limit: defaultLogGroupLimit,
@@ -181,8 +659,11 @@ func TestLoadConfig(t *testing.T) {
 				ReceiverSettings: config.NewReceiverSettings(component.NewID(typeStr)),
 				Region:           "us-west-1",
 				Logs: &LogsConfig{
+					Mode:                modePoll,
 					PollInterval:        time.Minute,
 					MaxEventsPerRequest: defaultEventLimit,
+					MaxDecompressedSize: defaultMaxDecompressedSize,
+					BodyFormat:          bodyFormatRaw,
 					Groups: GroupConfig{
 						AutodiscoverConfig: &AutodiscoverConfig{
 							Limit:  100,
@@ -198,8 +679,11 @@ func TestLoadConfig(t *testing.T) {
 				ReceiverSettings: config.NewReceiverSettings(component.NewID(typeStr)),
 				Region:           "us-west-1",
 				Logs: &LogsConfig{
+					Mode:                modePoll,
 					PollInterval:        time.Minute,
 					MaxEventsPerRequest: defaultEventLimit,
+					MaxDecompressedSize: defaultMaxDecompressedSize,
+					BodyFormat:          bodyFormatRaw,
 					Groups: GroupConfig{
 						AutodiscoverConfig: &AutodiscoverConfig{
 							Limit: 100,
@@ -217,8 +701,11 @@ func TestLoadConfig(t *testing.T) {
 				ReceiverSettings: config.NewReceiverSettings(component.NewID(typeStr)),
 				Region:           "us-west-1",
 				Logs: &LogsConfig{
+					Mode:                modePoll,
 					PollInterval:        time.Minute,
 					MaxEventsPerRequest: defaultEventLimit,
+					MaxDecompressedSize: defaultMaxDecompressedSize,
+					BodyFormat:          bodyFormatRaw,
 					Groups: GroupConfig{
 						AutodiscoverConfig: &AutodiscoverConfig{
 							Limit: 100,
@@ -236,9 +723,13 @@ func TestLoadConfig(t *testing.T) {
 				ReceiverSettings: config.NewReceiverSettings(component.NewID(typeStr)),
 				Profile:          "my-profile",
 				Region:           "us-west-1",
+				StorageID:        &storageID,
 				Logs: &LogsConfig{
+					Mode:                modePoll,
 					PollInterval:        5 * time.Minute,
 					MaxEventsPerRequest: defaultEventLimit,
+					MaxDecompressedSize: defaultMaxDecompressedSize,
+					BodyFormat:          bodyFormatRaw,
 					Groups: GroupConfig{
 						NamedConfigs: map[string]StreamConfig{
 							"/aws/eks/dev-0/cluster": {},
@@ -254,18 +745,146 @@ func TestLoadConfig(t *testing.T) {
 				Profile:          "my-profile",
 				Region:           "us-west-1",
 				Logs: &LogsConfig{
+					Mode:                modePoll,
 					PollInterval:        5 * time.Minute,
 					MaxEventsPerRequest: defaultEventLimit,
+					MaxDecompressedSize: defaultMaxDecompressedSize,
+					BodyFormat:          bodyFormatRaw,
+					Groups: GroupConfig{
+						NamedConfigs: map[string]StreamConfig{
+							"/aws/eks/dev-0/cluster": {
+								Names:   []*string{aws.String("kube-apiserver-ea9c831555adca1815ae04b87661klasdj")},
+								Exclude: []string{".*test.*"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "start-token",
+			expectedConfig: &Config{
+				ReceiverSettings: config.NewReceiverSettings(component.NewID(typeStr)),
+				Region:           "us-west-1",
+				StorageID:        &storageID,
+				Logs: &LogsConfig{
+					Mode:                modePoll,
+					PollInterval:        time.Minute,
+					MaxEventsPerRequest: defaultEventLimit,
+					MaxDecompressedSize: defaultMaxDecompressedSize,
+					BodyFormat:          bodyFormatRaw,
 					Groups: GroupConfig{
 						NamedConfigs: map[string]StreamConfig{
+							"/aws/eks/dev-0/cluster": {},
+						},
+						StartTokens: map[string]StartTokenConfig{
 							"/aws/eks/dev-0/cluster": {
-								Names: []*string{aws.String("kube-apiserver-ea9c831555adca1815ae04b87661klasdj")},
+								NextToken: "f/37182698236483840043627617306674548952564681411567517696",
+								StartTime: "2022-11-01T00:00:00Z",
 							},
 						},
 					},
 				},
 			},
 		},
+		{
+			name: "s3",
+			expectedConfig: &Config{
+				ReceiverSettings: config.NewReceiverSettings(component.NewID(typeStr)),
+				Region:           "us-west-1",
+				Logs: &LogsConfig{
+					Mode:                modeS3,
+					PollInterval:        5 * time.Minute,
+					MaxEventsPerRequest: defaultEventLimit,
+					MaxDecompressedSize: defaultMaxDecompressedSize,
+					BodyFormat:          bodyFormatRaw,
+					Groups: GroupConfig{
+						AutodiscoverConfig: &AutodiscoverConfig{
+							Limit: defaultLogGroupLimit,
+						},
+					},
+					S3: &S3Config{
+						Bucket: "my-log-exports",
+						Prefix: "exports/eks",
+					},
+				},
+			},
+		},
+		{
+			name: "insights",
+			expectedConfig: &Config{
+				ReceiverSettings: config.NewReceiverSettings(component.NewID(typeStr)),
+				Region:           "us-west-1",
+				Logs: &LogsConfig{
+					Mode:                modeInsights,
+					PollInterval:        5 * time.Minute,
+					MaxEventsPerRequest: defaultEventLimit,
+					MaxDecompressedSize: defaultMaxDecompressedSize,
+					BodyFormat:          bodyFormatRaw,
+					Groups: GroupConfig{
+						AutodiscoverConfig: &AutodiscoverConfig{
+							Limit: defaultLogGroupLimit,
+						},
+					},
+					Insights: &InsightsConfig{
+						Query:     "fields @timestamp, @message | filter @message like /ERROR/",
+						LogGroups: []string{"/aws/lambda/checkout"},
+						Timeout:   2 * time.Minute,
+						Limit:     5000,
+					},
+				},
+			},
+		},
+		{
+			name: "alarms",
+			expectedConfig: &Config{
+				ReceiverSettings: config.NewReceiverSettings(component.NewID(typeStr)),
+				Region:           "us-west-1",
+				Logs: &LogsConfig{
+					Mode:                modeAlarms,
+					PollInterval:        5 * time.Minute,
+					MaxEventsPerRequest: defaultEventLimit,
+					MaxDecompressedSize: defaultMaxDecompressedSize,
+					BodyFormat:          bodyFormatRaw,
+					Groups: GroupConfig{
+						AutodiscoverConfig: &AutodiscoverConfig{
+							Limit: defaultLogGroupLimit,
+						},
+					},
+					Alarms: &AlarmsConfig{
+						Names: []string{"checkout-latency-high", "checkout-errors"},
+					},
+				},
+			},
+		},
+		{
+			name: "aws-http",
+			expectedConfig: &Config{
+				ReceiverSettings: config.NewReceiverSettings(component.NewID(typeStr)),
+				Region:           "us-west-1",
+				Logs: &LogsConfig{
+					Mode:                modePoll,
+					PollInterval:        time.Minute,
+					MaxEventsPerRequest: defaultEventLimit,
+					MaxDecompressedSize: defaultMaxDecompressedSize,
+					BodyFormat:          bodyFormatRaw,
+					Groups: GroupConfig{
+						AutodiscoverConfig: &AutodiscoverConfig{
+							Limit: defaultLogGroupLimit,
+						},
+					},
+				},
+				AWS: &AWSConfig{
+					HTTP: HTTPConfig{
+						Timeout:             10 * time.Second,
+						MaxRetries:          aws.Int(5),
+						MaxIdleConns:        50,
+						MaxIdleConnsPerHost: 10,
+						IdleConnTimeout:     time.Minute,
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+)
+
+// gzipBase64Prefix is the base64 encoding of the gzip magic number followed by the deflate method,
+// which every base64 encoded gzip stream starts with.
+const gzipBase64Prefix = "H4sI"
+
+var errDecompressedSizeExceeded = errors.New("decompressed size exceeds the configured max_decompressed_size")
+
+// maxSizeReader fails reading once more than the remaining bytes have been read from the underlying
+// reader, guarding against decompression bombs. A limit of 0 means there is no limit.
+type maxSizeReader struct {
+	r         io.Reader
+	remaining int64
+	unlimited bool
+}
+
+func newMaxSizeReader(r io.Reader, limit int64) *maxSizeReader {
+	return &maxSizeReader{r: r, remaining: limit, unlimited: limit <= 0}
+}
+
+func (m *maxSizeReader) Read(p []byte) (int, error) {
+	n, err := m.r.Read(p)
+	if m.unlimited {
+		return n, err
+	}
+	m.remaining -= int64(n)
+	if m.remaining < 0 {
+		return n, errDecompressedSizeExceeded
+	}
+	return n, err
+}
+
+// decompressMessage returns the decompressed content of a message holding base64 encoded gzip data,
+// which is how compressed payloads are written as Cloudwatch Logs events. Any other message is
+// returned as is. maxSize is the largest decompressed size that is accepted, 0 means no limit.
+func decompressMessage(message string, maxSize int64) (string, error) {
+	if !strings.HasPrefix(message, gzipBase64Prefix) {
+		return message, nil
+	}
+	compressed, err := base64.StdEncoding.DecodeString(message)
+	if err != nil {
+		// not base64 after all, the message merely starts with the same characters
+		return message, nil
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return message, nil
+	}
+	defer gz.Close()
+
+	decompressed, err := io.ReadAll(newMaxSizeReader(gz, maxSize))
+	if err != nil {
+		return "", err
+	}
+	return string(decompressed), nil
+}
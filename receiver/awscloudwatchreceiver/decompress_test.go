@@ -0,0 +1,136 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+)
+
+func TestDecompressMessage(t *testing.T) {
+	compressed := gzipBase64(t, testLogStreamMessage)
+
+	cases := []struct {
+		name        string
+		message     string
+		maxSize     int64
+		expected    string
+		expectedErr error
+	}{
+		{name: "plain", message: testLogStreamMessage, maxSize: 1024, expected: testLogStreamMessage},
+		{name: "gzip", message: compressed, maxSize: 1024, expected: testLogStreamMessage},
+		{name: "gzip exactly at the limit", message: compressed, maxSize: int64(len(testLogStreamMessage)), expected: testLogStreamMessage},
+		{name: "gzip without limit", message: compressed, maxSize: 0, expected: testLogStreamMessage},
+		{name: "gzip exceeding the limit", message: compressed, maxSize: 16, expectedErr: errDecompressedSizeExceeded},
+		{name: "prefix only", message: "H4sI is not gzip", maxSize: 1024, expected: "H4sI is not gzip"},
+		{name: "base64 without gzip", message: "H4sIAAAA", maxSize: 1024, expected: "H4sIAAAA"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			message, err := decompressMessage(tc.message, tc.maxSize)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, message)
+		})
+	}
+}
+
+func TestProcessEventsGzip(t *testing.T) {
+	output := &cloudwatchlogs.FilterLogEventsOutput{
+		Events: []*cloudwatchlogs.FilteredLogEvent{
+			{
+				EventId:       aws.String("compressed"),
+				LogStreamName: aws.String(testLogStreamName),
+				Message:       aws.String(gzipBase64(t, testLogStreamMessage)),
+				Timestamp:     aws.Int64(testTimeStamp),
+			},
+			{
+				EventId:       aws.String("bomb"),
+				LogStreamName: aws.String(testLogStreamName),
+				Message:       aws.String(gzipBase64(t, strings.Repeat("a", 4096))),
+				Timestamp:     aws.Int64(testTimeStamp),
+			},
+		},
+	}
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.MaxDecompressedSize = 1024
+	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), &consumertest.LogsSink{})
+
+	logs, _ := logsRcvr.processEvents(pcommon.NewTimestampFromTime(time.Now()), testLogGroupName, output)
+	// the event decompressing to more than the limit is rejected
+	require.Equal(t, 1, logs.LogRecordCount())
+	record := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, testLogStreamMessage, record.Body().Str())
+	id, ok := record.Attributes().Get("id")
+	require.True(t, ok)
+	require.Equal(t, "compressed", id.Str())
+}
+
+func TestS3ModeMaxDecompressedSize(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.Mode = modeS3
+	cfg.Logs.S3 = &S3Config{Bucket: testS3Bucket, Prefix: testS3Prefix}
+	cfg.Logs.MaxDecompressedSize = 1024
+
+	sink := &consumertest.LogsSink{}
+	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+	mc := &mockS3Client{}
+	mc.On("ListObjectsV2WithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&s3.ListObjectsV2Output{
+			Contents:    []*s3.Object{{Key: aws.String(testS3KeyOne)}},
+			IsTruncated: aws.Bool(false),
+		}, nil)
+	mc.On("GetObjectWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&s3.GetObjectOutput{
+			Body: gzipBody(t, "2022-10-07T18:10:51.014Z "+strings.Repeat("a", 4096)+"\n"),
+		}, nil)
+	logsRcvr.s3Client = mc
+
+	require.ErrorIs(t, logsRcvr.pollS3(context.Background()), errDecompressedSizeExceeded)
+	require.Zero(t, sink.LogRecordCount())
+
+	// the rejected object is not read again
+	require.NoError(t, logsRcvr.pollS3(context.Background()))
+	mc.AssertNumberOfCalls(t, "GetObjectWithContext", 1)
+}
+
+func gzipBase64(t *testing.T, contents string) string {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(contents))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
@@ -0,0 +1,194 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// emfNamespaceAttribute is the data point attribute holding the namespace of an embedded metric
+const emfNamespaceAttribute = "cloudwatch.namespace"
+
+// emfUnits maps the units of the embedded metric format to their UCUM equivalent
+var emfUnits = map[string]string{
+	"Seconds":          "s",
+	"Microseconds":     "us",
+	"Milliseconds":     "ms",
+	"Bytes":            "By",
+	"Kilobytes":        "kBy",
+	"Megabytes":        "MBy",
+	"Gigabytes":        "GBy",
+	"Terabytes":        "TBy",
+	"Bits":             "bit",
+	"Kilobits":         "kbit",
+	"Megabits":         "Mbit",
+	"Gigabits":         "Gbit",
+	"Terabits":         "Tbit",
+	"Percent":          "%",
+	"Count":            "1",
+	"Bytes/Second":     "By/s",
+	"Kilobytes/Second": "kBy/s",
+	"Megabytes/Second": "MBy/s",
+	"Gigabytes/Second": "GBy/s",
+	"Terabytes/Second": "TBy/s",
+	"Bits/Second":      "bit/s",
+	"Kilobits/Second":  "kbit/s",
+	"Megabits/Second":  "Mbit/s",
+	"Gigabits/Second":  "Gbit/s",
+	"Terabits/Second":  "Tbit/s",
+	"Count/Second":     "1/s",
+	"None":             "",
+}
+
+// emfMetadata is the `_aws` block of an event in the embedded metric format,
+// see https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch_Embedded_Metric_Format_Specification.html
+type emfMetadata struct {
+	Timestamp         int64          `json:"Timestamp"`
+	CloudWatchMetrics []emfDirective `json:"CloudWatchMetrics"`
+}
+
+type emfDirective struct {
+	Namespace  string                `json:"Namespace"`
+	Dimensions [][]string            `json:"Dimensions"`
+	Metrics    []emfMetricDefinition `json:"Metrics"`
+}
+
+type emfMetricDefinition struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit"`
+}
+
+// emfEvent is a parsed event in the embedded metric format
+type emfEvent struct {
+	metadata emfMetadata
+	members  map[string]json.RawMessage
+}
+
+// parseEMF parses the message of an event, returning false if it is not in the embedded metric format.
+func parseEMF(message string) (*emfEvent, bool) {
+	// avoid decoding messages that cannot be embedded metric format events
+	if !strings.HasPrefix(strings.TrimSpace(message), "{") || !strings.Contains(message, "CloudWatchMetrics") {
+		return nil, false
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(message), &members); err != nil {
+		return nil, false
+	}
+	raw, ok := members["_aws"]
+	if !ok {
+		return nil, false
+	}
+	var metadata emfMetadata
+	if err := json.Unmarshal(raw, &metadata); err != nil || len(metadata.CloudWatchMetrics) == 0 {
+		return nil, false
+	}
+	return &emfEvent{metadata: metadata, members: members}, true
+}
+
+// appendMetrics appends a gauge for every metric defined by the event, with a data point for each of its
+// values and dimension sets. eventTimestamp is used when the event does not define its own timestamp.
+func (e *emfEvent) appendMetrics(metrics pmetric.MetricSlice, eventTimestamp time.Time) error {
+	ts := eventTimestamp
+	if e.metadata.Timestamp != 0 {
+		ts = time.UnixMilli(e.metadata.Timestamp)
+	}
+	timestamp := pcommon.NewTimestampFromTime(ts)
+
+	for _, directive := range e.metadata.CloudWatchMetrics {
+		dimensionSets, err := e.dimensionSets(directive.Dimensions)
+		if err != nil {
+			return err
+		}
+		for _, definition := range directive.Metrics {
+			values, err := e.values(definition.Name)
+			if err != nil {
+				return err
+			}
+
+			metric := metrics.AppendEmpty()
+			metric.SetName(definition.Name)
+			if unit, ok := emfUnits[definition.Unit]; ok {
+				metric.SetUnit(unit)
+			} else {
+				metric.SetUnit(definition.Unit)
+			}
+			points := metric.SetEmptyGauge().DataPoints()
+			for _, dimensions := range dimensionSets {
+				for _, value := range values {
+					point := points.AppendEmpty()
+					point.SetTimestamp(timestamp)
+					point.SetDoubleValue(value)
+					point.Attributes().PutStr(emfNamespaceAttribute, directive.Namespace)
+					for key, dimension := range dimensions {
+						point.Attributes().PutStr(key, dimension)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// dimensionSets resolves the values of the dimensions of every dimension set, a directive
+// without dimensions results in a single set without any dimension.
+func (e *emfEvent) dimensionSets(dimensions [][]string) ([]map[string]string, error) {
+	if len(dimensions) == 0 {
+		return []map[string]string{{}}, nil
+	}
+	sets := make([]map[string]string, 0, len(dimensions))
+	for _, keys := range dimensions {
+		set := make(map[string]string, len(keys))
+		for _, key := range keys {
+			raw, ok := e.members[key]
+			if !ok {
+				return nil, fmt.Errorf("dimension %q is not defined by the event", key)
+			}
+			var value interface{}
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return nil, fmt.Errorf("unable to decode dimension %q: %w", key, err)
+			}
+			if s, ok := value.(string); ok {
+				set[key] = s
+			} else {
+				set[key] = fmt.Sprint(value)
+			}
+		}
+		sets = append(sets, set)
+	}
+	return sets, nil
+}
+
+// values returns the values of the metric, which are either a single number or an array of numbers
+func (e *emfEvent) values(name string) ([]float64, error) {
+	raw, ok := e.members[name]
+	if !ok {
+		return nil, fmt.Errorf("metric %q is not defined by the event", name)
+	}
+	var value float64
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return []float64{value}, nil
+	}
+	var values []float64
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, fmt.Errorf("metric %q must be a number or an array of numbers: %w", name, err)
+	}
+	return values, nil
+}
@@ -0,0 +1,148 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+const testEMFEvent = `{
+  "_aws": {
+    "Timestamp": 1665166251014,
+    "CloudWatchMetrics": [
+      {
+        "Namespace": "checkout",
+        "Dimensions": [["service"], ["service", "functionVersion"]],
+        "Metrics": [
+          {"Name": "latency", "Unit": "Milliseconds"},
+          {"Name": "orders", "Unit": "Count"}
+        ]
+      }
+    ]
+  },
+  "service": "payments",
+  "functionVersion": 3,
+  "latency": [12.5, 30],
+  "orders": 2,
+  "requestId": "989ffbf8-9ace-4817-a57c-e4dd734019ee"
+}`
+
+func TestParseEMF(t *testing.T) {
+	event, ok := parseEMF(testEMFEvent)
+	require.True(t, ok)
+
+	metrics := pmetric.NewMetricSlice()
+	require.NoError(t, event.appendMetrics(metrics, time.Now()))
+	require.Equal(t, 2, metrics.Len())
+	ts := pcommon.NewTimestampFromTime(time.UnixMilli(testTimeStamp))
+
+	latency := metrics.At(0)
+	require.Equal(t, "latency", latency.Name())
+	require.Equal(t, "ms", latency.Unit())
+	points := latency.Gauge().DataPoints()
+	// every value is reported for every dimension set
+	require.Equal(t, 4, points.Len())
+	require.Equal(t, 12.5, points.At(0).DoubleValue())
+	require.Equal(t, ts, points.At(0).Timestamp())
+	require.Equal(t, map[string]interface{}{"cloudwatch.namespace": "checkout", "service": "payments"}, points.At(0).Attributes().AsRaw())
+	require.Equal(t, 30.0, points.At(1).DoubleValue())
+	require.Equal(t, 12.5, points.At(2).DoubleValue())
+	require.Equal(t, map[string]interface{}{"cloudwatch.namespace": "checkout", "service": "payments", "functionVersion": "3"}, points.At(2).Attributes().AsRaw())
+
+	orders := metrics.At(1)
+	require.Equal(t, "orders", orders.Name())
+	require.Equal(t, "1", orders.Unit())
+	require.Equal(t, 2, orders.Gauge().DataPoints().Len())
+	require.Equal(t, 2.0, orders.Gauge().DataPoints().At(0).DoubleValue())
+}
+
+func TestParseEMFNotEmbeddedMetricFormat(t *testing.T) {
+	for _, message := range []string{
+		testLogStreamMessage,
+		`{"level": "info", "msg": "CloudWatchMetrics"}`,
+		`{"_aws": {"CloudWatchMetrics": []}}`,
+		`{"_aws": "CloudWatchMetrics"}`,
+	} {
+		_, ok := parseEMF(message)
+		require.False(t, ok, message)
+	}
+}
+
+func TestParseEMFInvalidMembers(t *testing.T) {
+	event, ok := parseEMF(`{"_aws": {"CloudWatchMetrics": [{"Namespace": "ns", "Dimensions": [["missing"]], "Metrics": [{"Name": "m"}]}]}, "m": 1}`)
+	require.True(t, ok)
+	require.EqualError(t, event.appendMetrics(pmetric.NewMetricSlice(), time.Now()), `dimension "missing" is not defined by the event`)
+
+	event, ok = parseEMF(`{"_aws": {"CloudWatchMetrics": [{"Namespace": "ns", "Metrics": [{"Name": "m"}]}]}, "m": "one"}`)
+	require.True(t, ok)
+	require.ErrorContains(t, event.appendMetrics(pmetric.NewMetricSlice(), time.Now()), `metric "m" must be a number or an array of numbers`)
+}
+
+func TestProcessEventsEMF(t *testing.T) {
+	output := &cloudwatchlogs.FilterLogEventsOutput{
+		Events: []*cloudwatchlogs.FilteredLogEvent{
+			{
+				EventId:       aws.String("emf"),
+				LogStreamName: aws.String(testLogStreamName),
+				Message:       aws.String(testEMFEvent),
+				Timestamp:     aws.Int64(testTimeStamp),
+			},
+			{
+				EventId:       aws.String("plain"),
+				LogStreamName: aws.String(testLogStreamName),
+				Message:       aws.String(testLogStreamMessage),
+				Timestamp:     aws.Int64(testTimeStamp),
+			},
+		},
+	}
+
+	cases := []struct {
+		name            string
+		emf             *EMFConfig
+		expectedLogs    int
+		expectedMetrics int
+	}{
+		{name: "disabled", emf: nil, expectedLogs: 2, expectedMetrics: 0},
+		{name: "metrics only", emf: &EMFConfig{}, expectedLogs: 1, expectedMetrics: 2},
+		{name: "keep logs", emf: &EMFConfig{KeepLogs: true}, expectedLogs: 2, expectedMetrics: 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Region = "us-west-1"
+			cfg.Logs.EMF = tc.emf
+			logsRcvr := newLogsReceiver(cfg, zap.NewNop(), &consumertest.LogsSink{})
+
+			logs, metrics := logsRcvr.processEvents(pcommon.NewTimestampFromTime(time.Now()), testLogGroupName, output)
+			require.Equal(t, tc.expectedLogs, logs.LogRecordCount())
+			require.Equal(t, tc.expectedMetrics, metrics.MetricCount())
+			if tc.expectedMetrics == 0 {
+				return
+			}
+			group, ok := metrics.ResourceMetrics().At(0).Resource().Attributes().Get("cloudwatch.log.group.name")
+			require.True(t, ok)
+			require.Equal(t, testLogGroupName, group.Str())
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+)
+
+var (
+	emptyPolls = stats.Int64(
+		"awscloudwatchreceiver/cloudwatch_empty_polls",
+		"Number of polls of a log group that read no events",
+		stats.UnitDimensionless)
+
+	emptyPollsView = &view.View{
+		Name:        "receiver/" + typeStr + "/cloudwatch_empty_polls",
+		Description: emptyPolls.Description(),
+		Measure:     emptyPolls,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{receiverNameKey, logGroupKey},
+	}
+)
+
+// recordPolledGroups records the polls of the groups that read no events, by the number of events read from every
+// polled group. A warning is logged once a group read no events for emptyPollWarnThreshold consecutive polls.
+func (l *logsReceiver) recordPolledGroups(polled map[string]int) {
+	for group, count := range polled {
+		if count > 0 {
+			delete(l.emptyPolls, group)
+			continue
+		}
+		_ = stats.RecordWithTags(
+			context.Background(),
+			[]tag.Mutator{tag.Upsert(receiverNameKey, l.id.String()), tag.Upsert(logGroupKey, group)},
+			emptyPolls.M(1),
+		)
+		l.emptyPolls[group]++
+		if l.emptyPollWarnThreshold > 0 && l.emptyPolls[group] == l.emptyPollWarnThreshold {
+			l.logger.Warn("the log group returned no events for consecutive polls, its configuration may be wrong",
+				zap.String("log group", group), zap.Int("empty polls", l.emptyPolls[group]))
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestPollEmptyGroups(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ReceiverSettings.SetIDName(t.Name())
+	cfg.Region = "us-west-1"
+	cfg.Logs.EmptyPollWarnThreshold = 3
+	cfg.Logs.Groups = GroupConfig{
+		NamedConfigs: map[string]StreamConfig{
+			"empty":   {},
+			"healthy": {},
+			"failing": {},
+		},
+	}
+	core, observedLogs := observer.New(zapcore.WarnLevel)
+	logsRcvr := newLogsReceiver(cfg, zap.New(core), &consumertest.LogsSink{})
+	mc := &mockClient{}
+	mc.On("FilterLogEventsWithContext", mock.Anything, groupInput("empty"), mock.Anything).Return(
+		&cloudwatchlogs.FilterLogEventsOutput{}, nil)
+	mc.On("FilterLogEventsWithContext", mock.Anything, groupInput("healthy"), mock.Anything).Return(
+		&cloudwatchlogs.FilterLogEventsOutput{
+			Events: []*cloudwatchlogs.FilteredLogEvent{
+				{
+					EventId:       aws.String(testEventID),
+					LogStreamName: aws.String(testLogStreamName),
+					Message:       aws.String(testLogStreamMessage),
+					Timestamp:     aws.Int64(testTimeStamp),
+				},
+			},
+		}, nil)
+	mc.On("FilterLogEventsWithContext", mock.Anything, groupInput("failing"), mock.Anything).Return(
+		(*cloudwatchlogs.FilterLogEventsOutput)(nil), awserr.New(cloudwatchlogs.ErrCodeServiceUnavailableException, "try again", nil))
+	logsRcvr.client = mc
+
+	warnings := func() int {
+		return observedLogs.FilterMessage("the log group returned no events for consecutive polls, its configuration may be wrong").Len()
+	}
+	for i := 1; i < 3; i++ {
+		require.Error(t, logsRcvr.poll(context.Background()))
+		require.Equal(t, int64(i), emptyPollCount(t, cfg.ID().String(), "empty"))
+		require.Equal(t, 0, warnings())
+	}
+
+	// the warning fires once the threshold is reached, and only once
+	require.Error(t, logsRcvr.poll(context.Background()))
+	require.Equal(t, int64(3), emptyPollCount(t, cfg.ID().String(), "empty"))
+	require.Equal(t, 1, warnings())
+	require.Equal(t, map[string]interface{}{"log group": "empty", "empty polls": int64(3)}, observedLogs.All()[0].ContextMap())
+	require.Error(t, logsRcvr.poll(context.Background()))
+	require.Equal(t, int64(4), emptyPollCount(t, cfg.ID().String(), "empty"))
+	require.Equal(t, 1, warnings())
+
+	// groups that read events or failed are not empty polls
+	require.Equal(t, int64(0), emptyPollCount(t, cfg.ID().String(), "healthy"))
+	require.Equal(t, int64(0), emptyPollCount(t, cfg.ID().String(), "failing"))
+	require.Equal(t, map[string]int{"empty": 4}, logsRcvr.emptyPolls)
+}
+
+func TestPollEmptyGroupsResetOnEvents(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ReceiverSettings.SetIDName(t.Name())
+	cfg.Region = "us-west-1"
+	cfg.Logs.EmptyPollWarnThreshold = 2
+	cfg.Logs.Groups = GroupConfig{
+		NamedConfigs: map[string]StreamConfig{
+			testLogGroupName: {},
+		},
+	}
+	core, observedLogs := observer.New(zapcore.WarnLevel)
+	logsRcvr := newLogsReceiver(cfg, zap.New(core), &consumertest.LogsSink{})
+	mc := &mockClient{}
+	mc.On("FilterLogEventsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&cloudwatchlogs.FilterLogEventsOutput{}, nil).Once()
+	mc.On("FilterLogEventsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&cloudwatchlogs.FilterLogEventsOutput{
+			Events: []*cloudwatchlogs.FilteredLogEvent{
+				{
+					EventId:       aws.String(testEventID),
+					LogStreamName: aws.String(testLogStreamName),
+					Message:       aws.String(testLogStreamMessage),
+					Timestamp:     aws.Int64(testTimeStamp),
+				},
+			},
+		}, nil).Once()
+	mc.On("FilterLogEventsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&cloudwatchlogs.FilterLogEventsOutput{}, nil)
+	logsRcvr.client = mc
+
+	// the events of the second poll restart the count of consecutive empty polls
+	for i := 0; i < 3; i++ {
+		require.NoError(t, logsRcvr.poll(context.Background()))
+	}
+	require.Equal(t, int64(2), emptyPollCount(t, cfg.ID().String(), testLogGroupName))
+	require.Equal(t, 0, observedLogs.Len())
+	require.NoError(t, logsRcvr.poll(context.Background()))
+	require.Equal(t, 1, observedLogs.Len())
+}
+
+// emptyPollCount returns the number of empty polls of the log group, 0 if none was recorded
+func emptyPollCount(t *testing.T, receiverName string, group string) int64 {
+	rows, err := view.RetrieveData(emptyPollsView.Name)
+	require.NoError(t, err)
+	for _, row := range rows {
+		tags := map[string]string{}
+		for _, tag := range row.Tags {
+			tags[tag.Key.Name()] = tag.Value
+		}
+		if tags[receiverNameKey.Name()] == receiverName && tags[logGroupKey.Name()] == group {
+			return row.Data.(*view.CountData).Value
+		}
+	}
+	return 0
+}
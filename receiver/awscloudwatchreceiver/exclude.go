@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var (
+	excludedEvents = stats.Int64(
+		"awscloudwatchreceiver/cloudwatch_excluded_events",
+		"Number of events of a log group dropped for matching an exclude pattern",
+		stats.UnitDimensionless)
+
+	excludedEventsView = &view.View{
+		Name:        "receiver/" + typeStr + "/cloudwatch_excluded_events",
+		Description: excludedEvents.Description(),
+		Measure:     excludedEvents,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{receiverNameKey, logGroupKey},
+	}
+)
+
+// messageExcluder drops the events whose message matches any of the exclude patterns, such as the noise of
+// load balancer health checks, for the cases the filter patterns of the CloudWatch API cannot express
+type messageExcluder struct {
+	patterns []*regexp.Regexp
+}
+
+// newMessageExcluder compiles the exclude patterns, nil is returned if no pattern is configured
+func newMessageExcluder(patterns []string) (*messageExcluder, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	e := &messageExcluder{}
+	for _, p := range patterns {
+		pattern, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compile exclude pattern %q: %w", p, err)
+		}
+		e.patterns = append(e.patterns, pattern)
+	}
+	return e, nil
+}
+
+// excluded returns true if the message matches any of the exclude patterns. A nil messageExcluder excludes nothing.
+func (e *messageExcluder) excluded(message string) bool {
+	if e == nil {
+		return false
+	}
+	for _, pattern := range e.patterns {
+		if pattern.MatchString(message) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordExcludedEvents records the number of events of the group dropped for matching an exclude pattern
+func (l *logsReceiver) recordExcludedEvents(group string, count int) {
+	if count == 0 {
+		return
+	}
+	_ = stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{tag.Upsert(receiverNameKey, l.id.String()), tag.Upsert(logGroupKey, group)},
+		excludedEvents.M(int64(count)),
+	)
+}
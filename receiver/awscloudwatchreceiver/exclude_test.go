@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+func TestPollExcludePatterns(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ReceiverSettings.SetIDName(t.Name())
+	cfg.Region = "us-west-1"
+	cfg.Logs.ExcludePatterns = []string{`ELB-HealthChecker/\d`, `"GET /health(z)? HTTP`}
+	cfg.Logs.Groups = GroupConfig{
+		NamedConfigs: map[string]StreamConfig{
+			testLogGroupName: {},
+		},
+	}
+	messages := []string{
+		`10.0.1.17 - - [01/Nov/2022:00:00:00 +0000] "GET /healthz HTTP/1.1" 200 2 "-" "kube-probe/1.24"`,
+		`10.0.1.23 - - [01/Nov/2022:00:00:01 +0000] "GET /orders/42 HTTP/1.1" 200 512 "-" "curl/7.85.0"`,
+		`10.0.1.5 - - [01/Nov/2022:00:00:02 +0000] "GET / HTTP/1.1" 200 12 "-" "ELB-HealthChecker/2.0"`,
+		`payment 42 declined: insufficient funds`,
+	}
+	events := make([]*cloudwatchlogs.FilteredLogEvent, 0, len(messages))
+	for _, message := range messages {
+		events = append(events, &cloudwatchlogs.FilteredLogEvent{
+			EventId:       aws.String(testEventID),
+			LogStreamName: aws.String(testLogStreamName),
+			Message:       aws.String(message),
+			Timestamp:     aws.Int64(testTimeStamp),
+		})
+	}
+	sink := &consumertest.LogsSink{}
+	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+	mc := &mockClient{}
+	mc.On("FilterLogEventsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&cloudwatchlogs.FilterLogEventsOutput{Events: events}, nil)
+	logsRcvr.client = mc
+
+	// the health checks are dropped, the other events are emitted in order
+	require.NoError(t, logsRcvr.poll(context.Background()))
+	var bodies []string
+	for _, logs := range sink.AllLogs() {
+		for i := 0; i < logs.ResourceLogs().Len(); i++ {
+			bodies = append(bodies, logs.ResourceLogs().At(i).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+		}
+	}
+	require.Equal(t, []string{messages[1], messages[3]}, bodies)
+	require.Equal(t, float64(2), excludedEventCount(t, cfg.ID().String(), testLogGroupName))
+
+	require.NoError(t, logsRcvr.poll(context.Background()))
+	require.Equal(t, 4, sink.LogRecordCount())
+	require.Equal(t, float64(4), excludedEventCount(t, cfg.ID().String(), testLogGroupName))
+}
+
+func TestMessageExcluder(t *testing.T) {
+	excluder, err := newMessageExcluder(nil)
+	require.NoError(t, err)
+	require.Nil(t, excluder)
+	require.False(t, excluder.excluded("GET /health"))
+
+	excluder, err = newMessageExcluder([]string{"^GET /health", "ELB-HealthChecker"})
+	require.NoError(t, err)
+	require.True(t, excluder.excluded("GET /health HTTP/1.1"))
+	require.True(t, excluder.excluded(`"user_agent":"ELB-HealthChecker/2.0"`))
+	require.False(t, excluder.excluded("POST /health HTTP/1.1"))
+
+	_, err = newMessageExcluder([]string{"("})
+	require.ErrorContains(t, err, `unable to compile exclude pattern "("`)
+}
+
+// excludedEventCount returns the number of events of the log group dropped by the exclude patterns, 0 if none was recorded
+func excludedEventCount(t *testing.T, receiverName string, group string) float64 {
+	rows, err := view.RetrieveData(excludedEventsView.Name)
+	require.NoError(t, err)
+	for _, row := range rows {
+		tags := map[string]string{}
+		for _, tag := range row.Tags {
+			tags[tag.Key.Name()] = tag.Value
+		}
+		if tags[receiverNameKey.Name()] == receiverName && tags[logGroupKey.Name()] == group {
+			return row.Data.(*view.SumData).Value
+		}
+	}
+	return 0
+}
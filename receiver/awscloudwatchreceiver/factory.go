@@ -20,6 +20,8 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/sharedcomponent"
 )
 
 const (
@@ -33,6 +35,7 @@ func NewFactory() component.ReceiverFactory {
 		typeStr,
 		createDefaultConfig,
 		component.WithLogsReceiver(createLogsReceiver, stabilityLevel),
+		component.WithMetricsReceiver(createMetricsReceiver, stabilityLevel),
 	)
 }
 
@@ -43,16 +46,45 @@ func createLogsReceiver(
 	consumer consumer.Logs,
 ) (component.LogsReceiver, error) {
 	cfg := rConf.(*Config)
-	rcvr := newLogsReceiver(cfg, params.Logger, consumer)
-	return rcvr, nil
+	r := receivers.GetOrAdd(cfg, func() component.Component {
+		return newLogsReceiver(cfg, params.Logger, nil)
+	})
+	r.Unwrap().(*logsReceiver).consumer = consumer
+	return r, nil
 }
 
+// createMetricsReceiver creates a receiver emitting the metrics embedded in events in the Cloudwatch
+// embedded metric format, or the metrics of the log groups in the log_group_metrics mode, it shares
+// the polling with the logs receiver of the same config.
+func createMetricsReceiver(
+	ctx context.Context,
+	params component.ReceiverCreateSettings,
+	rConf component.ReceiverConfig,
+	consumer consumer.Metrics,
+) (component.MetricsReceiver, error) {
+	cfg := rConf.(*Config)
+	if cfg.Logs.EMF == nil && cfg.Logs.Mode != modeLogGroupMetrics {
+		params.Logger.Warn("metrics are only emitted from events in the embedded metric format, which is not enabled by logs.emf")
+	}
+	r := receivers.GetOrAdd(cfg, func() component.Component {
+		return newLogsReceiver(cfg, params.Logger, nil)
+	})
+	r.Unwrap().(*logsReceiver).metricsConsumer = consumer
+	return r, nil
+}
+
+// receivers are shared by the logs and metrics pipelines of the same config so that events are only polled once
+var receivers = sharedcomponent.NewSharedComponents()
+
 func createDefaultConfig() component.ReceiverConfig {
 	return &Config{
 		ReceiverSettings: config.NewReceiverSettings(component.NewID(typeStr)),
 		Logs: &LogsConfig{
+			Mode:                modePoll,
 			PollInterval:        defaultPollInterval,
 			MaxEventsPerRequest: defaultEventLimit,
+			MaxDecompressedSize: defaultMaxDecompressedSize,
+			BodyFormat:          bodyFormatRaw,
 			Groups: GroupConfig{
 				AutodiscoverConfig: &AutodiscoverConfig{
 					Limit: defaultLogGroupLimit,
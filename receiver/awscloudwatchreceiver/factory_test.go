@@ -20,6 +20,9 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/sharedcomponent"
 )
 
 func TestType(t *testing.T) {
@@ -39,3 +42,22 @@ func TestCreateLogsReceiver(t *testing.T) {
 	)
 	require.NoError(t, err)
 }
+
+func TestCreateMetricsReceiverSharesLogsReceiver(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-2"
+	cfg.Logs.EMF = &EMFConfig{}
+	logsSink := &consumertest.LogsSink{}
+	metricsSink := &consumertest.MetricsSink{}
+
+	logsRcvr, err := NewFactory().CreateLogsReceiver(context.Background(), componenttest.NewNopReceiverCreateSettings(), cfg, logsSink)
+	require.NoError(t, err)
+	metricsRcvr, err := NewFactory().CreateMetricsReceiver(context.Background(), componenttest.NewNopReceiverCreateSettings(), cfg, metricsSink)
+	require.NoError(t, err)
+	require.Same(t, logsRcvr, metricsRcvr)
+
+	rcvr := logsRcvr.(*sharedcomponent.SharedComponent).Unwrap().(*logsReceiver)
+	require.Equal(t, logsSink, rcvr.consumer)
+	require.Equal(t, metricsSink, rcvr.metricsConsumer)
+	require.NoError(t, logsRcvr.Shutdown(context.Background()))
+}
@@ -4,11 +4,15 @@ go 1.18
 
 require (
 	github.com/aws/aws-sdk-go v1.44.133
+	github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage v0.64.0
+	github.com/open-telemetry/opentelemetry-collector-contrib/internal/sharedcomponent v0.64.0
 	github.com/stretchr/testify v1.8.1
+	go.opencensus.io v0.24.0
 	go.opentelemetry.io/collector v0.64.2-0.20221117234814-4565692c50a7
 	go.opentelemetry.io/collector/component v0.0.0-20221117234814-4565692c50a7
 	go.opentelemetry.io/collector/consumer v0.0.0-20221117234814-4565692c50a7
 	go.opentelemetry.io/collector/pdata v0.64.2-0.20221117234814-4565692c50a7
+	go.uber.org/atomic v1.10.0
 	go.uber.org/multierr v1.8.0
 	go.uber.org/zap v1.23.0
 )
@@ -31,7 +35,6 @@ require (
 	go.opentelemetry.io/otel v1.11.1 // indirect
 	go.opentelemetry.io/otel/metric v0.33.0 // indirect
 	go.opentelemetry.io/otel/trace v1.11.1 // indirect
-	go.uber.org/atomic v1.10.0 // indirect
 	golang.org/x/net v0.1.0 // indirect
 	golang.org/x/sys v0.2.0 // indirect
 	golang.org/x/text v0.4.0 // indirect
@@ -40,3 +43,7 @@ require (
 	google.golang.org/protobuf v1.28.1 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/open-telemetry/opentelemetry-collector-contrib/internal/sharedcomponent => ../../internal/sharedcomponent
+
+replace github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage => ../../extension/storage
@@ -0,0 +1,109 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"go.uber.org/zap"
+)
+
+// errNoGroupsMatched is returned by checkGroups when no log group matches the configured groups, Start only fails with it when fail_on_empty is set
+var errNoGroupsMatched = errors.New("no log groups match the configured groups")
+
+// checkGroups logs how many log groups match the configured groups and fails if none does.
+func (l *logsReceiver) checkGroups(ctx context.Context) error {
+	count, err := l.countGroups(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to check the log groups: %w", err)
+	}
+	l.logger.Info("log groups matching the configured groups will be collected", zap.Int("count", count))
+	if count == 0 {
+		return errNoGroupsMatched
+	}
+	return nil
+}
+
+// countGroups returns the number of log groups that are discovered, or the number of named log groups that exist.
+func (l *logsReceiver) countGroups(ctx context.Context) (int, error) {
+	if l.autodiscover != nil {
+		groups, err := l.discoverGroups(ctx, l.autodiscover)
+		if err != nil {
+			return 0, err
+		}
+		return len(distinctGroupNames(groups)), nil
+	}
+	if err := l.ensureSession(); err != nil {
+		return 0, fmt.Errorf("unable to establish a session to describe log groups: %w", err)
+	}
+	count := 0
+	for _, name := range distinctGroupNames(l.groupRequests) {
+		exists, err := l.groupExists(ctx, name)
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			l.logger.Warn("the named log group does not exist", zap.String("log group", name))
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// groupExists looks for the log group among the log groups whose name starts with its name
+func (l *logsReceiver) groupExists(ctx context.Context, name string) (bool, error) {
+	var nextToken *string
+	for {
+		output, err := l.client.DescribeLogGroupsWithContext(ctx, &cloudwatchlogs.DescribeLogGroupsInput{
+			LogGroupNamePrefix: aws.String(name),
+			NextToken:          nextToken,
+		})
+		if err != nil {
+			return false, fmt.Errorf("unable to describe log group %q: %w", name, err)
+		}
+		for _, group := range output.LogGroups {
+			if aws.StringValue(group.LogGroupName) == name {
+				return true, nil
+			}
+		}
+		if output.NextToken == nil {
+			return false, nil
+		}
+		nextToken = output.NextToken
+	}
+}
+
+// distinctGroupNames returns the sorted names of the log groups of the requests, a log group has a request
+// for each of its stream prefixes.
+func distinctGroupNames(requests []groupRequest) []string {
+	seen := map[string]struct{}{}
+	names := []string{}
+	for _, request := range requests {
+		name := request.groupName()
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
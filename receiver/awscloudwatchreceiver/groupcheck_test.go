@@ -0,0 +1,157 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func describeGroupsClient(names ...string) *mockClient {
+	groups := []*cloudwatchlogs.LogGroup{}
+	for _, name := range names {
+		groups = append(groups, &cloudwatchlogs.LogGroup{LogGroupName: aws.String(name)})
+	}
+	mc := &mockClient{}
+	mc.On("DescribeLogGroupsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&cloudwatchlogs.DescribeLogGroupsOutput{LogGroups: groups}, nil)
+	return mc
+}
+
+func TestStartFailOnEmpty(t *testing.T) {
+	cases := []struct {
+		name        string
+		groups      GroupConfig
+		described   []string
+		expectedErr error
+		count       int64
+	}{
+		{
+			name:        "no discovered groups",
+			groups:      GroupConfig{AutodiscoverConfig: &AutodiscoverConfig{Limit: 10, Prefix: "/aws/eks/"}},
+			expectedErr: errNoGroupsMatched,
+		},
+		{
+			name:      "discovered groups",
+			groups:    GroupConfig{AutodiscoverConfig: &AutodiscoverConfig{Limit: 10, Prefix: "/aws/eks/"}},
+			described: []string{"/aws/eks/one", "/aws/eks/two", "/aws/eks/three"},
+			count:     3,
+		},
+		{
+			name: "missing named groups",
+			groups: GroupConfig{NamedConfigs: map[string]StreamConfig{
+				"/aws/eks/missing": {},
+			}},
+			described:   []string{"/aws/eks/missing-too"},
+			expectedErr: errNoGroupsMatched,
+		},
+		{
+			name: "named groups",
+			groups: GroupConfig{NamedConfigs: map[string]StreamConfig{
+				"/aws/eks/one":     {Prefixes: []*string{aws.String("a"), aws.String("b")}},
+				"/aws/eks/missing": {},
+			}},
+			described: []string{"/aws/eks/one", "/aws/eks/missing-too"},
+			count:     1,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Region = "us-west-1"
+			cfg.Logs.FailOnEmpty = true
+			cfg.Logs.Groups = tc.groups
+
+			core, logs := observer.New(zap.InfoLevel)
+			logsRcvr := newLogsReceiver(cfg, zap.New(core), &consumertest.LogsSink{})
+			logsRcvr.client = describeGroupsClient(tc.described...)
+			logsRcvr.stsClient = defaultMockSTSClient()
+
+			err := logsRcvr.Start(context.Background(), componenttest.NewNopHost())
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			entries := logs.FilterMessage("log groups matching the configured groups will be collected").All()
+			require.Len(t, entries, 1)
+			require.Equal(t, tc.count, entries[0].ContextMap()["count"])
+			require.NoError(t, logsRcvr.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestStartLogsGroupCount(t *testing.T) {
+	cases := []struct {
+		name      string
+		described []string
+		count     int64
+	}{
+		{
+			name:      "matched groups",
+			described: []string{testLogGroupName},
+			count:     1,
+		},
+		{
+			name: "no matched groups",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Region = "us-west-1"
+			cfg.Logs.Groups = GroupConfig{NamedConfigs: map[string]StreamConfig{testLogGroupName: {}}}
+
+			core, logs := observer.New(zap.InfoLevel)
+			logsRcvr := newLogsReceiver(cfg, zap.New(core), &consumertest.LogsSink{})
+			logsRcvr.client = describeGroupsClient(tc.described...)
+			logsRcvr.stsClient = defaultMockSTSClient()
+
+			// without fail_on_empty the count is logged and the receiver starts whatever it is
+			require.NoError(t, logsRcvr.Start(context.Background(), componenttest.NewNopHost()))
+			entries := logs.FilterMessage("log groups matching the configured groups will be collected").All()
+			require.Len(t, entries, 1)
+			require.Equal(t, tc.count, entries[0].ContextMap()["count"])
+			require.Equal(t, tc.count == 0, logs.FilterMessage("starting to poll without log groups matching the configured groups").Len() == 1)
+			require.NoError(t, logsRcvr.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestStartFailOnEmptyDescribeError(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.FailOnEmpty = true
+	cfg.Logs.Groups = GroupConfig{NamedConfigs: map[string]StreamConfig{testLogGroupName: {}}}
+
+	mc := &mockClient{}
+	mc.On("DescribeLogGroupsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&cloudwatchlogs.DescribeLogGroupsOutput{}, errors.New("throttled"))
+	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), &consumertest.LogsSink{})
+	logsRcvr.client = mc
+
+	err := logsRcvr.Start(context.Background(), componenttest.NewNopHost())
+	require.ErrorContains(t, err, "unable to check the log groups")
+}
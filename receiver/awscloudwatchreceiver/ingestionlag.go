@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var (
+	ingestionLag = stats.Float64(
+		"awscloudwatchreceiver/cloudwatch_ingestion_lag_seconds",
+		"Time between the newest event read from the log group and the poll that read it",
+		stats.UnitSeconds)
+
+	ingestionLagView = &view.View{
+		Name:        "receiver/" + typeStr + "/cloudwatch_ingestion_lag_seconds",
+		Description: ingestionLag.Description(),
+		Measure:     ingestionLag,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{receiverNameKey, logGroupKey},
+	}
+)
+
+// newestTimestamp returns the timestamp of the newest of the events, false if none of them has a timestamp
+func newestTimestamp(events []*cloudwatchlogs.FilteredLogEvent) (time.Time, bool) {
+	var newest int64
+	found := false
+	for _, e := range events {
+		if e.Timestamp == nil {
+			continue
+		}
+		if !found || *e.Timestamp > newest {
+			newest = *e.Timestamp
+			found = true
+		}
+	}
+	return time.UnixMilli(newest), found
+}
+
+// recordIngestionLag records how far the newest of the events read from the log group is behind the given time,
+// nothing is recorded if there are no events
+func (l *logsReceiver) recordIngestionLag(group string, now time.Time, events []*cloudwatchlogs.FilteredLogEvent) {
+	newest, ok := newestTimestamp(events)
+	if !ok {
+		return
+	}
+	_ = stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{tag.Upsert(receiverNameKey, l.id.String()), tag.Upsert(logGroupKey, group)},
+		ingestionLag.M(now.Sub(newest).Seconds()),
+	)
+}
@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+func TestPollRecordsIngestionLag(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ReceiverSettings.SetIDName(t.Name())
+	cfg.Region = "us-west-1"
+	cfg.Logs.Groups = GroupConfig{
+		NamedConfigs: map[string]StreamConfig{
+			"behind":  {},
+			"current": {},
+			"idle":    {},
+		},
+	}
+	now := time.UnixMilli(testTimeStamp).Add(time.Hour)
+	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), &consumertest.LogsSink{})
+	logsRcvr.now = func() time.Time { return now }
+	mc := &mockClient{}
+	mc.On("FilterLogEventsWithContext", mock.Anything, groupInput("behind"), mock.Anything).Return(
+		&cloudwatchlogs.FilterLogEventsOutput{
+			Events: []*cloudwatchlogs.FilteredLogEvent{
+				lagTestEvent(now.Add(-5 * time.Minute)),
+				// the lag is measured from the newest event, regardless of the order of the events
+				lagTestEvent(now.Add(-90 * time.Second)),
+				lagTestEvent(now.Add(-2 * time.Minute)),
+			},
+		}, nil)
+	mc.On("FilterLogEventsWithContext", mock.Anything, groupInput("current"), mock.Anything).Return(
+		&cloudwatchlogs.FilterLogEventsOutput{
+			Events: []*cloudwatchlogs.FilteredLogEvent{lagTestEvent(now.Add(-1500 * time.Millisecond))},
+		}, nil)
+	mc.On("FilterLogEventsWithContext", mock.Anything, groupInput("idle"), mock.Anything).Return(
+		&cloudwatchlogs.FilterLogEventsOutput{}, nil)
+	logsRcvr.client = mc
+
+	require.NoError(t, logsRcvr.poll(context.Background()))
+	lag, ok := ingestionLagValue(t, cfg.ID().String(), "behind")
+	require.True(t, ok)
+	require.Equal(t, 90.0, lag)
+	lag, ok = ingestionLagValue(t, cfg.ID().String(), "current")
+	require.True(t, ok)
+	require.Equal(t, 1.5, lag)
+	// no lag is recorded for a log group without events
+	_, ok = ingestionLagValue(t, cfg.ID().String(), "idle")
+	require.False(t, ok)
+}
+
+func TestNewestTimestamp(t *testing.T) {
+	_, ok := newestTimestamp(nil)
+	require.False(t, ok)
+	_, ok = newestTimestamp([]*cloudwatchlogs.FilteredLogEvent{{EventId: aws.String(testEventID)}})
+	require.False(t, ok)
+
+	newest, ok := newestTimestamp([]*cloudwatchlogs.FilteredLogEvent{
+		{Timestamp: aws.Int64(testTimeStamp)},
+		{},
+		{Timestamp: aws.Int64(testTimeStamp + 1000)},
+	})
+	require.True(t, ok)
+	require.True(t, newest.Equal(time.UnixMilli(testTimeStamp+1000)))
+}
+
+func lagTestEvent(ts time.Time) *cloudwatchlogs.FilteredLogEvent {
+	return &cloudwatchlogs.FilteredLogEvent{
+		EventId:       aws.String(testEventID),
+		LogStreamName: aws.String(testLogStreamName),
+		Message:       aws.String(testLogStreamMessage),
+		Timestamp:     aws.Int64(ts.UnixMilli()),
+	}
+}
+
+// ingestionLagValue returns the last recorded ingestion lag of the log group, false if none was recorded
+func ingestionLagValue(t *testing.T, receiverName string, group string) (float64, bool) {
+	rows, err := view.RetrieveData(ingestionLagView.Name)
+	require.NoError(t, err)
+	for _, row := range rows {
+		tags := map[string]string{}
+		for _, tag := range row.Tags {
+			tags[tag.Key.Name()] = tag.Value
+		}
+		if tags[receiverNameKey.Name()] == receiverName && tags[logGroupKey.Name()] == group {
+			return row.Data.(*view.LastValueData).Value, true
+		}
+	}
+	return 0, false
+}
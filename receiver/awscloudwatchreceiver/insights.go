@@ -0,0 +1,199 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+const (
+	// insightsTimestampLayout is the layout of the @timestamp field of query results, which is always UTC
+	insightsTimestampLayout = "2006-01-02 15:04:05.000"
+	insightsTimestampField  = "@timestamp"
+	insightsMessageField    = "@message"
+	// insightsPtrField identifies the event of a result row and is only useful to GetLogRecord, so it is not emitted
+	insightsPtrField = "@ptr"
+)
+
+type insightsClient interface {
+	StartQueryWithContext(ctx context.Context, input *cloudwatchlogs.StartQueryInput, opts ...request.Option) (*cloudwatchlogs.StartQueryOutput, error)
+	GetQueryResultsWithContext(ctx context.Context, input *cloudwatchlogs.GetQueryResultsInput, opts ...request.Option) (*cloudwatchlogs.GetQueryResultsOutput, error)
+	StopQueryWithContext(ctx context.Context, input *cloudwatchlogs.StopQueryInput, opts ...request.Option) (*cloudwatchlogs.StopQueryOutput, error)
+}
+
+// pollInsights runs the configured query over the time since the last successful query and
+// emits every result row as a log record. A failed query is retried over the same time range
+// by the next poll.
+func (l *logsReceiver) pollInsights(ctx context.Context) error {
+	err := l.ensureInsightsSession()
+	if err != nil {
+		return err
+	}
+
+	// the time range of a query is in whole seconds and includes both ends, so the range of the
+	// next query starts in the second after the end of the previous one
+	startTime, endTime := l.nextStartTime.Unix(), time.Now().Unix()
+	if startTime > endTime {
+		return nil
+	}
+	limit := l.insights.Limit
+	if limit == 0 {
+		limit = defaultInsightsLimit
+	}
+	resp, err := l.insightsClient.StartQueryWithContext(ctx, &cloudwatchlogs.StartQueryInput{
+		QueryString:   aws.String(l.insights.Query),
+		LogGroupNames: aws.StringSlice(l.insights.LogGroups),
+		StartTime:     aws.Int64(startTime),
+		EndTime:       aws.Int64(endTime),
+		Limit:         aws.Int64(int64(limit)),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to start insights query: %w", err)
+	}
+	queryID := aws.StringValue(resp.QueryId)
+
+	results, done, err := l.waitForQuery(ctx, queryID)
+	if err != nil || done {
+		return err
+	}
+	if len(results) >= limit {
+		l.logger.Warn("insights query returned as many rows as its limit, rows beyond the limit were not collected",
+			zap.String("query id", queryID), zap.Int("limit", limit))
+	}
+
+	logs := l.processInsightsResults(queryID, results)
+	if logs.LogRecordCount() > 0 {
+		if err = l.consumer.ConsumeLogs(ctx, logs); err != nil {
+			return fmt.Errorf("unable to consume the results of insights query %s: %w", queryID, err)
+		}
+	}
+	l.nextStartTime = time.Unix(endTime+1, 0)
+	return nil
+}
+
+// waitForQuery checks the status of the query every status interval until it completes and returns its results.
+// done is true if the receiver was shut down while waiting, in which case the query is stopped.
+func (l *logsReceiver) waitForQuery(ctx context.Context, queryID string) (results [][]*cloudwatchlogs.ResultField, done bool, err error) {
+	timeout, interval := l.insights.Timeout, l.insights.StatusInterval
+	if timeout == 0 {
+		timeout = defaultInsightsTimeout
+	}
+	if interval == 0 {
+		interval = defaultInsightsStatusInterval
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		resp, err := l.insightsClient.GetQueryResultsWithContext(ctx, &cloudwatchlogs.GetQueryResultsInput{
+			QueryId: aws.String(queryID),
+		})
+		if err != nil {
+			l.stopQuery(ctx, queryID)
+			return nil, false, fmt.Errorf("unable to get the results of insights query %s: %w", queryID, err)
+		}
+
+		switch status := aws.StringValue(resp.Status); status {
+		case cloudwatchlogs.QueryStatusComplete:
+			return resp.Results, false, nil
+		case cloudwatchlogs.QueryStatusScheduled, cloudwatchlogs.QueryStatusRunning:
+		default:
+			return nil, false, fmt.Errorf("insights query %s did not complete, status: %s", queryID, status)
+		}
+
+		select {
+		case <-ctx.Done():
+			l.stopQuery(ctx, queryID)
+			return nil, true, nil
+		case _, ok := <-l.doneChan:
+			if !ok {
+				l.stopQuery(ctx, queryID)
+				return nil, true, nil
+			}
+		case <-deadline.C:
+			l.stopQuery(ctx, queryID)
+			return nil, false, fmt.Errorf("insights query %s did not complete within %s", queryID, timeout)
+		case <-t.C:
+		}
+	}
+}
+
+// stopQuery stops a running query so that it no longer counts towards the concurrent query quota
+func (l *logsReceiver) stopQuery(ctx context.Context, queryID string) {
+	_, err := l.insightsClient.StopQueryWithContext(ctx, &cloudwatchlogs.StopQueryInput{
+		QueryId: aws.String(queryID),
+	})
+	if err != nil {
+		l.logger.Debug("unable to stop insights query", zap.String("query id", queryID), zap.Error(err))
+	}
+}
+
+func (l *logsReceiver) processInsightsResults(queryID string, results [][]*cloudwatchlogs.ResultField) plog.Logs {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	resourceAttributes := rl.Resource().Attributes()
+	l.putCloudAttributes(resourceAttributes)
+	resourceAttributes.PutStr("cloudwatch.insights.query.id", queryID)
+	records := rl.ScopeLogs().AppendEmpty().LogRecords()
+
+	observedTime := pcommon.NewTimestampFromTime(time.Now())
+	for _, row := range results {
+		logRecord := records.AppendEmpty()
+		logRecord.SetObservedTimestamp(observedTime)
+		attrs := logRecord.Attributes()
+		for _, field := range row {
+			name, value := aws.StringValue(field.Field), aws.StringValue(field.Value)
+			switch name {
+			case "", insightsPtrField:
+				continue
+			case insightsMessageField:
+				setBody(logRecord, value, l.bodyFormat, l.attributePrefix)
+				if l.severityParser != nil {
+					l.severityParser.parse(value, logRecord)
+				}
+				continue
+			case insightsTimestampField:
+				if ts, err := time.Parse(insightsTimestampLayout, value); err == nil {
+					logRecord.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+				}
+			}
+			attrs.PutStr(name, value)
+		}
+	}
+	return logs
+}
+
+func (l *logsReceiver) ensureInsightsSession() error {
+	if l.insightsClient != nil {
+		return nil
+	}
+	s, err := l.newSession()
+	if err != nil {
+		return err
+	}
+	l.insightsClient = cloudwatchlogs.New(s)
+	return nil
+}
@@ -0,0 +1,251 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var (
+	testInsightsQuery   = "fields @timestamp, @message, level | filter level = 'error'"
+	testInsightsQueryID = "12ab3456-12ab-123a-789e-1234567890ab"
+)
+
+func TestInsightsQueryLifecycle(t *testing.T) {
+	logsRcvr, sink := newInsightsTestReceiver()
+	mc := &mockInsightsClient{}
+	mc.On("StartQueryWithContext", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.StartQueryInput) bool {
+		return aws.StringValue(input.QueryString) == testInsightsQuery &&
+			len(input.LogGroupNames) == 1 && aws.StringValue(input.LogGroupNames[0]) == testLogGroupName &&
+			aws.Int64Value(input.StartTime) <= aws.Int64Value(input.EndTime)
+	}), mock.Anything).Return(&cloudwatchlogs.StartQueryOutput{QueryId: aws.String(testInsightsQueryID)}, nil)
+	mc.onStatus(cloudwatchlogs.QueryStatusScheduled).Once()
+	mc.onStatus(cloudwatchlogs.QueryStatusRunning).Once()
+	mc.On("GetQueryResultsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&cloudwatchlogs.GetQueryResultsOutput{
+			Status: aws.String(cloudwatchlogs.QueryStatusComplete),
+			Results: [][]*cloudwatchlogs.ResultField{
+				testResultRow("2022-10-07 18:10:51.014", "error: disk full", "error"),
+				testResultRow("2022-10-07 18:10:52.000", "error: disk still full", "error"),
+			},
+		}, nil).Once()
+	logsRcvr.insightsClient = mc
+
+	startTime := logsRcvr.nextStartTime
+	require.NoError(t, logsRcvr.pollInsights(context.Background()))
+	mc.AssertNumberOfCalls(t, "GetQueryResultsWithContext", 3)
+	mc.AssertNotCalled(t, "StopQueryWithContext", mock.Anything, mock.Anything, mock.Anything)
+	require.True(t, logsRcvr.nextStartTime.After(startTime))
+
+	require.Len(t, sink.AllLogs(), 1)
+	require.Equal(t, 2, sink.LogRecordCount())
+	rl := sink.AllLogs()[0].ResourceLogs().At(0)
+	queryID, ok := rl.Resource().Attributes().Get("cloudwatch.insights.query.id")
+	require.True(t, ok)
+	require.Equal(t, testInsightsQueryID, queryID.Str())
+	region, ok := rl.Resource().Attributes().Get("aws.region")
+	require.True(t, ok)
+	require.Equal(t, "us-west-1", region.Str())
+
+	record := rl.ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, pcommon.NewTimestampFromTime(time.UnixMilli(testTimeStamp)), record.Timestamp())
+	require.Equal(t, "error: disk full", record.Body().Str())
+	require.Equal(t, map[string]interface{}{
+		"@timestamp": "2022-10-07 18:10:51.014",
+		"level":      "error",
+	}, record.Attributes().AsRaw())
+}
+
+func TestInsightsQueryTimeRanges(t *testing.T) {
+	logsRcvr, _ := newInsightsTestReceiver()
+	mc := &mockInsightsClient{}
+	var input *cloudwatchlogs.StartQueryInput
+	mc.On("StartQueryWithContext", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		input = args.Get(1).(*cloudwatchlogs.StartQueryInput)
+	}).Return(&cloudwatchlogs.StartQueryOutput{QueryId: aws.String(testInsightsQueryID)}, nil)
+	mc.On("GetQueryResultsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&cloudwatchlogs.GetQueryResultsOutput{Status: aws.String(cloudwatchlogs.QueryStatusComplete)}, nil)
+	logsRcvr.insightsClient = mc
+
+	require.NoError(t, logsRcvr.pollInsights(context.Background()))
+	require.Equal(t, int64(defaultInsightsLimit), aws.Int64Value(input.Limit))
+	// both ends of the range are included, so the next range starts in the following second
+	require.Equal(t, aws.Int64Value(input.EndTime)+1, logsRcvr.nextStartTime.Unix())
+
+	// no query is started until the next range has begun
+	logsRcvr.nextStartTime = time.Now().Add(time.Minute)
+	require.NoError(t, logsRcvr.pollInsights(context.Background()))
+	mc.AssertNumberOfCalls(t, "StartQueryWithContext", 1)
+}
+
+func TestInsightsQueryLimitReached(t *testing.T) {
+	logsRcvr, sink := newInsightsTestReceiver()
+	core, observedLogs := observer.New(zap.WarnLevel)
+	logsRcvr.logger = zap.New(core)
+	logsRcvr.insights.Limit = 2
+	mc := &mockInsightsClient{}
+	mc.On("StartQueryWithContext", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.StartQueryInput) bool {
+		return aws.Int64Value(input.Limit) == 2
+	}), mock.Anything).Return(&cloudwatchlogs.StartQueryOutput{QueryId: aws.String(testInsightsQueryID)}, nil)
+	mc.On("GetQueryResultsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&cloudwatchlogs.GetQueryResultsOutput{
+			Status: aws.String(cloudwatchlogs.QueryStatusComplete),
+			Results: [][]*cloudwatchlogs.ResultField{
+				testResultRow("2022-10-07 18:10:51.014", "error: disk full", "error"),
+				testResultRow("2022-10-07 18:10:52.000", "error: disk still full", "error"),
+			},
+		}, nil)
+	logsRcvr.insightsClient = mc
+
+	require.NoError(t, logsRcvr.pollInsights(context.Background()))
+	require.Equal(t, 2, sink.LogRecordCount())
+	require.Equal(t, 1, observedLogs.FilterMessageSnippet("as many rows as its limit").Len())
+}
+
+func TestInsightsQueryFailed(t *testing.T) {
+	logsRcvr, sink := newInsightsTestReceiver()
+	mc := &mockInsightsClient{}
+	mc.On("StartQueryWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&cloudwatchlogs.StartQueryOutput{QueryId: aws.String(testInsightsQueryID)}, nil)
+	mc.onStatus(cloudwatchlogs.QueryStatusRunning).Once()
+	mc.onStatus(cloudwatchlogs.QueryStatusFailed).Once()
+	logsRcvr.insightsClient = mc
+
+	startTime := logsRcvr.nextStartTime
+	err := logsRcvr.pollInsights(context.Background())
+	require.ErrorContains(t, err, "status: Failed")
+	require.Equal(t, startTime, logsRcvr.nextStartTime)
+	require.Empty(t, sink.AllLogs())
+}
+
+func TestInsightsQueryTimeout(t *testing.T) {
+	logsRcvr, sink := newInsightsTestReceiver()
+	logsRcvr.insights.Timeout = 50 * time.Millisecond
+	mc := &mockInsightsClient{}
+	mc.On("StartQueryWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&cloudwatchlogs.StartQueryOutput{QueryId: aws.String(testInsightsQueryID)}, nil)
+	mc.onStatus(cloudwatchlogs.QueryStatusRunning)
+	mc.On("StopQueryWithContext", mock.Anything, &cloudwatchlogs.StopQueryInput{QueryId: aws.String(testInsightsQueryID)}, mock.Anything).Return(
+		&cloudwatchlogs.StopQueryOutput{Success: aws.Bool(true)}, nil)
+	logsRcvr.insightsClient = mc
+
+	startTime := logsRcvr.nextStartTime
+	err := logsRcvr.pollInsights(context.Background())
+	require.ErrorContains(t, err, "did not complete within")
+	mc.AssertNumberOfCalls(t, "StopQueryWithContext", 1)
+	require.Equal(t, startTime, logsRcvr.nextStartTime)
+	require.Empty(t, sink.AllLogs())
+}
+
+func TestInsightsStartQueryError(t *testing.T) {
+	logsRcvr, _ := newInsightsTestReceiver()
+	mc := &mockInsightsClient{}
+	mc.On("StartQueryWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		(*cloudwatchlogs.StartQueryOutput)(nil), errors.New("LimitExceededException"))
+	logsRcvr.insightsClient = mc
+
+	require.ErrorContains(t, logsRcvr.pollInsights(context.Background()), "unable to start insights query")
+	mc.AssertNotCalled(t, "GetQueryResultsWithContext", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestInsightsMode(t *testing.T) {
+	logsRcvr, sink := newInsightsTestReceiver()
+	mc := &mockInsightsClient{}
+	mc.On("StartQueryWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&cloudwatchlogs.StartQueryOutput{QueryId: aws.String(testInsightsQueryID)}, nil)
+	mc.On("GetQueryResultsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&cloudwatchlogs.GetQueryResultsOutput{
+			Status:  aws.String(cloudwatchlogs.QueryStatusComplete),
+			Results: [][]*cloudwatchlogs.ResultField{testResultRow("2022-10-07 18:10:51.014", testLogStreamMessage, "info")},
+		}, nil)
+	logsRcvr.insightsClient = mc
+	logsRcvr.stsClient = defaultMockSTSClient()
+
+	require.NoError(t, logsRcvr.Start(context.Background(), componenttest.NewNopHost()))
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() > 0
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, logsRcvr.Shutdown(context.Background()))
+
+	rl := sink.AllLogs()[0].ResourceLogs().At(0)
+	accountID, ok := rl.Resource().Attributes().Get("cloud.account.id")
+	require.True(t, ok)
+	require.Equal(t, testAccountID, accountID.Str())
+	require.Equal(t, testLogStreamMessage, rl.ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+}
+
+func newInsightsTestReceiver() (*logsReceiver, *consumertest.LogsSink) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.PollInterval = 100 * time.Millisecond
+	cfg.Logs.Mode = modeInsights
+	cfg.Logs.Insights = &InsightsConfig{
+		Query:          testInsightsQuery,
+		LogGroups:      []string{testLogGroupName},
+		StatusInterval: time.Millisecond,
+	}
+
+	sink := &consumertest.LogsSink{}
+	return newLogsReceiver(cfg, zap.NewNop(), sink), sink
+}
+
+// testResultRow returns a result row of the test query, as returned by GetQueryResults
+func testResultRow(timestamp, message, level string) []*cloudwatchlogs.ResultField {
+	return []*cloudwatchlogs.ResultField{
+		{Field: aws.String("@timestamp"), Value: aws.String(timestamp)},
+		{Field: aws.String("@message"), Value: aws.String(message)},
+		{Field: aws.String("level"), Value: aws.String(level)},
+		{Field: aws.String("@ptr"), Value: aws.String("CmAKJwojMTIzNDU2Nzg5MDEyOnRlc3QtbG9nLWdyb3VwLW5hbWUQARI1")},
+	}
+}
+
+type mockInsightsClient struct {
+	mock.Mock
+}
+
+// onStatus sets up GetQueryResults to return a query that has not completed yet
+func (mc *mockInsightsClient) onStatus(status string) *mock.Call {
+	return mc.On("GetQueryResultsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&cloudwatchlogs.GetQueryResultsOutput{Status: aws.String(status)}, nil)
+}
+
+func (mc *mockInsightsClient) StartQueryWithContext(ctx context.Context, input *cloudwatchlogs.StartQueryInput, opts ...request.Option) (*cloudwatchlogs.StartQueryOutput, error) {
+	args := mc.Called(ctx, input, opts)
+	return args.Get(0).(*cloudwatchlogs.StartQueryOutput), args.Error(1)
+}
+
+func (mc *mockInsightsClient) GetQueryResultsWithContext(ctx context.Context, input *cloudwatchlogs.GetQueryResultsInput, opts ...request.Option) (*cloudwatchlogs.GetQueryResultsOutput, error) {
+	args := mc.Called(ctx, input, opts)
+	return args.Get(0).(*cloudwatchlogs.GetQueryResultsOutput), args.Error(1)
+}
+
+func (mc *mockInsightsClient) StopQueryWithContext(ctx context.Context, input *cloudwatchlogs.StopQueryInput, opts ...request.Option) (*cloudwatchlogs.StopQueryOutput, error) {
+	args := mc.Called(ctx, input, opts)
+	return args.Get(0).(*cloudwatchlogs.StopQueryOutput), args.Error(1)
+}
@@ -29,6 +29,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/consumer/consumertest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/sharedcomponent"
 )
 
 func TestLoggingIntegration(t *testing.T) {
@@ -54,9 +56,12 @@ func TestLoggingIntegration(t *testing.T) {
 	)
 	require.NoError(t, err)
 
-	rcvr, ok := recv.(*logsReceiver)
+	shared, ok := recv.(*sharedcomponent.SharedComponent)
+	require.True(t, ok)
+	rcvr, ok := shared.Unwrap().(*logsReceiver)
 	require.True(t, ok)
 	rcvr.client = mc
+	rcvr.stsClient = defaultMockSTSClient()
 
 	err = recv.Start(context.Background(), componenttest.NewNopHost())
 	require.NoError(t, err)
@@ -0,0 +1,144 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const (
+	logGroupStoredBytesMetric   = "aws.cloudwatch.log_group.stored_bytes"
+	logGroupRetentionDaysMetric = "aws.cloudwatch.log_group.retention_days"
+	// logGroupNameAttribute is the data point attribute holding the name of the log group
+	logGroupNameAttribute = "cloudwatch.log.group.name"
+)
+
+// pollLogGroupMetrics describes the configured log groups, or the discovered ones, and emits their stored
+// bytes and retention as metrics.
+func (l *logsReceiver) pollLogGroupMetrics(ctx context.Context) error {
+	if err := l.ensureSession(); err != nil {
+		return fmt.Errorf("unable to establish a session to describe log groups: %w", err)
+	}
+	groups, err := l.describeLogGroups(ctx)
+	if err != nil {
+		return err
+	}
+	if len(groups) == 0 || l.metricsConsumer == nil {
+		return nil
+	}
+	if err = l.metricsConsumer.ConsumeMetrics(ctx, l.logGroupMetrics(groups)); err != nil {
+		return fmt.Errorf("unable to consume the log group metrics: %w", err)
+	}
+	return nil
+}
+
+// describeLogGroups returns the named log groups, or up to the limit of log groups discovered by autodiscover.
+func (l *logsReceiver) describeLogGroups(ctx context.Context) ([]*cloudwatchlogs.LogGroup, error) {
+	if l.autodiscover != nil {
+		input := &cloudwatchlogs.DescribeLogGroupsInput{}
+		if l.autodiscover.Prefix != "" {
+			input.LogGroupNamePrefix = aws.String(l.autodiscover.Prefix)
+		}
+		groups := []*cloudwatchlogs.LogGroup{}
+		err := l.describeLogGroupPages(ctx, input, func(group *cloudwatchlogs.LogGroup) bool {
+			groups = append(groups, group)
+			return len(groups) < l.autodiscover.Limit
+		})
+		return groups, err
+	}
+
+	groups := []*cloudwatchlogs.LogGroup{}
+	for _, name := range distinctGroupNames(l.groupRequests) {
+		var found *cloudwatchlogs.LogGroup
+		err := l.describeLogGroupPages(ctx, &cloudwatchlogs.DescribeLogGroupsInput{LogGroupNamePrefix: aws.String(name)}, func(group *cloudwatchlogs.LogGroup) bool {
+			if aws.StringValue(group.LogGroupName) == name {
+				found = group
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		if found != nil {
+			groups = append(groups, found)
+		}
+	}
+	return groups, nil
+}
+
+// describeLogGroupPages calls fn with every log group described by the input until fn returns false.
+func (l *logsReceiver) describeLogGroupPages(ctx context.Context, input *cloudwatchlogs.DescribeLogGroupsInput, fn func(*cloudwatchlogs.LogGroup) bool) error {
+	for {
+		output, err := l.client.DescribeLogGroupsWithContext(ctx, input)
+		if err != nil {
+			return fmt.Errorf("unable to describe log groups: %w", err)
+		}
+		for _, group := range output.LogGroups {
+			if !fn(group) {
+				return nil
+			}
+		}
+		if output.NextToken == nil {
+			return nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+// logGroupMetrics converts the log groups into a gauge of their stored bytes and a gauge of their retention,
+// log groups whose events never expire have no retention data point.
+func (l *logsReceiver) logGroupMetrics(groups []*cloudwatchlogs.LogGroup) pmetric.Metrics {
+	metrics := pmetric.NewMetrics()
+	rm := metrics.ResourceMetrics().AppendEmpty()
+	l.putCloudAttributes(rm.Resource().Attributes())
+	scopeMetrics := rm.ScopeMetrics().AppendEmpty().Metrics()
+
+	storedBytes := scopeMetrics.AppendEmpty()
+	storedBytes.SetName(logGroupStoredBytesMetric)
+	storedBytes.SetDescription("The number of bytes of events stored in the log group.")
+	storedBytes.SetUnit("By")
+	storedBytesPoints := storedBytes.SetEmptyGauge().DataPoints()
+
+	retentionDays := scopeMetrics.AppendEmpty()
+	retentionDays.SetName(logGroupRetentionDaysMetric)
+	retentionDays.SetDescription("The number of days the events of the log group are retained for.")
+	retentionDays.SetUnit("d")
+	retentionDaysPoints := retentionDays.SetEmptyGauge().DataPoints()
+
+	now := pcommon.NewTimestampFromTime(l.now())
+	for _, group := range groups {
+		name := aws.StringValue(group.LogGroupName)
+		point := storedBytesPoints.AppendEmpty()
+		point.SetTimestamp(now)
+		point.SetIntValue(aws.Int64Value(group.StoredBytes))
+		point.Attributes().PutStr(logGroupNameAttribute, name)
+
+		if group.RetentionInDays == nil {
+			continue
+		}
+		point = retentionDaysPoints.AppendEmpty()
+		point.SetTimestamp(now)
+		point.SetIntValue(aws.Int64Value(group.RetentionInDays))
+		point.Attributes().PutStr(logGroupNameAttribute, name)
+	}
+	return metrics
+}
@@ -0,0 +1,169 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+func TestLogGroupMetrics(t *testing.T) {
+	logsRcvr, sink := newLogGroupMetricsTestReceiver(nil)
+	mc := &mockClient{}
+	mc.On("DescribeLogGroupsWithContext", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.DescribeLogGroupsInput) bool {
+		return input.NextToken == nil
+	}), mock.Anything).Return(&cloudwatchlogs.DescribeLogGroupsOutput{
+		LogGroups: []*cloudwatchlogs.LogGroup{
+			{LogGroupName: aws.String("/aws/lambda/checkout"), StoredBytes: aws.Int64(2048), RetentionInDays: aws.Int64(30)},
+		},
+		NextToken: aws.String("next"),
+	}, nil)
+	mc.On("DescribeLogGroupsWithContext", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.DescribeLogGroupsInput) bool {
+		return aws.StringValue(input.NextToken) == "next"
+	}), mock.Anything).Return(&cloudwatchlogs.DescribeLogGroupsOutput{
+		LogGroups: []*cloudwatchlogs.LogGroup{
+			// the events of a log group without retention never expire
+			{LogGroupName: aws.String("/aws/lambda/payments"), StoredBytes: aws.Int64(512)},
+		},
+	}, nil)
+	logsRcvr.client = mc
+
+	require.NoError(t, logsRcvr.pollLogGroupMetrics(context.Background()))
+	require.Len(t, sink.AllMetrics(), 1)
+	rm := sink.AllMetrics()[0].ResourceMetrics().At(0)
+	region, ok := rm.Resource().Attributes().Get("cloud.region")
+	require.True(t, ok)
+	require.Equal(t, "us-west-1", region.Str())
+
+	metrics := rm.ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, metrics.Len())
+	storedBytes := metrics.At(0)
+	require.Equal(t, logGroupStoredBytesMetric, storedBytes.Name())
+	require.Equal(t, "By", storedBytes.Unit())
+	require.Equal(t, map[string]int64{"/aws/lambda/checkout": 2048, "/aws/lambda/payments": 512}, logGroupValues(t, storedBytes))
+	retentionDays := metrics.At(1)
+	require.Equal(t, logGroupRetentionDaysMetric, retentionDays.Name())
+	require.Equal(t, "d", retentionDays.Unit())
+	require.Equal(t, map[string]int64{"/aws/lambda/checkout": 30}, logGroupValues(t, retentionDays))
+}
+
+func TestLogGroupMetricsAutodiscoverLimit(t *testing.T) {
+	logsRcvr, sink := newLogGroupMetricsTestReceiver(nil)
+	logsRcvr.autodiscover = &AutodiscoverConfig{Limit: 1, Prefix: "/aws/lambda"}
+	mc := &mockClient{}
+	mc.On("DescribeLogGroupsWithContext", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.DescribeLogGroupsInput) bool {
+		return aws.StringValue(input.LogGroupNamePrefix) == "/aws/lambda"
+	}), mock.Anything).Return(&cloudwatchlogs.DescribeLogGroupsOutput{
+		LogGroups: []*cloudwatchlogs.LogGroup{
+			{LogGroupName: aws.String("/aws/lambda/checkout"), StoredBytes: aws.Int64(2048), RetentionInDays: aws.Int64(30)},
+			{LogGroupName: aws.String("/aws/lambda/payments"), StoredBytes: aws.Int64(512), RetentionInDays: aws.Int64(7)},
+		},
+		NextToken: aws.String("next"),
+	}, nil)
+	logsRcvr.client = mc
+
+	require.NoError(t, logsRcvr.pollLogGroupMetrics(context.Background()))
+	metrics := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, map[string]int64{"/aws/lambda/checkout": 2048}, logGroupValues(t, metrics.At(0)))
+	require.Equal(t, map[string]int64{"/aws/lambda/checkout": 30}, logGroupValues(t, metrics.At(1)))
+	mc.AssertNumberOfCalls(t, "DescribeLogGroupsWithContext", 1)
+}
+
+func TestLogGroupMetricsNamedGroups(t *testing.T) {
+	logsRcvr, sink := newLogGroupMetricsTestReceiver(map[string]StreamConfig{
+		testLogGroupName: {},
+		"missing":        {},
+	})
+	mc := &mockClient{}
+	mc.On("DescribeLogGroupsWithContext", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.DescribeLogGroupsInput) bool {
+		return aws.StringValue(input.LogGroupNamePrefix) == testLogGroupName
+	}), mock.Anything).Return(&cloudwatchlogs.DescribeLogGroupsOutput{
+		LogGroups: []*cloudwatchlogs.LogGroup{
+			// the prefix of a named log group also matches the log groups whose name starts with its name
+			{LogGroupName: aws.String(testLogGroupName + "-staging"), StoredBytes: aws.Int64(1), RetentionInDays: aws.Int64(1)},
+			{LogGroupName: aws.String(testLogGroupName), StoredBytes: aws.Int64(4096), RetentionInDays: aws.Int64(90)},
+		},
+	}, nil)
+	mc.On("DescribeLogGroupsWithContext", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.DescribeLogGroupsInput) bool {
+		return aws.StringValue(input.LogGroupNamePrefix) == "missing"
+	}), mock.Anything).Return(&cloudwatchlogs.DescribeLogGroupsOutput{}, nil)
+	logsRcvr.client = mc
+
+	require.NoError(t, logsRcvr.pollLogGroupMetrics(context.Background()))
+	metrics := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, map[string]int64{testLogGroupName: 4096}, logGroupValues(t, metrics.At(0)))
+	require.Equal(t, map[string]int64{testLogGroupName: 90}, logGroupValues(t, metrics.At(1)))
+}
+
+func TestLogGroupMetricsDescribeError(t *testing.T) {
+	logsRcvr, sink := newLogGroupMetricsTestReceiver(nil)
+	mc := &mockClient{}
+	mc.On("DescribeLogGroupsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&cloudwatchlogs.DescribeLogGroupsOutput{}, errors.New("throttled"))
+	logsRcvr.client = mc
+
+	require.ErrorContains(t, logsRcvr.pollLogGroupMetrics(context.Background()), "throttled")
+	require.Empty(t, sink.AllMetrics())
+}
+
+func TestLogGroupMetricsPolling(t *testing.T) {
+	logsRcvr, sink := newLogGroupMetricsTestReceiver(nil)
+	logsRcvr.client = defaultMockClient()
+	logsRcvr.stsClient = defaultMockSTSClient()
+
+	require.NoError(t, logsRcvr.Start(context.Background(), componenttest.NewNopHost()))
+	require.Eventually(t, func() bool {
+		return len(sink.AllMetrics()) > 0
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, logsRcvr.Shutdown(context.Background()))
+}
+
+func newLogGroupMetricsTestReceiver(named map[string]StreamConfig) (*logsReceiver, *consumertest.MetricsSink) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.PollInterval = 100 * time.Millisecond
+	cfg.Logs.Mode = modeLogGroupMetrics
+	if named != nil {
+		cfg.Logs.Groups = GroupConfig{NamedConfigs: named}
+	}
+
+	sink := &consumertest.MetricsSink{}
+	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), nil)
+	logsRcvr.metricsConsumer = sink
+	return logsRcvr, sink
+}
+
+// logGroupValues returns the values of the data points of the gauge by the name of their log group
+func logGroupValues(t *testing.T, metric pmetric.Metric) map[string]int64 {
+	values := map[string]int64{}
+	points := metric.Gauge().DataPoints()
+	for i := 0; i < points.Len(); i++ {
+		name, ok := points.At(i).Attributes().Get(logGroupNameAttribute)
+		require.True(t, ok)
+		values[name.Str()] = points.At(i).IntValue()
+	}
+	return values
+}
@@ -16,36 +16,97 @@ package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 )
 
 type logsReceiver struct {
 	region              string
+	partition           string
 	profile             string
 	imdsEndpoint        string
 	pollInterval        time.Duration
 	maxEventsPerRequest int
+	maxEventsPerPoll    int
+	maxDecompressedSize int64
+	bodyFormat          string
+	attributePrefix     string
+	failOnEmpty         bool
+	minTimestamp        *minTimestamp
+	excluder            *messageExcluder
 	nextStartTime       time.Time
-	groupRequests       []groupRequest
-	autodiscover        *AutodiscoverConfig
-	logger              *zap.Logger
-	client              client
-	consumer            consumer.Logs
-	wg                  *sync.WaitGroup
-	doneChan            chan bool
+	resume              *pollResume
+	// groupStartTimes holds the start of the time window of the groups that failed or were paused by their
+	// circuit breaker, they are polled from there until they succeed so that none of their events are missed
+	groupStartTimes map[string]time.Time
+	// unreadGroups collects the groups that fail or are paused in the current time window
+	unreadGroups map[string]time.Time
+	// startTokens are the configured positions the groups are read from until the first time window is read,
+	// they take precedence over the restored checkpoint
+	startTokens map[string]*startToken
+	// emptyPollWarnThreshold is the number of consecutive empty polls of a group after which a warning is logged
+	emptyPollWarnThreshold int
+	// emptyPolls holds the number of consecutive polls of the groups that read no events
+	emptyPolls         map[string]int
+	groupRequests      []groupRequest
+	autodiscover       *AutodiscoverConfig
+	autodiscoverFilter *streamFilter
+	mode               string
+	s3                 *S3Config
+	insights           *InsightsConfig
+	alarms             *AlarmsConfig
+	awsHTTP            *HTTPConfig
+	// s3LastKey is the key of the last export object processed in s3 mode, the objects are listed after it
+	s3LastKey         string
+	severityParser    *severityParser
+	serviceClassifier *serviceClassifier
+	streamAttributes  *streamAttributeParser
+	emf               *EMFConfig
+	circuitBreaker    *circuitBreaker
+	now               func() time.Time
+	accountID         string
+	logger            *zap.Logger
+	client            client
+	s3Client          s3Client
+	insightsClient    insightsClient
+	alarmsClient      alarmsClient
+	stsClient         stsClient
+	consumer          consumer.Logs
+	metricsConsumer   consumer.Metrics
+	id                component.ID
+	storageID         *component.ID
+	storageClient     storage.Client
+	wg                *sync.WaitGroup
+	doneChan          chan bool
+}
+
+// pollResume is where a poll stopped after reaching the maximum number of events per poll,
+// the next poll continues from there using the same time window.
+type pollResume struct {
+	groupIndex int
+	group      string
+	nextToken  string
+	startTime  time.Time
+	endTime    time.Time
 }
 
 type client interface {
@@ -53,9 +114,14 @@ type client interface {
 	FilterLogEventsWithContext(ctx context.Context, input *cloudwatchlogs.FilterLogEventsInput, opts ...request.Option) (*cloudwatchlogs.FilterLogEventsOutput, error)
 }
 
+type stsClient interface {
+	GetCallerIdentityWithContext(ctx context.Context, input *sts.GetCallerIdentityInput, opts ...request.Option) (*sts.GetCallerIdentityOutput, error)
+}
+
 type streamNames struct {
-	group string
-	names []*string
+	group  string
+	names  []*string
+	filter *streamFilter
 }
 
 func (sn *streamNames) request(limit int, nextToken string, st, et *time.Time) *cloudwatchlogs.FilterLogEventsInput {
@@ -78,9 +144,14 @@ func (sn *streamNames) groupName() string {
 	return sn.group
 }
 
+func (sn *streamNames) streamFilter() *streamFilter {
+	return sn.filter
+}
+
 type streamPrefix struct {
 	group  string
 	prefix *string
+	filter *streamFilter
 }
 
 func (sp *streamPrefix) request(limit int, nextToken string, st, et *time.Time) *cloudwatchlogs.FilterLogEventsInput {
@@ -101,18 +172,35 @@ func (sp *streamPrefix) groupName() string {
 	return sp.group
 }
 
+func (sp *streamPrefix) streamFilter() *streamFilter {
+	return sp.filter
+}
+
 type groupRequest interface {
 	request(limit int, nextToken string, st, et *time.Time) *cloudwatchlogs.FilterLogEventsInput
 	groupName() string
+	// streamFilter returns the filter of the events by their log stream, nil if all events are kept
+	streamFilter() *streamFilter
 }
 
 func newLogsReceiver(cfg *Config, logger *zap.Logger, consumer consumer.Logs) *logsReceiver {
 	groups := []groupRequest{}
-	for logGroupName, sc := range cfg.Logs.Groups.NamedConfigs {
+	// the groups are polled in a stable order so that a persisted resume refers to the same group after a restart
+	logGroupNames := make([]string, 0, len(cfg.Logs.Groups.NamedConfigs))
+	for logGroupName := range cfg.Logs.Groups.NamedConfigs {
+		logGroupNames = append(logGroupNames, logGroupName)
+	}
+	sort.Strings(logGroupNames)
+	for _, logGroupName := range logGroupNames {
+		sc := cfg.Logs.Groups.NamedConfigs[logGroupName]
+		filter, err := newStreamFilter(sc)
+		if err != nil {
+			logger.Error("unable to create the stream filter, events of all streams will be collected", zap.String("log group", logGroupName), zap.Error(err))
+		}
 		for _, prefix := range sc.Prefixes {
-			groups = append(groups, &streamPrefix{group: logGroupName, prefix: prefix})
+			groups = append(groups, &streamPrefix{group: logGroupName, prefix: prefix, filter: filter})
 		}
-		groups = append(groups, &streamNames{group: logGroupName, names: sc.Names})
+		groups = append(groups, &streamNames{group: logGroupName, names: sc.Names, filter: filter})
 	}
 
 	// safeguard from using both
@@ -121,24 +209,124 @@ func newLogsReceiver(cfg *Config, logger *zap.Logger, consumer consumer.Logs) *l
 		autodiscover = nil
 	}
 
+	var autodiscoverFilter *streamFilter
+	if autodiscover != nil {
+		var err error
+		autodiscoverFilter, err = newStreamFilter(autodiscover.Streams)
+		if err != nil {
+			logger.Error("unable to create the stream filter, events of all streams will be collected", zap.Error(err))
+		}
+	}
+
+	severityParser, err := newSeverityParser(cfg.Logs.Severity)
+	if err != nil {
+		logger.Error("unable to create the severity parser, severity will not be parsed", zap.Error(err))
+	}
+
+	classifier, err := newServiceClassifier(cfg.Logs.Services)
+	if err != nil {
+		logger.Error("unable to create the service classifier, the aws service will not be set", zap.Error(err))
+	}
+
+	streamAttributes, err := newStreamAttributeParser(cfg.Logs.StreamAttributes)
+	if err != nil {
+		logger.Error("unable to create the stream attribute parser, stream attributes will not be extracted", zap.Error(err))
+	}
+
+	minTimestamp, err := parseMinTimestamp(cfg.Logs.MinEventTimestamp)
+	if err != nil {
+		logger.Error("unable to parse the minimum event timestamp, events will not be discarded", zap.Error(err))
+	}
+
+	excluder, err := newMessageExcluder(cfg.Logs.ExcludePatterns)
+	if err != nil {
+		logger.Error("unable to compile the exclude patterns, events will not be excluded", zap.Error(err))
+	}
+
+	if err = registerViews(); err != nil {
+		logger.Error("unable to register the metrics of the receiver", zap.Error(err))
+	}
+
+	var breaker *circuitBreaker
+	if cfg.Logs.CircuitBreaker != nil {
+		breaker, err = newCircuitBreaker(cfg.Logs.CircuitBreaker, cfg.ID().String())
+		if err != nil {
+			logger.Error("unable to register the circuit breaker metrics, log groups will be polled regardless of failures", zap.Error(err))
+		}
+	}
+
+	var awsHTTP *HTTPConfig
+	if cfg.AWS != nil {
+		awsHTTP = &cfg.AWS.HTTP
+	}
+
 	return &logsReceiver{
-		region:              cfg.Region,
-		profile:             cfg.Profile,
-		consumer:            consumer,
-		maxEventsPerRequest: cfg.Logs.MaxEventsPerRequest,
-		imdsEndpoint:        cfg.IMDSEndpoint,
-		autodiscover:        autodiscover,
-		pollInterval:        cfg.Logs.PollInterval,
-		nextStartTime:       time.Now().Add(-cfg.Logs.PollInterval),
-		groupRequests:       groups,
-		logger:              logger,
-		wg:                  &sync.WaitGroup{},
-		doneChan:            make(chan bool),
+		region:                 cfg.Region,
+		partition:              regionPartition(cfg.Region),
+		profile:                cfg.Profile,
+		consumer:               consumer,
+		maxEventsPerRequest:    cfg.Logs.MaxEventsPerRequest,
+		maxEventsPerPoll:       cfg.Logs.MaxEventsPerPoll,
+		maxDecompressedSize:    cfg.Logs.MaxDecompressedSize,
+		bodyFormat:             cfg.Logs.BodyFormat,
+		attributePrefix:        cfg.Logs.AttributePrefix,
+		failOnEmpty:            cfg.Logs.FailOnEmpty,
+		minTimestamp:           minTimestamp,
+		excluder:               excluder,
+		imdsEndpoint:           cfg.IMDSEndpoint,
+		autodiscover:           autodiscover,
+		autodiscoverFilter:     autodiscoverFilter,
+		pollInterval:           cfg.Logs.PollInterval,
+		nextStartTime:          time.Now().Add(-cfg.Logs.PollInterval),
+		groupStartTimes:        map[string]time.Time{},
+		unreadGroups:           map[string]time.Time{},
+		startTokens:            newStartTokens(cfg.Logs.Groups.StartTokens),
+		emptyPollWarnThreshold: cfg.Logs.EmptyPollWarnThreshold,
+		emptyPolls:             map[string]int{},
+		groupRequests:          groups,
+		mode:                   cfg.Logs.Mode,
+		s3:                     cfg.Logs.S3,
+		insights:               cfg.Logs.Insights,
+		alarms:                 cfg.Logs.Alarms,
+		awsHTTP:                awsHTTP,
+		severityParser:         severityParser,
+		serviceClassifier:      classifier,
+		streamAttributes:       streamAttributes,
+		emf:                    cfg.Logs.EMF,
+		circuitBreaker:         breaker,
+		now:                    time.Now,
+		logger:                 logger,
+		id:                     cfg.ID(),
+		storageID:              cfg.StorageID,
+		storageClient:          storage.NewNopClient(),
+		wg:                     &sync.WaitGroup{},
+		doneChan:               make(chan bool),
 	}
 }
 
 func (l *logsReceiver) Start(ctx context.Context, host component.Host) error {
 	l.logger.Debug("starting to poll for Cloudwatch logs")
+	if l.mode == modePoll {
+		if err := l.checkGroups(ctx); err != nil {
+			if l.failOnEmpty {
+				return err
+			}
+			l.logger.Warn("starting to poll without log groups matching the configured groups", zap.Error(err))
+		}
+	}
+	storageClient, err := getStorageClient(ctx, host, l.storageID, l.id)
+	if err != nil {
+		return fmt.Errorf("failed to set up storage: %w", err)
+	}
+	l.storageClient = storageClient
+	if l.mode == modeS3 {
+		if err = l.loadS3Cursor(ctx); err != nil {
+			l.logger.Error("unable to restore the s3 cursor, the export objects are listed from the start", zap.Error(err))
+		}
+	} else if err = l.loadCheckpoint(ctx); err != nil {
+		l.logger.Error("unable to restore the poll from the checkpoint, polling starts from the current time", zap.Error(err))
+	}
+	l.resolveAccountID(ctx)
 	l.wg.Add(1)
 	go l.startPolling(ctx)
 	return nil
@@ -148,7 +336,7 @@ func (l *logsReceiver) Shutdown(ctx context.Context) error {
 	l.logger.Debug("shutting down logs receiver")
 	close(l.doneChan)
 	l.wg.Wait()
-	return nil
+	return l.storageClient.Close(ctx)
 }
 
 func (l *logsReceiver) startPolling(ctx context.Context) {
@@ -162,7 +350,36 @@ func (l *logsReceiver) startPolling(ctx context.Context) {
 		case <-l.doneChan:
 			return
 		case <-t.C:
-			if l.autodiscover != nil {
+			if l.mode == modeS3 {
+				if err := l.pollS3(ctx); err != nil {
+					l.logger.Error("there was an error reading log exports from s3", zap.Error(err))
+				}
+				continue
+			}
+
+			if l.mode == modeInsights {
+				if err := l.pollInsights(ctx); err != nil {
+					l.logger.Error("there was an error running the insights query", zap.Error(err))
+				}
+				continue
+			}
+
+			if l.mode == modeAlarms {
+				if err := l.pollAlarms(ctx); err != nil {
+					l.logger.Error("there was an error polling the alarm history", zap.Error(err))
+				}
+				continue
+			}
+
+			if l.mode == modeLogGroupMetrics {
+				if err := l.pollLogGroupMetrics(ctx); err != nil {
+					l.logger.Error("there was an error emitting the log group metrics", zap.Error(err))
+				}
+				continue
+			}
+
+			// the discovered groups are kept while resuming a poll so that it continues with the same groups
+			if l.autodiscover != nil && (l.resume == nil || len(l.groupRequests) == 0) {
 				group, err := l.discoverGroups(ctx, l.autodiscover)
 				if err != nil {
 					l.logger.Error("unable to perform discovery of log groups", zap.Error(err))
@@ -175,6 +392,9 @@ func (l *logsReceiver) startPolling(ctx context.Context) {
 			if err != nil {
 				l.logger.Error("there was an error during the poll", zap.Error(err))
 			}
+			if err = l.writeCheckpoint(ctx); err != nil {
+				l.logger.Error("unable to write the checkpoint of the poll", zap.Error(err))
+			}
 		}
 	}
 }
@@ -183,39 +403,141 @@ func (l *logsReceiver) poll(ctx context.Context) error {
 	var errs error
 	startTime := l.nextStartTime
 	endTime := time.Now()
-	for _, r := range l.groupRequests {
-		if err := l.pollForLogs(ctx, r, startTime, endTime); err != nil {
+	groupIndex, nextToken := 0, ""
+	if l.resume != nil {
+		startTime, endTime = l.resume.startTime, l.resume.endTime
+		// the time window is read again from the first group if the groups changed since the poll stopped
+		if l.resume.groupIndex < len(l.groupRequests) && l.groupRequests[l.resume.groupIndex].groupName() == l.resume.group {
+			groupIndex, nextToken = l.resume.groupIndex, l.resume.nextToken
+		}
+		l.resume = nil
+	}
+
+	// the events read from every group that was polled successfully, the groups that read none are recorded as empty polls
+	polled := map[string]int{}
+	defer l.recordPolledGroups(polled)
+
+	remaining := l.maxEventsPerPoll
+	for i := groupIndex; i < len(l.groupRequests); i++ {
+		group := l.groupRequests[i].groupName()
+		groupStartTime := startTime
+		if st, ok := l.groupStartTimes[group]; ok && st.Before(startTime) {
+			groupStartTime = st
+		}
+		st := l.startTokens[group]
+		if st != nil && !st.startTime.IsZero() {
+			groupStartTime = st.startTime
+		}
+		if !l.circuitBreaker.allow(group) {
+			l.logger.Debug("skipping the log group while its circuit breaker is open", zap.String("log group", group))
+			l.markUnread(group, groupStartTime)
+			nextToken = ""
+			continue
+		}
+		if st != nil && st.nextToken != "" {
+			nextToken, st.nextToken = st.nextToken, ""
+		}
+		resumeToken, count, err := l.pollForLogs(ctx, l.groupRequests[i], groupStartTime, endTime, nextToken, remaining)
+		var denied *accessDeniedError
+		if errors.As(err, &denied) {
+			l.recordAccessDenied(group)
+		}
+		if err != nil {
 			errs = multierr.Append(errs, err)
+			l.markUnread(group, groupStartTime)
+		} else {
+			polled[group] += count
+		}
+		if l.circuitBreaker.recordResult(group, err) {
+			l.logger.Warn("pausing the polling of the log group after consecutive failures",
+				zap.String("log group", group), zap.Duration("cooldown", l.circuitBreaker.cooldown))
+		}
+		nextToken = ""
+		if l.maxEventsPerPoll <= 0 {
+			continue
+		}
+
+		remaining -= count
+		if remaining > 0 {
+			continue
+		}
+		if resumeToken != "" {
+			l.resume = &pollResume{groupIndex: i, group: group, nextToken: resumeToken, startTime: startTime, endTime: endTime}
+			return errs
+		}
+		if i+1 < len(l.groupRequests) {
+			l.resume = &pollResume{groupIndex: i + 1, group: l.groupRequests[i+1].groupName(), startTime: startTime, endTime: endTime}
+			return errs
 		}
 	}
 	l.nextStartTime = endTime
+	l.groupStartTimes, l.unreadGroups = l.unreadGroups, map[string]time.Time{}
+	l.startTokens = nil
 	return errs
 }
 
-func (l *logsReceiver) pollForLogs(ctx context.Context, pc groupRequest, startTime, endTime time.Time) error {
+// markUnread keeps the start of the time window of a group whose events were not read,
+// so that the next time window of the group starts there.
+func (l *logsReceiver) markUnread(group string, startTime time.Time) {
+	if _, ok := l.unreadGroups[group]; !ok {
+		l.unreadGroups[group] = startTime
+	}
+}
+
+// pollForLogs reads the events of the group page by page starting from the given token. If maxEvents
+// is greater than 0 reading stops once that many events have been read, returning the token of the
+// next page so that the group can be resumed. An empty token is returned once all pages are read.
+func (l *logsReceiver) pollForLogs(ctx context.Context, pc groupRequest, startTime, endTime time.Time, startToken string, maxEvents int) (string, int, error) {
 	err := l.ensureSession()
 	if err != nil {
-		return err
+		return "", 0, err
 	}
-	nextToken := aws.String("")
+	nextToken := aws.String(startToken)
 
+	count := 0
 	for nextToken != nil {
 		select {
 		// if done, we want to stop processing paginated stream of events
 		case _, ok := <-l.doneChan:
 			if !ok {
-				return nil
+				return "", count, nil
 			}
 		default:
-			input := pc.request(l.maxEventsPerRequest, *nextToken, &startTime, &endTime)
+			limit := l.maxEventsPerRequest
+			if maxEvents > 0 {
+				if count >= maxEvents {
+					return *nextToken, count, nil
+				}
+				if maxEvents-count < limit {
+					limit = maxEvents - count
+				}
+			}
+			input := pc.request(limit, *nextToken, &startTime, &endTime)
 			resp, err := l.client.FilterLogEventsWithContext(ctx, input)
+			if denied := asAccessDenied(pc.groupName(), err); denied != nil {
+				return "", count, denied
+			}
 			if err != nil {
-				l.logger.Error("unable to retrieve logs from cloudwatch", zap.String("log group", pc.groupName()), zap.Error(err))
-				break
+				return "", count, fmt.Errorf("unable to retrieve logs from cloudwatch for log group %q: %w", pc.groupName(), err)
+			}
+			count += len(resp.Events)
+			now := l.now()
+			l.recordIngestionLag(pc.groupName(), now, resp.Events)
+			events := resp
+			if filter := pc.streamFilter(); filter != nil {
+				events = &cloudwatchlogs.FilterLogEventsOutput{Events: filter.filter(resp.Events)}
+			}
+			if l.minTimestamp != nil {
+				events = &cloudwatchlogs.FilterLogEventsOutput{Events: l.minTimestamp.filter(now, events.Events)}
+			}
+			observedTime := pcommon.NewTimestampFromTime(now)
+			logs, metrics := l.processEvents(observedTime, pc.groupName(), events)
+			if metrics.DataPointCount() > 0 && l.metricsConsumer != nil {
+				if err = l.metricsConsumer.ConsumeMetrics(ctx, metrics); err != nil {
+					l.logger.Error("unable to consume metrics", zap.Error(err))
+				}
 			}
-			observedTime := pcommon.NewTimestampFromTime(time.Now())
-			logs := l.processEvents(observedTime, pc.groupName(), resp)
-			if logs.LogRecordCount() > 0 {
+			if logs.LogRecordCount() > 0 && l.consumer != nil {
 				if err = l.consumer.ConsumeLogs(ctx, logs); err != nil {
 					l.logger.Error("unable to consume logs", zap.Error(err))
 					break
@@ -224,11 +546,15 @@ func (l *logsReceiver) pollForLogs(ctx context.Context, pc groupRequest, startTi
 			nextToken = resp.NextToken
 		}
 	}
-	return nil
+	return "", count, nil
 }
 
-func (l *logsReceiver) processEvents(now pcommon.Timestamp, logGroupName string, output *cloudwatchlogs.FilterLogEventsOutput) plog.Logs {
+// processEvents converts the events into log records. When the embedded metric format is enabled the metrics
+// embedded in events are extracted as well, the log records of those events are only kept if configured.
+func (l *logsReceiver) processEvents(now pcommon.Timestamp, logGroupName string, output *cloudwatchlogs.FilterLogEventsOutput) (plog.Logs, pmetric.Metrics) {
 	logs := plog.NewLogs()
+	metrics := pmetric.NewMetrics()
+	excluded := 0
 	for _, e := range output.Events {
 		if e.Timestamp == nil {
 			l.logger.Error("unable to determine timestamp of event as the timestamp is nil")
@@ -245,22 +571,73 @@ func (l *logsReceiver) processEvents(now pcommon.Timestamp, logGroupName string,
 			continue
 		}
 
-		rl := logs.ResourceLogs().AppendEmpty()
-		resourceAttributes := rl.Resource().Attributes()
-		resourceAttributes.PutStr("aws.region", l.region)
-		resourceAttributes.PutStr("cloudwatch.log.group.name", logGroupName)
-		if e.LogStreamName != nil {
-			resourceAttributes.PutStr("cloudwatch.log.stream", *e.LogStreamName)
+		message, err := decompressMessage(*e.Message, l.maxDecompressedSize)
+		if err != nil {
+			l.logger.Error("unable to decompress the message of the event, skipping entry", zap.String("event.id", *e.EventId), zap.Error(err))
+			continue
+		}
+
+		if l.excluder.excluded(message) {
+			excluded++
+			continue
 		}
 
+		ts := time.UnixMilli(*e.Timestamp)
+		if l.emf != nil {
+			if event, ok := parseEMF(message); ok {
+				extracted := pmetric.NewMetricSlice()
+				if err := event.appendMetrics(extracted, ts); err != nil {
+					l.logger.Error("unable to extract embedded metrics from event", zap.String("event.id", *e.EventId), zap.Error(err))
+				} else {
+					rm := metrics.ResourceMetrics().AppendEmpty()
+					l.putResourceAttributes(rm.Resource().Attributes(), logGroupName, e)
+					extracted.MoveAndAppendTo(rm.ScopeMetrics().AppendEmpty().Metrics())
+				}
+				if !l.emf.KeepLogs {
+					continue
+				}
+			}
+		}
+
+		rl := logs.ResourceLogs().AppendEmpty()
+		l.putResourceAttributes(rl.Resource().Attributes(), logGroupName, e)
+
 		logRecord := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
 		logRecord.SetObservedTimestamp(now)
-		ts := time.UnixMilli(*e.Timestamp)
 		logRecord.SetTimestamp(pcommon.NewTimestampFromTime(ts))
-		logRecord.Body().SetStr(*e.Message)
+		setBody(logRecord, message, l.bodyFormat, l.attributePrefix)
 		logRecord.Attributes().PutStr("id", *e.EventId)
+		if l.severityParser != nil {
+			l.severityParser.parse(message, logRecord)
+		}
+	}
+	l.recordExcludedEvents(logGroupName, excluded)
+	return logs, metrics
+}
+
+func (l *logsReceiver) putResourceAttributes(resourceAttributes pcommon.Map, logGroupName string, e *cloudwatchlogs.FilteredLogEvent) {
+	l.putCloudAttributes(resourceAttributes)
+	resourceAttributes.PutStr("cloudwatch.log.group.name", logGroupName)
+	if l.serviceClassifier != nil {
+		if service := l.serviceClassifier.classify(logGroupName); service != "" {
+			resourceAttributes.PutStr(serviceAttribute, service)
+		}
+	}
+	if e.LogStreamName != nil {
+		resourceAttributes.PutStr("cloudwatch.log.stream", *e.LogStreamName)
+		l.streamAttributes.parse(*e.LogStreamName, resourceAttributes)
+	}
+}
+
+// putCloudAttributes adds the attributes identifying the AWS region and account the logs were collected from
+func (l *logsReceiver) putCloudAttributes(resourceAttributes pcommon.Map) {
+	resourceAttributes.PutStr("aws.region", l.region)
+	resourceAttributes.PutStr("aws.partition", l.partition)
+	resourceAttributes.PutStr("cloud.provider", "aws")
+	resourceAttributes.PutStr("cloud.region", l.region)
+	if l.accountID != "" {
+		resourceAttributes.PutStr("cloud.account.id", l.accountID)
 	}
-	return logs
 }
 
 func (l *logsReceiver) discoverGroups(ctx context.Context, auto *AutodiscoverConfig) ([]groupRequest, error) {
@@ -297,16 +674,16 @@ func (l *logsReceiver) discoverGroups(ctx context.Context, auto *AutodiscoverCon
 			l.logger.Debug("discovered log group", zap.String("log group", lg.GoString()))
 			// default behavior is to collect all if not stream filtered
 			if len(auto.Streams.Names) == 0 && len(auto.Streams.Prefixes) == 0 {
-				groups = append(groups, &streamNames{group: *lg.LogGroupName})
+				groups = append(groups, &streamNames{group: *lg.LogGroupName, filter: l.autodiscoverFilter})
 				continue
 			}
 
 			for _, prefix := range auto.Streams.Prefixes {
-				groups = append(groups, &streamPrefix{group: *lg.LogGroupName, prefix: prefix})
+				groups = append(groups, &streamPrefix{group: *lg.LogGroupName, prefix: prefix, filter: l.autodiscoverFilter})
 			}
 
 			if len(auto.Streams.Names) > 0 {
-				groups = append(groups, &streamNames{group: *lg.LogGroupName, names: auto.Streams.Names})
+				groups = append(groups, &streamNames{group: *lg.LogGroupName, names: auto.Streams.Names, filter: l.autodiscoverFilter})
 			}
 		}
 		nextToken = dlgResults.NextToken
@@ -315,11 +692,59 @@ func (l *logsReceiver) discoverGroups(ctx context.Context, auto *AutodiscoverCon
 	return groups, nil
 }
 
+// resolveAccountID looks up the account id of the configured credentials once at start so that it
+// can be attached to all emitted logs without calling STS on every poll. Logs are emitted without
+// the account id if it cannot be resolved.
+func (l *logsReceiver) resolveAccountID(ctx context.Context) {
+	err := l.ensureSTSSession()
+	if err != nil {
+		l.logger.Warn("unable to establish a session to resolve the account id, logs will not include cloud.account.id", zap.Error(err))
+		return
+	}
+	identity, err := l.stsClient.GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		l.logger.Warn("unable to resolve the account id, logs will not include cloud.account.id", zap.Error(err))
+		return
+	}
+	l.accountID = aws.StringValue(identity.Account)
+	l.logger.Info("resolved account id", zap.String("account.id", l.accountID), zap.String("arn", aws.StringValue(identity.Arn)))
+}
+
+func (l *logsReceiver) ensureSTSSession() error {
+	if l.stsClient != nil {
+		return nil
+	}
+	s, err := l.newSession()
+	if err != nil {
+		return err
+	}
+	l.stsClient = sts.New(s)
+	return nil
+}
+
 func (l *logsReceiver) ensureSession() error {
 	if l.client != nil {
 		return nil
 	}
+	s, err := l.newSession()
+	l.client = cloudwatchlogs.New(s)
+	return err
+}
+
+// regionPartition returns the partition of the region, e.g. aws-cn for cn-north-1. Regions
+// that are not known to the SDK are assumed to be in the standard aws partition.
+func regionPartition(region string) string {
+	if p, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); ok {
+		return p.ID()
+	}
+	return endpoints.AwsPartitionID
+}
+
+func (l *logsReceiver) newSession() (*session.Session, error) {
 	awsConfig := aws.NewConfig().WithRegion(l.region)
+	if l.awsHTTP != nil {
+		applyHTTPConfig(awsConfig, l.awsHTTP)
+	}
 	options := session.Options{
 		Config: *awsConfig,
 	}
@@ -329,7 +754,27 @@ func (l *logsReceiver) ensureSession() error {
 	if l.profile != "" {
 		options.Profile = l.profile
 	}
-	s, err := session.NewSessionWithOptions(options)
-	l.client = cloudwatchlogs.New(s)
-	return err
+	return session.NewSessionWithOptions(options)
+}
+
+// applyHTTPConfig sets the retries and the HTTP client of the configuration of the AWS SDK. The SDK keeps
+// its default client if none of the settings of the client are set.
+func applyHTTPConfig(awsConfig *aws.Config, cfg *HTTPConfig) {
+	if cfg.MaxRetries != nil {
+		awsConfig.WithMaxRetries(*cfg.MaxRetries)
+	}
+	if cfg.Timeout == 0 && cfg.MaxIdleConns == 0 && cfg.MaxIdleConnsPerHost == 0 && cfg.IdleConnTimeout == 0 {
+		return
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.MaxIdleConns > 0 {
+		transport.MaxIdleConns = cfg.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	if cfg.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	awsConfig.WithHTTPClient(&http.Client{Timeout: cfg.Timeout, Transport: transport})
 }
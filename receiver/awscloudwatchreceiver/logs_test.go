@@ -16,23 +16,29 @@ package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	awsclient "github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestStart(t *testing.T) {
@@ -42,9 +48,12 @@ func TestStart(t *testing.T) {
 
 	sink := &consumertest.LogsSink{}
 	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+	logsRcvr.stsClient = defaultMockSTSClient()
 
 	err := logsRcvr.Start(context.Background(), componenttest.NewNopHost())
 	require.NoError(t, err)
+	// the account id is resolved before the first poll
+	require.Equal(t, testAccountID, logsRcvr.accountID)
 
 	err = logsRcvr.Shutdown(context.Background())
 	require.NoError(t, err)
@@ -65,6 +74,7 @@ func TestPrefixedConfig(t *testing.T) {
 	sink := &consumertest.LogsSink{}
 	alertRcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
 	alertRcvr.client = defaultMockClient()
+	alertRcvr.stsClient = defaultMockSTSClient()
 
 	err := alertRcvr.Start(context.Background(), componenttest.NewNopHost())
 	require.NoError(t, err)
@@ -97,6 +107,7 @@ func TestPrefixedNamedStreamsConfig(t *testing.T) {
 	sink := &consumertest.LogsSink{}
 	alertRcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
 	alertRcvr.client = defaultMockClient()
+	alertRcvr.stsClient = defaultMockSTSClient()
 
 	err := alertRcvr.Start(context.Background(), componenttest.NewNopHost())
 	require.NoError(t, err)
@@ -131,6 +142,7 @@ func TestDiscovery(t *testing.T) {
 	sink := &consumertest.LogsSink{}
 	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
 	logsRcvr.client = defaultMockClient()
+	logsRcvr.stsClient = defaultMockSTSClient()
 
 	require.NoError(t, logsRcvr.Start(context.Background(), componenttest.NewNopHost()))
 	require.Eventually(t, func() bool {
@@ -157,13 +169,14 @@ func TestShutdownWhileCollecting(t *testing.T) {
 	sink := &consumertest.LogsSink{}
 	alertRcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
 	doneChan := make(chan time.Time, 1)
-	mc := &mockClient{}
+	mc := describeGroupsClient(testLogGroupName)
 	mc.On("FilterLogEventsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.FilterLogEventsOutput{
 		Events:    []*cloudwatchlogs.FilteredLogEvent{},
 		NextToken: aws.String("next"),
 	}, nil).
 		WaitUntil(doneChan)
 	alertRcvr.client = mc
+	alertRcvr.stsClient = defaultMockSTSClient()
 
 	err := alertRcvr.Start(context.Background(), componenttest.NewNopHost())
 	require.NoError(t, err)
@@ -176,6 +189,218 @@ func TestShutdownWhileCollecting(t *testing.T) {
 	require.NoError(t, alertRcvr.Shutdown(context.Background()))
 }
 
+func TestAccountID(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.PollInterval = 1 * time.Second
+	cfg.Logs.Groups = GroupConfig{
+		NamedConfigs: map[string]StreamConfig{
+			testLogGroupName: {
+				Names: []*string{&testLogStreamName},
+			},
+		},
+	}
+
+	sink := &consumertest.LogsSink{}
+	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+	logsRcvr.client = defaultMockClient()
+	mc := defaultMockSTSClient()
+	logsRcvr.stsClient = mc
+
+	require.NoError(t, logsRcvr.Start(context.Background(), componenttest.NewNopHost()))
+	require.Eventually(t, func() bool {
+		return len(sink.AllLogs()) >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, logsRcvr.Shutdown(context.Background()))
+
+	// the account id is only resolved once, not on every poll
+	mc.AssertNumberOfCalls(t, "GetCallerIdentityWithContext", 1)
+	for _, logs := range sink.AllLogs() {
+		accountID, ok := logs.ResourceLogs().At(0).Resource().Attributes().Get("cloud.account.id")
+		require.True(t, ok)
+		require.Equal(t, testAccountID, accountID.Str())
+	}
+}
+
+func TestAccountIDError(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.PollInterval = 1 * time.Second
+	cfg.Logs.Groups = GroupConfig{
+		NamedConfigs: map[string]StreamConfig{
+			testLogGroupName: {
+				Names: []*string{&testLogStreamName},
+			},
+		},
+	}
+
+	sink := &consumertest.LogsSink{}
+	core, observedLogs := observer.New(zap.WarnLevel)
+	logsRcvr := newLogsReceiver(cfg, zap.New(core), sink)
+	logsRcvr.client = defaultMockClient()
+	mc := &mockSTSClient{}
+	mc.On("GetCallerIdentityWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		(*sts.GetCallerIdentityOutput)(nil), errors.New("access denied"))
+	logsRcvr.stsClient = mc
+
+	require.NoError(t, logsRcvr.Start(context.Background(), componenttest.NewNopHost()))
+	require.Equal(t, 1, observedLogs.FilterMessageSnippet("unable to resolve the account id").Len())
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() > 0
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, logsRcvr.Shutdown(context.Background()))
+
+	// logs are still emitted without the account id when it could not be resolved
+	_, ok := sink.AllLogs()[0].ResourceLogs().At(0).Resource().Attributes().Get("cloud.account.id")
+	require.False(t, ok)
+}
+
+func TestRegionAttributes(t *testing.T) {
+	cases := []struct {
+		region    string
+		partition string
+	}{
+		{region: "us-west-1", partition: "aws"},
+		{region: "eu-central-2", partition: "aws"},
+		{region: "cn-north-1", partition: "aws-cn"},
+		{region: "cn-northwest-1", partition: "aws-cn"},
+		{region: "us-gov-west-1", partition: "aws-us-gov"},
+		{region: "us-iso-east-1", partition: "aws-iso"},
+		{region: "us-isob-east-1", partition: "aws-iso-b"},
+		{region: "xx-unknown-1", partition: "aws"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.region, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Region = tc.region
+			cfg.Logs.Groups = GroupConfig{
+				NamedConfigs: map[string]StreamConfig{
+					testLogGroupName: {
+						Names: []*string{&testLogStreamName},
+					},
+				},
+			}
+
+			sink := &consumertest.LogsSink{}
+			logsRcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+			logsRcvr.client = defaultMockClient()
+			require.NoError(t, logsRcvr.poll(context.Background()))
+			require.Len(t, sink.AllLogs(), 1)
+
+			attrs := sink.AllLogs()[0].ResourceLogs().At(0).Resource().Attributes().AsRaw()
+			require.Equal(t, "aws", attrs["cloud.provider"])
+			require.Equal(t, tc.region, attrs["cloud.region"])
+			require.Equal(t, tc.region, attrs["aws.region"])
+			require.Equal(t, tc.partition, attrs["aws.partition"])
+		})
+	}
+}
+
+func TestAWSHTTPConfig(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.AWS = &AWSConfig{
+		HTTP: HTTPConfig{
+			Timeout:             5 * time.Second,
+			MaxRetries:          aws.Int(7),
+			MaxIdleConns:        20,
+			MaxIdleConnsPerHost: 4,
+			IdleConnTimeout:     30 * time.Second,
+		},
+	}
+
+	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), &consumertest.LogsSink{})
+	require.NoError(t, logsRcvr.ensureSession())
+	clientConfig := logsRcvr.client.(*cloudwatchlogs.CloudWatchLogs).Config
+	require.Equal(t, 7, aws.IntValue(clientConfig.MaxRetries))
+	require.Equal(t, 7, logsRcvr.client.(*cloudwatchlogs.CloudWatchLogs).MaxRetries())
+	require.Equal(t, 5*time.Second, clientConfig.HTTPClient.Timeout)
+	transport, ok := clientConfig.HTTPClient.Transport.(*http.Transport)
+	require.True(t, ok)
+	require.Equal(t, 20, transport.MaxIdleConns)
+	require.Equal(t, 4, transport.MaxIdleConnsPerHost)
+	require.Equal(t, 30*time.Second, transport.IdleConnTimeout)
+	// the other settings of the transport are the defaults of the Go HTTP client
+	require.Equal(t, http.DefaultTransport.(*http.Transport).TLSHandshakeTimeout, transport.TLSHandshakeTimeout)
+}
+
+func TestAWSHTTPConfigDefaults(t *testing.T) {
+	cases := []struct {
+		name string
+		aws  *AWSConfig
+	}{
+		{name: "no aws config"},
+		{name: "empty http config", aws: &AWSConfig{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Region = "us-west-1"
+			cfg.AWS = tc.aws
+
+			logsRcvr := newLogsReceiver(cfg, zap.NewNop(), &consumertest.LogsSink{})
+			require.NoError(t, logsRcvr.ensureSession())
+			client := logsRcvr.client.(*cloudwatchlogs.CloudWatchLogs)
+			require.Same(t, http.DefaultClient, client.Config.HTTPClient)
+			require.Equal(t, aws.UseServiceDefaultRetries, aws.IntValue(client.Config.MaxRetries))
+			require.Equal(t, awsclient.DefaultRetryerMaxNumRetries, client.MaxRetries())
+		})
+	}
+}
+
+func TestMaxEventsPerPoll(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.MaxEventsPerRequest = 10
+	cfg.Logs.MaxEventsPerPoll = 15
+	cfg.Logs.Groups = GroupConfig{
+		NamedConfigs: map[string]StreamConfig{
+			testLogGroupName: {
+				Names: []*string{&testLogStreamName},
+			},
+		},
+	}
+
+	sink := &consumertest.LogsSink{}
+	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+	pc := &pagedClient{totalEvents: 25}
+	logsRcvr.client = pc
+
+	// the first poll stops once the cap is reached, requesting only the remaining events on the last page
+	require.NoError(t, logsRcvr.poll(context.Background()))
+	require.Equal(t, 15, sink.LogRecordCount())
+	require.Len(t, pc.requests, 2)
+	require.Equal(t, int64(5), aws.Int64Value(pc.requests[1].Limit))
+	require.NotNil(t, logsRcvr.resume)
+	firstStartTime := logsRcvr.nextStartTime
+
+	// the second poll resumes from the saved token within the same time window
+	require.NoError(t, logsRcvr.poll(context.Background()))
+	require.Equal(t, 25, sink.LogRecordCount())
+	require.Len(t, pc.requests, 3)
+	require.Equal(t, "15", aws.StringValue(pc.requests[2].NextToken))
+	require.Equal(t, pc.requests[0].StartTime, pc.requests[2].StartTime)
+	require.Equal(t, pc.requests[0].EndTime, pc.requests[2].EndTime)
+	require.Nil(t, logsRcvr.resume)
+	require.True(t, logsRcvr.nextStartTime.After(firstStartTime))
+
+	// the following poll starts a new time window from the beginning
+	require.NoError(t, logsRcvr.poll(context.Background()))
+	require.Nil(t, pc.requests[3].NextToken)
+	require.Equal(t, pc.requests[2].EndTime, pc.requests[3].StartTime)
+}
+
+func defaultMockSTSClient() *mockSTSClient {
+	mc := &mockSTSClient{}
+	mc.On("GetCallerIdentityWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&sts.GetCallerIdentityOutput{
+			Account: aws.String(testAccountID),
+			Arn:     aws.String("arn:aws:iam::" + testAccountID + ":user/otel"),
+			UserId:  aws.String("AIDAEXAMPLE"),
+		}, nil)
+	return mc
+}
+
 func defaultMockClient() client {
 	mc := &mockClient{}
 	mc.On("DescribeLogGroupsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
@@ -348,6 +573,7 @@ func compareLogRecord(expected, actual plog.LogRecord) error {
 }
 
 var (
+	testAccountID        = "123456789012"
 	testLogGroupName     = "test-log-group-name"
 	testLogStreamName    = "test-log-stream-name"
 	testLogStreamPrefix  = "test-log-stream"
@@ -371,6 +597,55 @@ func (mc *mockClient) FilterLogEventsWithContext(ctx context.Context, input *clo
 	return args.Get(0).(*cloudwatchlogs.FilterLogEventsOutput), args.Error(1)
 }
 
+type mockSTSClient struct {
+	mock.Mock
+}
+
+func (mc *mockSTSClient) GetCallerIdentityWithContext(ctx context.Context, input *sts.GetCallerIdentityInput, opts ...request.Option) (*sts.GetCallerIdentityOutput, error) {
+	args := mc.Called(ctx, input, opts)
+	return args.Get(0).(*sts.GetCallerIdentityOutput), args.Error(1)
+}
+
+// pagedClient returns totalEvents events across pages of at most the requested limit,
+// the token of each page is the offset of its first event.
+type pagedClient struct {
+	client
+	totalEvents int
+	requests    []*cloudwatchlogs.FilterLogEventsInput
+}
+
+// DescribeLogGroupsWithContext describes the log group named by the prefix, so that any named log group exists
+func (pc *pagedClient) DescribeLogGroupsWithContext(ctx context.Context, input *cloudwatchlogs.DescribeLogGroupsInput, opts ...request.Option) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+	return &cloudwatchlogs.DescribeLogGroupsOutput{
+		LogGroups: []*cloudwatchlogs.LogGroup{{LogGroupName: input.LogGroupNamePrefix}},
+	}, nil
+}
+
+func (pc *pagedClient) FilterLogEventsWithContext(ctx context.Context, input *cloudwatchlogs.FilterLogEventsInput, opts ...request.Option) (*cloudwatchlogs.FilterLogEventsOutput, error) {
+	pc.requests = append(pc.requests, input)
+	offset := 0
+	if input.NextToken != nil {
+		offset, _ = strconv.Atoi(*input.NextToken)
+	}
+	output := &cloudwatchlogs.FilterLogEventsOutput{}
+	end := offset + int(aws.Int64Value(input.Limit))
+	if end > pc.totalEvents {
+		end = pc.totalEvents
+	}
+	for i := offset; i < end; i++ {
+		output.Events = append(output.Events, &cloudwatchlogs.FilteredLogEvent{
+			EventId:       aws.String(strconv.Itoa(i)),
+			LogStreamName: aws.String(testLogStreamName),
+			Message:       aws.String(testLogStreamMessage),
+			Timestamp:     aws.Int64(testTimeStamp),
+		})
+	}
+	if end < pc.totalEvents {
+		output.NextToken = aws.String(strconv.Itoa(end))
+	}
+	return output, nil
+}
+
 func readLogs(path string) (plog.Logs, error) {
 	f, err := os.Open(path)
 	if err != nil {
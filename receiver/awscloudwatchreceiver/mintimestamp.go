@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+)
+
+// minTimestamp is the timestamp below which events are discarded, either an absolute time or a duration before the poll
+type minTimestamp struct {
+	absolute time.Time
+	relative time.Duration
+}
+
+// parseMinTimestamp parses an RFC 3339 time or a positive duration, nil is returned if value is empty
+func parseMinTimestamp(value string) (*minTimestamp, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if absolute, err := time.Parse(time.RFC3339, value); err == nil {
+		return &minTimestamp{absolute: absolute}, nil
+	}
+	relative, err := time.ParseDuration(value)
+	if err != nil || relative <= 0 {
+		return nil, errInvalidMinEventTimestamp
+	}
+	return &minTimestamp{relative: relative}, nil
+}
+
+// threshold returns the timestamp below which events are discarded by a poll at the given time
+func (m *minTimestamp) threshold(now time.Time) time.Time {
+	if m.relative > 0 {
+		return now.Add(-m.relative)
+	}
+	return m.absolute
+}
+
+// filter returns the events whose timestamp is not below the threshold at the given time. Events
+// without a timestamp are kept, they are reported when processed.
+func (m *minTimestamp) filter(now time.Time, events []*cloudwatchlogs.FilteredLogEvent) []*cloudwatchlogs.FilteredLogEvent {
+	threshold := m.threshold(now).UnixMilli()
+	kept := make([]*cloudwatchlogs.FilteredLogEvent, 0, len(events))
+	for _, e := range events {
+		if e.Timestamp != nil && *e.Timestamp < threshold {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
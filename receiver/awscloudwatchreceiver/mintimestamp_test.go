@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+func TestParseMinTimestamp(t *testing.T) {
+	m, err := parseMinTimestamp("")
+	require.NoError(t, err)
+	require.Nil(t, m)
+
+	m, err = parseMinTimestamp("2022-11-01T00:00:00Z")
+	require.NoError(t, err)
+	require.True(t, m.threshold(time.Now()).Equal(time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)))
+
+	m, err = parseMinTimestamp("24h")
+	require.NoError(t, err)
+	now := time.UnixMilli(testTimeStamp)
+	require.True(t, m.threshold(now).Equal(now.Add(-24*time.Hour)))
+
+	for _, invalid := range []string{"yesterday", "0s", "-1h", "2022-11-01"} {
+		_, err = parseMinTimestamp(invalid)
+		require.ErrorIs(t, err, errInvalidMinEventTimestamp, invalid)
+	}
+}
+
+func TestPollDiscardsEventsBelowMinTimestamp(t *testing.T) {
+	now := time.UnixMilli(testTimeStamp)
+	cases := []struct {
+		name         string
+		minTimestamp string
+	}{
+		{
+			name:         "absolute",
+			minTimestamp: now.Add(-time.Hour).UTC().Format(time.RFC3339Nano),
+		},
+		{
+			name:         "relative",
+			minTimestamp: "1h",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Region = "us-west-1"
+			cfg.Logs.MinEventTimestamp = tc.minTimestamp
+			cfg.Logs.Groups = GroupConfig{
+				NamedConfigs: map[string]StreamConfig{
+					testLogGroupName: {},
+				},
+			}
+			sink := &consumertest.LogsSink{}
+			logsRcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+			logsRcvr.now = func() time.Time { return now }
+			mc := &mockClient{}
+			mc.On("FilterLogEventsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+				&cloudwatchlogs.FilterLogEventsOutput{
+					Events: []*cloudwatchlogs.FilteredLogEvent{
+						minTimestampTestEvent("replayed", now.Add(-2*time.Hour)),
+						minTimestampTestEvent("below", now.Add(-time.Hour-time.Millisecond)),
+						minTimestampTestEvent("at", now.Add(-time.Hour)),
+						minTimestampTestEvent("above", now.Add(-time.Minute)),
+					},
+				}, nil)
+			logsRcvr.client = mc
+
+			require.NoError(t, logsRcvr.poll(context.Background()))
+			var ids []string
+			for _, logs := range sink.AllLogs() {
+				for i := 0; i < logs.ResourceLogs().Len(); i++ {
+					records := logs.ResourceLogs().At(i).ScopeLogs().At(0).LogRecords()
+					for j := 0; j < records.Len(); j++ {
+						id, _ := records.At(j).Attributes().Get("id")
+						ids = append(ids, id.Str())
+					}
+				}
+			}
+			require.Equal(t, []string{"at", "above"}, ids)
+		})
+	}
+}
+
+func minTimestampTestEvent(id string, ts time.Time) *cloudwatchlogs.FilteredLogEvent {
+	return &cloudwatchlogs.FilteredLogEvent{
+		EventId:       aws.String(id),
+		LogStreamName: aws.String(testLogStreamName),
+		Message:       aws.String(testLogStreamMessage),
+		Timestamp:     aws.Int64(ts.UnixMilli()),
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// maxExportLineSize is the largest line that will be read from an export object,
+// Cloudwatch Logs events are limited to 256KB so this leaves plenty of headroom.
+const maxExportLineSize = 1024 * 1024
+
+type s3Client interface {
+	ListObjectsV2WithContext(ctx context.Context, input *s3.ListObjectsV2Input, opts ...request.Option) (*s3.ListObjectsV2Output, error)
+	GetObjectWithContext(ctx context.Context, input *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error)
+}
+
+// s3CursorKey is the key of the S3 cursor in the storage
+const s3CursorKey = "cloudwatch_logs_s3_cursor"
+
+// s3Cursor is the position of the listing of the export objects that is persisted in the storage after
+// every processed object, so that a restarted receiver does not process the objects it already processed.
+type s3Cursor struct {
+	// LastKey is the key of the last processed object, S3 lists the objects in the lexicographic order of their keys
+	LastKey string `json:"last_key"`
+}
+
+// pollS3 lists the configured bucket and prefix for Cloudwatch Logs export objects and emits the events of
+// every object after the last processed one, in the order they are listed. The listing stops at an object
+// that fails to be processed so that it is processed again by the next poll, unless it can never succeed.
+func (l *logsReceiver) pollS3(ctx context.Context) error {
+	err := l.ensureS3Session()
+	if err != nil {
+		return err
+	}
+
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(l.s3.Bucket),
+	}
+	if l.s3.Prefix != "" {
+		input.Prefix = aws.String(l.s3.Prefix)
+	}
+	if l.s3LastKey != "" {
+		input.StartAfter = aws.String(l.s3LastKey)
+	}
+
+	var errs error
+	for {
+		select {
+		// if done, we want to stop processing the listing of objects
+		case _, ok := <-l.doneChan:
+			if !ok {
+				return errs
+			}
+		default:
+		}
+
+		resp, err := l.s3Client.ListObjectsV2WithContext(ctx, input)
+		if err != nil {
+			return multierr.Append(errs, fmt.Errorf("unable to list objects in s3 bucket %s: %w", l.s3.Bucket, err))
+		}
+
+		for _, obj := range resp.Contents {
+			if obj.Key == nil {
+				continue
+			}
+			key := *obj.Key
+			// the objects up to the cursor are skipped even if the listing does not start after it
+			if key <= l.s3LastKey {
+				continue
+			}
+			// exports also write a permission check object which does not contain any events
+			if !strings.HasSuffix(key, ".gz") {
+				errs = multierr.Append(errs, l.advanceS3Cursor(ctx, key))
+				continue
+			}
+
+			logs, metrics, err := l.readS3Object(ctx, key)
+			if err != nil {
+				// an object that is too large to decompress will never succeed, so it is not retried
+				if errors.Is(err, errDecompressedSizeExceeded) {
+					errs = multierr.Append(errs, err)
+					errs = multierr.Append(errs, l.advanceS3Cursor(ctx, key))
+					continue
+				}
+				return multierr.Append(errs, err)
+			}
+			if metrics.DataPointCount() > 0 && l.metricsConsumer != nil {
+				if err = l.metricsConsumer.ConsumeMetrics(ctx, metrics); err != nil {
+					l.logger.Error("unable to consume metrics", zap.String("key", key), zap.Error(err))
+				}
+			}
+			if logs.LogRecordCount() > 0 {
+				if err = l.consumer.ConsumeLogs(ctx, logs); err != nil {
+					return multierr.Append(errs, fmt.Errorf("unable to consume the logs of s3 object %s: %w", key, err))
+				}
+			}
+			errs = multierr.Append(errs, l.advanceS3Cursor(ctx, key))
+		}
+
+		if !aws.BoolValue(resp.IsTruncated) || resp.NextContinuationToken == nil {
+			return errs
+		}
+		input.ContinuationToken = resp.NextContinuationToken
+	}
+}
+
+// advanceS3Cursor moves the cursor past the object with the given key and persists it.
+func (l *logsReceiver) advanceS3Cursor(ctx context.Context, key string) error {
+	l.s3LastKey = key
+	data, err := json.Marshal(&s3Cursor{LastKey: key})
+	if err != nil {
+		return fmt.Errorf("unable to encode the s3 cursor: %w", err)
+	}
+	if err = l.storageClient.Set(ctx, s3CursorKey, data); err != nil {
+		return fmt.Errorf("unable to write the s3 cursor: %w", err)
+	}
+	return nil
+}
+
+// loadS3Cursor restores the cursor of the listing from the storage. A cursor outside of the configured
+// prefix, which was changed since it was persisted, is discarded.
+func (l *logsReceiver) loadS3Cursor(ctx context.Context) error {
+	data, err := l.storageClient.Get(ctx, s3CursorKey)
+	if err != nil {
+		return fmt.Errorf("unable to read the s3 cursor: %w", err)
+	}
+	if data == nil {
+		return nil
+	}
+	var cursor s3Cursor
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return fmt.Errorf("unable to decode the s3 cursor: %w", err)
+	}
+	if strings.HasPrefix(cursor.LastKey, l.s3.Prefix) {
+		l.s3LastKey = cursor.LastKey
+	}
+	return nil
+}
+
+// readS3Object converts the lines of an export object into log records. When the embedded metric format
+// is enabled the metrics embedded in lines are extracted as well, the log records of those lines are only
+// kept if configured.
+func (l *logsReceiver) readS3Object(ctx context.Context, key string) (plog.Logs, pmetric.Metrics, error) {
+	resp, err := l.s3Client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(l.s3.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return plog.Logs{}, pmetric.Metrics{}, fmt.Errorf("unable to retrieve s3 object %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return plog.Logs{}, pmetric.Metrics{}, fmt.Errorf("unable to decompress s3 object %s: %w", key, err)
+	}
+	defer gz.Close()
+
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	resourceAttributes := rl.Resource().Attributes()
+	l.putCloudAttributes(resourceAttributes)
+	resourceAttributes.PutStr("aws.s3.bucket", l.s3.Bucket)
+	resourceAttributes.PutStr("aws.s3.key", key)
+	if stream := l.exportStreamName(key); stream != "" {
+		resourceAttributes.PutStr("cloudwatch.log.stream", stream)
+		l.streamAttributes.parse(stream, resourceAttributes)
+	}
+	records := rl.ScopeLogs().AppendEmpty().LogRecords()
+
+	metrics := pmetric.NewMetrics()
+	extracted := pmetric.NewMetricSlice()
+
+	observedTime := pcommon.NewTimestampFromTime(time.Now())
+	scanner := bufio.NewScanner(newMaxSizeReader(gz, l.maxDecompressedSize))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxExportLineSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		ts, message, ok := parseExportLine(line)
+		if !ok {
+			message = line
+		}
+		if l.emf != nil {
+			if event, isEMF := parseEMF(message); isEMF {
+				metricTime := ts
+				if !ok {
+					metricTime = observedTime.AsTime()
+				}
+				lineMetrics := pmetric.NewMetricSlice()
+				if err := event.appendMetrics(lineMetrics, metricTime); err != nil {
+					l.logger.Error("unable to extract embedded metrics from exported line", zap.String("key", key), zap.Error(err))
+				} else {
+					lineMetrics.MoveAndAppendTo(extracted)
+				}
+				if !l.emf.KeepLogs {
+					continue
+				}
+			}
+		}
+
+		logRecord := records.AppendEmpty()
+		logRecord.SetObservedTimestamp(observedTime)
+		if ok {
+			logRecord.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+		}
+		setBody(logRecord, message, l.bodyFormat, l.attributePrefix)
+		if l.severityParser != nil {
+			l.severityParser.parse(message, logRecord)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return plog.Logs{}, pmetric.Metrics{}, fmt.Errorf("unable to read s3 object %s: %w", key, err)
+	}
+	if extracted.Len() > 0 {
+		rm := metrics.ResourceMetrics().AppendEmpty()
+		resourceAttributes.CopyTo(rm.Resource().Attributes())
+		extracted.MoveAndAppendTo(rm.ScopeMetrics().AppendEmpty().Metrics())
+	}
+	return logs, metrics, nil
+}
+
+// exportStreamName determines the log stream of an export object, export objects
+// are written in the form of <prefix>/<task id>/<log stream name>/<sequence>.gz
+func (l *logsReceiver) exportStreamName(key string) string {
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(key, l.s3.Prefix), "/")
+	parts := strings.Split(trimmed, "/")
+	if len(parts) < 3 {
+		return ""
+	}
+	return strings.Join(parts[1:len(parts)-1], "/")
+}
+
+// parseExportLine splits an exported line into its timestamp and message, each line
+// of an export is the RFC3339 timestamp of the event followed by a space and the message.
+func parseExportLine(line string) (time.Time, string, bool) {
+	idx := strings.IndexByte(line, ' ')
+	if idx < 0 {
+		return time.Time{}, "", false
+	}
+	ts, err := time.Parse(time.RFC3339Nano, line[:idx])
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return ts, line[idx+1:], true
+}
+
+func (l *logsReceiver) ensureS3Session() error {
+	if l.s3Client != nil {
+		return nil
+	}
+	s, err := l.newSession()
+	if err != nil {
+		return err
+	}
+	l.s3Client = s3.New(s)
+	return nil
+}
@@ -0,0 +1,275 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+)
+
+var (
+	testS3Bucket = "test-export-bucket"
+	testS3Prefix = "exports"
+	testS3KeyOne = "exports/c6f3a2b4-task/test-log-stream-name/000000.gz"
+	testS3KeyTwo = "exports/c6f3a2b4-task/2022/10/07/[$LATEST]abc/000000.gz"
+)
+
+func TestS3Mode(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.PollInterval = 1 * time.Second
+	cfg.Logs.Mode = modeS3
+	cfg.Logs.S3 = &S3Config{
+		Bucket: testS3Bucket,
+		Prefix: testS3Prefix,
+	}
+
+	sink := &consumertest.LogsSink{}
+	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+	mc := defaultMockS3Client(t)
+	logsRcvr.s3Client = mc
+	logsRcvr.stsClient = defaultMockSTSClient()
+
+	require.NoError(t, logsRcvr.Start(context.Background(), componenttest.NewNopHost()))
+	require.Eventually(t, func() bool {
+		return len(sink.AllLogs()) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// wait for subsequent polls to ensure that objects are not reprocessed
+	require.Eventually(t, func() bool {
+		return mc.listCalls.Load() >= 3
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, logsRcvr.Shutdown(context.Background()))
+
+	require.Len(t, sink.AllLogs(), 2)
+	require.Equal(t, 3, sink.LogRecordCount())
+	mc.AssertNumberOfCalls(t, "GetObjectWithContext", 2)
+
+	first := sink.AllLogs()[1].ResourceLogs().At(0)
+	stream, ok := first.Resource().Attributes().Get("cloudwatch.log.stream")
+	require.True(t, ok)
+	require.Equal(t, testLogStreamName, stream.Str())
+	key, ok := first.Resource().Attributes().Get("aws.s3.key")
+	require.True(t, ok)
+	require.Equal(t, testS3KeyOne, key.Str())
+	accountID, ok := first.Resource().Attributes().Get("cloud.account.id")
+	require.True(t, ok)
+	require.Equal(t, testAccountID, accountID.Str())
+
+	record := first.ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, pcommon.NewTimestampFromTime(time.UnixMilli(testTimeStamp)), record.Timestamp())
+	require.Equal(t, testLogStreamMessage, record.Body().Str())
+
+	second := sink.AllLogs()[0].ResourceLogs().At(0)
+	stream, ok = second.Resource().Attributes().Get("cloudwatch.log.stream")
+	require.True(t, ok)
+	require.Equal(t, "2022/10/07/[$LATEST]abc", stream.Str())
+}
+
+func TestS3CursorSurvivesRestart(t *testing.T) {
+	storageID := storagetest.NewStorageID("test")
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.StorageID = &storageID
+	cfg.Logs.PollInterval = time.Hour
+	cfg.Logs.Mode = modeS3
+	cfg.Logs.S3 = &S3Config{Bucket: testS3Bucket, Prefix: testS3Prefix}
+	sink := &consumertest.LogsSink{}
+
+	first := newLogsReceiver(cfg, zap.NewNop(), sink)
+	first.s3Client = defaultMockS3Client(t)
+	first.stsClient = defaultMockSTSClient()
+	require.NoError(t, first.Start(context.Background(), host))
+	require.NoError(t, first.pollS3(context.Background()))
+	require.NoError(t, first.Shutdown(context.Background()))
+	require.Len(t, sink.AllLogs(), 2)
+
+	// the restarted receiver lists the objects after the last processed one and processes none of them again
+	mc := defaultMockS3Client(t)
+	second := newLogsReceiver(cfg, zap.NewNop(), sink)
+	second.s3Client = mc
+	second.stsClient = defaultMockSTSClient()
+	require.NoError(t, second.Start(context.Background(), host))
+	require.Equal(t, testS3KeyOne, second.s3LastKey)
+	require.NoError(t, second.pollS3(context.Background()))
+	require.NoError(t, second.Shutdown(context.Background()))
+	require.Len(t, sink.AllLogs(), 2)
+	mc.AssertNumberOfCalls(t, "GetObjectWithContext", 0)
+	input := mc.Calls[0].Arguments.Get(1).(*s3.ListObjectsV2Input)
+	require.Equal(t, testS3KeyOne, aws.StringValue(input.StartAfter))
+
+	// a cursor outside of the prefix, which was changed, is discarded
+	cfg.Logs.S3 = &S3Config{Bucket: testS3Bucket, Prefix: "other-exports"}
+	third := newLogsReceiver(cfg, zap.NewNop(), sink)
+	third.stsClient = defaultMockSTSClient()
+	require.NoError(t, third.Start(context.Background(), host))
+	require.Empty(t, third.s3LastKey)
+	require.NoError(t, third.Shutdown(context.Background()))
+}
+
+func TestS3ModeRetriesFailedObject(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.Mode = modeS3
+	cfg.Logs.S3 = &S3Config{Bucket: testS3Bucket, Prefix: testS3Prefix}
+	sink := &consumertest.LogsSink{}
+	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+	mc := &mockS3Client{}
+	mc.On("ListObjectsV2WithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&s3.ListObjectsV2Output{
+			Contents:    []*s3.Object{{Key: aws.String(testS3KeyTwo)}, {Key: aws.String(testS3KeyOne)}},
+			IsTruncated: aws.Bool(false),
+		}, nil)
+	mc.On("GetObjectWithContext", mock.Anything, &s3.GetObjectInput{Bucket: aws.String(testS3Bucket), Key: aws.String(testS3KeyTwo)}, mock.Anything).Return(
+		(*s3.GetObjectOutput)(nil), errors.New("throttled")).Once()
+	mc.On("GetObjectWithContext", mock.Anything, &s3.GetObjectInput{Bucket: aws.String(testS3Bucket), Key: aws.String(testS3KeyTwo)}, mock.Anything).Return(
+		&s3.GetObjectOutput{Body: gzipBody(t, "2022-10-07T18:10:52.000Z START RequestId: 1\n")}, nil)
+	mc.On("GetObjectWithContext", mock.Anything, &s3.GetObjectInput{Bucket: aws.String(testS3Bucket), Key: aws.String(testS3KeyOne)}, mock.Anything).Return(
+		&s3.GetObjectOutput{Body: gzipBody(t, "2022-10-07T18:10:51.014Z "+testLogStreamMessage+"\n")}, nil)
+	logsRcvr.s3Client = mc
+	logsRcvr.stsClient = defaultMockSTSClient()
+
+	// the listing stops at the failed object, so that the objects are processed in order by the next poll
+	require.ErrorContains(t, logsRcvr.pollS3(context.Background()), "throttled")
+	require.Empty(t, sink.AllLogs())
+	require.Empty(t, logsRcvr.s3LastKey)
+
+	require.NoError(t, logsRcvr.pollS3(context.Background()))
+	require.Len(t, sink.AllLogs(), 2)
+	require.Equal(t, testS3KeyOne, logsRcvr.s3LastKey)
+}
+
+func TestReadS3ObjectEMF(t *testing.T) {
+	var line bytes.Buffer
+	require.NoError(t, json.Compact(&line, []byte(testEMFEvent)))
+	contents := "2022-10-07T18:10:51.014Z " + line.String() + "\n2022-10-07T18:10:52.000Z " + testLogStreamMessage + "\n"
+
+	cases := []struct {
+		name            string
+		emf             *EMFConfig
+		expectedLogs    int
+		expectedMetrics int
+	}{
+		{name: "disabled", emf: nil, expectedLogs: 2, expectedMetrics: 0},
+		{name: "metrics only", emf: &EMFConfig{}, expectedLogs: 1, expectedMetrics: 2},
+		{name: "keep logs", emf: &EMFConfig{KeepLogs: true}, expectedLogs: 2, expectedMetrics: 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Region = "us-west-1"
+			cfg.Logs.Mode = modeS3
+			cfg.Logs.S3 = &S3Config{Bucket: testS3Bucket, Prefix: testS3Prefix}
+			cfg.Logs.EMF = tc.emf
+			logsRcvr := newLogsReceiver(cfg, zap.NewNop(), &consumertest.LogsSink{})
+			mc := &mockS3Client{}
+			mc.On("GetObjectWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+				&s3.GetObjectOutput{Body: gzipBody(t, contents)}, nil)
+			logsRcvr.s3Client = mc
+
+			logs, metrics, err := logsRcvr.readS3Object(context.Background(), testS3KeyOne)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedLogs, logs.LogRecordCount())
+			require.Equal(t, tc.expectedMetrics, metrics.MetricCount())
+			if tc.expectedMetrics == 0 {
+				return
+			}
+			key, ok := metrics.ResourceMetrics().At(0).Resource().Attributes().Get("aws.s3.key")
+			require.True(t, ok)
+			require.Equal(t, testS3KeyOne, key.Str())
+		})
+	}
+}
+
+func TestParseExportLine(t *testing.T) {
+	ts, message, ok := parseExportLine("2022-10-07T18:10:51.014Z some message with spaces")
+	require.True(t, ok)
+	require.Equal(t, time.UnixMilli(testTimeStamp).UTC(), ts)
+	require.Equal(t, "some message with spaces", message)
+
+	_, _, ok = parseExportLine("not-a-timestamp message")
+	require.False(t, ok)
+
+	_, _, ok = parseExportLine("message")
+	require.False(t, ok)
+}
+
+func defaultMockS3Client(t *testing.T) *mockS3Client {
+	mc := &mockS3Client{}
+	mc.On("ListObjectsV2WithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&s3.ListObjectsV2Output{
+			Contents: []*s3.Object{
+				// S3 lists the objects in the lexicographic order of their keys
+				{Key: aws.String("exports/aws-logs-write-test")},
+				{Key: aws.String(testS3KeyTwo)},
+				{Key: aws.String(testS3KeyOne)},
+			},
+			IsTruncated: aws.Bool(false),
+		}, nil)
+	mc.On("GetObjectWithContext", mock.Anything, &s3.GetObjectInput{Bucket: aws.String(testS3Bucket), Key: aws.String(testS3KeyOne)}, mock.Anything).Return(
+		&s3.GetObjectOutput{
+			Body: gzipBody(t, "2022-10-07T18:10:51.014Z "+testLogStreamMessage+"\n"),
+		}, nil)
+	mc.On("GetObjectWithContext", mock.Anything, &s3.GetObjectInput{Bucket: aws.String(testS3Bucket), Key: aws.String(testS3KeyTwo)}, mock.Anything).Return(
+		&s3.GetObjectOutput{
+			Body: gzipBody(t, "2022-10-07T18:10:52.000Z START RequestId: 1\n2022-10-07T18:10:53.000Z END RequestId: 1\n"),
+		}, nil)
+	return mc
+}
+
+func gzipBody(t *testing.T, contents string) io.ReadCloser {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(contents))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return io.NopCloser(&buf)
+}
+
+type mockS3Client struct {
+	mock.Mock
+	listCalls atomic.Int64
+}
+
+func (mc *mockS3Client) ListObjectsV2WithContext(ctx context.Context, input *s3.ListObjectsV2Input, opts ...request.Option) (*s3.ListObjectsV2Output, error) {
+	mc.listCalls.Add(1)
+	args := mc.Called(ctx, input, opts)
+	return args.Get(0).(*s3.ListObjectsV2Output), args.Error(1)
+}
+
+func (mc *mockS3Client) GetObjectWithContext(ctx context.Context, input *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error) {
+	args := mc.Called(ctx, input, opts)
+	return args.Get(0).(*s3.GetObjectOutput), args.Error(1)
+}
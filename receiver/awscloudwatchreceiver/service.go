@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// serviceAttribute is the resource attribute holding the AWS service that wrote the events of a log group
+const serviceAttribute = "aws.service"
+
+// defaultServiceMappings classify the log groups that AWS services create with their well known names
+var defaultServiceMappings = []ServiceMapping{
+	{Pattern: `^/aws/lambda/`, Service: "lambda"},
+	{Pattern: `^/aws/rds/`, Service: "rds"},
+	{Pattern: `^API-Gateway-Execution-Logs_`, Service: "apigateway"},
+	{Pattern: `^/aws/apigateway/`, Service: "apigateway"},
+	{Pattern: `^/(aws/)?ecs/`, Service: "ecs"},
+	{Pattern: `^/aws/eks/`, Service: "eks"},
+	{Pattern: `^/aws/codebuild/`, Service: "codebuild"},
+	{Pattern: `^/aws/batch/`, Service: "batch"},
+	{Pattern: `^/aws/elasticbeanstalk/`, Service: "elasticbeanstalk"},
+	{Pattern: `^/aws/(vendedlogs/)?states/`, Service: "stepfunctions"},
+	{Pattern: `^/aws/kinesisfirehose/`, Service: "firehose"},
+	{Pattern: `^/aws-glue/`, Service: "glue"},
+	{Pattern: `^/aws/sagemaker/`, Service: "sagemaker"},
+	{Pattern: `^/aws/appsync/`, Service: "appsync"},
+	{Pattern: `^/aws/OpenSearchService/`, Service: "opensearch"},
+	{Pattern: `^aws-cloudtrail-logs`, Service: "cloudtrail"},
+}
+
+// serviceClassifier derives the AWS service that wrote the events of a log group from the name of the log group
+type serviceClassifier struct {
+	patterns []*regexp.Regexp
+	services []string
+	// cache holds the service of every classified log group, as the same log groups are polled over and over
+	cache map[string]string
+}
+
+// newServiceClassifier builds a classifier evaluating the configured mappings, in order, before the default mappings
+func newServiceClassifier(cfg *ServiceConfig) (*serviceClassifier, error) {
+	mappings := defaultServiceMappings
+	if cfg != nil {
+		mappings = cfg.Mappings
+		if !cfg.DisableDefaults {
+			mappings = append(append([]ServiceMapping{}, cfg.Mappings...), defaultServiceMappings...)
+		}
+	}
+
+	c := &serviceClassifier{cache: map[string]string{}}
+	for _, mapping := range mappings {
+		pattern, err := regexp.Compile(mapping.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compile service pattern %q: %w", mapping.Pattern, err)
+		}
+		c.patterns = append(c.patterns, pattern)
+		c.services = append(c.services, mapping.Service)
+	}
+	return c, nil
+}
+
+// classify returns the service of the first mapping whose pattern matches the log group name, or an
+// empty string if none matches.
+func (c *serviceClassifier) classify(logGroupName string) string {
+	if service, ok := c.cache[logGroupName]; ok {
+		return service
+	}
+	var service string
+	for i, pattern := range c.patterns {
+		if pattern.MatchString(logGroupName) {
+			service = c.services[i]
+			break
+		}
+	}
+	c.cache[logGroupName] = service
+	return service
+}
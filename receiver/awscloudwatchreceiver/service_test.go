@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+)
+
+func TestServiceClassifierDefaults(t *testing.T) {
+	classifier, err := newServiceClassifier(nil)
+	require.NoError(t, err)
+
+	cases := map[string]string{
+		"/aws/lambda/checkout":                          "lambda",
+		"/aws/rds/instance/orders/postgresql":           "rds",
+		"/aws/rds/cluster/orders/error":                 "rds",
+		"API-Gateway-Execution-Logs_a1b2c3d4e5/prod":    "apigateway",
+		"/aws/apigateway/welcome":                       "apigateway",
+		"/aws/vendedlogs/states/checkout-state-machine": "stepfunctions",
+		"/ecs/checkout":                                 "ecs",
+		"/custom/payments":                              "",
+	}
+	for logGroupName, expected := range cases {
+		t.Run(logGroupName, func(t *testing.T) {
+			require.Equal(t, expected, classifier.classify(logGroupName))
+		})
+	}
+}
+
+func TestServiceClassifierMappings(t *testing.T) {
+	mappings := []ServiceMapping{
+		{Pattern: "^/custom/payments", Service: "payments"},
+		// the configured mappings take precedence over the default mappings
+		{Pattern: "^/aws/lambda/batch-", Service: "batch-jobs"},
+	}
+
+	classifier, err := newServiceClassifier(&ServiceConfig{Mappings: mappings})
+	require.NoError(t, err)
+	require.Equal(t, "payments", classifier.classify("/custom/payments"))
+	require.Equal(t, "batch-jobs", classifier.classify("/aws/lambda/batch-nightly"))
+	require.Equal(t, "lambda", classifier.classify("/aws/lambda/checkout"))
+
+	classifier, err = newServiceClassifier(&ServiceConfig{Mappings: mappings, DisableDefaults: true})
+	require.NoError(t, err)
+	require.Equal(t, "payments", classifier.classify("/custom/payments"))
+	require.Equal(t, "", classifier.classify("/aws/lambda/checkout"))
+}
+
+func TestServiceClassifierInvalidPattern(t *testing.T) {
+	_, err := newServiceClassifier(&ServiceConfig{Mappings: []ServiceMapping{{Pattern: "(", Service: "broken"}}})
+	require.ErrorContains(t, err, "unable to compile service pattern")
+}
+
+func TestProcessEventsService(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+
+	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), &consumertest.LogsSink{})
+	output := &cloudwatchlogs.FilterLogEventsOutput{
+		Events: []*cloudwatchlogs.FilteredLogEvent{
+			{
+				EventId:       &testEventID,
+				LogStreamName: aws.String(testLogStreamName),
+				Message:       aws.String(testLogStreamMessage),
+				Timestamp:     aws.Int64(testTimeStamp),
+			},
+		},
+	}
+
+	logs, _ := logsRcvr.processEvents(pcommon.NewTimestampFromTime(time.Now()), "/aws/lambda/checkout", output)
+	service, ok := logs.ResourceLogs().At(0).Resource().Attributes().Get(serviceAttribute)
+	require.True(t, ok)
+	require.Equal(t, "lambda", service.Str())
+
+	// the attribute is not set on log groups that are not classified
+	logs, _ = logsRcvr.processEvents(pcommon.NewTimestampFromTime(time.Now()), "/custom/payments", output)
+	_, ok = logs.ResourceLogs().At(0).Resource().Attributes().Get(serviceAttribute)
+	require.False(t, ok)
+}
@@ -0,0 +1,100 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// levelCaptureGroup is the name of the capture group that holds the level when a severity regex is configured
+const levelCaptureGroup = "level"
+
+var severityNumbers = map[string]plog.SeverityNumber{
+	"trace":       plog.SeverityNumberTrace,
+	"debug":       plog.SeverityNumberDebug,
+	"info":        plog.SeverityNumberInfo,
+	"information": plog.SeverityNumberInfo,
+	"notice":      plog.SeverityNumberInfo2,
+	"warn":        plog.SeverityNumberWarn,
+	"warning":     plog.SeverityNumberWarn,
+	"error":       plog.SeverityNumberError,
+	"err":         plog.SeverityNumberError,
+	"critical":    plog.SeverityNumberFatal,
+	"crit":        plog.SeverityNumberFatal,
+	"fatal":       plog.SeverityNumberFatal,
+	"panic":       plog.SeverityNumberFatal,
+}
+
+// severityParser extracts the level embedded in a log message and sets the severity of the log record
+type severityParser struct {
+	regex      *regexp.Regexp
+	levelIndex int
+	jsonField  string
+}
+
+func newSeverityParser(cfg *SeverityConfig) (*severityParser, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	if cfg.JSONField != "" {
+		return &severityParser{jsonField: cfg.JSONField}, nil
+	}
+
+	regex, err := regexp.Compile(cfg.Regex)
+	if err != nil {
+		return nil, err
+	}
+	// prefer the named capture group, falling back to the first capture group or the whole match
+	levelIndex := regex.SubexpIndex(levelCaptureGroup)
+	if levelIndex < 0 && regex.NumSubexp() > 0 {
+		levelIndex = 1
+	}
+	if levelIndex < 0 {
+		levelIndex = 0
+	}
+	return &severityParser{regex: regex, levelIndex: levelIndex}, nil
+}
+
+// parse sets the severity of the record based on the level found in the message. If no level
+// is found the severity is left unset, unknown levels are kept as text with an unspecified number.
+func (p *severityParser) parse(message string, record plog.LogRecord) {
+	level, ok := p.level(message)
+	if !ok {
+		return
+	}
+	record.SetSeverityText(level)
+	record.SetSeverityNumber(severityNumbers[strings.ToLower(level)])
+}
+
+func (p *severityParser) level(message string) (string, bool) {
+	if p.jsonField != "" {
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(message), &fields); err != nil {
+			return "", false
+		}
+		level, ok := fields[p.jsonField].(string)
+		return level, ok && level != ""
+	}
+
+	match := p.regex.FindStringSubmatch(message)
+	if match == nil || match[p.levelIndex] == "" {
+		return "", false
+	}
+	return match[p.levelIndex], true
+}
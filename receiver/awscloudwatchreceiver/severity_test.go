@@ -0,0 +1,162 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+func TestSeverityParserRegex(t *testing.T) {
+	cases := []struct {
+		name           string
+		regex          string
+		message        string
+		expectedNumber plog.SeverityNumber
+		expectedText   string
+	}{
+		{
+			name:           "named group info",
+			regex:          `level=(?P<level>\w+)`,
+			message:        `time="2022-10-07T18:10:46Z" level=info msg="access granted"`,
+			expectedNumber: plog.SeverityNumberInfo,
+			expectedText:   "info",
+		},
+		{
+			name:           "first group warn",
+			regex:          `^\[(\w+)\]`,
+			message:        "[WARN] disk usage above 80%",
+			expectedNumber: plog.SeverityNumberWarn,
+			expectedText:   "WARN",
+		},
+		{
+			name:           "whole match error",
+			regex:          `ERROR|FATAL`,
+			message:        "2022-10-07 ERROR unable to connect",
+			expectedNumber: plog.SeverityNumberError,
+			expectedText:   "ERROR",
+		},
+		{
+			name:           "fatal",
+			regex:          `level=(?P<level>\w+)`,
+			message:        "level=CRITICAL out of memory",
+			expectedNumber: plog.SeverityNumberFatal,
+			expectedText:   "CRITICAL",
+		},
+		{
+			name:           "unknown level",
+			regex:          `level=(?P<level>\w+)`,
+			message:        "level=verbose some message",
+			expectedNumber: plog.SeverityNumberUnspecified,
+			expectedText:   "verbose",
+		},
+		{
+			name:           "absent level",
+			regex:          `level=(?P<level>\w+)`,
+			message:        "START RequestId: 8f5a3c",
+			expectedNumber: plog.SeverityNumberUnspecified,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, err := newSeverityParser(&SeverityConfig{Regex: tc.regex})
+			require.NoError(t, err)
+
+			record := plog.NewLogRecord()
+			parser.parse(tc.message, record)
+			require.Equal(t, tc.expectedNumber, record.SeverityNumber())
+			require.Equal(t, tc.expectedText, record.SeverityText())
+		})
+	}
+}
+
+func TestSeverityParserJSON(t *testing.T) {
+	cases := []struct {
+		name           string
+		message        string
+		expectedNumber plog.SeverityNumber
+		expectedText   string
+	}{
+		{
+			name:           "debug",
+			message:        `{"level":"debug","msg":"cache miss"}`,
+			expectedNumber: plog.SeverityNumberDebug,
+			expectedText:   "debug",
+		},
+		{
+			name:           "warning",
+			message:        `{"level":"Warning","msg":"slow request"}`,
+			expectedNumber: plog.SeverityNumberWarn,
+			expectedText:   "Warning",
+		},
+		{
+			name:           "missing field",
+			message:        `{"msg":"no level"}`,
+			expectedNumber: plog.SeverityNumberUnspecified,
+		},
+		{
+			name:           "non string field",
+			message:        `{"level":3,"msg":"numeric level"}`,
+			expectedNumber: plog.SeverityNumberUnspecified,
+		},
+		{
+			name:           "not json",
+			message:        "level=error plain text",
+			expectedNumber: plog.SeverityNumberUnspecified,
+		},
+	}
+
+	parser, err := newSeverityParser(&SeverityConfig{JSONField: "level"})
+	require.NoError(t, err)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			record := plog.NewLogRecord()
+			parser.parse(tc.message, record)
+			require.Equal(t, tc.expectedNumber, record.SeverityNumber())
+			require.Equal(t, tc.expectedText, record.SeverityText())
+		})
+	}
+}
+
+func TestProcessEventsSeverity(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.Severity = &SeverityConfig{Regex: `level=(?P<level>\w+)`}
+
+	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), &consumertest.LogsSink{})
+	output := &cloudwatchlogs.FilterLogEventsOutput{
+		Events: []*cloudwatchlogs.FilteredLogEvent{
+			{
+				EventId:       &testEventID,
+				LogStreamName: aws.String(testLogStreamName),
+				Message:       aws.String(testLogStreamMessage),
+				Timestamp:     aws.Int64(testTimeStamp),
+			},
+		},
+	}
+	logs, _ := logsRcvr.processEvents(pcommon.NewTimestampFromTime(time.Now()), testLogGroupName, output)
+	record := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, plog.SeverityNumberInfo, record.SeverityNumber())
+	require.Equal(t, "info", record.SeverityText())
+}
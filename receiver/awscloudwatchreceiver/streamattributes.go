@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"fmt"
+	"regexp"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// defaultStreamPattern matches the names of the log streams of Lambda functions, such as
+// 2024/01/01/[$LATEST]2b8f3c1e0a9d4f6e8c7b5a4d3e2f1a0b, made of the date, the version and the instance
+const defaultStreamPattern = `^(?P<date>\d{4}/\d{2}/\d{2})/\[(?P<version>[^\]]+)\](?P<instance>\w+)$`
+
+// defaultStreamAttributes are the resource attributes of the named groups of the default pattern
+var defaultStreamAttributes = map[string]string{
+	"date":     "cloudwatch.log.stream.date",
+	"version":  "faas.version",
+	"instance": "faas.instance",
+}
+
+// streamAttributeParser extracts resource attributes from the log stream name following a naming convention
+type streamAttributeParser struct {
+	pattern *regexp.Regexp
+	// attributes holds the resource attribute of every named group of the pattern, by the index of the group
+	attributes []string
+}
+
+// newStreamAttributeParser builds a parser of the configured pattern, or nil if no stream attributes are configured.
+// A named group is set as the configured attribute, the default attribute of the group or else its name.
+func newStreamAttributeParser(cfg *StreamAttributesConfig) (*streamAttributeParser, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	expr := cfg.Pattern
+	if expr == "" {
+		expr = defaultStreamPattern
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compile stream pattern %q: %w", expr, err)
+	}
+
+	p := &streamAttributeParser{pattern: pattern, attributes: make([]string, len(pattern.SubexpNames()))}
+	named := false
+	for i, group := range pattern.SubexpNames() {
+		if group == "" {
+			continue
+		}
+		named = true
+		switch {
+		case cfg.Attributes[group] != "":
+			p.attributes[i] = cfg.Attributes[group]
+		case defaultStreamAttributes[group] != "":
+			p.attributes[i] = defaultStreamAttributes[group]
+		default:
+			p.attributes[i] = group
+		}
+	}
+	if !named {
+		return nil, errNoStreamPatternGroups
+	}
+	return p, nil
+}
+
+// parse sets the non empty named groups of the log stream name as resource attributes. Nothing is set
+// if the name does not match the pattern. A nil streamAttributeParser sets nothing.
+func (p *streamAttributeParser) parse(logStreamName string, resourceAttributes pcommon.Map) {
+	if p == nil {
+		return
+	}
+	match := p.pattern.FindStringSubmatch(logStreamName)
+	for i, value := range match {
+		if p.attributes[i] != "" && value != "" {
+			resourceAttributes.PutStr(p.attributes[i], value)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+)
+
+func TestStreamAttributeParserLambda(t *testing.T) {
+	parser, err := newStreamAttributeParser(&StreamAttributesConfig{})
+	require.NoError(t, err)
+
+	cases := map[string]map[string]interface{}{
+		"2024/01/01/[$LATEST]2b8f3c1e0a9d4f6e8c7b5a4d3e2f1a0b": {
+			"cloudwatch.log.stream.date": "2024/01/01",
+			"faas.version":               "$LATEST",
+			"faas.instance":              "2b8f3c1e0a9d4f6e8c7b5a4d3e2f1a0b",
+		},
+		"2023/12/31/[42]abc123": {
+			"cloudwatch.log.stream.date": "2023/12/31",
+			"faas.version":               "42",
+			"faas.instance":              "abc123",
+		},
+		// streams that do not follow the naming convention get no attribute
+		"ecs/checkout/7f1c2d3e4b5a": {},
+	}
+	for logStreamName, expected := range cases {
+		t.Run(logStreamName, func(t *testing.T) {
+			attrs := pcommon.NewMap()
+			parser.parse(logStreamName, attrs)
+			require.Equal(t, expected, attrs.AsRaw())
+		})
+	}
+}
+
+func TestStreamAttributeParserPattern(t *testing.T) {
+	parser, err := newStreamAttributeParser(&StreamAttributesConfig{
+		Pattern:    `^(?P<service>[^/]+)/(?P<task>[0-9a-f]+)(/(?P<container>.+))?$`,
+		Attributes: map[string]string{"task": "aws.ecs.task.id"},
+	})
+	require.NoError(t, err)
+
+	attrs := pcommon.NewMap()
+	parser.parse("checkout/7f1c2d3e4b5a/web", attrs)
+	require.Equal(t, map[string]interface{}{
+		"service":         "checkout",
+		"aws.ecs.task.id": "7f1c2d3e4b5a",
+		"container":       "web",
+	}, attrs.AsRaw())
+
+	// optional groups that are not matched are not set
+	attrs = pcommon.NewMap()
+	parser.parse("checkout/7f1c2d3e4b5a", attrs)
+	require.Equal(t, map[string]interface{}{
+		"service":         "checkout",
+		"aws.ecs.task.id": "7f1c2d3e4b5a",
+	}, attrs.AsRaw())
+}
+
+func TestStreamAttributeParserInvalid(t *testing.T) {
+	parser, err := newStreamAttributeParser(nil)
+	require.NoError(t, err)
+	require.Nil(t, parser)
+
+	_, err = newStreamAttributeParser(&StreamAttributesConfig{Pattern: "(?P<date>"})
+	require.ErrorContains(t, err, "unable to compile stream pattern")
+
+	_, err = newStreamAttributeParser(&StreamAttributesConfig{Pattern: `^(\d+)/(\w+)$`})
+	require.ErrorIs(t, err, errNoStreamPatternGroups)
+}
+
+func TestProcessEventsStreamAttributes(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.StreamAttributes = &StreamAttributesConfig{}
+
+	logsRcvr := newLogsReceiver(cfg, zap.NewNop(), &consumertest.LogsSink{})
+	output := &cloudwatchlogs.FilterLogEventsOutput{
+		Events: []*cloudwatchlogs.FilteredLogEvent{
+			{
+				EventId:       &testEventID,
+				LogStreamName: aws.String("2024/01/01/[$LATEST]2b8f3c1e0a9d4f6e8c7b5a4d3e2f1a0b"),
+				Message:       aws.String(testLogStreamMessage),
+				Timestamp:     aws.Int64(testTimeStamp),
+			},
+		},
+	}
+
+	logs, _ := logsRcvr.processEvents(pcommon.NewTimestampFromTime(time.Now()), "/aws/lambda/checkout", output)
+	attrs := logs.ResourceLogs().At(0).Resource().Attributes()
+	instance, ok := attrs.Get("faas.instance")
+	require.True(t, ok)
+	require.Equal(t, "2b8f3c1e0a9d4f6e8c7b5a4d3e2f1a0b", instance.Str())
+	version, ok := attrs.Get("faas.version")
+	require.True(t, ok)
+	require.Equal(t, "$LATEST", version.Str())
+	date, ok := attrs.Get("cloudwatch.log.stream.date")
+	require.True(t, ok)
+	require.Equal(t, "2024/01/01", date.Str())
+}
@@ -0,0 +1,95 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+)
+
+// streamFilter selects the events of a log group by the name of their log stream. The events of a
+// stream are kept if its name matches any of the include expressions, or there are none, and
+// does not match any of the exclude expressions.
+type streamFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// newStreamFilter compiles the stream_include and stream_exclude expressions of the config.
+// A nil filter is returned if neither is configured.
+func newStreamFilter(cfg StreamConfig) (*streamFilter, error) {
+	if len(cfg.Include) == 0 && len(cfg.Exclude) == 0 {
+		return nil, nil
+	}
+	include, err := compileStreamRegexes("stream_include", cfg.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compileStreamRegexes("stream_exclude", cfg.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &streamFilter{include: include, exclude: exclude}, nil
+}
+
+func compileStreamRegexes(option string, exprs []string) ([]*regexp.Regexp, error) {
+	regexes := make([]*regexp.Regexp, 0, len(exprs))
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compile %s regex %q: %w", option, expr, err)
+		}
+		regexes = append(regexes, re)
+	}
+	return regexes, nil
+}
+
+// matches reports whether the events of the stream are kept, a nil filter keeps all streams.
+func (f *streamFilter) matches(stream string) bool {
+	if f == nil {
+		return true
+	}
+	for _, re := range f.exclude {
+		if re.MatchString(stream) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, re := range f.include {
+		if re.MatchString(stream) {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns the events of the streams that are kept.
+func (f *streamFilter) filter(events []*cloudwatchlogs.FilteredLogEvent) []*cloudwatchlogs.FilteredLogEvent {
+	if f == nil {
+		return events
+	}
+	kept := make([]*cloudwatchlogs.FilteredLogEvent, 0, len(events))
+	for _, e := range events {
+		if f.matches(aws.StringValue(e.LogStreamName)) {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
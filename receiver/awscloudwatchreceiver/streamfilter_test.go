@@ -0,0 +1,142 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+var testStreams = []string{"app-prod-1", "app-test-1", "app-prod-2", "worker-prod", "worker-test"}
+
+func TestStreamFilter(t *testing.T) {
+	cases := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		expected []string
+	}{
+		{
+			name:     "no filter",
+			expected: testStreams,
+		},
+		{
+			name:     "include",
+			include:  []string{"^app-"},
+			expected: []string{"app-prod-1", "app-test-1", "app-prod-2"},
+		},
+		{
+			name:     "include any",
+			include:  []string{"prod-1$", "^worker"},
+			expected: []string{"app-prod-1", "worker-prod", "worker-test"},
+		},
+		{
+			name:     "exclude",
+			exclude:  []string{".*test.*"},
+			expected: []string{"app-prod-1", "app-prod-2", "worker-prod"},
+		},
+		{
+			name:     "include and exclude",
+			include:  []string{"^app-"},
+			exclude:  []string{".*test.*"},
+			expected: []string{"app-prod-1", "app-prod-2"},
+		},
+		{
+			name:    "nothing included",
+			include: []string{"^db-"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name+" named", func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Region = "us-west-1"
+			cfg.Logs.Groups = GroupConfig{
+				NamedConfigs: map[string]StreamConfig{
+					testLogGroupName: {Include: tc.include, Exclude: tc.exclude},
+				},
+			}
+
+			sink := &consumertest.LogsSink{}
+			logsRcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+			logsRcvr.client = multiStreamMockClient()
+
+			require.NoError(t, logsRcvr.poll(context.Background()))
+			require.Equal(t, tc.expected, collectedStreams(sink))
+		})
+
+		t.Run(tc.name+" autodiscover", func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Region = "us-west-1"
+			cfg.Logs.Groups = GroupConfig{
+				AutodiscoverConfig: &AutodiscoverConfig{
+					Limit:   defaultLogGroupLimit,
+					Streams: StreamConfig{Include: tc.include, Exclude: tc.exclude},
+				},
+			}
+
+			sink := &consumertest.LogsSink{}
+			logsRcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+			logsRcvr.client = multiStreamMockClient()
+
+			groups, err := logsRcvr.discoverGroups(context.Background(), logsRcvr.autodiscover)
+			require.NoError(t, err)
+			logsRcvr.groupRequests = groups
+			require.NoError(t, logsRcvr.poll(context.Background()))
+			require.Equal(t, tc.expected, collectedStreams(sink))
+		})
+	}
+}
+
+// multiStreamMockClient returns a single log group with one event in each of the test streams
+func multiStreamMockClient() client {
+	events := make([]*cloudwatchlogs.FilteredLogEvent, 0, len(testStreams))
+	for _, stream := range testStreams {
+		events = append(events, &cloudwatchlogs.FilteredLogEvent{
+			EventId:       aws.String(stream),
+			LogStreamName: aws.String(stream),
+			Message:       aws.String(testLogStreamMessage),
+			Timestamp:     aws.Int64(testTimeStamp),
+		})
+	}
+
+	mc := &mockClient{}
+	mc.On("DescribeLogGroupsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&cloudwatchlogs.DescribeLogGroupsOutput{
+			LogGroups: []*cloudwatchlogs.LogGroup{{LogGroupName: &testLogGroupName}},
+		}, nil)
+	mc.On("FilterLogEventsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&cloudwatchlogs.FilterLogEventsOutput{Events: events}, nil)
+	return mc
+}
+
+// collectedStreams returns the log stream of every collected log record in order
+func collectedStreams(sink *consumertest.LogsSink) []string {
+	var streams []string
+	for _, logs := range sink.AllLogs() {
+		for i := 0; i < logs.ResourceLogs().Len(); i++ {
+			stream, _ := logs.ResourceLogs().At(i).Resource().Attributes().Get("cloudwatch.log.stream")
+			streams = append(streams, stream.Str())
+		}
+	}
+	return streams
+}
@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package solacereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/solacereceiver"
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// errBatchDue is returned by the wait for the next message once the pending batch is due to be forwarded
+var errBatchDue = errors.New("forwarding the pending batch is due")
+
+// spanBatch accumulates the traces of several messages, which are forwarded to the next consumer together
+type spanBatch struct {
+	traces ptrace.Traces
+	// spans is the number of spans of traces
+	spans int
+	// messages are the messages of the spans, in the order they were received
+	messages []*inboundMessage
+	// dueAt is when the batch is forwarded at the latest, it is set by the first message of the batch
+	dueAt time.Time
+}
+
+func newSpanBatch() *spanBatch {
+	return &spanBatch{traces: ptrace.NewTraces()}
+}
+
+// add moves the traces of the message to the batch, the batch is due timeout after its first message was added
+func (b *spanBatch) add(msg *inboundMessage, traces ptrace.Traces, timeout time.Duration) {
+	if len(b.messages) == 0 {
+		b.dueAt = time.Now().Add(timeout)
+	}
+	b.spans += traces.SpanCount()
+	b.messages = append(b.messages, msg)
+	traces.ResourceSpans().MoveAndAppendTo(b.traces.ResourceSpans())
+}
+
+// receiveNextMessage waits for the next message, the wait ends with errBatchDue once the pending batch is due
+func (s *solaceTracesReceiver) receiveNextMessage(ctx context.Context, service messagingService) (*inboundMessage, error) {
+	if s.batch == nil || len(s.batch.messages) == 0 {
+		return service.receiveMessage(ctx)
+	}
+	// the deadline only applies to the wait for a message, the disposition of a received message uses the parent context
+	receiveCtx, cancel := context.WithDeadline(ctx, s.batch.dueAt)
+	defer cancel()
+	msg, err := service.receiveMessage(receiveCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(receiveCtx.Err(), context.DeadlineExceeded) {
+		return nil, errBatchDue
+	}
+	return msg, err
+}
+
+// flushBatch forwards the spans of the pending batch to the next consumer and settles its messages afterwards,
+// it does nothing if no spans are pending. The messages were accepted on receipt in auto ack mode.
+func (s *solaceTracesReceiver) flushBatch(ctx context.Context, service messagingService) error {
+	if s.batch == nil || len(s.batch.messages) == 0 {
+		return nil
+	}
+	batch := s.batch
+	s.batch = newSpanBatch()
+	settled := s.config.AckMode == ackModeAuto
+	disposition := service.accept
+	if s.forwardTraces(ctx, batch.traces, len(batch.messages), settled) {
+		disposition = service.failed
+	}
+	if settled {
+		return nil
+	}
+	var err error
+	for _, msg := range batch.messages {
+		if actionErr := disposition(ctx, msg); err == nil && actionErr != nil {
+			err = actionErr
+		}
+		s.metrics.recordSettledMessage()
+	}
+	return err
+}
@@ -0,0 +1,152 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package solacereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/solacereceiver"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// newBatchReceiver returns a receiver batching by the given number of spans, whose messages each hold one span.
+// The calls to the consumer, with the number of spans consumed, and to the messaging service are recorded in order.
+func newBatchReceiver(t *testing.T, maxSpans int, timeout time.Duration, consumeErr error) (*solaceTracesReceiver, *mockMessagingService, *[]string) {
+	receiver, messagingService, unmarshaller := newReceiver(t)
+	receiver.config.Batch = BatchConfig{MaxSpans: maxSpans, Timeout: timeout}
+	calls := &[]string{}
+	receiver.nextConsumer, _ = consumer.NewTraces(func(ctx context.Context, td ptrace.Traces) error {
+		*calls = append(*calls, fmt.Sprintf("consume %d", td.SpanCount()))
+		return consumeErr
+	})
+	unmarshaller.unmarshalFunc = func(msg *inboundMessage) (ptrace.Traces, error) {
+		traces := ptrace.NewTraces()
+		traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+		return traces, nil
+	}
+	messagingService.ackFunc = func(ctx context.Context, msg *inboundMessage) error {
+		*calls = append(*calls, "ack")
+		return nil
+	}
+	messagingService.nackFunc = func(ctx context.Context, msg *inboundMessage) error {
+		*calls = append(*calls, "nack")
+		return nil
+	}
+	return receiver, messagingService, calls
+}
+
+// receiveMessagesThen makes the messaging service return the given number of messages, then the given error
+func receiveMessagesThen(messagingService *mockMessagingService, messages int, err error) {
+	received := 0
+	messagingService.receiveMessageFunc = func(ctx context.Context) (*inboundMessage, error) {
+		if received == messages {
+			return nil, err
+		}
+		received++
+		return &inboundMessage{}, nil
+	}
+}
+
+func TestReceiveMessagesBatchMaxSpans(t *testing.T) {
+	cases := []struct {
+		name          string
+		consumeErr    error
+		expectedCalls []string
+	}{
+		{
+			name:          "Acks After Forwarding",
+			expectedCalls: []string{"consume 3", "ack", "ack", "ack", "consume 3", "ack", "ack", "ack"},
+		},
+		{
+			name:          "Nacks On Temporary Error",
+			consumeErr:    errors.New("some error"),
+			expectedCalls: []string{"consume 3", "nack", "nack", "nack", "consume 3", "nack", "nack", "nack"},
+		},
+		{
+			name:          "Acks On Permanent Error",
+			consumeErr:    consumererror.NewPermanent(errors.New("some error")),
+			expectedCalls: []string{"consume 3", "ack", "ack", "ack", "consume 3", "ack", "ack", "ack"},
+		},
+	}
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			receiver, messagingService, calls := newBatchReceiver(t, 3, time.Hour, testCase.consumeErr)
+			receiveErr := errors.New("connection lost")
+			receiveMessagesThen(messagingService, 6, receiveErr)
+
+			assert.Equal(t, receiveErr, receiver.receiveMessages(context.Background(), messagingService))
+			// the spans of six messages are forwarded in two calls of the consumer instead of six
+			assert.Equal(t, testCase.expectedCalls, *calls)
+			assert.Equal(t, int64(0), unackedMessagesValue(t, receiver))
+			assert.Nil(t, receiver.batch)
+		})
+	}
+}
+
+func TestReceiveMessagesBatchTimeout(t *testing.T) {
+	receiver, messagingService, calls := newBatchReceiver(t, 100, 10*time.Millisecond, nil)
+	received := 0
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	messagingService.receiveMessageFunc = func(receiveCtx context.Context) (*inboundMessage, error) {
+		if received < 2 {
+			received++
+			return &inboundMessage{}, nil
+		}
+		if len(receiver.batch.messages) > 0 {
+			// the messages are held until the batch is due
+			assert.Empty(t, *calls)
+			assert.Equal(t, int64(2), unackedMessagesValue(t, receiver))
+		} else {
+			// the batch was forwarded at its deadline, stop receiving
+			assert.Equal(t, []string{"consume 2", "ack", "ack"}, *calls)
+			cancel()
+		}
+		<-receiveCtx.Done()
+		return nil, receiveCtx.Err()
+	}
+
+	assert.Error(t, receiver.receiveMessages(ctx, messagingService))
+	assert.Equal(t, []string{"consume 2", "ack", "ack"}, *calls)
+	assert.Equal(t, int64(0), unackedMessagesValue(t, receiver))
+}
+
+func TestReceiveMessagesBatchFlushedOnFailback(t *testing.T) {
+	receiver, messagingService, calls := newBatchReceiver(t, 100, time.Hour, nil)
+	receiveMessagesThen(messagingService, 2, errFailback)
+
+	// the pending spans are forwarded before the receiver switches to the primary broker
+	assert.Equal(t, errFailback, receiver.receiveMessages(context.Background(), messagingService))
+	assert.Equal(t, []string{"consume 2", "ack", "ack"}, *calls)
+	assert.Equal(t, int64(0), unackedMessagesValue(t, receiver))
+}
+
+func TestReceiveMessagesBatchAutoAck(t *testing.T) {
+	receiver, messagingService, calls := newBatchReceiver(t, 2, time.Hour, nil)
+	receiver.config.AckMode = ackModeAuto
+	receiveErr := errors.New("connection lost")
+	receiveMessagesThen(messagingService, 4, receiveErr)
+
+	// the messages are accepted on receipt, their spans are still forwarded in batches
+	assert.Equal(t, receiveErr, receiver.receiveMessages(context.Background(), messagingService))
+	assert.Equal(t, []string{"ack", "ack", "consume 2", "ack", "ack", "consume 2"}, *calls)
+	assert.Equal(t, int64(0), unackedMessagesValue(t, receiver))
+}
@@ -0,0 +1,95 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package solacereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/solacereceiver"
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// defaultMaxDecompressedSize bounds the decompressed payload when max_message_size is not set,
+// so that a small, highly compressed message cannot exhaust the memory of the collector
+const defaultMaxDecompressedSize = 64 * 1024 * 1024
+
+var errDecompressedSizeExceeded = errors.New("decompressed payload exceeds the maximum size")
+
+// decompressPayload replaces the payload of the message with its decompressed content using the
+// given payload compression. With auto compression, the codec is detected from the content encoding
+// of the message, messages without a content encoding are left as is. The decompressed payload may
+// be at most maxSize bytes, or defaultMaxDecompressedSize if maxSize is 0.
+// Returns an error if the payload could not be decompressed.
+func decompressPayload(message *inboundMessage, compression string, maxSize int) error {
+	if compression == payloadCompressionAuto {
+		var err error
+		if compression, err = compressionFromContentEncoding(message); err != nil {
+			return err
+		}
+	}
+
+	var newReader func(io.Reader) (io.ReadCloser, error)
+	switch compression {
+	case payloadCompressionGzip:
+		newReader = func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) }
+	case payloadCompressionZlib:
+		newReader = zlib.NewReader
+	default:
+		return nil
+	}
+
+	data := message.GetData()
+	if len(data) == 0 {
+		return nil // the unmarshaller reports the empty payload
+	}
+	reader, err := newReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to decompress %s payload: %w", compression, err)
+	}
+	defer reader.Close()
+	if maxSize <= 0 {
+		maxSize = defaultMaxDecompressedSize
+	}
+	// read one byte past the limit to tell a payload of exactly maxSize bytes from a larger one
+	decompressed, err := io.ReadAll(io.LimitReader(reader, int64(maxSize)+1))
+	if err != nil {
+		return fmt.Errorf("failed to decompress %s payload: %w", compression, err)
+	}
+	if len(decompressed) > maxSize {
+		return fmt.Errorf("failed to decompress %s payload: %w: %d bytes", compression, errDecompressedSizeExceeded, maxSize)
+	}
+	message.Data = [][]byte{decompressed}
+	return nil
+}
+
+// compressionFromContentEncoding returns the payload compression matching the content encoding of the message.
+func compressionFromContentEncoding(message *inboundMessage) (string, error) {
+	if message.Properties == nil || message.Properties.ContentEncoding == nil {
+		return payloadCompressionNone, nil
+	}
+	switch encoding := strings.ToLower(string(*message.Properties.ContentEncoding)); encoding {
+	case "", "identity":
+		return payloadCompressionNone, nil
+	case "gzip", "x-gzip":
+		return payloadCompressionGzip, nil
+	case "zlib", "deflate":
+		return payloadCompressionZlib, nil
+	default:
+		return "", fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package solacereceiver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"testing"
+
+	"github.com/Azure/go-amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPayload = []byte("some span data")
+
+func TestDecompressPayload(t *testing.T) {
+	gzipped := compressTestPayload(t, payloadCompressionGzip, testPayload)
+	zlibbed := compressTestPayload(t, payloadCompressionZlib, testPayload)
+	tests := []struct {
+		name            string
+		compression     string
+		contentEncoding string
+		data            []byte
+		expected        []byte
+		expectedErr     bool
+	}{
+		{name: "none", compression: payloadCompressionNone, data: gzipped, expected: gzipped},
+		{name: "gzip", compression: payloadCompressionGzip, data: gzipped, expected: testPayload},
+		{name: "zlib", compression: payloadCompressionZlib, data: zlibbed, expected: testPayload},
+		{name: "auto without content encoding", compression: payloadCompressionAuto, data: testPayload, expected: testPayload},
+		{name: "auto identity", compression: payloadCompressionAuto, contentEncoding: "identity", data: testPayload, expected: testPayload},
+		{name: "auto gzip", compression: payloadCompressionAuto, contentEncoding: "gzip", data: gzipped, expected: testPayload},
+		{name: "auto deflate", compression: payloadCompressionAuto, contentEncoding: "deflate", data: zlibbed, expected: testPayload},
+		{name: "auto zlib", compression: payloadCompressionAuto, contentEncoding: "ZLIB", data: zlibbed, expected: testPayload},
+		{name: "auto unsupported encoding", compression: payloadCompressionAuto, contentEncoding: "br", data: testPayload, expectedErr: true},
+		{name: "corrupt gzip", compression: payloadCompressionGzip, data: testPayload, expectedErr: true},
+		{name: "truncated gzip", compression: payloadCompressionGzip, data: gzipped[:len(gzipped)-4], expectedErr: true},
+		{name: "corrupt zlib", compression: payloadCompressionZlib, data: testPayload, expectedErr: true},
+		{name: "gzip with zlib codec", compression: payloadCompressionZlib, data: gzipped, expectedErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &inboundMessage{Data: [][]byte{tt.data}}
+			if tt.contentEncoding != "" {
+				encoding := amqp.AMQPSymbol(tt.contentEncoding)
+				msg.Properties = &amqp.MessageProperties{ContentEncoding: &encoding}
+			}
+			err := decompressPayload(msg, tt.compression, 0)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, msg.GetData())
+		})
+	}
+}
+
+func TestDecompressPayloadEmpty(t *testing.T) {
+	msg := &inboundMessage{}
+	assert.NoError(t, decompressPayload(msg, payloadCompressionGzip, 0))
+	assert.Nil(t, msg.GetData())
+}
+
+func TestDecompressPayloadMaxSize(t *testing.T) {
+	// compressed zeros expand to several hundred times their size, past the default limit
+	bomb := compressTestPayload(t, payloadCompressionGzip, make([]byte, defaultMaxDecompressedSize+1))
+	require.Less(t, len(bomb)*100, defaultMaxDecompressedSize)
+	tests := []struct {
+		name        string
+		compression string
+		data        []byte
+		maxSize     int
+		expectedErr bool
+	}{
+		{name: "default limit exceeded", compression: payloadCompressionGzip, data: bomb, expectedErr: true},
+		{name: "limit exceeded", compression: payloadCompressionZlib, data: compressTestPayload(t, payloadCompressionZlib, testPayload), maxSize: len(testPayload) - 1, expectedErr: true},
+		{name: "exactly at limit", compression: payloadCompressionGzip, data: compressTestPayload(t, payloadCompressionGzip, testPayload), maxSize: len(testPayload)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &inboundMessage{Data: [][]byte{tt.data}}
+			err := decompressPayload(msg, tt.compression, tt.maxSize)
+			if tt.expectedErr {
+				assert.ErrorIs(t, err, errDecompressedSizeExceeded)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testPayload, msg.GetData())
+		})
+	}
+}
+
+// compressTestPayload compresses the data with the given payload compression
+func compressTestPayload(t *testing.T, compression string, data []byte) []byte {
+	var buf bytes.Buffer
+	var writer io.WriteCloser
+	switch compression {
+	case payloadCompressionGzip:
+		writer = gzip.NewWriter(&buf)
+	case payloadCompressionZlib:
+		writer = zlib.NewWriter(&buf)
+	default:
+		t.Fatalf("unsupported compression %s", compression)
+	}
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
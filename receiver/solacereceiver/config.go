@@ -17,6 +17,7 @@ package solacereceiver // import "github.com/open-telemetry/opentelemetry-collec
 import (
 	"errors"
 	"strings"
+	"time"
 
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/configtls"
@@ -25,13 +26,82 @@ import (
 const (
 	// 8Kb
 	saslMaxInitFrameSizeOverride = 8000
+
+	// span names are set to a constant, as the payload carries no name for the span
+	spanNameFromPayload = "payload"
+	// span names are set from the topic the traced message was published to
+	spanNameFromTopic = "topic"
+	// span names are set from an application message property, the name of which follows the prefix
+	spanNameFromHeaderPrefix = "header:"
+
+	// payloads are not compressed
+	payloadCompressionNone = "none"
+	// payloads are compressed with gzip
+	payloadCompressionGzip = "gzip"
+	// payloads are compressed with zlib
+	payloadCompressionZlib = "zlib"
+	// the compression of each payload is detected from the content encoding of the message
+	payloadCompressionAuto = "auto"
+
+	// spans are consumer spans, following the messaging semantic conventions
+	spanKindConsumer = "consumer"
+	// spans are server spans
+	spanKindServer = "server"
+	// spans are internal spans
+	spanKindInternal = "internal"
+
+	// the messaging attributes of the spans are named as in the semantic conventions up to v1.16.0
+	semconvVersion116 = "1.16"
+	// the messaging attributes of the spans are named as in the semantic conventions v1.17.0
+	semconvVersion117 = "1.17"
+
+	// spans with the header value get the ok status
+	spanStatusOk = "ok"
+	// spans with the header value get the error status
+	spanStatusError = "error"
+	// spans with the header value keep the unset status
+	spanStatusUnset = "unset"
+
+	// messages are settled once their spans have been consumed
+	ackModeClient = "client"
+	// messages are accepted on receipt, before their spans are consumed
+	ackModeAuto = "auto"
+
+	// authentication with the sasl_plain user name and password
+	authSchemePlain = "sasl_plain"
+	// authentication with the sasl_xauth2 bearer token
+	authSchemeXAuth2 = "sasl_xauth2"
+	// authentication with the TLS client certificate
+	authSchemeExternal = "sasl_external"
 )
 
+// defaultAuthSchemeOrder is the order the configured authentication schemes are attempted in
+var defaultAuthSchemeOrder = []string{authSchemePlain, authSchemeXAuth2, authSchemeExternal}
+
 var (
 	errMissingAuthDetails     = errors.New("authentication details are required, either for plain user name password or XOAUTH2 or client certificate")
 	errMissingQueueName       = errors.New("queue definition is required, queue definition has format queue://<queuename>")
 	errMissingPlainTextParams = errors.New("missing plain text auth params: Username, Password")
 	errMissingXauth2Params    = errors.New("missing xauth2 text auth params: Username, Bearer")
+	errInvalidHeartbeat       = errors.New("heartbeat interval must not be negative")
+	errInvalidHeartbeatJitter = errors.New("heartbeat jitter must be at least 0 and less than 1")
+	errEmptySelector          = errors.New("selector must not be empty when set")
+	errInvalidSpanNameFrom    = errors.New("span_name_from must be one of payload, topic or header:<name>")
+	errInvalidCompression     = errors.New("payload_compression must be one of none, gzip, zlib or auto")
+	errMissingTraceparent     = errors.New("propagate_trace_context.traceparent_header must not be empty when propagation is enabled")
+	errInvalidMaxMessageSize  = errors.New("max_message_size must not be negative")
+	errInvalidSpanKind        = errors.New("span_kind must be one of consumer, server or internal")
+	errInvalidSemconvVersion  = errors.New("semconv_version must be one of 1.16 or 1.17")
+	errInvalidAckMode         = errors.New("ack_mode must be one of client or auto")
+	errMissingStatusValues    = errors.New("span_status.values must not be empty when span_status.header is set")
+	errInvalidStatusValue     = errors.New("span_status.values must map header values to one of ok, error or unset")
+	errInvalidFailback        = errors.New("failback_interval must be positive when a secondary_broker is set")
+	errInvalidMaxMessageAge   = errors.New("max_message_age must not be negative")
+	errPartitionedSelector    = errors.New("selector must not be set when partition.enabled is set, partitioned queues do not support selectors")
+	errInvalidBatchMaxSpans   = errors.New("batch.max_spans must not be negative")
+	errInvalidBatchTimeout    = errors.New("batch.timeout must be positive when batch.max_spans is set")
+	errEmptyResourceAttribute = errors.New("resource_attributes must map application properties to non-empty attribute names")
+	errInvalidSchemeOrder     = errors.New("auth.scheme_order must only name configured schemes of sasl_plain, sasl_xauth2 or sasl_external, each at most once")
 )
 
 // Config defines configuration for Solace receiver.
@@ -40,12 +110,75 @@ type Config struct {
 	// The list of solace brokers (default localhost:5671)
 	Broker []string `mapstructure:"broker"`
 
+	// The disaster recovery broker the receiver fails over to when the primary broker, the first of the brokers, cannot be reached
+	SecondaryBroker string `mapstructure:"secondary_broker"`
+
+	// The interval at which the receiver connected to the secondary broker attempts to fail back to the primary broker
+	FailbackInterval time.Duration `mapstructure:"failback_interval"`
+
 	// The name of the solace queue to consume from, it is required parameter
 	Queue string `mapstructure:"queue"`
 
 	// The maximum number of unacknowledged messages the Solace broker can transmit, to configure AMQP Link
 	MaxUnacked uint32 `mapstructure:"max_unacknowledged"`
 
+	// The interval at which the Solace broker is requested to send heartbeats. If no frames are received
+	// from the broker within twice this interval the connection is considered dead and is reestablished.
+	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval"`
+
+	// The fraction, between 0 and 1, of the heartbeat interval it is randomly shortened by on every connection, so that
+	// receivers reconnecting at the same time do not request heartbeats in step. The interval is not jittered if 0.
+	HeartbeatJitter float64 `mapstructure:"heartbeat_jitter"`
+
+	// The JMS-style message selector evaluated by the Solace broker, only messages matching the selector are delivered
+	Selector string `mapstructure:"selector"`
+
+	// The source of the names of the received spans, one of payload, topic or header:<name>
+	SpanNameFrom string `mapstructure:"span_name_from"`
+
+	// The kind of the received spans, one of consumer, server or internal
+	SpanKind string `mapstructure:"span_kind"`
+
+	// The version of the semantic conventions the messaging attributes of the spans are named after, one of 1.16 or 1.17
+	SemconvVersion string `mapstructure:"semconv_version"`
+
+	// When the received messages are settled, one of client after their spans are consumed or auto on receipt
+	AckMode string `mapstructure:"ack_mode"`
+
+	// The compression of the message payloads, one of none, gzip, zlib or auto
+	PayloadCompression string `mapstructure:"payload_compression"`
+
+	// The largest payload in bytes that is unmarshalled, larger messages are rejected. 0 means no limit
+	MaxMessageSize int `mapstructure:"max_message_size"`
+
+	// The maximum age of a message by its creation time, older messages are dropped. 0 means no limit
+	MaxMessageAge time.Duration `mapstructure:"max_message_age"`
+
+	// The status of the spans from a header of the traced messages
+	SpanStatus SpanStatusConfig `mapstructure:"span_status"`
+
+	// The propagation of the W3C trace context from the headers of the traced messages
+	PropagateTraceContext TraceContextConfig `mapstructure:"propagate_trace_context"`
+
+	// The span link to the span a traced message is correlated with from a header of the message
+	SpanLink SpanLinkConfig `mapstructure:"span_link"`
+
+	// The handling of the messages the broker redelivers after a failed delivery
+	Redelivery RedeliveryConfig `mapstructure:"redelivery"`
+
+	// The resource attributes identifying the broker and message VPN the spans were received from
+	Provenance ProvenanceConfig `mapstructure:"provenance"`
+
+	// The consumption of a partitioned queue and the tagging of the spans with the partition key of their message
+	Partition PartitionConfig `mapstructure:"partition"`
+
+	// The batching of the spans of several messages, which are forwarded to the next consumer together
+	Batch BatchConfig `mapstructure:"batch"`
+
+	// ResourceAttributes maps application properties of the received messages, such as the ones identifying the
+	// system that produced the spans, to the resource attributes they are set as on the spans of the message
+	ResourceAttributes map[string]string `mapstructure:"resource_attributes"`
+
 	TLS configtls.TLSClientSetting `mapstructure:"tls,omitempty"`
 
 	Auth Authentication `mapstructure:"auth"`
@@ -56,17 +189,188 @@ func (cfg *Config) Validate() error {
 	if cfg.Auth.PlainText == nil && cfg.Auth.External == nil && cfg.Auth.XAuth2 == nil {
 		return errMissingAuthDetails
 	}
+	if !cfg.Auth.validSchemeOrder() {
+		return errInvalidSchemeOrder
+	}
 	if len(strings.TrimSpace(cfg.Queue)) == 0 {
 		return errMissingQueueName
 	}
+	if cfg.SecondaryBroker != "" && cfg.FailbackInterval <= 0 {
+		return errInvalidFailback
+	}
+	if cfg.HeartbeatInterval < 0 {
+		return errInvalidHeartbeat
+	}
+	if cfg.HeartbeatJitter < 0 || cfg.HeartbeatJitter >= 1 {
+		return errInvalidHeartbeatJitter
+	}
+	if cfg.Selector != "" && len(strings.TrimSpace(cfg.Selector)) == 0 {
+		return errEmptySelector
+	}
+	if cfg.Partition.Enabled && cfg.Selector != "" {
+		return errPartitionedSelector
+	}
+	if cfg.Batch.MaxSpans < 0 {
+		return errInvalidBatchMaxSpans
+	}
+	if cfg.Batch.MaxSpans > 0 && cfg.Batch.Timeout <= 0 {
+		return errInvalidBatchTimeout
+	}
+	for property, attribute := range cfg.ResourceAttributes {
+		if property == "" || attribute == "" {
+			return errEmptyResourceAttribute
+		}
+	}
+	if cfg.SpanNameFrom != spanNameFromPayload && cfg.SpanNameFrom != spanNameFromTopic &&
+		(!strings.HasPrefix(cfg.SpanNameFrom, spanNameFromHeaderPrefix) || cfg.SpanNameFrom == spanNameFromHeaderPrefix) {
+		return errInvalidSpanNameFrom
+	}
+	switch cfg.SpanKind {
+	case spanKindConsumer, spanKindServer, spanKindInternal:
+	default:
+		return errInvalidSpanKind
+	}
+	if _, ok := semconvAttributeKeys[cfg.SemconvVersion]; !ok {
+		return errInvalidSemconvVersion
+	}
+	switch cfg.AckMode {
+	case ackModeClient, ackModeAuto:
+	default:
+		return errInvalidAckMode
+	}
+	switch cfg.PayloadCompression {
+	case payloadCompressionNone, payloadCompressionGzip, payloadCompressionZlib, payloadCompressionAuto:
+	default:
+		return errInvalidCompression
+	}
+	if cfg.MaxMessageSize < 0 {
+		return errInvalidMaxMessageSize
+	}
+	if cfg.MaxMessageAge < 0 {
+		return errInvalidMaxMessageAge
+	}
+	if cfg.PropagateTraceContext.Enabled && len(strings.TrimSpace(cfg.PropagateTraceContext.TraceparentHeader)) == 0 {
+		return errMissingTraceparent
+	}
+	if cfg.SpanStatus.Header != "" && len(cfg.SpanStatus.Values) == 0 {
+		return errMissingStatusValues
+	}
+	for _, status := range cfg.SpanStatus.Values {
+		switch status {
+		case spanStatusOk, spanStatusError, spanStatusUnset:
+		default:
+			return errInvalidStatusValue
+		}
+	}
 	return nil
 }
 
+// SpanStatusConfig defines the status of the spans from a header that the producer of a traced message put in its headers.
+type SpanStatusConfig struct {
+	// The user property of the traced messages holding the status, the status is not taken from the headers if empty
+	Header string `mapstructure:"header"`
+	// Values maps the values of the header to the status codes of the spans, one of ok, error or unset
+	Values map[string]string `mapstructure:"values"`
+}
+
+// TraceContextConfig defines the propagation of the W3C trace context that the producer of a traced message put in its headers.
+type TraceContextConfig struct {
+	// Enabled sets the trace and parent span IDs of the spans from the traceparent header of the traced messages
+	Enabled bool `mapstructure:"enabled"`
+	// The user property of the traced messages holding the W3C traceparent
+	TraceparentHeader string `mapstructure:"traceparent_header"`
+	// The user property of the traced messages holding the W3C tracestate
+	TracestateHeader string `mapstructure:"tracestate_header"`
+}
+
+// SpanLinkConfig defines the span link to the span that the producer of a traced message correlated the message with,
+// such as the span of the request a reply message answers, which belongs to another trace than the message.
+type SpanLinkConfig struct {
+	// The user property of the traced messages holding the W3C traceparent of the correlated span, no link is added if empty
+	CorrelationHeader string `mapstructure:"correlation_header"`
+}
+
+// RedeliveryConfig defines the handling of the messages the broker redelivers after a failed delivery.
+type RedeliveryConfig struct {
+	// TagSpans sets the messaging.solace.redelivered attribute on the spans of redelivered messages
+	TagSpans bool `mapstructure:"tag_spans"`
+	// MaxDeliveryCount is the number of failed deliveries after which a message is rejected. 0 means no limit
+	MaxDeliveryCount uint32 `mapstructure:"max_delivery_count"`
+}
+
+// ProvenanceConfig defines the resource attributes identifying the broker and message VPN the spans were received from.
+type ProvenanceConfig struct {
+	// Enabled sets the messaging.solace.broker resource attribute to the host of the broker the receiver is connected to
+	Enabled bool `mapstructure:"enabled"`
+	// VPN is the value of the messaging.solace.vpn resource attribute, the attribute is not set if empty
+	VPN string `mapstructure:"vpn"`
+}
+
+// PartitionConfig defines the consumption of a partitioned queue, which the broker distributes across the consumers
+// bound to it by the partition key of the messages, so that the messages of a partition are consumed by one receiver.
+type PartitionConfig struct {
+	// Enabled sets the messaging.solace.partition_key attribute on the spans of messages with a partition key
+	Enabled bool `mapstructure:"enabled"`
+	// The application property of the received messages holding the partition key, the AMQP group-id of the
+	// messages, which the broker maps to the JMSXGroupID partition key, is used if empty
+	KeyHeader string `mapstructure:"key_header"`
+}
+
+// BatchConfig defines the batching of the spans of several messages into one call of the next consumer. The messages
+// of a batch are only settled once its spans were forwarded, so that they are redelivered if the spans are not consumed.
+type BatchConfig struct {
+	// MaxSpans is the number of spans at which the batch is forwarded, the spans of every message are forwarded on their own if 0
+	MaxSpans int `mapstructure:"max_spans"`
+	// Timeout is the longest the first message of a batch waits for the batch to be forwarded
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
 // Authentication defines authentication strategies.
 type Authentication struct {
 	PlainText *SaslPlainTextConfig `mapstructure:"sasl_plain"`
 	XAuth2    *SaslXAuth2Config    `mapstructure:"sasl_xauth2"`
 	External  *SaslExternalConfig  `mapstructure:"sasl_external"`
+	// SchemeOrder is the order the configured schemes are attempted in until the broker accepts one.
+	// Configured schemes that are not listed are attempted afterwards, in the default order.
+	SchemeOrder []string `mapstructure:"scheme_order"`
+}
+
+// configured returns true if the authentication scheme of the given name is configured
+func (a *Authentication) configured(scheme string) bool {
+	switch scheme {
+	case authSchemePlain:
+		return a.PlainText != nil
+	case authSchemeXAuth2:
+		return a.XAuth2 != nil
+	case authSchemeExternal:
+		return a.External != nil
+	}
+	return false
+}
+
+// validSchemeOrder returns true if the scheme order names every configured scheme at most once
+func (a *Authentication) validSchemeOrder() bool {
+	seen := map[string]bool{}
+	for _, scheme := range a.SchemeOrder {
+		if seen[scheme] || !a.configured(scheme) {
+			return false
+		}
+		seen[scheme] = true
+	}
+	return true
+}
+
+// schemeOrder returns the names of the configured schemes in the order they are attempted
+func (a *Authentication) schemeOrder() []string {
+	var order []string
+	seen := map[string]bool{}
+	for _, scheme := range append(append([]string{}, a.SchemeOrder...), defaultAuthSchemeOrder...) {
+		if !seen[scheme] && a.configured(scheme) {
+			order = append(order, scheme)
+		}
+		seen[scheme] = true
+	}
+	return order
 }
 
 // SaslPlainTextConfig defines SASL PLAIN authentication.
@@ -17,6 +17,7 @@ package solacereceiver // import "github.com/open-telemetry/opentelemetry-collec
 import (
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -42,14 +43,54 @@ func TestLoadConfig(t *testing.T) {
 			expected: &Config{
 				ReceiverSettings: config.NewReceiverSettings(component.NewID(componentType)),
 				Broker:           []string{"myHost:5671"},
+				SecondaryBroker:  "myBackupHost:5671",
+				FailbackInterval: time.Minute,
 				Auth: Authentication{
 					PlainText: &SaslPlainTextConfig{
 						Username: "otel",
 						Password: "otel01$",
 					},
+					SchemeOrder: []string{"sasl_plain"},
+				},
+				Queue:              "queue://#trace-profile123",
+				MaxUnacked:         1234,
+				HeartbeatInterval:  10 * time.Second,
+				HeartbeatJitter:    0.2,
+				Selector:           "service_name = 'checkout'",
+				SpanNameFrom:       "header:operation",
+				SpanKind:           "server",
+				SemconvVersion:     "1.17",
+				AckMode:            "auto",
+				PayloadCompression: "auto",
+				MaxMessageSize:     1048576,
+				MaxMessageAge:      10 * time.Minute,
+				SpanStatus: SpanStatusConfig{
+					Header: "outcome",
+					Values: map[string]string{"success": "ok", "failure": "error"},
+				},
+				PropagateTraceContext: TraceContextConfig{
+					Enabled:           true,
+					TraceparentHeader: "x-traceparent",
+					TracestateHeader:  "tracestate",
+				},
+				SpanLink: SpanLinkConfig{
+					CorrelationHeader: "x-correlation",
+				},
+				Redelivery: RedeliveryConfig{
+					TagSpans:         true,
+					MaxDeliveryCount: 5,
+				},
+				Provenance: ProvenanceConfig{
+					Enabled: true,
+					VPN:     "default",
+				},
+				Batch: BatchConfig{
+					MaxSpans: 512,
+					Timeout:  500 * time.Millisecond,
+				},
+				ResourceAttributes: map[string]string{
+					"source_system": "source.system",
 				},
-				Queue:      "queue://#trace-profile123",
-				MaxUnacked: 1234,
 				TLS: configtls.TLSClientSetting{
 					Insecure:           false,
 					InsecureSkipVerify: false,
@@ -99,6 +140,216 @@ func TestConfigValidateMissingQueue(t *testing.T) {
 	assert.Equal(t, errMissingQueueName, err)
 }
 
+func TestConfigValidateInvalidHeartbeatJitter(t *testing.T) {
+	for _, jitter := range []float64{-0.1, 1, 1.5} {
+		cfg := createDefaultConfig().(*Config)
+		cfg.Queue = "someQueue"
+		cfg.Auth.PlainText = &SaslPlainTextConfig{"Username", "Password"}
+		cfg.HeartbeatJitter = jitter
+		err := component.ValidateConfig(cfg)
+		assert.Equal(t, errInvalidHeartbeatJitter, err, jitter)
+	}
+}
+
+func TestConfigValidateNegativeHeartbeat(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Queue = "someQueue"
+	cfg.Auth.PlainText = &SaslPlainTextConfig{"Username", "Password"}
+	cfg.HeartbeatInterval = -1 * time.Second
+	err := component.ValidateConfig(cfg)
+	assert.Equal(t, errInvalidHeartbeat, err)
+}
+
+func TestConfigValidateBlankSelector(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Queue = "someQueue"
+	cfg.Auth.PlainText = &SaslPlainTextConfig{"Username", "Password"}
+	cfg.Selector = "  "
+	err := component.ValidateConfig(cfg)
+	assert.Equal(t, errEmptySelector, err)
+}
+
+func TestConfigValidatePartitionedSelector(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Queue = "someQueue"
+	cfg.Auth.PlainText = &SaslPlainTextConfig{"Username", "Password"}
+	cfg.Partition.Enabled = true
+	assert.NoError(t, component.ValidateConfig(cfg))
+	cfg.Selector = "service_name = 'checkout'"
+	err := component.ValidateConfig(cfg)
+	assert.Equal(t, errPartitionedSelector, err)
+}
+
+func TestConfigValidateBatch(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Queue = "someQueue"
+	cfg.Auth.PlainText = &SaslPlainTextConfig{"Username", "Password"}
+	cfg.Batch.MaxSpans = -1
+	assert.Equal(t, errInvalidBatchMaxSpans, component.ValidateConfig(cfg))
+	cfg.Batch = BatchConfig{MaxSpans: 100}
+	assert.Equal(t, errInvalidBatchTimeout, component.ValidateConfig(cfg))
+	// the timeout is not used if batching is disabled
+	cfg.Batch = BatchConfig{}
+	assert.NoError(t, component.ValidateConfig(cfg))
+}
+
+func TestConfigValidateEmptyResourceAttribute(t *testing.T) {
+	for name, mapping := range map[string]map[string]string{
+		"empty property":  {"": "source.system"},
+		"empty attribute": {"source_system": ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Queue = "someQueue"
+			cfg.Auth.PlainText = &SaslPlainTextConfig{"Username", "Password"}
+			cfg.ResourceAttributes = mapping
+			err := component.ValidateConfig(cfg)
+			assert.Equal(t, errEmptyResourceAttribute, err)
+		})
+	}
+}
+
+func TestConfigValidateInvalidSpanNameFrom(t *testing.T) {
+	for _, spanNameFrom := range []string{"", "destination", "header:"} {
+		t.Run(spanNameFrom, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Queue = "someQueue"
+			cfg.Auth.PlainText = &SaslPlainTextConfig{"Username", "Password"}
+			cfg.SpanNameFrom = spanNameFrom
+			err := component.ValidateConfig(cfg)
+			assert.Equal(t, errInvalidSpanNameFrom, err)
+		})
+	}
+}
+
+func TestConfigValidateInvalidSpanKind(t *testing.T) {
+	for _, spanKind := range []string{"", "client", "CONSUMER"} {
+		t.Run(spanKind, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Queue = "someQueue"
+			cfg.Auth.PlainText = &SaslPlainTextConfig{"Username", "Password"}
+			cfg.SpanKind = spanKind
+			err := component.ValidateConfig(cfg)
+			assert.Equal(t, errInvalidSpanKind, err)
+		})
+	}
+}
+
+func TestConfigValidateInvalidSemconvVersion(t *testing.T) {
+	for _, semconvVersion := range []string{"", "1.6", "v1.17"} {
+		t.Run(semconvVersion, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Queue = "someQueue"
+			cfg.Auth.PlainText = &SaslPlainTextConfig{"Username", "Password"}
+			cfg.SemconvVersion = semconvVersion
+			err := component.ValidateConfig(cfg)
+			assert.Equal(t, errInvalidSemconvVersion, err)
+		})
+	}
+}
+
+func TestConfigValidateInvalidAckMode(t *testing.T) {
+	for _, ackMode := range []string{"", "manual", "AUTO"} {
+		t.Run(ackMode, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Queue = "someQueue"
+			cfg.Auth.PlainText = &SaslPlainTextConfig{"Username", "Password"}
+			cfg.AckMode = ackMode
+			err := component.ValidateConfig(cfg)
+			assert.Equal(t, errInvalidAckMode, err)
+		})
+	}
+}
+
+func TestConfigValidateSpanStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		spanStatus SpanStatusConfig
+		want       error
+	}{
+		{name: "Not Configured"},
+		{name: "Valid", spanStatus: SpanStatusConfig{Header: "outcome", Values: map[string]string{"success": "ok", "failure": "error", "pending": "unset"}}},
+		{name: "Missing Values", spanStatus: SpanStatusConfig{Header: "outcome"}, want: errMissingStatusValues},
+		{name: "Invalid Value", spanStatus: SpanStatusConfig{Header: "outcome", Values: map[string]string{"success": "OK"}}, want: errInvalidStatusValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Queue = "someQueue"
+			cfg.Auth.PlainText = &SaslPlainTextConfig{"Username", "Password"}
+			cfg.SpanStatus = tt.spanStatus
+			err := component.ValidateConfig(cfg)
+			assert.Equal(t, tt.want, err)
+		})
+	}
+}
+
+func TestConfigValidateInvalidPayloadCompression(t *testing.T) {
+	for _, compression := range []string{"", "deflate", "GZIP"} {
+		t.Run(compression, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Queue = "someQueue"
+			cfg.Auth.PlainText = &SaslPlainTextConfig{"Username", "Password"}
+			cfg.PayloadCompression = compression
+			err := component.ValidateConfig(cfg)
+			assert.Equal(t, errInvalidCompression, err)
+		})
+	}
+}
+
+func TestConfigValidateNegativeMaxMessageSize(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Queue = "someQueue"
+	cfg.Auth.PlainText = &SaslPlainTextConfig{"Username", "Password"}
+	cfg.MaxMessageSize = -1
+	err := component.ValidateConfig(cfg)
+	assert.Equal(t, errInvalidMaxMessageSize, err)
+}
+
+func TestConfigValidateNegativeMaxMessageAge(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Queue = "someQueue"
+	cfg.Auth.PlainText = &SaslPlainTextConfig{"Username", "Password"}
+	cfg.MaxMessageAge = -time.Second
+	err := component.ValidateConfig(cfg)
+	assert.Equal(t, errInvalidMaxMessageAge, err)
+}
+
+func TestConfigValidateInvalidSchemeOrder(t *testing.T) {
+	for _, order := range [][]string{
+		{"sasl_anonymous"},
+		{"sasl_plain", "sasl_plain"},
+		{"sasl_xauth2"}, // not configured
+	} {
+		cfg := createDefaultConfig().(*Config)
+		cfg.Queue = "someQueue"
+		cfg.Auth.PlainText = &SaslPlainTextConfig{"Username", "Password"}
+		cfg.Auth.SchemeOrder = order
+		err := component.ValidateConfig(cfg)
+		assert.Equal(t, errInvalidSchemeOrder, err)
+	}
+}
+
+func TestConfigValidateInvalidFailbackInterval(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Queue = "someQueue"
+	cfg.Auth.PlainText = &SaslPlainTextConfig{"Username", "Password"}
+	cfg.SecondaryBroker = "backup:5671"
+	cfg.FailbackInterval = 0
+	err := component.ValidateConfig(cfg)
+	assert.Equal(t, errInvalidFailback, err)
+}
+
+func TestConfigValidateMissingTraceparentHeader(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Queue = "someQueue"
+	cfg.Auth.PlainText = &SaslPlainTextConfig{"Username", "Password"}
+	cfg.PropagateTraceContext.Enabled = true
+	cfg.PropagateTraceContext.TraceparentHeader = " "
+	err := component.ValidateConfig(cfg)
+	assert.Equal(t, errMissingTraceparent, err)
+}
+
 func TestConfigValidateSuccess(t *testing.T) {
 	successCases := map[string]func(*Config){
 		"With Plaintext Auth": func(c *Config) {
@@ -113,6 +364,26 @@ func TestConfigValidateSuccess(t *testing.T) {
 		"With External Auth": func(c *Config) {
 			c.Auth.External = &SaslExternalConfig{}
 		},
+		"With Span Name From Topic": func(c *Config) {
+			c.Auth.External = &SaslExternalConfig{}
+			c.SpanNameFrom = "topic"
+		},
+		"With Internal Span Kind": func(c *Config) {
+			c.Auth.External = &SaslExternalConfig{}
+			c.SpanKind = "internal"
+		},
+		"With Secondary Broker": func(c *Config) {
+			c.Auth.External = &SaslExternalConfig{}
+			c.SecondaryBroker = "backup:5671"
+		},
+		"With Gzip Payload Compression": func(c *Config) {
+			c.Auth.External = &SaslExternalConfig{}
+			c.PayloadCompression = "gzip"
+		},
+		"With Trace Context Propagation": func(c *Config) {
+			c.Auth.External = &SaslExternalConfig{}
+			c.PropagateTraceContext.Enabled = true
+		},
 	}
 
 	for caseName, configure := range successCases {
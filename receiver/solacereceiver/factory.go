@@ -16,6 +16,7 @@ package solacereceiver // import "github.com/open-telemetry/opentelemetry-collec
 
 import (
 	"context"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
@@ -32,6 +33,15 @@ const (
 	defaultMaxUnaked uint32 = 1000
 	// default value for host
 	defaultHost string = "localhost:5671"
+	// default value for the heartbeat interval, the connection is considered dead after two missed heartbeats
+	defaultHeartbeatInterval = 30 * time.Second
+	// default value for the interval between attempts to fail back to the primary broker
+	defaultFailbackInterval = 5 * time.Minute
+	// default value for the longest the first message of a batch waits for the batch to be forwarded
+	defaultBatchTimeout = 200 * time.Millisecond
+	// default headers of the W3C trace context
+	defaultTraceparentHeader = "traceparent"
+	defaultTracestateHeader  = "tracestate"
 )
 
 // NewFactory creates a factory for Solace receiver.
@@ -46,10 +56,24 @@ func NewFactory() component.ReceiverFactory {
 // createDefaultConfig creates the default configuration for receiver.
 func createDefaultConfig() component.ReceiverConfig {
 	return &Config{
-		ReceiverSettings: config.NewReceiverSettings(component.NewID(componentType)),
-		Broker:           []string{defaultHost},
-		MaxUnacked:       defaultMaxUnaked,
-		Auth:             Authentication{},
+		ReceiverSettings:   config.NewReceiverSettings(component.NewID(componentType)),
+		Broker:             []string{defaultHost},
+		MaxUnacked:         defaultMaxUnaked,
+		HeartbeatInterval:  defaultHeartbeatInterval,
+		FailbackInterval:   defaultFailbackInterval,
+		SpanNameFrom:       spanNameFromPayload,
+		SpanKind:           spanKindConsumer,
+		SemconvVersion:     semconvVersion116,
+		AckMode:            ackModeClient,
+		PayloadCompression: payloadCompressionNone,
+		PropagateTraceContext: TraceContextConfig{
+			TraceparentHeader: defaultTraceparentHeader,
+			TracestateHeader:  defaultTracestateHeader,
+		},
+		Batch: BatchConfig{
+			Timeout: defaultBatchTimeout,
+		},
+		Auth: Authentication{},
 		TLS: configtls.TLSClientSetting{
 			InsecureSkipVerify: false,
 			Insecure:           false,
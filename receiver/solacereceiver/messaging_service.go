@@ -16,7 +16,12 @@ package solacereceiver // import "github.com/open-telemetry/opentelemetry-collec
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
 
 	"github.com/Azure/go-amqp"
 	"go.uber.org/zap"
@@ -25,6 +30,10 @@ import (
 // inboundMessage is an alias for amqp.Message
 type inboundMessage = amqp.Message
 
+// errHeartbeatTimeout is returned when the connection is considered dead as no frames, including heartbeats,
+// have been received from the broker within the heartbeat timeout
+var errHeartbeatTimeout = errors.New("no heartbeat received from broker within the heartbeat timeout")
+
 // messagingService abstracts out the AMQP transport capabilities for unit testing
 type messagingService interface {
 	dial() error
@@ -32,6 +41,7 @@ type messagingService interface {
 	receiveMessage(ctx context.Context) (*inboundMessage, error)
 	accept(ctx context.Context, msg *inboundMessage) error
 	failed(ctx context.Context, msg *inboundMessage) error
+	reject(ctx context.Context, msg *inboundMessage) error
 }
 
 // messagingServiceFactory is a factory to create new messagingService instances
@@ -40,9 +50,9 @@ type messagingServiceFactory func() messagingService
 // connTLSConfig abstracts out amqp.ConnTLSConfig in order for substitution in tests
 var connTLSConfig = amqp.ConnTLSConfig
 
-// newAMQPMessagingServiceFactory creates a new messagingServiceFactory backed by AMQP
-func newAMQPMessagingServiceFactory(cfg *Config, logger *zap.Logger) (messagingServiceFactory, error) {
-	saslConnOption, authErr := toAMQPAuthentication(cfg)
+// newAMQPMessagingServiceFactory creates a new messagingServiceFactory backed by AMQP connecting to the given broker
+func newAMQPMessagingServiceFactory(cfg *Config, broker string, logger *zap.Logger) (messagingServiceFactory, error) {
+	saslSchemes, authErr := toAMQPAuthentication(cfg)
 	if authErr != nil {
 		return nil, authErr
 	}
@@ -56,7 +66,6 @@ func newAMQPMessagingServiceFactory(cfg *Config, logger *zap.Logger) (messagingS
 
 	var tlsConfig amqp.ConnOption
 
-	broker := cfg.Broker[0]
 	// If the TLS config is nil, insecure is true and we should use amqp rather than amqps
 	scheme := "amqp"
 	if loadedTLSConfig != nil {
@@ -66,14 +75,22 @@ func newAMQPMessagingServiceFactory(cfg *Config, logger *zap.Logger) (messagingS
 	amqpHostAddress := fmt.Sprintf("%s://%s", scheme, broker)
 
 	connectConfig := &amqpConnectConfig{
-		addr:       amqpHostAddress,
-		tlsConfig:  tlsConfig,
-		saslConfig: saslConnOption,
+		addr:        amqpHostAddress,
+		tlsConfig:   tlsConfig,
+		saslSchemes: saslSchemes,
+		// the broker is requested to send heartbeats at half of the idle timeout
+		idleTimeout:     2 * cfg.HeartbeatInterval,
+		heartbeatJitter: cfg.HeartbeatJitter,
 	}
 
 	receiverConfig := &amqpReceiverConfig{
-		queue:      cfg.Queue,
-		maxUnacked: cfg.MaxUnacked,
+		queue:       cfg.Queue,
+		maxUnacked:  cfg.MaxUnacked,
+		selector:    cfg.Selector,
+		partitioned: cfg.Partition.Enabled,
+	}
+	if cfg.MaxMessageSize > 0 {
+		receiverConfig.maxMessageSize = uint64(cfg.MaxMessageSize)
 	}
 
 	return func() messagingService {
@@ -88,14 +105,37 @@ func newAMQPMessagingServiceFactory(cfg *Config, logger *zap.Logger) (messagingS
 
 type amqpConnectConfig struct {
 	// conenct config
-	addr       string
-	saslConfig amqp.ConnOption
-	tlsConfig  amqp.ConnOption
+	addr string
+	// saslSchemes are the authentication schemes that are attempted in order until one succeeds
+	saslSchemes []saslScheme
+	tlsConfig   amqp.ConnOption
+	// idleTimeout is the maximum period between receiving frames before the connection is closed
+	idleTimeout time.Duration
+	// heartbeatJitter is the fraction of the idle timeout it is randomly shortened by on every connection
+	heartbeatJitter float64
+}
+
+// jitterFunc returns a random number in [0.0,1.0), it is abstracted out into a variable in order for substitutions
+var jitterFunc = rand.Float64
+
+// jitteredIdleTimeout returns the idle timeout shortened by a random part of the heartbeat jitter, so that the
+// heartbeats of receivers connecting at the same time, such as after the broker restarted, are spread out
+func (c *amqpConnectConfig) jitteredIdleTimeout() time.Duration {
+	if c.heartbeatJitter <= 0 {
+		return c.idleTimeout
+	}
+	return c.idleTimeout - time.Duration(float64(c.idleTimeout)*c.heartbeatJitter*jitterFunc())
 }
 
 type amqpReceiverConfig struct {
 	queue      string
 	maxUnacked uint32
+	// selector is the message selector used to filter the messages delivered by the broker, no filter is set if empty
+	selector string
+	// maxMessageSize is the largest message the broker may transfer over the link, unlimited if 0
+	maxMessageSize uint64
+	// partitioned is true if the queue is a partitioned queue, the broker assigns partitions of it to the link
+	partitioned bool
 }
 
 type amqpMessagingService struct {
@@ -108,6 +148,10 @@ type amqpMessagingService struct {
 	client   *amqp.Client
 	session  *amqp.Session
 	receiver *amqp.Receiver
+	// scheme is the authentication scheme the connection was established with
+	scheme string
+	// idleTimeout is the idle timeout of the connection, the jittered idle timeout of the connect config
+	idleTimeout time.Duration
 }
 
 // dialFunc is abstracted out into a variable in order for substitutions
@@ -118,13 +162,22 @@ var dialFunc = amqp.Dial
 const telemetryLinkName = "rx"
 
 func (m *amqpMessagingService) dial() (err error) {
-	opts := []amqp.ConnOption{m.connectConfig.saslConfig}
-	if m.connectConfig.tlsConfig != nil {
-		opts = append(opts, m.connectConfig.tlsConfig)
+	schemes := m.connectConfig.saslSchemes
+	if len(schemes) == 0 {
+		// without any scheme the connection is attempted once without SASL authentication
+		schemes = []saslScheme{{}}
 	}
-	m.logger.Debug("Dialing AMQP", zap.String("addr", m.connectConfig.addr))
-	m.client, err = dialFunc(m.connectConfig.addr, opts...)
-	if err != nil {
+	m.idleTimeout = m.connectConfig.jitteredIdleTimeout()
+	for i, scheme := range schemes {
+		m.client, err = m.dialScheme(scheme)
+		if err == nil {
+			m.scheme = scheme.name
+			break
+		}
+		if i+1 < len(schemes) && isAuthFailure(err) {
+			m.logger.Debug("AMQP authentication failure, attempting the next scheme", zap.String("scheme", scheme.name), zap.Error(err))
+			continue
+		}
 		m.logger.Debug("Dial AMQP failure", zap.Error(err))
 		return err
 	}
@@ -135,18 +188,58 @@ func (m *amqpMessagingService) dial() (err error) {
 		return err
 	}
 	m.logger.Debug("Creating new AMQP Receive Link", zap.String("source", m.receiverConfig.queue))
-	m.receiver, err = m.session.NewReceiver(
+	linkOpts := []amqp.LinkOption{
 		amqp.LinkSourceAddress(m.receiverConfig.queue),
 		amqp.LinkCredit(m.receiverConfig.maxUnacked),
 		amqp.LinkName(telemetryLinkName),
-	)
+	}
+	if m.receiverConfig.selector != "" {
+		linkOpts = append(linkOpts, amqp.LinkSelectorFilter(m.receiverConfig.selector))
+	}
+	if m.receiverConfig.maxMessageSize > 0 {
+		linkOpts = append(linkOpts, amqp.LinkMaxMessageSize(m.receiverConfig.maxMessageSize))
+	}
+	m.receiver, err = m.session.NewReceiver(linkOpts...)
 	if err != nil {
 		m.logger.Debug("Create AMQP Receiver Link failure", zap.Error(err))
 		return err
 	}
+	// the broker's offered capabilities and version are part of its open performative, which is not
+	// exposed by github.com/Azure/go-amqp, so only the settings requested by the receiver can be logged
+	m.logger.Info("Connected to broker",
+		zap.String("addr", m.connectConfig.addr),
+		zap.String("auth_scheme", m.scheme),
+		zap.String("queue", m.receiverConfig.queue),
+		zap.Bool("partitioned", m.receiverConfig.partitioned),
+		zap.Uint32("max_unacked", m.receiverConfig.maxUnacked),
+		zap.Duration("idle_timeout", m.idleTimeout),
+	)
 	return nil
 }
 
+// dialScheme dials the broker authenticating with the given scheme
+func (m *amqpMessagingService) dialScheme(scheme saslScheme) (*amqp.Client, error) {
+	var opts []amqp.ConnOption
+	if scheme.option != nil {
+		opts = append(opts, scheme.option)
+	}
+	if m.connectConfig.tlsConfig != nil {
+		opts = append(opts, m.connectConfig.tlsConfig)
+	}
+	if m.idleTimeout > 0 {
+		opts = append(opts, amqp.ConnIdleTimeout(m.idleTimeout))
+	}
+	m.logger.Debug("Dialing AMQP", zap.String("addr", m.connectConfig.addr), zap.String("scheme", scheme.name))
+	return dialFunc(m.connectConfig.addr, opts...)
+}
+
+// isAuthFailure returns true if the broker did not accept the SASL authentication. github.com/Azure/go-amqp
+// does not expose typed errors of the SASL negotiation, so its error messages are matched.
+func isAuthFailure(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "auth failed") || strings.Contains(msg, "no supported auth mechanism")
+}
+
 func (m *amqpMessagingService) close(ctx context.Context) {
 	if m.receiver != nil {
 		m.logger.Debug("Closing AMQP Receiver")
@@ -172,7 +265,13 @@ func (m *amqpMessagingService) close(ctx context.Context) {
 }
 
 func (m *amqpMessagingService) receiveMessage(ctx context.Context) (*inboundMessage, error) {
-	return m.receiver.Receive(ctx)
+	msg, err := m.receiver.Receive(ctx)
+	// a network timeout without the context being done indicates that the broker stopped sending heartbeats
+	var netErr net.Error
+	if err != nil && ctx.Err() == nil && errors.As(err, &netErr) && netErr.Timeout() {
+		return nil, fmt.Errorf("%w: %v", errHeartbeatTimeout, err)
+	}
+	return msg, err
 }
 
 func (m *amqpMessagingService) accept(ctx context.Context, msg *inboundMessage) error {
@@ -183,6 +282,10 @@ func (m *amqpMessagingService) failed(ctx context.Context, msg *inboundMessage)
 	return m.receiver.ModifyMessage(ctx, msg, true, false, nil)
 }
 
+func (m *amqpMessagingService) reject(ctx context.Context, msg *inboundMessage) error {
+	return m.receiver.RejectMessage(ctx, msg, nil)
+}
+
 // Allow for substitution in testing to assert correct data is passed to AMQP
 // Due to the way that AMQP authentication is configured in Azure/amqp, we
 // need to monkey substitute here since ConnSASL<auth> returns a function that
@@ -193,24 +296,35 @@ var (
 	connSASLExternal func(resp string) amqp.ConnOption                                          = amqp.ConnSASLExternal
 )
 
-// toAMQPAuthentication configures authentication in amqp.ConnOption slice
-func toAMQPAuthentication(config *Config) (amqp.ConnOption, error) {
-	if config.Auth.PlainText != nil {
-		plaintext := config.Auth.PlainText
-		if plaintext.Password == "" || plaintext.Username == "" {
-			return nil, errMissingPlainTextParams
-		}
-		return connSASLPlain(plaintext.Username, plaintext.Password), nil
-	}
-	if config.Auth.XAuth2 != nil {
-		xauth := config.Auth.XAuth2
-		if xauth.Bearer == "" || xauth.Username == "" {
-			return nil, errMissingXauth2Params
+// saslScheme is an authentication scheme of the connection to the broker
+type saslScheme struct {
+	name   string
+	option amqp.ConnOption
+}
+
+// toAMQPAuthentication configures the authentication schemes in the order they are attempted
+func toAMQPAuthentication(config *Config) ([]saslScheme, error) {
+	var schemes []saslScheme
+	for _, name := range config.Auth.schemeOrder() {
+		switch name {
+		case authSchemePlain:
+			plaintext := config.Auth.PlainText
+			if plaintext.Password == "" || plaintext.Username == "" {
+				return nil, errMissingPlainTextParams
+			}
+			schemes = append(schemes, saslScheme{name: name, option: connSASLPlain(plaintext.Username, plaintext.Password)})
+		case authSchemeXAuth2:
+			xauth := config.Auth.XAuth2
+			if xauth.Bearer == "" || xauth.Username == "" {
+				return nil, errMissingXauth2Params
+			}
+			schemes = append(schemes, saslScheme{name: name, option: connSASLXOAUTH2(xauth.Username, xauth.Bearer, saslMaxInitFrameSizeOverride)})
+		case authSchemeExternal:
+			schemes = append(schemes, saslScheme{name: name, option: connSASLExternal("")})
 		}
-		return connSASLXOAUTH2(xauth.Username, xauth.Bearer, saslMaxInitFrameSizeOverride), nil
 	}
-	if config.Auth.External != nil {
-		return connSASLExternal(""), nil
+	if len(schemes) == 0 {
+		return nil, errMissingAuthDetails
 	}
-	return nil, errMissingAuthDetails
+	return schemes, nil
 }
@@ -19,18 +19,23 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"math/rand"
 	"net"
+	"os"
 	"reflect"
 	"runtime"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/Azure/go-amqp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/configtls"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 const (
@@ -122,18 +127,22 @@ func TestNewAMQPMessagingServiceFactory(t *testing.T) {
 		{
 			name: "expecting success with TLS expecting an amqps connection",
 			cfg: &Config{ // invalid to only provide a key file
-				ReceiverSettings: receiverSettings,
-				Auth:             Authentication{PlainText: &SaslPlainTextConfig{Username: "user", Password: "password"}},
-				TLS:              configtls.TLSClientSetting{Insecure: false},
-				Broker:           []string{broker},
-				Queue:            queue,
-				MaxUnacked:       maxUnacked,
+				ReceiverSettings:  receiverSettings,
+				Auth:              Authentication{PlainText: &SaslPlainTextConfig{Username: "user", Password: "password"}},
+				TLS:               configtls.TLSClientSetting{Insecure: false},
+				Broker:            []string{broker},
+				Queue:             queue,
+				MaxUnacked:        maxUnacked,
+				HeartbeatInterval: 10 * time.Second,
+				HeartbeatJitter:   0.1,
 			},
 			want: &amqpMessagingService{
 				connectConfig: &amqpConnectConfig{
-					addr:       "amqps://" + broker,
-					saslConfig: amqp.ConnSASLPlain("user", "password"),
-					tlsConfig:  amqp.ConnTLSConfig(&tls.Config{}),
+					addr:            "amqps://" + broker,
+					saslSchemes:     []saslScheme{{name: authSchemePlain, option: amqp.ConnSASLPlain("user", "password")}},
+					tlsConfig:       amqp.ConnTLSConfig(&tls.Config{}),
+					idleTimeout:     20 * time.Second,
+					heartbeatJitter: 0.1,
 				},
 				receiverConfig: &amqpReceiverConfig{
 					queue:      queue,
@@ -152,16 +161,18 @@ func TestNewAMQPMessagingServiceFactory(t *testing.T) {
 				Broker:           []string{broker},
 				Queue:            queue,
 				MaxUnacked:       maxUnacked,
+				MaxMessageSize:   1048576,
 			},
 			want: &amqpMessagingService{
 				connectConfig: &amqpConnectConfig{
-					addr:       "amqp://" + broker,
-					saslConfig: amqp.ConnSASLPlain("user", "password"),
-					tlsConfig:  nil,
+					addr:        "amqp://" + broker,
+					saslSchemes: []saslScheme{{name: authSchemePlain, option: amqp.ConnSASLPlain("user", "password")}},
+					tlsConfig:   nil,
 				},
 				receiverConfig: &amqpReceiverConfig{
-					queue:      queue,
-					maxUnacked: maxUnacked,
+					queue:          queue,
+					maxUnacked:     maxUnacked,
+					maxMessageSize: 1048576,
 				},
 				logger: logger,
 			},
@@ -175,14 +186,14 @@ func TestNewAMQPMessagingServiceFactory(t *testing.T) {
 					return tt.want.connectConfig.tlsConfig
 				}
 			}
-			if tt.want != nil && tt.want.connectConfig.saslConfig != nil {
+			if tt.want != nil && len(tt.want.connectConfig.saslSchemes) > 0 {
 				connSASLPlain = func(username, password string) amqp.ConnOption {
 					connSASLPlain = amqp.ConnSASLPlain
-					return tt.want.connectConfig.saslConfig
+					return tt.want.connectConfig.saslSchemes[0].option
 				}
 			}
 
-			factory, err := newAMQPMessagingServiceFactory(tt.cfg, logger)
+			factory, err := newAMQPMessagingServiceFactory(tt.cfg, broker, logger)
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Nil(t, factory)
@@ -191,7 +202,13 @@ func TestNewAMQPMessagingServiceFactory(t *testing.T) {
 				actual := factory().(*amqpMessagingService)
 				// assert that want == actual, checking individual fields (due to function pointers can't use deep equal)
 				assert.Equal(t, tt.want.connectConfig.addr, actual.connectConfig.addr)
-				testFunctionEquality(t, tt.want.connectConfig.saslConfig, actual.connectConfig.saslConfig)
+				assert.Equal(t, tt.want.connectConfig.idleTimeout, actual.connectConfig.idleTimeout)
+				assert.Equal(t, tt.want.connectConfig.heartbeatJitter, actual.connectConfig.heartbeatJitter)
+				require.Len(t, actual.connectConfig.saslSchemes, len(tt.want.connectConfig.saslSchemes))
+				for i, scheme := range tt.want.connectConfig.saslSchemes {
+					assert.Equal(t, scheme.name, actual.connectConfig.saslSchemes[i].name)
+					testFunctionEquality(t, scheme.option, actual.connectConfig.saslSchemes[i].option)
+				}
 				testFunctionEquality(t, tt.want.connectConfig.tlsConfig, actual.connectConfig.tlsConfig)
 				assert.Equal(t, tt.want.receiverConfig, actual.receiverConfig)
 				assert.Equal(t, tt.want.logger, actual.logger)
@@ -210,9 +227,9 @@ func TestAMQPDialFailure(t *testing.T) {
 	}
 	service := &amqpMessagingService{
 		connectConfig: &amqpConnectConfig{
-			addr:       expectedAddr,
-			saslConfig: nil,
-			tlsConfig:  nil,
+			addr:        expectedAddr,
+			saslSchemes: nil,
+			tlsConfig:   nil,
 		},
 		receiverConfig: &amqpReceiverConfig{
 			queue:      "q",
@@ -239,9 +256,9 @@ func TestAMQPDialConfigOptionsWithoutTLS(t *testing.T) {
 	}
 	service := &amqpMessagingService{
 		connectConfig: &amqpConnectConfig{
-			addr:       expectedAddr,
-			saslConfig: expectedAuthConnOption,
-			tlsConfig:  nil,
+			addr:        expectedAddr,
+			saslSchemes: []saslScheme{{name: authSchemePlain, option: expectedAuthConnOption}},
+			tlsConfig:   nil,
 		},
 		receiverConfig: &amqpReceiverConfig{
 			queue:      "q",
@@ -272,9 +289,9 @@ func TestAMQPDialConfigOptionsWithTLS(t *testing.T) {
 	}
 	service := &amqpMessagingService{
 		connectConfig: &amqpConnectConfig{
-			addr:       expectedAddr,
-			saslConfig: expectedAuthConnOption,
-			tlsConfig:  expectedTLSConnOption,
+			addr:        expectedAddr,
+			saslSchemes: []saslScheme{{name: authSchemePlain, option: expectedAuthConnOption}},
+			tlsConfig:   expectedTLSConnOption,
 		},
 		receiverConfig: &amqpReceiverConfig{
 			queue:      "q",
@@ -291,6 +308,167 @@ func TestAMQPNewClientDialAndCloseSuccess(t *testing.T) {
 	closeMockedAMQPService(t, service, conn)
 }
 
+func TestAMQPNewClientDialLogsConnection(t *testing.T) {
+	conn := &connMock{
+		nextData: make(chan []byte, 100),
+	}
+	mockDialFunc(conn)
+	flowStartCalled := make(chan struct{})
+	mockWriteData(conn, [][]byte{[]byte(amqpProtocolHeaderResponse), []byte(amqpOpenResponse), []byte(amqpSessionBeginResponse), []byte(amqpAttachResponse)},
+		func(sentData, receivedData []byte) {
+			if len(sentData) > 10 && sentData[10] == 19 { // check if the type is 19 (flow)
+				close(flowStartCalled)
+			}
+		})
+
+	core, observedLogs := observer.New(zap.InfoLevel)
+	service := &amqpMessagingService{
+		connectConfig:  &amqpConnectConfig{addr: "some-addr", idleTimeout: 20 * time.Second},
+		receiverConfig: &amqpReceiverConfig{queue: "q", maxUnacked: 10000, partitioned: true},
+		logger:         zap.New(core),
+	}
+	assert.NoError(t, service.dial())
+	assertChannelClosed(t, flowStartCalled)
+
+	logs := observedLogs.FilterMessage("Connected to broker").All()
+	assert.Len(t, logs, 1)
+	assert.Equal(t, map[string]interface{}{
+		"addr":         "some-addr",
+		"auth_scheme":  "",
+		"queue":        "q",
+		"partitioned":  true,
+		"max_unacked":  uint32(10000),
+		"idle_timeout": 20 * time.Second,
+	}, logs[0].ContextMap())
+
+	closeMockedAMQPService(t, service, conn)
+}
+
+func TestAMQPNewClientDialSchemeOrder(t *testing.T) {
+	conn := &connMock{
+		nextData: make(chan []byte, 100),
+	}
+	xauth2Option := amqp.ConnSASLXOAUTH2("user", "bearer", saslMaxInitFrameSizeOverride)
+	plainOption := amqp.ConnSASLPlain("user", "password")
+	var attempted []amqp.ConnOption
+	dialFunc = func(addr string, opts ...amqp.ConnOption) (*amqp.Client, error) {
+		assert.Len(t, opts, 1)
+		attempted = append(attempted, opts[0])
+		if len(attempted) == 1 {
+			return nil, fmt.Errorf("SASL XOAUTH2 auth failed with code 0x1: ")
+		}
+		defer func() { dialFunc = amqp.Dial }() // reset dialFunc
+		return amqp.New(conn)
+	}
+	flowStartCalled := make(chan struct{})
+	mockWriteData(conn, [][]byte{[]byte(amqpProtocolHeaderResponse), []byte(amqpOpenResponse), []byte(amqpSessionBeginResponse), []byte(amqpAttachResponse)},
+		func(sentData, receivedData []byte) {
+			if len(sentData) > 10 && sentData[10] == 19 { // check if the type is 19 (flow)
+				close(flowStartCalled)
+			}
+		})
+
+	core, observedLogs := observer.New(zap.InfoLevel)
+	service := &amqpMessagingService{
+		connectConfig: &amqpConnectConfig{
+			addr: "some-addr",
+			saslSchemes: []saslScheme{
+				{name: authSchemeXAuth2, option: xauth2Option},
+				{name: authSchemePlain, option: plainOption},
+			},
+		},
+		receiverConfig: &amqpReceiverConfig{queue: "q", maxUnacked: 10000},
+		logger:         zap.New(core),
+	}
+	assert.NoError(t, service.dial())
+	assertChannelClosed(t, flowStartCalled)
+
+	require.Len(t, attempted, 2)
+	testFunctionEquality(t, xauth2Option, attempted[0])
+	testFunctionEquality(t, plainOption, attempted[1])
+	assert.Equal(t, authSchemePlain, service.scheme)
+	logs := observedLogs.FilterMessage("Connected to broker").All()
+	require.Len(t, logs, 1)
+	assert.Equal(t, authSchemePlain, logs[0].ContextMap()["auth_scheme"])
+
+	closeMockedAMQPService(t, service, conn)
+}
+
+func TestAMQPDialSchemeNonAuthFailure(t *testing.T) {
+	// only authentication failures fall through to the next scheme
+	var expectedErr = fmt.Errorf("connection refused")
+	calls := 0
+	dialFunc = func(addr string, opts ...amqp.ConnOption) (*amqp.Client, error) {
+		calls++
+		return nil, expectedErr
+	}
+	defer func() { dialFunc = amqp.Dial }() // reset dialFunc
+	service := &amqpMessagingService{
+		connectConfig: &amqpConnectConfig{
+			addr: "some-addr",
+			saslSchemes: []saslScheme{
+				{name: authSchemeXAuth2, option: amqp.ConnSASLXOAUTH2("user", "bearer", saslMaxInitFrameSizeOverride)},
+				{name: authSchemePlain, option: amqp.ConnSASLPlain("user", "password")},
+			},
+		},
+		receiverConfig: &amqpReceiverConfig{queue: "q", maxUnacked: 10000},
+		logger:         zap.NewNop(),
+	}
+	assert.Equal(t, expectedErr, service.dial())
+	assert.Equal(t, 1, calls)
+}
+
+func TestAMQPNewClientDialWithSelector(t *testing.T) {
+	const selector = "service_name = 'checkout'"
+	attach := dialAndCaptureAttach(t, &amqpReceiverConfig{queue: "q", maxUnacked: 10000, selector: selector})
+	assert.True(t, bytes.Contains(attach, []byte("apache.org:selector-filter:string")))
+	assert.True(t, bytes.Contains(attach, []byte(selector)))
+}
+
+func TestAMQPNewClientDialWithoutSelector(t *testing.T) {
+	attach := dialAndCaptureAttach(t, &amqpReceiverConfig{queue: "q", maxUnacked: 10000})
+	assert.False(t, bytes.Contains(attach, []byte("apache.org:selector-filter:string")))
+}
+
+func TestAMQPNewClientDialWithMaxMessageSize(t *testing.T) {
+	// max-message-size of the attach frame encoded as an AMQP ulong of 1048576
+	encodedSize := []byte{0x80, 0, 0, 0, 0, 0, 0x10, 0, 0}
+	attach := dialAndCaptureAttach(t, &amqpReceiverConfig{queue: "q", maxUnacked: 10000, maxMessageSize: 1048576})
+	assert.True(t, bytes.Contains(attach, encodedSize))
+	attach = dialAndCaptureAttach(t, &amqpReceiverConfig{queue: "q", maxUnacked: 10000})
+	assert.False(t, bytes.Contains(attach, encodedSize))
+}
+
+// dialAndCaptureAttach dials a mocked service with the given receiver config and returns the attach frame sent to the broker
+func dialAndCaptureAttach(t *testing.T, receiverConfig *amqpReceiverConfig) []byte {
+	conn := &connMock{
+		nextData: make(chan []byte, 100),
+	}
+	mockDialFunc(conn)
+	var attach []byte
+	flowStartCalled := make(chan struct{})
+	mockWriteData(conn, [][]byte{[]byte(amqpProtocolHeaderResponse), []byte(amqpOpenResponse), []byte(amqpSessionBeginResponse), []byte(amqpAttachResponse)},
+		func(sentData, receivedData []byte) {
+			if len(sentData) > 10 && sentData[10] == 18 { // check if the type is 18 (attach)
+				attach = append([]byte{}, sentData...)
+			}
+			if len(sentData) > 10 && sentData[10] == 19 { // check if the type is 19 (flow)
+				close(flowStartCalled)
+			}
+		})
+
+	service := &amqpMessagingService{
+		connectConfig:  &amqpConnectConfig{addr: "some-addr"},
+		receiverConfig: receiverConfig,
+		logger:         zap.NewNop(),
+	}
+	assert.NoError(t, service.dial())
+	assertChannelClosed(t, flowStartCalled)
+	closeMockedAMQPService(t, service, conn)
+	assert.NotNil(t, attach)
+	return attach
+}
+
 // validate that we still proceed with close if a context is cancelled and no receiver/session close messages are sent
 func TestAMQPNewClientDialAndCloseCtxTimeoutFailure(t *testing.T) {
 	service, conn := startMockedService(t)
@@ -396,6 +574,127 @@ func TestAMQPModifyMessage(t *testing.T) {
 	closeMockedAMQPService(t, service, conn)
 }
 
+func TestAMQPRejectMessage(t *testing.T) {
+	service, conn := startMockedService(t)
+	conn.nextData <- []byte(amqpHelloWorldMsg)
+	msg, err := service.receiveMessage(context.Background())
+	assert.NoError(t, err)
+	writeCalled := make(chan struct{})
+	conn.writeHandle = func(b []byte) (n int, err error) {
+		// assert that a disposition is written
+		assert.Equal(t, byte(0x15), b[10])
+		assert.Equal(t, byte(0x25), b[26]) // 0x25 at the 27th byte in this case means reject
+		close(writeCalled)
+		return len(b), nil
+	}
+	err = service.reject(context.Background(), msg)
+	assert.NoError(t, err)
+	assertChannelClosed(t, writeCalled)
+	closeMockedAMQPService(t, service, conn)
+}
+
+func TestAMQPReceiveMessageHeartbeatTimeout(t *testing.T) {
+	const idleTimeout = 100 * time.Millisecond
+	conn := &connMock{
+		nextData: make(chan []byte, 100),
+	}
+	dialFunc = func(addr string, opts ...amqp.ConnOption) (*amqp.Client, error) {
+		defer func() { dialFunc = amqp.Dial }() // reset dialFunc
+		// the mocked broker does not support sasl, only pass through the idle timeout
+		var connOpts []amqp.ConnOption
+		for _, opt := range opts {
+			if opt != nil {
+				connOpts = append(connOpts, opt)
+			}
+		}
+		assert.Len(t, connOpts, 1)
+		return amqp.New(conn, connOpts...)
+	}
+	writeData := [][]byte{[]byte(amqpProtocolHeaderResponse), []byte(amqpOpenResponse), []byte(amqpSessionBeginResponse), []byte(amqpAttachResponse)}
+	mockWriteData(conn, writeData)
+
+	service := &amqpMessagingService{
+		connectConfig:  &amqpConnectConfig{addr: "some-addr", idleTimeout: idleTimeout},
+		receiverConfig: &amqpReceiverConfig{queue: "q", maxUnacked: 10000},
+		logger:         zap.NewNop(),
+	}
+	assert.NoError(t, service.dial())
+
+	// the broker stops responding, expect the dead connection to be detected within the idle timeout
+	start := time.Now()
+	msg, err := service.receiveMessage(context.Background())
+	assert.Nil(t, msg)
+	assert.ErrorIs(t, err, errHeartbeatTimeout)
+	assert.Less(t, time.Since(start), 10*idleTimeout)
+	// the connection is already torn down, close should not block
+	service.close(context.Background())
+}
+
+func TestJitteredIdleTimeout(t *testing.T) {
+	defer func() { jitterFunc = rand.Float64 }()
+	config := &amqpConnectConfig{idleTimeout: 20 * time.Second}
+	jitterFunc = func() float64 { return 0.5 }
+	assert.Equal(t, 20*time.Second, config.jitteredIdleTimeout())
+
+	config.heartbeatJitter = 0.2
+	for _, tc := range []struct {
+		random   float64
+		expected time.Duration
+	}{
+		{random: 0, expected: 20 * time.Second},
+		{random: 0.5, expected: 18 * time.Second},
+		{random: 0.99, expected: 16040 * time.Millisecond},
+	} {
+		jitterFunc = func() float64 { return tc.random }
+		assert.Equal(t, tc.expected, config.jitteredIdleTimeout())
+	}
+
+	// every connection is given an idle timeout of its own within the bounds of the jitter
+	jitterFunc = rand.Float64
+	for i := 0; i < 100; i++ {
+		timeout := config.jitteredIdleTimeout()
+		assert.LessOrEqual(t, timeout, 20*time.Second)
+		assert.Greater(t, timeout, 16*time.Second)
+	}
+}
+
+func TestAMQPReceiveMessageHeartbeatTimeoutWithJitter(t *testing.T) {
+	defer func() { jitterFunc = rand.Float64 }()
+	jitterFunc = func() float64 { return 0.5 }
+	const idleTimeout = time.Second
+	conn := &connMock{
+		nextData: make(chan []byte, 100),
+	}
+	dialFunc = func(addr string, opts ...amqp.ConnOption) (*amqp.Client, error) {
+		defer func() { dialFunc = amqp.Dial }() // reset dialFunc
+		var connOpts []amqp.ConnOption
+		for _, opt := range opts {
+			if opt != nil {
+				connOpts = append(connOpts, opt)
+			}
+		}
+		return amqp.New(conn, connOpts...)
+	}
+	writeData := [][]byte{[]byte(amqpProtocolHeaderResponse), []byte(amqpOpenResponse), []byte(amqpSessionBeginResponse), []byte(amqpAttachResponse)}
+	mockWriteData(conn, writeData)
+
+	service := &amqpMessagingService{
+		connectConfig:  &amqpConnectConfig{addr: "some-addr", idleTimeout: idleTimeout, heartbeatJitter: 0.9},
+		receiverConfig: &amqpReceiverConfig{queue: "q", maxUnacked: 10000},
+		logger:         zap.NewNop(),
+	}
+	assert.NoError(t, service.dial())
+	assert.Equal(t, 550*time.Millisecond, service.idleTimeout)
+
+	// the broker stops responding, expect the dead connection to be detected within the jittered idle timeout
+	start := time.Now()
+	msg, err := service.receiveMessage(context.Background())
+	assert.Nil(t, msg)
+	assert.ErrorIs(t, err, errHeartbeatTimeout)
+	assert.Less(t, time.Since(start), idleTimeout)
+	service.close(context.Background())
+}
+
 func startMockedService(t *testing.T) (*amqpMessagingService, *connMock) {
 	conn := &connMock{
 		nextData: make(chan []byte, 100),
@@ -642,6 +941,46 @@ func TestConfigAMQPAuthenticationExternal(t *testing.T) {
 	assert.True(t, called)
 }
 
+func TestConfigAMQPAuthenticationSchemeOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		order []string
+		want  []string
+	}{
+		{
+			name: "default order",
+			want: []string{authSchemePlain, authSchemeXAuth2, authSchemeExternal},
+		},
+		{
+			name:  "configured order",
+			order: []string{authSchemeExternal, authSchemeXAuth2, authSchemePlain},
+			want:  []string{authSchemeExternal, authSchemeXAuth2, authSchemePlain},
+		},
+		{
+			name:  "unlisted schemes attempted last",
+			order: []string{authSchemeExternal},
+			want:  []string{authSchemeExternal, authSchemePlain, authSchemeXAuth2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Auth.PlainText = &SaslPlainTextConfig{Username: "user", Password: "password"}
+			cfg.Auth.XAuth2 = &SaslXAuth2Config{Username: "user", Bearer: "bearer"}
+			cfg.Auth.External = &SaslExternalConfig{}
+			cfg.Auth.SchemeOrder = tt.order
+			result, err := toAMQPAuthentication(cfg)
+			assert.NoError(t, err)
+			var names []string
+			for _, scheme := range result {
+				names = append(names, scheme.name)
+				assert.NotNil(t, scheme.option)
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
 func TestConfigAMQPAuthenticationNoDetails(t *testing.T) {
 	cfg := createDefaultConfig().(*Config)
 	result, err := toAMQPAuthentication(cfg)
@@ -657,11 +996,29 @@ type connMock struct {
 	writeHandle func([]byte) (n int, err error)
 	closeHandle func() error
 	remaining   *bytes.Reader
+
+	deadlineLock sync.Mutex
+	readDeadline time.Time
 }
 
 func (c *connMock) Read(b []byte) (n int, err error) {
 	if c.remaining == nil {
-		d := <-c.nextData
+		// honor the read deadline in order to simulate a broker that stopped responding
+		var timeout <-chan time.Time
+		c.deadlineLock.Lock()
+		deadline := c.readDeadline
+		c.deadlineLock.Unlock()
+		if !deadline.IsZero() {
+			timer := time.NewTimer(time.Until(deadline))
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		var d []byte
+		select {
+		case d = <-c.nextData:
+		case <-timeout:
+			return 0, os.ErrDeadlineExceeded
+		}
 		// the way this test fixture is designed, there is a race condition
 		// between write and read where data may be written to nextData on
 		// a call to Write and may be propogated prior to the return of Write.
@@ -699,6 +1056,9 @@ func (c *connMock) SetDeadline(t time.Time) error {
 	return nil
 }
 func (c *connMock) SetReadDeadline(t time.Time) error {
+	c.deadlineLock.Lock()
+	defer c.deadlineLock.Unlock()
+	c.readDeadline = t
 	return nil
 }
 func (c *connMock) SetWriteDeadline(t time.Time) error {
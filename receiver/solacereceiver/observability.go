@@ -16,9 +16,19 @@ package solacereceiver // import "github.com/open-telemetry/opentelemetry-collec
 
 import (
 	"context"
+	"sync"
 
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/featuregate"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/metric/instrument/syncint64"
+	"go.opentelemetry.io/otel/metric/unit"
+	"go.uber.org/atomic"
 )
 
 const (
@@ -27,8 +37,16 @@ const (
 	// metricPrefix used to prefix solace specific metrics
 	metricPrefix = "solacereceiver"
 	nameSep      = "/"
+	// meterName is the name of the meter of the internal telemetry recorded with OpenTelemetry
+	meterName = "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/solacereceiver"
+	// useOtelForInternalMetricsGateID is the collector feature gate that switches the internal telemetry
+	// of the collector from OpenCensus to OpenTelemetry
+	useOtelForInternalMetricsGateID = "telemetry.useOtelForInternalMetrics"
 )
 
+// queueKey tags the metrics of the messages received from a queue with the name of the queue
+var queueKey = tag.MustNewKey("queue")
+
 type receiverState uint8
 
 const (
@@ -40,32 +58,80 @@ const (
 	receiverStateTerminated
 )
 
+// brokerRole is the role of a broker in the disaster recovery pair of brokers
+type brokerRole uint8
+
+const (
+	brokerPrimary brokerRole = iota
+	brokerSecondary
+)
+
 type opencensusMetrics struct {
 	stats struct {
 		failedReconnections            *stats.Int64Measure
 		recoverableUnmarshallingErrors *stats.Int64Measure
 		fatalUnmarshallingErrors       *stats.Int64Measure
+		failedDecompressions           *stats.Int64Measure
+		oversizedMessages              *stats.Int64Measure
+		exceededDeliveries             *stats.Int64Measure
+		expiredMessages                *stats.Int64Measure
 		droppedSpanMessages            *stats.Int64Measure
 		receivedSpanMessages           *stats.Int64Measure
 		reportedSpans                  *stats.Int64Measure
 		receiverStatus                 *stats.Int64Measure
 		needUpgrade                    *stats.Int64Measure
+		unackedMessages                *stats.Int64Measure
+		activeBroker                   *stats.Int64Measure
 	}
 	views struct {
 		failedReconnections            *view.View
 		recoverableUnmarshallingErrors *view.View
 		fatalUnmarshallingErrors       *view.View
+		failedDecompressions           *view.View
+		oversizedMessages              *view.View
+		exceededDeliveries             *view.View
+		expiredMessages                *view.View
 		droppedSpanMessages            *view.View
 		receivedSpanMessages           *view.View
 		reportedSpans                  *view.View
 		receiverStatus                 *view.View
 		needUpgrade                    *view.View
+		unackedMessages                *view.View
+		activeBroker                   *view.View
 	}
+	// unacked is the number of messages that have been received but not yet settled. Each message is
+	// settled before the next one is received, so with a single connection it is either 0 or 1.
+	unacked *atomic.Int64
+	// otel records the measures with the instruments of the collector meter instead of the views, nil if the views are used
+	otel *otelMetrics
+	// resetLock serializes the resets of the views, so that a view is not registered while another reset unregisters it,
+	// and the resets with the recording of the unacked messages, so that a reset does not record a stale number of them
+	resetLock sync.Mutex
+}
+
+// otelMetrics records the measures of opencensusMetrics with OpenTelemetry instruments of the same name,
+// description and aggregation as their views.
+type otelMetrics struct {
+	counters map[*stats.Int64Measure]syncint64.Counter
+
+	mu sync.Mutex
+	// lastValues holds the last value recorded of every measure aggregated as last value, which is
+	// reported by an asynchronous gauge once the measure has been recorded
+	lastValues map[*stats.Int64Measure]int64
 }
 
-// receiver will register internal telemetry views
-func newOpenCensusMetrics(instanceName string) (*opencensusMetrics, error) {
-	m := &opencensusMetrics{}
+// internalMetricsMeter returns the meter of the collector the internal telemetry is recorded with if the collector
+// records its own internal telemetry with OpenTelemetry, or nil if the internal telemetry is recorded with OpenCensus.
+func internalMetricsMeter(settings component.TelemetrySettings) metric.Meter {
+	if settings.MeterProvider == nil || !featuregate.GetRegistry().IsEnabled(useOtelForInternalMetricsGateID) {
+		return nil
+	}
+	return settings.MeterProvider.Meter(meterName)
+}
+
+// receiver will register internal telemetry views, or the instruments of the given meter if not nil
+func newOpenCensusMetrics(instanceName string, meter metric.Meter) (*opencensusMetrics, error) {
+	m := &opencensusMetrics{unacked: atomic.NewInt64(0)}
 	prefix := metricPrefix + nameSep
 	if instanceName != "" {
 		prefix += instanceName + nameSep
@@ -74,43 +140,109 @@ func newOpenCensusMetrics(instanceName string) (*opencensusMetrics, error) {
 	m.stats.failedReconnections = stats.Int64(prefix+"failed_reconnections", "Number of failed broker reconnections", stats.UnitDimensionless)
 	m.stats.recoverableUnmarshallingErrors = stats.Int64(prefix+"recoverable_unmarshalling_errors", "Number of recoverable message unmarshalling errors", stats.UnitDimensionless)
 	m.stats.fatalUnmarshallingErrors = stats.Int64(prefix+"fatal_unmarshalling_errors", "Number of fatal message unmarshalling errors", stats.UnitDimensionless)
+	m.stats.failedDecompressions = stats.Int64(prefix+"failed_decompressions", "Number of message payloads that failed to decompress", stats.UnitDimensionless)
+	m.stats.oversizedMessages = stats.Int64(prefix+"oversized_messages", "Number of messages rejected for exceeding the maximum message size", stats.UnitDimensionless)
+	m.stats.exceededDeliveries = stats.Int64(prefix+"exceeded_deliveries", "Number of redelivered messages rejected for exceeding the maximum delivery count", stats.UnitDimensionless)
+	m.stats.expiredMessages = stats.Int64(prefix+"expired_messages", "Number of messages dropped for exceeding the maximum message age", stats.UnitDimensionless)
 	m.stats.droppedSpanMessages = stats.Int64(prefix+"dropped_span_messages", "Number of dropped span messages", stats.UnitDimensionless)
 	m.stats.receivedSpanMessages = stats.Int64(prefix+"received_span_messages", "Number of received span messages", stats.UnitDimensionless)
 	m.stats.reportedSpans = stats.Int64(prefix+"reported_spans", "Number of reported spans", stats.UnitDimensionless)
 	m.stats.receiverStatus = stats.Int64(prefix+"receiver_status", "Indicates the status of the receiver as an enum. 0 = starting, 1 = connecting, 2 = connected, 3 = disabled (often paired with needs_upgrade), 4 = terminating, 5 = terminated", stats.UnitDimensionless)
 	m.stats.needUpgrade = stats.Int64(prefix+"need_upgrade", "Indicates with value 1 that receiver requires an upgrade and is not compatible with messages received from a broker", stats.UnitDimensionless)
 
+	m.stats.unackedMessages = stats.Int64(prefix+"unacked_messages", "Number of messages that have been received but not yet acknowledged", stats.UnitDimensionless)
+	m.stats.activeBroker = stats.Int64(prefix+"active_broker", "Indicates the broker the receiver is connected to as an enum. 0 = primary, 1 = secondary", stats.UnitDimensionless)
+
 	m.views.failedReconnections = fromMeasure(m.stats.failedReconnections, view.Count())
 	m.views.recoverableUnmarshallingErrors = fromMeasure(m.stats.recoverableUnmarshallingErrors, view.Count())
 	m.views.fatalUnmarshallingErrors = fromMeasure(m.stats.fatalUnmarshallingErrors, view.Count())
-	m.views.droppedSpanMessages = fromMeasure(m.stats.droppedSpanMessages, view.Count())
-	m.views.receivedSpanMessages = fromMeasure(m.stats.receivedSpanMessages, view.Count())
-	m.views.reportedSpans = fromMeasure(m.stats.reportedSpans, view.Sum())
+	m.views.failedDecompressions = fromMeasure(m.stats.failedDecompressions, view.Count())
+	m.views.oversizedMessages = fromMeasure(m.stats.oversizedMessages, view.Count())
+	m.views.exceededDeliveries = fromMeasure(m.stats.exceededDeliveries, view.Count())
+	m.views.expiredMessages = fromMeasure(m.stats.expiredMessages, view.Count())
+	m.views.droppedSpanMessages = fromMeasure(m.stats.droppedSpanMessages, view.Count(), queueKey)
+	m.views.receivedSpanMessages = fromMeasure(m.stats.receivedSpanMessages, view.Count(), queueKey)
+	m.views.reportedSpans = fromMeasure(m.stats.reportedSpans, view.Sum(), queueKey)
 	m.views.receiverStatus = fromMeasure(m.stats.receiverStatus, view.LastValue())
 	m.views.needUpgrade = fromMeasure(m.stats.needUpgrade, view.LastValue())
+	m.views.unackedMessages = fromMeasure(m.stats.unackedMessages, view.LastValue())
+	m.views.activeBroker = fromMeasure(m.stats.activeBroker, view.LastValue())
 
-	err := view.Register(
+	if meter != nil {
+		otel, err := newOtelMetrics(meter, m.counterViews(), m.lastValueViews())
+		if err != nil {
+			return nil, err
+		}
+		m.otel = otel
+		return m, nil
+	}
+
+	if err := view.Register(m.allViews()...); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// counterViews returns the views counting or summing their measure
+func (m *opencensusMetrics) counterViews() []*view.View {
+	return []*view.View{
 		m.views.failedReconnections,
 		m.views.recoverableUnmarshallingErrors,
 		m.views.fatalUnmarshallingErrors,
+		m.views.failedDecompressions,
+		m.views.oversizedMessages,
+		m.views.exceededDeliveries,
+		m.views.expiredMessages,
 		m.views.droppedSpanMessages,
 		m.views.receivedSpanMessages,
 		m.views.reportedSpans,
+	}
+}
+
+// lastValueViews returns the views keeping the last value of their measure
+func (m *opencensusMetrics) lastValueViews() []*view.View {
+	return []*view.View{
 		m.views.receiverStatus,
 		m.views.needUpgrade,
-	)
-	if err != nil {
-		return nil, err
+		m.views.unackedMessages,
+		m.views.activeBroker,
 	}
-	return m, nil
 }
 
-func fromMeasure(measure stats.Measure, agg *view.Aggregation) *view.View {
+func (m *opencensusMetrics) allViews() []*view.View {
+	return append(m.counterViews(), m.lastValueViews()...)
+}
+
+// ResetMetrics zeroes the value of every view, for example to rotate the metrics or between integration tests, as
+// OpenCensus has no other way to reset a view than to register it again. Values recorded while the views are reset may
+// be lost. The number of unacked messages is not zeroed, as the messages it counts are still to be settled, but recorded
+// again with its current value. The counters of the OpenTelemetry meter are cumulative and cannot be reset, only the
+// gauges are reset, they are not reported until their measure is recorded again.
+func (m *opencensusMetrics) ResetMetrics() error {
+	m.resetLock.Lock()
+	defer m.resetLock.Unlock()
+	if m.otel != nil {
+		m.otel.mu.Lock()
+		m.otel.lastValues = map[*stats.Int64Measure]int64{m.stats.unackedMessages: m.unacked.Load()}
+		m.otel.mu.Unlock()
+		return nil
+	}
+	views := m.allViews()
+	view.Unregister(views...)
+	if err := view.Register(views...); err != nil {
+		return err
+	}
+	stats.Record(context.Background(), m.stats.unackedMessages.M(m.unacked.Load()))
+	return nil
+}
+
+func fromMeasure(measure stats.Measure, agg *view.Aggregation, tagKeys ...tag.Key) *view.View {
 	return &view.View{
 		Name:        buildReceiverCustomMetricName(measure.Name()),
 		Description: measure.Description(),
 		Measure:     measure,
 		Aggregation: agg,
+		TagKeys:     tagKeys,
 	}
 }
 
@@ -118,42 +250,146 @@ func buildReceiverCustomMetricName(metric string) string {
 	return receiverKey + nameSep + string(componentType) + nameSep + metric
 }
 
+// newOtelMetrics creates a counter for every view of counters and an asynchronous gauge for every view of gauges
+func newOtelMetrics(meter metric.Meter, counters []*view.View, gauges []*view.View) (*otelMetrics, error) {
+	o := &otelMetrics{
+		counters:   map[*stats.Int64Measure]syncint64.Counter{},
+		lastValues: map[*stats.Int64Measure]int64{},
+	}
+	for _, v := range counters {
+		counter, err := meter.SyncInt64().Counter(v.Name, instrument.WithDescription(v.Description), instrument.WithUnit(unit.Dimensionless))
+		if err != nil {
+			return nil, err
+		}
+		o.counters[v.Measure.(*stats.Int64Measure)] = counter
+	}
+	for _, v := range gauges {
+		measure := v.Measure.(*stats.Int64Measure)
+		gauge, err := meter.AsyncInt64().Gauge(v.Name, instrument.WithDescription(v.Description), instrument.WithUnit(unit.Dimensionless))
+		if err != nil {
+			return nil, err
+		}
+		err = meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) {
+			o.mu.Lock()
+			value, ok := o.lastValues[measure]
+			o.mu.Unlock()
+			if ok {
+				gauge.Observe(ctx, value)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// record adds the value to the counter of the measure, or sets it as the last value of the measure if
+// the measure has no counter. The value is tagged with the queue if not empty.
+func (o *otelMetrics) record(measure *stats.Int64Measure, value int64, queue string) {
+	counter, ok := o.counters[measure]
+	if !ok {
+		o.mu.Lock()
+		o.lastValues[measure] = value
+		o.mu.Unlock()
+		return
+	}
+	if queue == "" {
+		counter.Add(context.Background(), value)
+		return
+	}
+	counter.Add(context.Background(), value, attribute.String(queueKey.Name(), queue))
+}
+
+// record records the value of the measure with the views, or with the instruments of the meter if
+// configured. The value is tagged with the queue if not empty.
+func (m *opencensusMetrics) record(measure *stats.Int64Measure, value int64, queue string) {
+	if m.otel != nil {
+		m.otel.record(measure, value, queue)
+		return
+	}
+	if queue == "" {
+		stats.Record(context.Background(), measure.M(value))
+		return
+	}
+	_ = stats.RecordWithTags(context.Background(), []tag.Mutator{tag.Upsert(queueKey, queue)}, measure.M(value))
+}
+
 // recordFailedReconnection increments the metric that records failed reconnection event.
 func (m *opencensusMetrics) recordFailedReconnection() {
-	stats.Record(context.Background(), m.stats.failedReconnections.M(1))
+	m.record(m.stats.failedReconnections, 1, "")
 }
 
 // recordRecoverableUnmarshallingError increments the metric that records a recoverable error by trace message unmarshalling.
 func (m *opencensusMetrics) recordRecoverableUnmarshallingError() {
-	stats.Record(context.Background(), m.stats.recoverableUnmarshallingErrors.M(1))
+	m.record(m.stats.recoverableUnmarshallingErrors, 1, "")
 }
 
 // recordFatalUnmarshallingError increments the metric that records a fatal arrow by trace message unmarshalling.
 func (m *opencensusMetrics) recordFatalUnmarshallingError() {
-	stats.Record(context.Background(), m.stats.fatalUnmarshallingErrors.M(1))
+	m.record(m.stats.fatalUnmarshallingErrors, 1, "")
 }
 
-// recordDroppedSpanMessages increments the metric that records a dropped span message
-func (m *opencensusMetrics) recordDroppedSpanMessages() {
-	stats.Record(context.Background(), m.stats.droppedSpanMessages.M(1))
+// recordFailedDecompression increments the metric that records a message payload that failed to decompress.
+func (m *opencensusMetrics) recordFailedDecompression() {
+	m.record(m.stats.failedDecompressions, 1, "")
 }
 
-// recordReceivedSpanMessages increments the metric that records a received span message
-func (m *opencensusMetrics) recordReceivedSpanMessages() {
-	stats.Record(context.Background(), m.stats.receivedSpanMessages.M(1))
+// recordOversizedMessage increments the metric that records a message rejected for exceeding the maximum message size.
+func (m *opencensusMetrics) recordOversizedMessage() {
+	m.record(m.stats.oversizedMessages, 1, "")
 }
 
-// recordReportedSpans increments the metric that records the number of spans reported to the next consumer
-func (m *opencensusMetrics) recordReportedSpans() {
-	stats.Record(context.Background(), m.stats.reportedSpans.M(1))
+// recordExceededDeliveries increments the metric that records a redelivered message rejected for exceeding the maximum delivery count.
+func (m *opencensusMetrics) recordExceededDeliveries() {
+	m.record(m.stats.exceededDeliveries, 1, "")
+}
+
+// recordExpiredMessage increments the metric that records a message dropped for exceeding the maximum message age.
+func (m *opencensusMetrics) recordExpiredMessage() {
+	m.record(m.stats.expiredMessages, 1, "")
+}
+
+// recordDroppedSpanMessages increments the metric that records a dropped span message received from the given queue
+func (m *opencensusMetrics) recordDroppedSpanMessages(queue string) {
+	m.record(m.stats.droppedSpanMessages, 1, queue)
+}
+
+// recordReceivedSpanMessages increments the metric that records a span message received from the given queue
+func (m *opencensusMetrics) recordReceivedSpanMessages(queue string) {
+	m.record(m.stats.receivedSpanMessages, 1, queue)
+}
+
+// recordReportedSpans increments the metric that records the number of spans received from the given queue reported to the next consumer
+func (m *opencensusMetrics) recordReportedSpans(queue string) {
+	m.record(m.stats.reportedSpans, 1, queue)
 }
 
 // recordReceiverStatus sets the metric that records the current state of the receiver to the given state
 func (m *opencensusMetrics) recordReceiverStatus(status receiverState) {
-	stats.Record(context.Background(), m.stats.receiverStatus.M(int64(status)))
+	m.record(m.stats.receiverStatus, int64(status), "")
 }
 
 // RecordNeedRestart turns a need restart flag on
 func (m *opencensusMetrics) recordNeedUpgrade() {
-	stats.Record(context.Background(), m.stats.needUpgrade.M(1))
+	m.record(m.stats.needUpgrade, 1, "")
+}
+
+// recordUnackedMessage increments the metric that records the number of messages that have been received but not yet acknowledged
+func (m *opencensusMetrics) recordUnackedMessage() {
+	m.resetLock.Lock()
+	defer m.resetLock.Unlock()
+	m.record(m.stats.unackedMessages, m.unacked.Inc(), "")
+}
+
+// recordSettledMessage decrements the metric that records the number of messages that have been received but not yet acknowledged
+func (m *opencensusMetrics) recordSettledMessage() {
+	m.resetLock.Lock()
+	defer m.resetLock.Unlock()
+	m.record(m.stats.unackedMessages, m.unacked.Dec(), "")
+}
+
+// recordActiveBroker sets the metric that records the broker the receiver is connected to
+func (m *opencensusMetrics) recordActiveBroker(role brokerRole) {
+	m.record(m.stats.activeBroker, int64(role), "")
 }
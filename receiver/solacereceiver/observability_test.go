@@ -15,13 +15,19 @@
 package solacereceiver
 
 import (
+	"context"
 	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 )
 
 type metricsTestCase struct {
@@ -38,13 +44,28 @@ func TestRecordMetrics(t *testing.T) {
 		{metrics.recordFailedReconnection, metrics.views.failedReconnections, metrics.stats.failedReconnections, 3, 3},
 		{metrics.recordRecoverableUnmarshallingError, metrics.views.recoverableUnmarshallingErrors, metrics.stats.recoverableUnmarshallingErrors, 3, 3},
 		{metrics.recordFatalUnmarshallingError, metrics.views.fatalUnmarshallingErrors, metrics.stats.fatalUnmarshallingErrors, 3, 3},
-		{metrics.recordDroppedSpanMessages, metrics.views.droppedSpanMessages, metrics.stats.droppedSpanMessages, 3, 3},
-		{metrics.recordReceivedSpanMessages, metrics.views.receivedSpanMessages, metrics.stats.receivedSpanMessages, 3, 3},
-		{metrics.recordReportedSpans, metrics.views.reportedSpans, metrics.stats.reportedSpans, 3, 3},
+		{metrics.recordFailedDecompression, metrics.views.failedDecompressions, metrics.stats.failedDecompressions, 3, 3},
+		{metrics.recordOversizedMessage, metrics.views.oversizedMessages, metrics.stats.oversizedMessages, 3, 3},
+		{metrics.recordExceededDeliveries, metrics.views.exceededDeliveries, metrics.stats.exceededDeliveries, 3, 3},
+		{metrics.recordExpiredMessage, metrics.views.expiredMessages, metrics.stats.expiredMessages, 3, 3},
+		{func() {
+			metrics.recordDroppedSpanMessages(testQueue)
+		}, metrics.views.droppedSpanMessages, metrics.stats.droppedSpanMessages, 3, 3},
+		{func() {
+			metrics.recordReceivedSpanMessages(testQueue)
+		}, metrics.views.receivedSpanMessages, metrics.stats.receivedSpanMessages, 3, 3},
+		{func() {
+			metrics.recordReportedSpans(testQueue)
+		}, metrics.views.reportedSpans, metrics.stats.reportedSpans, 3, 3},
 		{func() {
 			metrics.recordReceiverStatus(receiverStateTerminated)
 		}, metrics.views.receiverStatus, metrics.stats.receiverStatus, 3, int(receiverStateTerminated)},
 		{metrics.recordNeedUpgrade, metrics.views.needUpgrade, metrics.stats.needUpgrade, 3, 1},
+		{metrics.recordUnackedMessage, metrics.views.unackedMessages, metrics.stats.unackedMessages, 3, 3},
+		{metrics.recordSettledMessage, metrics.views.unackedMessages, metrics.stats.unackedMessages, 2, 1},
+		{func() {
+			metrics.recordActiveBroker(brokerSecondary)
+		}, metrics.views.activeBroker, metrics.stats.activeBroker, 3, int(brokerSecondary)},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.m.Name(), func(t *testing.T) {
@@ -56,13 +77,242 @@ func TestRecordMetrics(t *testing.T) {
 	}
 }
 
+func TestRecordMetricsQueueTag(t *testing.T) {
+	metrics := newTestMetrics(t)
+	testCases := []struct {
+		name   string
+		record func(queue string)
+		v      *view.View
+	}{
+		{"dropped_span_messages", metrics.recordDroppedSpanMessages, metrics.views.droppedSpanMessages},
+		{"received_span_messages", metrics.recordReceivedSpanMessages, metrics.views.receivedSpanMessages},
+		{"reported_spans", metrics.recordReportedSpans, metrics.views.reportedSpans},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.record("queue://#telemetry-a")
+			tc.record("queue://#telemetry-a")
+			tc.record("queue://#telemetry-b")
+			assert.Equal(t, map[string]int64{"queue://#telemetry-a": 2, "queue://#telemetry-b": 1}, valuesByQueue(t, tc.v))
+		})
+	}
+}
+
+func TestRecordMetricsOpenTelemetry(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter(meterName)
+	openCensus := newTestMetrics(t)
+	openTelemetry, err := newOpenCensusMetrics(t.Name(), meter)
+	require.NoError(t, err)
+	require.NotNil(t, openTelemetry.otel)
+
+	for _, metrics := range []*opencensusMetrics{openCensus, openTelemetry} {
+		metrics.recordFailedReconnection()
+		metrics.recordRecoverableUnmarshallingError()
+		metrics.recordFatalUnmarshallingError()
+		metrics.recordFailedDecompression()
+		metrics.recordOversizedMessage()
+		metrics.recordExceededDeliveries()
+		metrics.recordExpiredMessage()
+		metrics.recordExpiredMessage()
+		metrics.recordDroppedSpanMessages("queue://#telemetry-a")
+		metrics.recordReceivedSpanMessages("queue://#telemetry-a")
+		metrics.recordReceivedSpanMessages("queue://#telemetry-b")
+		metrics.recordReportedSpans("queue://#telemetry-a")
+		metrics.recordReportedSpans("queue://#telemetry-a")
+		metrics.recordReceiverStatus(receiverStateConnecting)
+		metrics.recordReceiverStatus(receiverStateConnected)
+		metrics.recordUnackedMessage()
+		metrics.recordUnackedMessage()
+		metrics.recordSettledMessage()
+		metrics.recordActiveBroker(brokerSecondary)
+	}
+
+	// need_upgrade is never recorded, so neither of the paths reports it
+	expected := map[string]map[string]int64{}
+	for _, v := range openCensus.allViews() {
+		if values := openCensusValues(t, v); len(values) > 0 {
+			expected[v.Name] = values
+		}
+	}
+	assert.Equal(t, map[string]int64{"": 2}, expected[openCensus.views.expiredMessages.Name])
+	assert.Equal(t, map[string]int64{"": int64(receiverStateConnected)}, expected[openCensus.views.receiverStatus.Name])
+	assert.Equal(t, map[string]int64{"": 1}, expected[openCensus.views.unackedMessages.Name])
+
+	collected, err := reader.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, expected, openTelemetryValues(t, collected))
+}
+
+func TestInternalMetricsMeter(t *testing.T) {
+	// the collector does not record its internal telemetry with OpenTelemetry by default
+	assert.Nil(t, internalMetricsMeter(componenttest.NewNopTelemetrySettings()))
+}
+
+func TestResetMetrics(t *testing.T) {
+	metrics := newTestMetrics(t)
+	recordAllMetrics(metrics)
+	for _, v := range metrics.allViews() {
+		require.NotEmpty(t, openCensusValues(t, v), v.Name)
+	}
+
+	require.NoError(t, metrics.ResetMetrics())
+	for _, v := range metrics.allViews() {
+		if v == metrics.views.unackedMessages {
+			continue
+		}
+		assert.Empty(t, openCensusValues(t, v), v.Name)
+	}
+	// the message received before the reset is still to be settled
+	assert.Equal(t, int64(1), metrics.unacked.Load())
+	validateMetric(t, metrics.views.unackedMessages, 1)
+
+	// the values recorded after the reset do not accumulate with the values recorded before
+	recordAllMetrics(metrics)
+	for _, v := range metrics.allViews() {
+		if v == metrics.views.unackedMessages {
+			continue
+		}
+		validateMetric(t, v, 1)
+	}
+	validateMetric(t, metrics.views.unackedMessages, 2)
+	metrics.recordSettledMessage()
+	validateMetric(t, metrics.views.unackedMessages, 1)
+}
+
+func TestResetMetricsConcurrently(t *testing.T) {
+	metrics := newTestMetrics(t)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, metrics.ResetMetrics())
+		}()
+		go func() {
+			defer wg.Done()
+			recordAllMetrics(metrics)
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, metrics.ResetMetrics())
+	for _, v := range metrics.allViews() {
+		if v == metrics.views.unackedMessages {
+			continue
+		}
+		assert.Empty(t, openCensusValues(t, v), v.Name)
+	}
+	// no unacked message recorded concurrently with a reset is lost
+	validateMetric(t, metrics.views.unackedMessages, 10)
+}
+
+func TestResetMetricsOpenTelemetry(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter(meterName)
+	metrics, err := newOpenCensusMetrics(t.Name(), meter)
+	require.NoError(t, err)
+	metrics.recordFailedReconnection()
+	metrics.recordReceiverStatus(receiverStateConnected)
+	metrics.recordUnackedMessage()
+
+	require.NoError(t, metrics.ResetMetrics())
+	collected, err := reader.Collect(context.Background())
+	require.NoError(t, err)
+	// the counters are cumulative, only the gauges are reset but for the number of unacked messages
+	assert.Equal(t, map[string]map[string]int64{
+		metrics.views.failedReconnections.Name: {"": 1},
+		metrics.views.unackedMessages.Name:     {"": 1},
+	}, openTelemetryValues(t, collected))
+}
+
+// recordAllMetrics records every metric once, so that each view has the value 1
+func recordAllMetrics(metrics *opencensusMetrics) {
+	metrics.recordFailedReconnection()
+	metrics.recordRecoverableUnmarshallingError()
+	metrics.recordFatalUnmarshallingError()
+	metrics.recordFailedDecompression()
+	metrics.recordOversizedMessage()
+	metrics.recordExceededDeliveries()
+	metrics.recordExpiredMessage()
+	metrics.recordDroppedSpanMessages("queue://#telemetry")
+	metrics.recordReceivedSpanMessages("queue://#telemetry")
+	metrics.recordReportedSpans("queue://#telemetry")
+	metrics.recordReceiverStatus(receiverStateConnecting)
+	metrics.recordNeedUpgrade()
+	metrics.recordUnackedMessage()
+	metrics.recordActiveBroker(brokerSecondary)
+}
+
+// openCensusValues returns the value of the view for every queue it is tagged with, the value of a view
+// without tags is returned for an empty queue
+func openCensusValues(t *testing.T, v *view.View) map[string]int64 {
+	rows, err := view.RetrieveData(v.Name)
+	require.NoError(t, err)
+	values := map[string]int64{}
+	for _, row := range rows {
+		var queue string
+		if len(row.Tags) > 0 {
+			queue = row.Tags[0].Value
+		}
+		value := reflect.Indirect(reflect.ValueOf(row.Data)).FieldByName("Value").Interface()
+		switch value := value.(type) {
+		case int64:
+			values[queue] = value
+		case float64:
+			values[queue] = int64(value)
+		}
+	}
+	return values
+}
+
+// openTelemetryValues returns the value of every collected metric with data points for every queue it is
+// tagged with, the value of a metric without attributes is returned for an empty queue
+func openTelemetryValues(t *testing.T, collected metricdata.ResourceMetrics) map[string]map[string]int64 {
+	values := map[string]map[string]int64{}
+	for _, scope := range collected.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			var dataPoints []metricdata.DataPoint[int64]
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				dataPoints = data.DataPoints
+			case metricdata.Gauge[int64]:
+				dataPoints = data.DataPoints
+			default:
+				t.Fatalf("unexpected data of metric %s: %T", m.Name, m.Data)
+			}
+			if len(dataPoints) == 0 {
+				continue
+			}
+			values[m.Name] = map[string]int64{}
+			for _, dp := range dataPoints {
+				queue, _ := dp.Attributes.Value(attribute.Key(queueKey.Name()))
+				values[m.Name][queue.AsString()] = dp.Value
+			}
+		}
+	}
+	return values
+}
+
+// valuesByQueue returns the value of the view for every queue it is tagged with
+func valuesByQueue(t *testing.T, v *view.View) map[string]int64 {
+	rows, err := view.RetrieveData(v.Name)
+	require.NoError(t, err)
+	values := map[string]int64{}
+	for _, row := range rows {
+		require.Len(t, row.Tags, 1)
+		require.Equal(t, queueKey, row.Tags[0].Key)
+		value := reflect.Indirect(reflect.ValueOf(row.Data)).FieldByName("Value").Interface()
+		switch value := value.(type) {
+		case int64:
+			values[row.Tags[0].Value] = value
+		case float64:
+			values[row.Tags[0].Value] = int64(value)
+		}
+	}
+	return values
+}
+
 func validateMetric(t *testing.T, v *view.View, expected interface{}) {
-	// hack to reset stats to 0
-	defer func() {
-		view.Unregister(v)
-		err := view.Register(v)
-		assert.NoError(t, err)
-	}()
 	rows, err := view.RetrieveData(v.Name)
 	assert.NoError(t, err)
 	if expected != nil {
@@ -85,14 +335,14 @@ func TestRegisterViewsExpectingFailure(t *testing.T) {
 		Aggregation: view.Sum(),
 	})
 	require.NoError(t, err)
-	metrics, err := newOpenCensusMetrics(t.Name())
+	metrics, err := newOpenCensusMetrics(t.Name(), nil)
 	assert.Error(t, err)
 	assert.Nil(t, metrics)
 }
 
 // newTestMetrics builds a new metrics that will cleanup when testing.T completes
 func newTestMetrics(t *testing.T) *opencensusMetrics {
-	m, err := newOpenCensusMetrics(t.Name())
+	m, err := newOpenCensusMetrics(t.Name(), nil)
 	require.NoError(t, err)
 	t.Cleanup(func() {
 		unregisterMetrics(m)
@@ -102,14 +352,5 @@ func newTestMetrics(t *testing.T) *opencensusMetrics {
 
 // unregisterMetrics is used to unregister the metrics for testing purposes
 func unregisterMetrics(metrics *opencensusMetrics) {
-	view.Unregister(
-		metrics.views.failedReconnections,
-		metrics.views.recoverableUnmarshallingErrors,
-		metrics.views.fatalUnmarshallingErrors,
-		metrics.views.droppedSpanMessages,
-		metrics.views.receivedSpanMessages,
-		metrics.views.reportedSpans,
-		metrics.views.receiverStatus,
-		metrics.views.needUpgrade,
-	)
+	view.Unregister(metrics.allViews()...)
 }
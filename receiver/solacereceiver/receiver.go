@@ -17,12 +17,15 @@ package solacereceiver // import "github.com/open-telemetry/opentelemetry-collec
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net"
 	"sync"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
 )
@@ -42,10 +45,18 @@ type solaceTracesReceiver struct {
 	shutdownWaitGroup *sync.WaitGroup
 	// newFactory is the constructor to use to build new messagingServiceFactory instances
 	factory messagingServiceFactory
+	// secondaryFactory builds the messaging services connecting to the secondary broker, nil if no secondary broker is configured
+	secondaryFactory messagingServiceFactory
+	// activeBroker is the broker the reconnection loop connects to, it is only accessed by the reconnection loop
+	activeBroker brokerRole
 	// terminating is used to indicate that the receiver is terminating
 	terminating *atomic.Bool
 	// retryTimeout is the timeout between connection attempts
 	retryTimeout time.Duration
+
+	// batch holds the spans of the current connection that are not forwarded yet, it is only accessed
+	// by the reconnection loop and is nil if batching is disabled
+	batch *spanBatch
 }
 
 // newTracesReceiver creates a new solaceTraceReceiver as a component.TracesReceiver
@@ -60,19 +71,28 @@ func newTracesReceiver(config *Config, receiverCreateSettings component.Receiver
 		return nil, err
 	}
 
-	factory, err := newAMQPMessagingServiceFactory(config, receiverCreateSettings.Logger)
+	factory, err := newAMQPMessagingServiceFactory(config, config.Broker[0], receiverCreateSettings.Logger)
 	if err != nil {
 		receiverCreateSettings.Logger.Warn("Error validating messaging service configuration", zap.Any("error", err))
 		return nil, err
 	}
 
-	metrics, err := newOpenCensusMetrics(config.ID().Name())
+	var secondaryFactory messagingServiceFactory
+	if config.SecondaryBroker != "" {
+		secondaryFactory, err = newAMQPMessagingServiceFactory(config, config.SecondaryBroker, receiverCreateSettings.Logger)
+		if err != nil {
+			receiverCreateSettings.Logger.Warn("Error validating secondary messaging service configuration", zap.Any("error", err))
+			return nil, err
+		}
+	}
+
+	metrics, err := newOpenCensusMetrics(config.ID().Name(), internalMetricsMeter(receiverCreateSettings.TelemetrySettings))
 	if err != nil {
 		receiverCreateSettings.Logger.Warn("Error registering metrics", zap.Any("error", err))
 		return nil, err
 	}
 
-	unmarshaller := newTracesUnmarshaller(receiverCreateSettings.Logger, metrics)
+	unmarshaller := newTracesUnmarshaller(receiverCreateSettings.Logger, metrics, config)
 
 	return &solaceTracesReceiver{
 		instanceID:        config.ID(),
@@ -83,6 +103,7 @@ func newTracesReceiver(config *Config, receiverCreateSettings component.Receiver
 		unmarshaller:      unmarshaller,
 		shutdownWaitGroup: &sync.WaitGroup{},
 		factory:           factory,
+		secondaryFactory:  secondaryFactory,
 		retryTimeout:      1 * time.Second,
 		terminating:       atomic.NewBool(false),
 	}, nil
@@ -147,19 +168,36 @@ reconnectionLoop:
 					s.recordConnectionState(receiverStateConnecting)
 				}
 			}()
-			service := s.factory()
-			defer service.close(ctx)
+			service := s.newMessagingService()
+			// the service is replaced by the service connected to the primary broker on failback
+			defer func() { service.close(ctx) }()
 
 			if err := service.dial(); err != nil {
 				s.settings.Logger.Debug("Encountered error while connecting messaging service", zap.Error(err))
 				s.metrics.recordFailedReconnection()
+				s.failover()
 				return
 			}
 			// dial was successful, record the connected state
 			s.recordConnectionState(receiverStateConnected)
+			s.metrics.recordActiveBroker(s.activeBroker)
 
-			if err := s.receiveMessages(ctx, service); err != nil {
+			err := s.receiveMessages(ctx, s.withFailback(service))
+			for errors.Is(err, errFailback) {
+				// keep receiving from the secondary broker unless the primary broker can be reached again
+				if primary := s.dialPrimary(); primary != nil {
+					service.close(ctx)
+					service = primary
+				}
+				err = s.receiveMessages(ctx, s.withFailback(service))
+			}
+			if err != nil {
 				s.settings.Logger.Debug("Encountered error while receiving messages", zap.Error(err))
+				if errors.Is(err, errHeartbeatTimeout) {
+					// the connection was detected as dead, count the forced reconnect as a failure
+					s.metrics.recordFailedReconnection()
+					return
+				}
 				if errors.Is(err, errUnknownTraceMessgeVersion) {
 					s.metrics.recordNeedUpgrade()
 					disable = true
@@ -172,6 +210,72 @@ reconnectionLoop:
 	}
 }
 
+// newMessagingService builds a new messaging service connecting to the active broker
+func (s *solaceTracesReceiver) newMessagingService() messagingService {
+	if s.activeBroker == brokerSecondary {
+		return s.secondaryFactory()
+	}
+	return s.factory()
+}
+
+// failover switches the active broker to the other broker of the disaster recovery pair after
+// the active broker could not be reached. It does nothing if no secondary broker is configured.
+func (s *solaceTracesReceiver) failover() {
+	if s.secondaryFactory == nil {
+		return
+	}
+	if s.activeBroker == brokerPrimary {
+		s.settings.Logger.Warn("Failing over to the secondary broker")
+		s.activeBroker = brokerSecondary
+	} else {
+		s.activeBroker = brokerPrimary
+	}
+}
+
+// withFailback interrupts the receiving of messages from the secondary broker once the failback interval elapsed,
+// such that the primary broker is attempted again. The messages of the primary broker are received without interruption.
+func (s *solaceTracesReceiver) withFailback(service messagingService) messagingService {
+	if s.activeBroker != brokerSecondary {
+		return service
+	}
+	return &failbackMessagingService{messagingService: service, failbackAt: time.Now().Add(s.config.FailbackInterval)}
+}
+
+// dialPrimary attempts to connect to the primary broker while connected to the secondary broker,
+// returning the connected messaging service or nil if the primary broker still cannot be reached.
+func (s *solaceTracesReceiver) dialPrimary() messagingService {
+	primary := s.factory()
+	if err := primary.dial(); err != nil {
+		s.settings.Logger.Debug("Primary broker still unreachable, staying on the secondary broker", zap.Error(err))
+		primary.close(context.Background())
+		return nil
+	}
+	s.settings.Logger.Info("Failed back to the primary broker")
+	s.activeBroker = brokerPrimary
+	s.metrics.recordActiveBroker(s.activeBroker)
+	return primary
+}
+
+// errFailback is returned by the receiving of messages from the secondary broker once a failback to the primary broker is due
+var errFailback = errors.New("failback to the primary broker is due")
+
+// failbackMessagingService interrupts the wait for the next message once failbackAt is reached
+type failbackMessagingService struct {
+	messagingService
+	failbackAt time.Time
+}
+
+func (f *failbackMessagingService) receiveMessage(ctx context.Context) (*inboundMessage, error) {
+	// the deadline only applies to the wait for a message, the disposition of a received message uses the parent context
+	receiveCtx, cancel := context.WithDeadline(ctx, f.failbackAt)
+	defer cancel()
+	msg, err := f.messagingService.receiveMessage(receiveCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(receiveCtx.Err(), context.DeadlineExceeded) {
+		return nil, errFailback
+	}
+	return msg, err
+}
+
 // recordConnectionState will record the given connection state unless in the terminating state.
 // This does not fully prevent the state transitions terminating->(state)->terminated but
 // is a best effort without mutex protection and additional state tracking, and in reality if
@@ -184,6 +288,16 @@ func (s *solaceTracesReceiver) recordConnectionState(state receiverState) {
 
 // receiveMessages will continuously receive, unmarshal and propagate messages
 func (s *solaceTracesReceiver) receiveMessages(ctx context.Context, service messagingService) error {
+	if s.config.Batch.MaxSpans > 0 {
+		s.batch = newSpanBatch()
+		// the pending spans are forwarded before the receiver reconnects or shuts down
+		defer func() {
+			if err := s.flushBatch(ctx, service); err != nil {
+				s.settings.Logger.Debug("Encountered error while settling the messages of the pending batch", zap.Error(err))
+			}
+			s.batch = nil
+		}()
+	}
 	for {
 		select { // ctx.Done will be closed when we should terminate
 		case <-ctx.Done():
@@ -201,20 +315,79 @@ func (s *solaceTracesReceiver) receiveMessages(ctx context.Context, service mess
 // receiveMessage is the heart of the receiver's control flow. It will receive messages, unmarshal the message and forward the trace.
 // Will return an error if a fatal error occurs. It is expected that any error returned will cause a connection close.
 func (s *solaceTracesReceiver) receiveMessage(ctx context.Context, service messagingService) (err error) {
-	msg, err := service.receiveMessage(ctx)
+	msg, err := s.receiveNextMessage(ctx, service)
+	if errors.Is(err, errBatchDue) {
+		return s.flushBatch(ctx, service)
+	}
+	if errors.Is(err, errFailback) {
+		return err // not a failure of the connection, the failback to the primary broker is due
+	}
 	if err != nil {
 		s.settings.Logger.Warn("Failed to receive message from messaging service", zap.Error(err))
 		return err // propagate any receive message error up to caller
 	}
 	// only set the disposition action after we have received a message successfully
 	disposition := service.accept
+	s.metrics.recordUnackedMessage()
+	// in auto ack mode the message is accepted on receipt, so it is never redelivered whatever happens to its spans
+	settled := false
+	if s.config.AckMode == ackModeAuto {
+		settled = true
+		actionErr := service.accept(ctx, msg)
+		s.metrics.recordSettledMessage()
+		if actionErr != nil {
+			return actionErr
+		}
+	}
+	// a batched message is settled once the spans of its batch were forwarded
+	batched := false
 	defer func() { // on return of receiveMessage, we want to either ack or nack the message
+		if settled || batched {
+			return
+		}
 		if actionErr := disposition(ctx, msg); err == nil && actionErr != nil {
 			err = actionErr
 		}
+		s.metrics.recordSettledMessage()
 	}()
 	// message received successfully
-	s.metrics.recordReceivedSpanMessages()
+	s.metrics.recordReceivedSpanMessages(s.config.Queue)
+	// reject oversized messages before they are decompressed or unmarshalled
+	if s.config.MaxMessageSize > 0 {
+		if size := payloadSize(msg); size > s.config.MaxMessageSize {
+			s.settings.Logger.Warn("Rejecting message exceeding the maximum message size", zap.Int("size", size), zap.Int("max_message_size", s.config.MaxMessageSize))
+			disposition = service.reject
+			s.metrics.recordOversizedMessage()
+			s.metrics.recordDroppedSpanMessages(s.config.Queue)
+			return nil
+		}
+	}
+	// reject poison messages the broker keeps redelivering instead of failing them once more
+	if s.config.Redelivery.MaxDeliveryCount > 0 {
+		if count := deliveryCount(msg); count >= s.config.Redelivery.MaxDeliveryCount {
+			s.settings.Logger.Warn("Rejecting message exceeding the maximum delivery count", zap.Uint32("delivery_count", count), zap.Uint32("max_delivery_count", s.config.Redelivery.MaxDeliveryCount))
+			disposition = service.reject
+			s.metrics.recordExceededDeliveries()
+			s.metrics.recordDroppedSpanMessages(s.config.Queue)
+			return nil
+		}
+	}
+	// drop stale messages, they are acked so that the broker does not redeliver them
+	if s.config.MaxMessageAge > 0 {
+		if age, ok := messageAge(msg, time.Now()); ok && age > s.config.MaxMessageAge {
+			s.settings.Logger.Debug("Dropping message exceeding the maximum message age", zap.Duration("age", age), zap.Duration("max_message_age", s.config.MaxMessageAge))
+			s.metrics.recordExpiredMessage()
+			s.metrics.recordDroppedSpanMessages(s.config.Queue)
+			return nil
+		}
+	}
+	// decompress the payload. decompression errors are not fatal, the message is acked and its content dropped
+	if decompressErr := decompressPayload(msg, s.config.PayloadCompression, s.config.MaxMessageSize); decompressErr != nil {
+		s.settings.Logger.Error("Encountered error while decompressing message payload", zap.Error(decompressErr))
+		s.metrics.recordFailedDecompression()
+		s.metrics.recordDroppedSpanMessages(s.config.Queue)
+		return nil
+	}
 	// unmarshal the message. unmarshalling errors are not fatal unless the version is unknown
 	traces, unmarshalErr := s.unmarshaller.unmarshal(msg)
 	if unmarshalErr != nil {
@@ -224,26 +397,142 @@ func (s *solaceTracesReceiver) receiveMessage(ctx context.Context, service messa
 			disposition = service.failed // if we don't know the version, reject the trace message since we will disable the receiver
 			return unmarshalErr
 		}
-		s.metrics.recordDroppedSpanMessages() // if the error is some other unmarshalling error, we will ack the message and drop the content
-		return nil                            // don't propagate error, but don't continue forwarding traces
+		s.metrics.recordDroppedSpanMessages(s.config.Queue) // if the error is some other unmarshalling error, we will ack the message and drop the content
+		return nil                                          // don't propagate error, but don't continue forwarding traces
 	}
-	// forward to next consumer. Forwarding errors are not fatal so are not propagated to the caller.
-	// Temporary consumer errors will lead to redelivered messages, permanent will be accepted
-	forwardErr := s.nextConsumer.ConsumeTraces(ctx, traces)
-	if forwardErr != nil {
-		if !consumererror.IsPermanent(forwardErr) { // reject the message if the error is not permanent so we can retry, don't increment dropped span messages
-			s.settings.Logger.Warn("Encountered temporary error while forwarding traces to next receiver, will allow redelivery", zap.Error(forwardErr))
-			disposition = service.failed
-		} else { // error is permanent, we want to accept the message and increment the number of dropped messages
-			s.settings.Logger.Warn("Encountered permanent error while forwarding traces to next receiver, will swallow trace", zap.Error(forwardErr))
-			s.metrics.recordDroppedSpanMessages()
+	if s.config.Provenance.Enabled {
+		s.stampProvenance(traces)
+	}
+	if s.config.Partition.Enabled {
+		s.stampPartitionKey(msg, traces)
+	}
+	if len(s.config.ResourceAttributes) > 0 {
+		s.stampResourceAttributes(msg, traces)
+	}
+	if s.batch != nil {
+		batched = true
+		s.batch.add(msg, traces, s.config.Batch.Timeout)
+		if s.batch.spans >= s.config.Batch.MaxSpans {
+			return s.flushBatch(ctx, service)
 		}
-	} else {
-		s.metrics.recordReportedSpans()
+		return nil
+	}
+	if s.forwardTraces(ctx, traces, 1, settled) {
+		disposition = service.failed
 	}
 	return nil
 }
 
+// forwardTraces forwards the traces of the given number of messages to the next consumer. Forwarding errors are not fatal
+// so are not returned. It returns true if the messages are to be failed so that the broker redelivers them, which is the
+// case for temporary consumer errors, permanent errors lead to accepted messages.
+func (s *solaceTracesReceiver) forwardTraces(ctx context.Context, traces ptrace.Traces, messages int, settled bool) bool {
+	forwardErr := s.nextConsumer.ConsumeTraces(ctx, traces)
+	if forwardErr == nil {
+		for i := 0; i < messages; i++ {
+			s.metrics.recordReportedSpans(s.config.Queue)
+		}
+		return false
+	}
+	if !settled && !consumererror.IsPermanent(forwardErr) { // fail the messages if the error is not permanent so we can retry, don't increment dropped span messages
+		s.settings.Logger.Warn("Encountered temporary error while forwarding traces to next receiver, will allow redelivery", zap.Error(forwardErr))
+		return true
+	}
+	if settled { // the messages were accepted on receipt and cannot be redelivered, so their spans are dropped
+		s.settings.Logger.Warn("Encountered error while forwarding traces to next receiver, will swallow trace of the acknowledged message", zap.Error(forwardErr))
+	} else { // error is permanent, we want to accept the messages and increment the number of dropped messages
+		s.settings.Logger.Warn("Encountered permanent error while forwarding traces to next receiver, will swallow trace", zap.Error(forwardErr))
+	}
+	for i := 0; i < messages; i++ {
+		s.metrics.recordDroppedSpanMessages(s.config.Queue)
+	}
+	return false
+}
+
+// stampProvenance sets the resource attributes identifying the active broker and the configured message VPN on the traces
+func (s *solaceTracesReceiver) stampProvenance(traces ptrace.Traces) {
+	const (
+		brokerAttrKey = "messaging.solace.broker"
+		vpnAttrKey    = "messaging.solace.vpn"
+	)
+	broker := s.config.Broker[0]
+	if s.activeBroker == brokerSecondary {
+		broker = s.config.SecondaryBroker
+	}
+	host := brokerHost(broker)
+	resourceSpans := traces.ResourceSpans()
+	for i := 0; i < resourceSpans.Len(); i++ {
+		attrs := resourceSpans.At(i).Resource().Attributes()
+		attrs.PutStr(brokerAttrKey, host)
+		if s.config.Provenance.VPN != "" {
+			attrs.PutStr(vpnAttrKey, s.config.Provenance.VPN)
+		}
+	}
+}
+
+// stampPartitionKey sets the attribute holding the partition key of the message on its spans, the spans
+// are left as they are if the message has no partition key
+func (s *solaceTracesReceiver) stampPartitionKey(msg *inboundMessage, traces ptrace.Traces) {
+	const partitionKeyAttrKey = "messaging.solace.partition_key"
+	key, ok := partitionKey(msg, s.config.Partition.KeyHeader)
+	if !ok {
+		return
+	}
+	resourceSpans := traces.ResourceSpans()
+	for i := 0; i < resourceSpans.Len(); i++ {
+		scopeSpans := resourceSpans.At(i).ScopeSpans()
+		for j := 0; j < scopeSpans.Len(); j++ {
+			spans := scopeSpans.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				spans.At(k).Attributes().PutStr(partitionKeyAttrKey, key)
+			}
+		}
+	}
+}
+
+// stampResourceAttributes sets the resource attributes mapped from the application properties of the message on
+// its traces, properties the message does not have are left out. Values of other than basic types are set as strings.
+func (s *solaceTracesReceiver) stampResourceAttributes(msg *inboundMessage, traces ptrace.Traces) {
+	resourceSpans := traces.ResourceSpans()
+	for property, attribute := range s.config.ResourceAttributes {
+		value, ok := msg.ApplicationProperties[property]
+		if !ok || value == nil {
+			continue
+		}
+		for i := 0; i < resourceSpans.Len(); i++ {
+			attrs := resourceSpans.At(i).Resource().Attributes()
+			switch value.(type) {
+			case string, bool, int8, int16, int32, int64, uint8, uint16, uint32, uint64, float32, float64, []byte:
+				attrs.PutEmpty(attribute).FromRaw(value)
+			default:
+				attrs.PutStr(attribute, fmt.Sprint(value))
+			}
+		}
+	}
+}
+
+// partitionKey returns the partition key of the message from the given application property, or from the
+// group-id of the message if no property is given, false if the message has no string partition key
+func partitionKey(msg *inboundMessage, header string) (string, bool) {
+	if header != "" {
+		key, ok := msg.ApplicationProperties[header].(string)
+		return key, ok && key != ""
+	}
+	if msg.Properties == nil || msg.Properties.GroupID == nil || *msg.Properties.GroupID == "" {
+		return "", false
+	}
+	return *msg.Properties.GroupID, true
+}
+
+// brokerHost returns the host of the broker address, or the address as is if it has no port
+func brokerHost(broker string) string {
+	host, _, err := net.SplitHostPort(broker)
+	if err != nil {
+		return broker
+	}
+	return host
+}
+
 func sleep(ctx context.Context, d time.Duration) {
 	timer := time.NewTimer(d)
 	select {
@@ -252,3 +541,28 @@ func sleep(ctx context.Context, d time.Duration) {
 	case <-timer.C:
 	}
 }
+
+// payloadSize returns the size in bytes of the payload of the message
+func payloadSize(msg *inboundMessage) int {
+	size := 0
+	for _, data := range msg.Data {
+		size += len(data)
+	}
+	return size
+}
+
+// deliveryCount returns the number of failed deliveries of the message before this one, 0 unless the message is redelivered
+func deliveryCount(msg *inboundMessage) uint32 {
+	if msg.Header == nil {
+		return 0
+	}
+	return msg.Header.DeliveryCount
+}
+
+// messageAge returns the time elapsed since the creation of the message, false if the message has no creation time
+func messageAge(msg *inboundMessage, now time.Time) (time.Duration, bool) {
+	if msg.Properties == nil || msg.Properties.CreationTime == nil {
+		return 0, false
+	}
+	return now.Sub(*msg.Properties.CreationTime), true
+}
@@ -17,13 +17,19 @@ package solacereceiver // import "github.com/open-telemetry/opentelemetry-collec
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/Azure/go-amqp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/pdata/ptrace"
@@ -169,6 +175,613 @@ func TestReceiveMessage(t *testing.T) {
 	}
 }
 
+func TestReceiveMessageUnackedMessages(t *testing.T) {
+	receiver, messagingService, unmarshaller := newReceiver(t)
+	messagingService.receiveMessageFunc = func(ctx context.Context) (*inboundMessage, error) {
+		return &inboundMessage{}, nil
+	}
+	unmarshaller.unmarshalFunc = func(msg *inboundMessage) (ptrace.Traces, error) {
+		return ptrace.NewTraces(), nil
+	}
+	releaseAcks := make(chan struct{})
+	messagingService.ackFunc = func(ctx context.Context, msg *inboundMessage) error {
+		<-releaseAcks
+		return nil
+	}
+
+	const messages = 3
+	wg := &sync.WaitGroup{}
+	for i := 0; i < messages; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, receiver.receiveMessage(context.Background(), messagingService))
+		}()
+	}
+	// the messages are held until they are acked. A connection receives one message at a time,
+	// concurrent calls stand in for the messages of several connections
+	assert.Eventually(t, func() bool {
+		return unackedMessagesValue(t, receiver) == messages
+	}, time.Second, 10*time.Millisecond)
+
+	close(releaseAcks)
+	wg.Wait()
+	assert.Equal(t, int64(0), unackedMessagesValue(t, receiver))
+}
+
+func TestReceiveMessageAckMode(t *testing.T) {
+	cases := []struct {
+		name       string
+		ackMode    string
+		consumeErr error
+		// the calls to the consumer and the messaging service, in order
+		expectedCalls []string
+		// whether or not the message is expected to be counted as dropped
+		expectDropped bool
+	}{
+		{
+			name:          "Client Acks After Consume",
+			ackMode:       ackModeClient,
+			expectedCalls: []string{"consume", "ack"},
+		},
+		{
+			name:          "Client Nacks On Temporary Error",
+			ackMode:       ackModeClient,
+			consumeErr:    errors.New("some error"),
+			expectedCalls: []string{"consume", "nack"},
+		},
+		{
+			name:          "Auto Acks On Receipt",
+			ackMode:       ackModeAuto,
+			expectedCalls: []string{"ack", "consume"},
+		},
+		{ // the message was already acked, so it cannot be redelivered and its spans are dropped
+			name:          "Auto Drops On Temporary Error",
+			ackMode:       ackModeAuto,
+			consumeErr:    errors.New("some error"),
+			expectedCalls: []string{"ack", "consume"},
+			expectDropped: true,
+		},
+	}
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			receiver, messagingService, unmarshaller := newReceiver(t)
+			receiver.config.AckMode = testCase.ackMode
+			var calls []string
+			receiver.nextConsumer, _ = consumer.NewTraces(func(ctx context.Context, td ptrace.Traces) error {
+				calls = append(calls, "consume")
+				return testCase.consumeErr
+			})
+			messagingService.receiveMessageFunc = func(ctx context.Context) (*inboundMessage, error) {
+				return &inboundMessage{}, nil
+			}
+			unmarshaller.unmarshalFunc = func(msg *inboundMessage) (ptrace.Traces, error) {
+				return ptrace.NewTraces(), nil
+			}
+			messagingService.ackFunc = func(ctx context.Context, msg *inboundMessage) error {
+				calls = append(calls, "ack")
+				return nil
+			}
+			messagingService.nackFunc = func(ctx context.Context, msg *inboundMessage) error {
+				calls = append(calls, "nack")
+				return nil
+			}
+
+			assert.NoError(t, receiver.receiveMessage(context.Background(), messagingService))
+			assert.Equal(t, testCase.expectedCalls, calls)
+			assert.Equal(t, int64(0), unackedMessagesValue(t, receiver))
+			if testCase.expectDropped {
+				validateReceiverMetrics(t, receiver, 1, 1, nil, nil)
+			}
+		})
+	}
+}
+
+func TestReceiveMessageAutoAckError(t *testing.T) {
+	receiver, messagingService, _ := newReceiver(t)
+	receiver.config.AckMode = ackModeAuto
+	someError := errors.New("some error")
+	messagingService.receiveMessageFunc = func(ctx context.Context) (*inboundMessage, error) {
+		return &inboundMessage{}, nil
+	}
+	messagingService.ackFunc = func(ctx context.Context, msg *inboundMessage) error {
+		return someError
+	}
+	// the message is neither unmarshalled nor forwarded if it cannot be acked on receipt
+	assert.Equal(t, someError, receiver.receiveMessage(context.Background(), messagingService))
+	assert.Equal(t, int64(0), unackedMessagesValue(t, receiver))
+}
+
+func TestReceiveMessageDecompression(t *testing.T) {
+	cases := []struct {
+		name                 string
+		compression          string
+		data                 []byte
+		maxMessageSize       int
+		expectUnmarshal      bool
+		failedDecompressions interface{}
+		droppedMsgVal        interface{}
+	}{
+		{
+			name:            "Gzip Payload",
+			compression:     payloadCompressionGzip,
+			data:            compressTestPayload(t, payloadCompressionGzip, testPayload),
+			expectUnmarshal: true,
+		},
+		{
+			name:            "Zlib Payload",
+			compression:     payloadCompressionZlib,
+			data:            compressTestPayload(t, payloadCompressionZlib, testPayload),
+			expectUnmarshal: true,
+		},
+		{
+			name:                 "Corrupt Payload",
+			compression:          payloadCompressionGzip,
+			data:                 testPayload,
+			failedDecompressions: 1,
+			droppedMsgVal:        1,
+		},
+		{
+			name:                 "Payload Exceeding Max Message Size Once Decompressed",
+			compression:          payloadCompressionGzip,
+			data:                 compressTestPayload(t, payloadCompressionGzip, make([]byte, 1024*1024)),
+			maxMessageSize:       64 * 1024,
+			failedDecompressions: 1,
+			droppedMsgVal:        1,
+		},
+	}
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			receiver, messagingService, unmarshaller := newReceiver(t)
+			receiver.config.PayloadCompression = testCase.compression
+			receiver.config.MaxMessageSize = testCase.maxMessageSize
+			messagingService.receiveMessageFunc = func(ctx context.Context) (*inboundMessage, error) {
+				return &inboundMessage{Data: [][]byte{testCase.data}}, nil
+			}
+			var ackCalled, unmarshalCalled bool
+			messagingService.ackFunc = func(ctx context.Context, msg *inboundMessage) error {
+				ackCalled = true
+				return nil
+			}
+			unmarshaller.unmarshalFunc = func(msg *inboundMessage) (ptrace.Traces, error) {
+				unmarshalCalled = true
+				assert.Equal(t, testPayload, msg.GetData())
+				return ptrace.NewTraces(), nil
+			}
+
+			assert.NoError(t, receiver.receiveMessage(context.Background(), messagingService))
+			// messages failing to decompress are acked and dropped
+			assert.True(t, ackCalled)
+			assert.Equal(t, testCase.expectUnmarshal, unmarshalCalled)
+			validateMetric(t, receiver.metrics.views.failedDecompressions, testCase.failedDecompressions)
+			validateMetric(t, receiver.metrics.views.droppedSpanMessages, testCase.droppedMsgVal)
+		})
+	}
+}
+
+func TestReceiveMessageMaxMessageSize(t *testing.T) {
+	cases := []struct {
+		name              string
+		maxMessageSize    int
+		data              [][]byte
+		expectReject      bool
+		oversizedMessages interface{}
+		droppedMsgVal     interface{}
+	}{
+		{
+			name:           "No Limit",
+			maxMessageSize: 0,
+			data:           [][]byte{make([]byte, 1024)},
+		},
+		{
+			name:           "Within Limit",
+			maxMessageSize: 1024,
+			data:           [][]byte{make([]byte, 1024)},
+		},
+		{
+			name:              "Exceeds Limit",
+			maxMessageSize:    1024,
+			data:              [][]byte{make([]byte, 1025)},
+			expectReject:      true,
+			oversizedMessages: 1,
+			droppedMsgVal:     1,
+		},
+		{
+			name:              "Sections Exceed Limit",
+			maxMessageSize:    1024,
+			data:              [][]byte{make([]byte, 512), make([]byte, 513)},
+			expectReject:      true,
+			oversizedMessages: 1,
+			droppedMsgVal:     1,
+		},
+	}
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			receiver, messagingService, unmarshaller := newReceiver(t)
+			receiver.config.MaxMessageSize = testCase.maxMessageSize
+			messagingService.receiveMessageFunc = func(ctx context.Context) (*inboundMessage, error) {
+				return &inboundMessage{Data: testCase.data}, nil
+			}
+			var ackCalled, rejectCalled, unmarshalCalled bool
+			messagingService.ackFunc = func(ctx context.Context, msg *inboundMessage) error {
+				ackCalled = true
+				return nil
+			}
+			messagingService.rejectFunc = func(ctx context.Context, msg *inboundMessage) error {
+				rejectCalled = true
+				return nil
+			}
+			unmarshaller.unmarshalFunc = func(msg *inboundMessage) (ptrace.Traces, error) {
+				unmarshalCalled = true
+				return ptrace.NewTraces(), nil
+			}
+
+			assert.NoError(t, receiver.receiveMessage(context.Background(), messagingService))
+			assert.Equal(t, testCase.expectReject, rejectCalled)
+			assert.Equal(t, !testCase.expectReject, ackCalled)
+			assert.Equal(t, !testCase.expectReject, unmarshalCalled)
+			validateMetric(t, receiver.metrics.views.oversizedMessages, testCase.oversizedMessages)
+			validateMetric(t, receiver.metrics.views.droppedSpanMessages, testCase.droppedMsgVal)
+			// rejected messages are settled
+			assert.Equal(t, int64(0), unackedMessagesValue(t, receiver))
+		})
+	}
+}
+
+func TestReceiveMessageMaxDeliveryCount(t *testing.T) {
+	cases := []struct {
+		name               string
+		maxDeliveryCount   uint32
+		header             *amqp.MessageHeader
+		expectReject       bool
+		exceededDeliveries interface{}
+		droppedMsgVal      interface{}
+	}{
+		{
+			name:             "No Limit",
+			maxDeliveryCount: 0,
+			header:           &amqp.MessageHeader{DeliveryCount: 100},
+		},
+		{
+			name:             "No Header",
+			maxDeliveryCount: 3,
+		},
+		{
+			name:             "Within Limit",
+			maxDeliveryCount: 3,
+			header:           &amqp.MessageHeader{DeliveryCount: 2},
+		},
+		{
+			name:               "Exceeds Limit",
+			maxDeliveryCount:   3,
+			header:             &amqp.MessageHeader{DeliveryCount: 3},
+			expectReject:       true,
+			exceededDeliveries: 1,
+			droppedMsgVal:      1,
+		},
+	}
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			receiver, messagingService, unmarshaller := newReceiver(t)
+			receiver.config.Redelivery.MaxDeliveryCount = testCase.maxDeliveryCount
+			messagingService.receiveMessageFunc = func(ctx context.Context) (*inboundMessage, error) {
+				return &inboundMessage{Header: testCase.header}, nil
+			}
+			var ackCalled, rejectCalled, unmarshalCalled bool
+			messagingService.ackFunc = func(ctx context.Context, msg *inboundMessage) error {
+				ackCalled = true
+				return nil
+			}
+			messagingService.rejectFunc = func(ctx context.Context, msg *inboundMessage) error {
+				rejectCalled = true
+				return nil
+			}
+			unmarshaller.unmarshalFunc = func(msg *inboundMessage) (ptrace.Traces, error) {
+				unmarshalCalled = true
+				return ptrace.NewTraces(), nil
+			}
+
+			assert.NoError(t, receiver.receiveMessage(context.Background(), messagingService))
+			assert.Equal(t, testCase.expectReject, rejectCalled)
+			assert.Equal(t, !testCase.expectReject, ackCalled)
+			assert.Equal(t, !testCase.expectReject, unmarshalCalled)
+			validateMetric(t, receiver.metrics.views.exceededDeliveries, testCase.exceededDeliveries)
+			validateMetric(t, receiver.metrics.views.droppedSpanMessages, testCase.droppedMsgVal)
+		})
+	}
+}
+
+func TestReceiveMessageMaxMessageAge(t *testing.T) {
+	fresh := time.Now().Add(-time.Second)
+	stale := time.Now().Add(-time.Hour)
+	cases := []struct {
+		name            string
+		maxMessageAge   time.Duration
+		properties      *amqp.MessageProperties
+		expectDropped   bool
+		expiredMessages interface{}
+		droppedMsgVal   interface{}
+	}{
+		{
+			name:          "No Limit",
+			maxMessageAge: 0,
+			properties:    &amqp.MessageProperties{CreationTime: &stale},
+		},
+		{
+			name:          "No Creation Time",
+			maxMessageAge: time.Minute,
+			properties:    &amqp.MessageProperties{},
+		},
+		{
+			name:          "No Properties",
+			maxMessageAge: time.Minute,
+		},
+		{
+			name:          "Fresh",
+			maxMessageAge: time.Minute,
+			properties:    &amqp.MessageProperties{CreationTime: &fresh},
+		},
+		{
+			name:            "Stale",
+			maxMessageAge:   time.Minute,
+			properties:      &amqp.MessageProperties{CreationTime: &stale},
+			expectDropped:   true,
+			expiredMessages: 1,
+			droppedMsgVal:   1,
+		},
+	}
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			receiver, messagingService, unmarshaller := newReceiver(t)
+			receiver.config.MaxMessageAge = testCase.maxMessageAge
+			messagingService.receiveMessageFunc = func(ctx context.Context) (*inboundMessage, error) {
+				return &inboundMessage{Properties: testCase.properties}, nil
+			}
+			var ackCalled, unmarshalCalled bool
+			messagingService.ackFunc = func(ctx context.Context, msg *inboundMessage) error {
+				ackCalled = true
+				return nil
+			}
+			unmarshaller.unmarshalFunc = func(msg *inboundMessage) (ptrace.Traces, error) {
+				unmarshalCalled = true
+				return ptrace.NewTraces(), nil
+			}
+
+			assert.NoError(t, receiver.receiveMessage(context.Background(), messagingService))
+			// stale messages are acked so that the broker does not redeliver them
+			assert.True(t, ackCalled)
+			assert.Equal(t, !testCase.expectDropped, unmarshalCalled)
+			validateMetric(t, receiver.metrics.views.expiredMessages, testCase.expiredMessages)
+			validateMetric(t, receiver.metrics.views.droppedSpanMessages, testCase.droppedMsgVal)
+		})
+	}
+}
+
+func TestReceiveMessageProvenance(t *testing.T) {
+	cases := []struct {
+		name         string
+		provenance   ProvenanceConfig
+		activeBroker brokerRole
+		expected     map[string]interface{}
+	}{
+		{
+			name:     "Disabled",
+			expected: map[string]interface{}{"service.name": "router"},
+		},
+		{
+			name:       "Primary Broker",
+			provenance: ProvenanceConfig{Enabled: true, VPN: "default"},
+			expected: map[string]interface{}{
+				"service.name":            "router",
+				"messaging.solace.broker": "myHost",
+				"messaging.solace.vpn":    "default",
+			},
+		},
+		{
+			name:         "Secondary Broker",
+			provenance:   ProvenanceConfig{Enabled: true, VPN: "default"},
+			activeBroker: brokerSecondary,
+			expected: map[string]interface{}{
+				"service.name":            "router",
+				"messaging.solace.broker": "myBackupHost",
+				"messaging.solace.vpn":    "default",
+			},
+		},
+		{
+			name:       "No VPN",
+			provenance: ProvenanceConfig{Enabled: true},
+			expected: map[string]interface{}{
+				"service.name":            "router",
+				"messaging.solace.broker": "myHost",
+			},
+		},
+	}
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			receiver, messagingService, unmarshaller := newReceiver(t)
+			receiver.config.Broker = []string{"myHost:5671"}
+			receiver.config.SecondaryBroker = "myBackupHost:5671"
+			receiver.config.Provenance = testCase.provenance
+			receiver.activeBroker = testCase.activeBroker
+			sink := &consumertest.TracesSink{}
+			receiver.nextConsumer = sink
+			messagingService.receiveMessageFunc = func(ctx context.Context) (*inboundMessage, error) {
+				return &inboundMessage{}, nil
+			}
+			messagingService.ackFunc = func(ctx context.Context, msg *inboundMessage) error {
+				return nil
+			}
+			unmarshaller.unmarshalFunc = func(msg *inboundMessage) (ptrace.Traces, error) {
+				traces := ptrace.NewTraces()
+				traces.ResourceSpans().AppendEmpty().Resource().Attributes().PutStr("service.name", "router")
+				return traces, nil
+			}
+
+			require.NoError(t, receiver.receiveMessage(context.Background(), messagingService))
+			require.Len(t, sink.AllTraces(), 1)
+			assert.Equal(t, testCase.expected, sink.AllTraces()[0].ResourceSpans().At(0).Resource().Attributes().AsRaw())
+		})
+	}
+}
+
+func TestReceiveMessagePartitionKey(t *testing.T) {
+	groupID := func(id string) *inboundMessage {
+		return &inboundMessage{Properties: &amqp.MessageProperties{GroupID: &id}}
+	}
+	cases := []struct {
+		name      string
+		partition PartitionConfig
+		messages  []*inboundMessage
+		expected  []map[string]interface{}
+	}{
+		{
+			name:      "Group ID",
+			partition: PartitionConfig{Enabled: true},
+			messages:  []*inboundMessage{groupID("partition-a"), groupID("partition-b"), {}, groupID("partition-a")},
+			expected: []map[string]interface{}{
+				{"messaging.solace.partition_key": "partition-a"},
+				{"messaging.solace.partition_key": "partition-b"},
+				{},
+				{"messaging.solace.partition_key": "partition-a"},
+			},
+		},
+		{
+			name:      "Key Header",
+			partition: PartitionConfig{Enabled: true, KeyHeader: "partition"},
+			messages: []*inboundMessage{
+				{ApplicationProperties: map[string]interface{}{"partition": "partition-a"}},
+				{ApplicationProperties: map[string]interface{}{"partition": "partition-b"}},
+				{ApplicationProperties: map[string]interface{}{"partition": int64(1)}},
+				groupID("partition-c"),
+			},
+			expected: []map[string]interface{}{
+				{"messaging.solace.partition_key": "partition-a"},
+				{"messaging.solace.partition_key": "partition-b"},
+				{},
+				{},
+			},
+		},
+		{
+			name:     "Disabled",
+			messages: []*inboundMessage{groupID("partition-a"), groupID("partition-b")},
+			expected: []map[string]interface{}{{}, {}},
+		},
+	}
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			receiver, messagingService, unmarshaller := newReceiver(t)
+			receiver.config.Partition = testCase.partition
+			sink := &consumertest.TracesSink{}
+			receiver.nextConsumer = sink
+			next := 0
+			messagingService.receiveMessageFunc = func(ctx context.Context) (*inboundMessage, error) {
+				msg := testCase.messages[next]
+				next++
+				return msg, nil
+			}
+			var accepted []*inboundMessage
+			messagingService.ackFunc = func(ctx context.Context, msg *inboundMessage) error {
+				accepted = append(accepted, msg)
+				return nil
+			}
+			unmarshaller.unmarshalFunc = func(msg *inboundMessage) (ptrace.Traces, error) {
+				traces := ptrace.NewTraces()
+				traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+				return traces, nil
+			}
+
+			for range testCase.messages {
+				require.NoError(t, receiver.receiveMessage(context.Background(), messagingService))
+			}
+			// the messages of every partition are consumed and settled
+			assert.Equal(t, testCase.messages, accepted)
+			require.Len(t, sink.AllTraces(), len(testCase.expected))
+			for i, traces := range sink.AllTraces() {
+				assert.Equal(t, testCase.expected[i], traces.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Attributes().AsRaw())
+			}
+		})
+	}
+}
+
+func TestReceiveMessageResourceAttributes(t *testing.T) {
+	messages := []*inboundMessage{
+		{ApplicationProperties: map[string]interface{}{"source_system": "checkout", "region": "eu-west-1", "shard": int32(3)}},
+		{ApplicationProperties: map[string]interface{}{"source_system": "billing", "shard": nil, "other": "ignored"}},
+		{ApplicationProperties: map[string]interface{}{"source_system": amqp.UUID{1}, "region": true}},
+		{},
+	}
+	expected := []map[string]interface{}{
+		{"service.name": "router", "source.system": "checkout", "cloud.region": "eu-west-1", "source.shard": int64(3)},
+		{"service.name": "router", "source.system": "billing"},
+		{"service.name": "router", "source.system": "01000000-0000-0000-0000-000000000000", "cloud.region": true},
+		{"service.name": "router"},
+	}
+	cases := []struct {
+		name  string
+		batch BatchConfig
+	}{
+		{name: "Per Message"},
+		// the spans of the messages of a batch keep the resource of their message
+		{name: "Batched", batch: BatchConfig{MaxSpans: 4, Timeout: time.Hour}},
+	}
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			receiver, messagingService, unmarshaller := newReceiver(t)
+			receiver.config.ResourceAttributes = map[string]string{
+				"source_system": "source.system",
+				"region":        "cloud.region",
+				"shard":         "source.shard",
+			}
+			receiver.config.Batch = testCase.batch
+			sink := &consumertest.TracesSink{}
+			receiver.nextConsumer = sink
+			next := 0
+			messagingService.receiveMessageFunc = func(ctx context.Context) (*inboundMessage, error) {
+				if next == len(messages) {
+					return nil, errors.New("connection lost")
+				}
+				next++
+				return messages[next-1], nil
+			}
+			messagingService.ackFunc = func(ctx context.Context, msg *inboundMessage) error {
+				return nil
+			}
+			unmarshaller.unmarshalFunc = func(msg *inboundMessage) (ptrace.Traces, error) {
+				traces := ptrace.NewTraces()
+				resourceSpans := traces.ResourceSpans().AppendEmpty()
+				resourceSpans.Resource().Attributes().PutStr("service.name", "router")
+				resourceSpans.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+				return traces, nil
+			}
+
+			assert.Error(t, receiver.receiveMessages(context.Background(), messagingService))
+			var resources []map[string]interface{}
+			for _, traces := range sink.AllTraces() {
+				for i := 0; i < traces.ResourceSpans().Len(); i++ {
+					resources = append(resources, traces.ResourceSpans().At(i).Resource().Attributes().AsRaw())
+				}
+			}
+			assert.Equal(t, expected, resources)
+		})
+	}
+}
+
+func TestBrokerHost(t *testing.T) {
+	assert.Equal(t, "myHost", brokerHost("myHost:5671"))
+	assert.Equal(t, "::1", brokerHost("[::1]:5671"))
+	assert.Equal(t, "myHost", brokerHost("myHost"))
+}
+
+// unackedMessagesValue returns the last recorded number of unacked messages, or -1 if none was recorded
+func unackedMessagesValue(t *testing.T, receiver *solaceTracesReceiver) int64 {
+	rows, err := view.RetrieveData(receiver.metrics.views.unackedMessages.Name)
+	assert.NoError(t, err)
+	if len(rows) != 1 {
+		return -1
+	}
+	return int64(rows[0].Data.(*view.LastValueData).Value)
+}
+
 // receiveMessages ctx done return
 func TestReceiveMessagesTerminateWithCtxDone(t *testing.T) {
 	receiver, messagingService, unmarshaller := newReceiver(t)
@@ -288,6 +901,138 @@ func TestReceiverDialFailureContinue(t *testing.T) {
 	validateReceiverMetrics(t, receiver, nil, nil, nil, nil)
 }
 
+func TestReceiverHeartbeatTimeoutReconnect(t *testing.T) {
+	receiver, msgService, _ := newReceiver(t)
+	dialCalled := 0
+	redialDone := make(chan struct{})
+	msgService.dialFunc = func() error {
+		dialCalled++
+		if dialCalled == 2 {
+			close(redialDone)
+		}
+		return nil
+	}
+	msgService.closeFunc = func(ctx context.Context) {}
+	receiveCalled := 0
+	msgService.receiveMessageFunc = func(ctx context.Context) (*inboundMessage, error) {
+		receiveCalled++
+		if receiveCalled == 1 {
+			// the broker stopped sending heartbeats on the first connection
+			return nil, fmt.Errorf("%w: %v", errHeartbeatTimeout, os.ErrDeadlineExceeded)
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	// start the receiver
+	err := receiver.Start(context.Background(), nil)
+	assert.NoError(t, err)
+
+	// expect the dead connection to be closed and a new connection to be dialed
+	assertChannelClosed(t, redialDone)
+	validateMetric(t, receiver.metrics.views.failedReconnections, 1)
+
+	err = receiver.Shutdown(context.Background())
+	assert.NoError(t, err)
+	validateMetric(t, receiver.metrics.views.receiverStatus, receiverStateTerminated)
+	validateReceiverMetrics(t, receiver, nil, nil, nil, nil)
+}
+
+func TestReceiverFailoverToSecondaryBroker(t *testing.T) {
+	receiver, primaryService, _ := newReceiver(t)
+	receiver.config.FailbackInterval = time.Hour
+	secondaryService := &mockMessagingService{}
+	receiver.secondaryFactory = func() messagingService {
+		return secondaryService
+	}
+	primaryService.dialFunc = func() error {
+		return errors.New("primary broker unreachable")
+	}
+	primaryService.closeFunc = func(ctx context.Context) {}
+	secondaryService.dialFunc = func() error {
+		return nil
+	}
+	secondaryService.closeFunc = func(ctx context.Context) {}
+	receiveCalled := make(chan struct{})
+	secondaryService.receiveMessageFunc = func(ctx context.Context) (*inboundMessage, error) {
+		close(receiveCalled)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	// start the receiver
+	err := receiver.Start(context.Background(), nil)
+	assert.NoError(t, err)
+
+	// expect the secondary broker to be used once the primary broker cannot be reached
+	assertChannelClosed(t, receiveCalled)
+	validateMetric(t, receiver.metrics.views.failedReconnections, 1)
+	validateMetric(t, receiver.metrics.views.activeBroker, brokerSecondary)
+	validateMetric(t, receiver.metrics.views.receiverStatus, receiverStateConnected)
+
+	err = receiver.Shutdown(context.Background())
+	assert.NoError(t, err)
+	validateMetric(t, receiver.metrics.views.receiverStatus, receiverStateTerminated)
+}
+
+func TestReceiverFailbackToPrimaryBroker(t *testing.T) {
+	receiver, primaryService, _ := newReceiver(t)
+	receiver.config.FailbackInterval = 10 * time.Millisecond
+	secondaryService := &mockMessagingService{}
+	receiver.secondaryFactory = func() messagingService {
+		return secondaryService
+	}
+	// the primary broker is down for the first two attempts, the connection and the first failback
+	primaryDialCalled := 0
+	primaryService.dialFunc = func() error {
+		primaryDialCalled++
+		if primaryDialCalled <= 2 {
+			return errors.New("primary broker unreachable")
+		}
+		return nil
+	}
+	primaryService.closeFunc = func(ctx context.Context) {}
+	primaryReceiveCalled := make(chan struct{})
+	primaryService.receiveMessageFunc = func(ctx context.Context) (*inboundMessage, error) {
+		close(primaryReceiveCalled)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	secondaryDialCalled := 0
+	secondaryService.dialFunc = func() error {
+		secondaryDialCalled++
+		return nil
+	}
+	secondaryClosed := make(chan struct{})
+	secondaryService.closeFunc = func(ctx context.Context) {
+		close(secondaryClosed)
+	}
+	secondaryReceiveCalled := 0
+	secondaryService.receiveMessageFunc = func(ctx context.Context) (*inboundMessage, error) {
+		secondaryReceiveCalled++
+		if secondaryReceiveCalled == 1 {
+			validateMetric(t, receiver.metrics.views.activeBroker, brokerSecondary)
+		}
+		// no messages are flowing, the wait is interrupted by the failback interval
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	// start the receiver
+	err := receiver.Start(context.Background(), nil)
+	assert.NoError(t, err)
+
+	// expect the connection to the secondary broker to be replaced once the primary broker recovered
+	assertChannelClosed(t, secondaryClosed)
+	assertChannelClosed(t, primaryReceiveCalled)
+	validateMetric(t, receiver.metrics.views.activeBroker, brokerPrimary)
+	// the failed failback attempt is not a failed reconnection, the receiver stayed connected to the secondary broker
+	validateMetric(t, receiver.metrics.views.failedReconnections, 1)
+
+	err = receiver.Shutdown(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, secondaryDialCalled)
+	assert.Equal(t, 3, primaryDialCalled)
+	validateMetric(t, receiver.metrics.views.receiverStatus, receiverStateTerminated)
+}
+
 func TestReceiverUnmarshalVersionFailureExpectingDisable(t *testing.T) {
 	receiver, msgService, unmarshaller := newReceiver(t)
 	dialDone := make(chan struct{})
@@ -350,7 +1095,7 @@ func newReceiver(t *testing.T) (*solaceTracesReceiver, *mockMessagingService, *m
 	receiver := &solaceTracesReceiver{
 		settings:          componenttest.NewNopReceiverCreateSettings(),
 		instanceID:        component.NewID(component.Type(t.Name())),
-		config:            &Config{},
+		config:            &Config{Queue: testQueue},
 		nextConsumer:      consumertest.NewNop(),
 		metrics:           metrics,
 		unmarshaller:      unmarshaller,
@@ -362,7 +1107,15 @@ func newReceiver(t *testing.T) (*solaceTracesReceiver, *mockMessagingService, *m
 	return receiver, service, unmarshaller
 }
 
+const testQueue = "queue://#trace-profile123"
+
 func validateReceiverMetrics(t *testing.T, receiver *solaceTracesReceiver, receivedMsgVal, droppedMsgVal, fatalUnmarshalling, reportedSpan interface{}) {
+	// the metrics of the messages are tagged with the queue they were received from
+	for _, v := range []*view.View{receiver.metrics.views.receivedSpanMessages, receiver.metrics.views.droppedSpanMessages, receiver.metrics.views.reportedSpans} {
+		for queue := range valuesByQueue(t, v) {
+			assert.Equal(t, testQueue, queue)
+		}
+	}
 	validateMetric(t, receiver.metrics.views.receivedSpanMessages, receivedMsgVal)
 	validateMetric(t, receiver.metrics.views.droppedSpanMessages, droppedMsgVal)
 	validateMetric(t, receiver.metrics.views.fatalUnmarshallingErrors, fatalUnmarshalling)
@@ -375,6 +1128,7 @@ type mockMessagingService struct {
 	receiveMessageFunc func(ctx context.Context) (*inboundMessage, error)
 	ackFunc            func(ctx context.Context, msg *inboundMessage) error
 	nackFunc           func(ctx context.Context, msg *inboundMessage) error
+	rejectFunc         func(ctx context.Context, msg *inboundMessage) error
 }
 
 func (m *mockMessagingService) dial() error {
@@ -413,6 +1167,13 @@ func (m *mockMessagingService) failed(ctx context.Context, msg *inboundMessage)
 	panic("did not expect nack to be called")
 }
 
+func (m *mockMessagingService) reject(ctx context.Context, msg *inboundMessage) error {
+	if m.rejectFunc != nil {
+		return m.rejectFunc(ctx, msg)
+	}
+	panic("did not expect reject to be called")
+}
+
 type mockUnmarshaller struct {
 	unmarshalFunc func(msg *inboundMessage) (ptrace.Traces, error)
 }
@@ -0,0 +1,68 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package solacereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/solacereceiver"
+
+// semconvKeys are the keys of the attributes of the spans that the semantic conventions name, the keys of the
+// attributes specific to Solace are the same in every version
+type semconvKeys struct {
+	protocol         string
+	protocolVersion  string
+	messageID        string
+	conversationID   string
+	payloadSizeBytes string
+	destination      string
+	hostIP           string
+	hostPort         string
+	peerIP           string
+	peerPort         string
+}
+
+// semconvAttributeKeys are the keys of the attributes of every supported semconv_version
+var semconvAttributeKeys = map[string]semconvKeys{
+	semconvVersion116: {
+		protocol:         "messaging.protocol",
+		protocolVersion:  "messaging.protocol_version",
+		messageID:        "messaging.message_id",
+		conversationID:   "messaging.conversation_id",
+		payloadSizeBytes: "messaging.message_payload_size_bytes",
+		destination:      "messaging.destination",
+		hostIP:           "net.host.ip",
+		hostPort:         "net.host.port",
+		peerIP:           "net.peer.ip",
+		peerPort:         "net.peer.port",
+	},
+	// v1.17.0 moved the message attributes under messaging.message, the destination under messaging.destination
+	// and the protocol and socket addresses under net
+	semconvVersion117: {
+		protocol:         "net.app.protocol.name",
+		protocolVersion:  "net.app.protocol.version",
+		messageID:        "messaging.message.id",
+		conversationID:   "messaging.message.conversation_id",
+		payloadSizeBytes: "messaging.message.payload_size_bytes",
+		destination:      "messaging.destination.name",
+		hostIP:           "net.sock.host.addr",
+		hostPort:         "net.host.port",
+		peerIP:           "net.sock.peer.addr",
+		peerPort:         "net.sock.peer.port",
+	},
+}
+
+// toAttributeKeys returns the attribute keys of the configured semconv_version, the keys of v1.16.0 unless configured otherwise.
+func toAttributeKeys(semconvVersion string) semconvKeys {
+	if keys, ok := semconvAttributeKeys[semconvVersion]; ok {
+		return keys
+	}
+	return semconvAttributeKeys[semconvVersion116]
+}
@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package solacereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/solacereceiver"
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+var (
+	errInvalidTraceparent = errors.New("traceparent must have the format <version>-<trace-id>-<parent-id>-<trace-flags>")
+	errInvalidTraceID     = errors.New("traceparent has an all zero trace-id")
+	errInvalidParentID    = errors.New("traceparent has an all zero parent-id")
+)
+
+// parseTraceparent returns the trace ID and the parent span ID of a W3C traceparent header.
+// See https://www.w3.org/TR/trace-context/#traceparent-header
+func parseTraceparent(traceparent string) (pcommon.TraceID, pcommon.SpanID, error) {
+	const (
+		version00Length = 55
+		unknownVersion  = "ff"
+	)
+	traceparent = strings.TrimSpace(traceparent)
+	parts := strings.Split(traceparent, "-")
+	if len(parts) < 4 {
+		return pcommon.TraceID{}, pcommon.SpanID{}, errInvalidTraceparent
+	}
+	version := parts[0]
+	if len(version) != 2 || !isLowerHex(version) || version == unknownVersion {
+		return pcommon.TraceID{}, pcommon.SpanID{}, errInvalidTraceparent
+	}
+	// version 00 has exactly four fields, future versions may append more
+	if version == "00" && len(traceparent) != version00Length {
+		return pcommon.TraceID{}, pcommon.SpanID{}, errInvalidTraceparent
+	}
+	if len(parts[3]) != 2 || !isLowerHex(parts[3]) {
+		return pcommon.TraceID{}, pcommon.SpanID{}, errInvalidTraceparent
+	}
+
+	var traceID pcommon.TraceID
+	if len(parts[1]) != 2*len(traceID) || !isLowerHex(parts[1]) {
+		return pcommon.TraceID{}, pcommon.SpanID{}, errInvalidTraceparent
+	}
+	_, _ = hex.Decode(traceID[:], []byte(parts[1]))
+	if traceID.IsEmpty() {
+		return pcommon.TraceID{}, pcommon.SpanID{}, errInvalidTraceID
+	}
+
+	var parentID pcommon.SpanID
+	if len(parts[2]) != 2*len(parentID) || !isLowerHex(parts[2]) {
+		return pcommon.TraceID{}, pcommon.SpanID{}, errInvalidTraceparent
+	}
+	_, _ = hex.Decode(parentID[:], []byte(parts[2]))
+	if parentID.IsEmpty() {
+		return pcommon.TraceID{}, pcommon.SpanID{}, errInvalidParentID
+	}
+	return traceID, parentID, nil
+}
+
+// isLowerHex returns true if s only contains lowercase hexadecimal digits, as required by the traceparent header.
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package solacereceiver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	wantTraceID := pcommon.TraceID([16]byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36})
+	wantParentID := pcommon.SpanID([8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7})
+	for name, traceparent := range map[string]string{
+		"Version 00":     "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"Not Sampled":    "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+		"Surrounded":     " 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01 ",
+		"Future Version": "cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-what-the-future-holds",
+	} {
+		t.Run(name, func(t *testing.T) {
+			traceID, parentID, err := parseTraceparent(traceparent)
+			require.NoError(t, err)
+			assert.Equal(t, wantTraceID, traceID)
+			assert.Equal(t, wantParentID, parentID)
+		})
+	}
+}
+
+func TestParseTraceparentInvalid(t *testing.T) {
+	for name, tc := range map[string]struct {
+		traceparent string
+		want        error
+	}{
+		"Empty":              {"", errInvalidTraceparent},
+		"Missing Fields":     {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", errInvalidTraceparent},
+		"Invalid Version":    {"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", errInvalidTraceparent},
+		"Version 00 Extra":   {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", errInvalidTraceparent},
+		"Uppercase":          {"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", errInvalidTraceparent},
+		"Short Trace ID":     {"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01", errInvalidTraceparent},
+		"Short Parent ID":    {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902-01", errInvalidTraceparent},
+		"Invalid Flags":      {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0x", errInvalidTraceparent},
+		"Zero Trace ID":      {"00-00000000000000000000000000000000-00f067aa0ba902b7-01", errInvalidTraceID},
+		"Zero Parent ID":     {"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", errInvalidParentID},
+		"Not Hex Parent ID":  {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902zz-01", errInvalidTraceparent},
+		"Not Hex Trace ID":   {"00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01", errInvalidTraceparent},
+		"Not Hex Version":    {"0g-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", errInvalidTraceparent},
+		"Long Version Field": {"000-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", errInvalidTraceparent},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := parseTraceparent(tc.traceparent)
+			assert.Equal(t, tc.want, err)
+		})
+	}
+}
@@ -36,19 +36,57 @@ type tracesUnmarshaller interface {
 	unmarshal(message *inboundMessage) (ptrace.Traces, error)
 }
 
-// newUnmarshalleer returns a new unmarshaller ready for message unmarshalling
-func newTracesUnmarshaller(logger *zap.Logger, metrics *opencensusMetrics) tracesUnmarshaller {
+// newUnmarshalleer returns a new unmarshaller ready for message unmarshalling.
+// The spans are built as configured with span_name_from, span_kind, semconv_version, propagate_trace_context,
+// span_status, span_link and redelivery.tag_spans.
+func newTracesUnmarshaller(logger *zap.Logger, metrics *opencensusMetrics, config *Config) tracesUnmarshaller {
 	return &solaceTracesUnmarshaller{
 		logger:  logger,
 		metrics: metrics,
 		// v1 unmarshaller is implemented by solaceMessageUnmarshallerV1
 		v1: &solaceMessageUnmarshallerV1{
-			logger:  logger,
-			metrics: metrics,
+			logger:         logger,
+			metrics:        metrics,
+			spanNameFrom:   config.SpanNameFrom,
+			spanKind:       toSpanKind(config.SpanKind),
+			attributeKeys:  toAttributeKeys(config.SemconvVersion),
+			traceContext:   config.PropagateTraceContext,
+			statusHeader:   config.SpanStatus.Header,
+			statusCodes:    toStatusCodes(config.SpanStatus.Values),
+			linkHeader:     config.SpanLink.CorrelationHeader,
+			tagRedelivered: config.Redelivery.TagSpans,
 		},
 	}
 }
 
+// toStatusCodes returns the span status codes of the header values configured with span_status.values.
+func toStatusCodes(values map[string]string) map[string]ptrace.StatusCode {
+	codes := make(map[string]ptrace.StatusCode, len(values))
+	for value, status := range values {
+		switch status {
+		case spanStatusOk:
+			codes[value] = ptrace.StatusCodeOk
+		case spanStatusError:
+			codes[value] = ptrace.StatusCodeError
+		default:
+			codes[value] = ptrace.StatusCodeUnset
+		}
+	}
+	return codes
+}
+
+// toSpanKind returns the span kind of the configured span_kind, spans are consumer spans unless configured otherwise.
+func toSpanKind(spanKind string) ptrace.SpanKind {
+	switch spanKind {
+	case spanKindServer:
+		return ptrace.SpanKindServer
+	case spanKindInternal:
+		return ptrace.SpanKindInternal
+	default:
+		return ptrace.SpanKindConsumer
+	}
+}
+
 // solaceTracesUnmarshaller implements tracesUnmarshaller.
 type solaceTracesUnmarshaller struct {
 	logger  *zap.Logger
@@ -89,8 +127,21 @@ func (u *solaceTracesUnmarshaller) unmarshal(message *inboundMessage) (ptrace.Tr
 }
 
 type solaceMessageUnmarshallerV1 struct {
-	logger  *zap.Logger
-	metrics *opencensusMetrics
+	logger       *zap.Logger
+	metrics      *opencensusMetrics
+	spanNameFrom string
+	spanKind     ptrace.SpanKind
+	traceContext TraceContextConfig
+	// statusHeader is the user property holding the status of the spans, the status is not mapped if empty
+	statusHeader string
+	// statusCodes are the status codes of the values of the statusHeader
+	statusCodes map[string]ptrace.StatusCode
+	// linkHeader is the user property holding the traceparent of the correlated span, no link is added if empty
+	linkHeader string
+	// attributeKeys are the keys of the attributes named by the configured version of the semantic conventions
+	attributeKeys semconvKeys
+	// tagRedelivered sets the redelivered attribute on the spans of redelivered messages
+	tagRedelivered bool
 }
 
 // unmarshal implements tracesUnmarshaller.unmarshal
@@ -101,6 +152,9 @@ func (u *solaceMessageUnmarshallerV1) unmarshal(message *inboundMessage) (ptrace
 	}
 	traces := ptrace.NewTraces()
 	u.populateTraces(spanData, traces)
+	if u.tagRedelivered && deliveryCount(message) > 0 {
+		u.tagRedeliveredSpans(traces)
+	}
 	return traces, nil
 }
 
@@ -118,6 +172,21 @@ func (u *solaceMessageUnmarshallerV1) unmarshalToSpanData(message *inboundMessag
 	return &spanData, nil
 }
 
+// tagRedeliveredSpans marks the spans of a message that the broker redelivered after a failed delivery.
+func (u *solaceMessageUnmarshallerV1) tagRedeliveredSpans(traces ptrace.Traces) {
+	const redeliveredAttrKey = "messaging.solace.redelivered"
+	resourceSpans := traces.ResourceSpans()
+	for i := 0; i < resourceSpans.Len(); i++ {
+		scopeSpans := resourceSpans.At(i).ScopeSpans()
+		for j := 0; j < scopeSpans.Len(); j++ {
+			spans := scopeSpans.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				spans.At(k).Attributes().PutBool(redeliveredAttrKey, true)
+			}
+		}
+	}
+}
+
 // createSpan will create a new Span from the given traces and map the given SpanData to the span.
 // This will set all required fields such as name version, trace and span ID, parent span ID (if applicable),
 // timestamps, errors and states.
@@ -150,12 +219,8 @@ func (u *solaceMessageUnmarshallerV1) mapResourceSpanAttributes(spanData *model_
 }
 
 func (u *solaceMessageUnmarshallerV1) mapClientSpanData(spanData *model_v1.SpanData, clientSpan ptrace.Span) {
-	const clientSpanName = "(topic) receive"
-
-	// client span constants
-	clientSpan.SetName(clientSpanName)
-	// SPAN_KIND_CONSUMER == 5
-	clientSpan.SetKind(5)
+	clientSpan.SetName(u.clientSpanName(spanData))
+	clientSpan.SetKind(u.spanKind)
 
 	// map trace ID
 	var traceID [16]byte
@@ -179,11 +244,106 @@ func (u *solaceMessageUnmarshallerV1) mapClientSpanData(spanData *model_v1.SpanD
 	if spanData.ErrorDescription != "" {
 		clientSpan.Status().SetCode(ptrace.StatusCodeError)
 		clientSpan.Status().SetMessage(spanData.ErrorDescription)
+	} else if u.statusHeader != "" {
+		u.mapStatusHeader(spanData, clientSpan)
 	}
 	// trace state
 	if spanData.TraceState != nil {
 		clientSpan.TraceState().FromRaw(*spanData.TraceState)
 	}
+	// the trace context propagated by the producer of the traced message takes precedence
+	if u.traceContext.Enabled {
+		u.propagateTraceContext(spanData, clientSpan)
+	}
+	if u.linkHeader != "" {
+		u.linkCorrelatedSpan(spanData, clientSpan)
+	}
+}
+
+// mapStatusHeader sets the status of the span from the status header of the traced message. Spans of messages
+// without the header or with a value that is not mapped keep the unset status.
+func (u *solaceMessageUnmarshallerV1) mapStatusHeader(spanData *model_v1.SpanData, clientSpan ptrace.Span) {
+	value, ok := userPropertyString(spanData, u.statusHeader)
+	if !ok {
+		return
+	}
+	if code, ok := u.statusCodes[value]; ok {
+		clientSpan.Status().SetCode(code)
+	}
+}
+
+// propagateTraceContext continues the trace of the W3C traceparent header of the traced message, the span
+// keeps its own span ID and becomes a child of the span of the producer. The trace state is replaced with the
+// tracestate header, which belongs to the propagated trace. Spans without a traceparent header are left as is.
+func (u *solaceMessageUnmarshallerV1) propagateTraceContext(spanData *model_v1.SpanData, clientSpan ptrace.Span) {
+	header, ok := userPropertyString(spanData, u.traceContext.TraceparentHeader)
+	if !ok {
+		return
+	}
+	traceID, parentSpanID, err := parseTraceparent(header)
+	if err != nil {
+		u.logger.Warn("Received span with an invalid traceparent header", zap.String("traceparent", header), zap.Error(err))
+		u.metrics.recordRecoverableUnmarshallingError()
+		return
+	}
+	clientSpan.SetTraceID(traceID)
+	clientSpan.SetParentSpanID(parentSpanID)
+	traceState, _ := userPropertyString(spanData, u.traceContext.TracestateHeader)
+	clientSpan.TraceState().FromRaw(traceState)
+}
+
+// linkCorrelatedSpan adds a span link to the span of the W3C traceparent in the correlation header of the traced message.
+// The correlated span belongs to another trace, so the span keeps its trace and parent. Spans of messages without
+// the header are left as is.
+func (u *solaceMessageUnmarshallerV1) linkCorrelatedSpan(spanData *model_v1.SpanData, clientSpan ptrace.Span) {
+	header, ok := userPropertyString(spanData, u.linkHeader)
+	if !ok {
+		return
+	}
+	traceID, spanID, err := parseTraceparent(header)
+	if err != nil {
+		u.logger.Warn("Received span with an invalid correlation header", zap.String("correlation", header), zap.Error(err))
+		u.metrics.recordRecoverableUnmarshallingError()
+		return
+	}
+	link := clientSpan.Links().AppendEmpty()
+	link.SetTraceID(traceID)
+	link.SetSpanID(spanID)
+}
+
+// clientSpanName returns the name of the client span from the configured source. It falls back to
+// the constant name if the source is not available on the span data.
+func (u *solaceMessageUnmarshallerV1) clientSpanName(spanData *model_v1.SpanData) string {
+	const (
+		clientSpanName   = "(topic) receive"
+		clientSpanSuffix = " receive" // Final should be `<topic> receive`
+	)
+	switch {
+	case u.spanNameFrom == spanNameFromTopic:
+		if spanData.Topic != "" {
+			return spanData.Topic + clientSpanSuffix
+		}
+	case strings.HasPrefix(u.spanNameFrom, spanNameFromHeaderPrefix):
+		if name, ok := userPropertyString(spanData, strings.TrimPrefix(u.spanNameFrom, spanNameFromHeaderPrefix)); ok {
+			return name
+		}
+	}
+	return clientSpanName
+}
+
+// userPropertyString returns the value of the user property with the given name if it is a non-empty string or destination.
+func userPropertyString(spanData *model_v1.SpanData, name string) (string, bool) {
+	property := spanData.UserProperties[name]
+	if property == nil {
+		return "", false
+	}
+	switch v := property.Value.(type) {
+	case *model_v1.SpanData_UserPropertyValue_StringValue:
+		return v.StringValue, v.StringValue != ""
+	case *model_v1.SpanData_UserPropertyValue_DestinationValue:
+		return v.DestinationValue, v.DestinationValue != ""
+	}
+	return "", false
 }
 
 // mapAttributes takes a set of attributes from SpanData and maps them to ClientSpan.Attributes().
@@ -200,12 +360,6 @@ func (u *solaceMessageUnmarshallerV1) mapClientSpanAttributes(spanData *model_v1
 	attrMap.PutStr(operationAttrKey, operationAttrValue)
 	// attributes from spanData
 	const (
-		protocolAttrKey                    = "messaging.protocol"
-		protocolVersionAttrKey             = "messaging.protocol_version"
-		messageIDAttrKey                   = "messaging.message_id"
-		conversationIDAttrKey              = "messaging.conversation_id"
-		payloadSizeBytesAttrKey            = "messaging.message_payload_size_bytes"
-		destinationAttrKey                 = "messaging.destination"
 		clientUsernameAttrKey              = "messaging.solace.client_username"
 		clientNameAttrKey                  = "messaging.solace.client_name"
 		replicationGroupMessageIDAttrKey   = "messaging.solace.replication_group_message_id"
@@ -218,26 +372,22 @@ func (u *solaceMessageUnmarshallerV1) mapClientSpanAttributes(spanData *model_v1
 		receiveTimeAttrKey                 = "messaging.solace.broker_receive_time_unix_nano"
 		droppedUserPropertiesAttrKey       = "messaging.solace.dropped_application_message_properties"
 		deliveryModeAttrKey                = "messaging.solace.delivery_mode"
-		hostIPAttrKey                      = "net.host.ip"
-		hostPortAttrKey                    = "net.host.port"
-		peerIPAttrKey                      = "net.peer.ip"
-		peerPortAttrKey                    = "net.peer.port"
 	)
-	attrMap.PutStr(protocolAttrKey, spanData.Protocol)
+	attrMap.PutStr(u.attributeKeys.protocol, spanData.Protocol)
 	if spanData.ProtocolVersion != nil {
-		attrMap.PutStr(protocolVersionAttrKey, *spanData.ProtocolVersion)
+		attrMap.PutStr(u.attributeKeys.protocolVersion, *spanData.ProtocolVersion)
 	}
 	if spanData.ApplicationMessageId != nil {
-		attrMap.PutStr(messageIDAttrKey, *spanData.ApplicationMessageId)
+		attrMap.PutStr(u.attributeKeys.messageID, *spanData.ApplicationMessageId)
 	}
 	if spanData.CorrelationId != nil {
-		attrMap.PutStr(conversationIDAttrKey, *spanData.CorrelationId)
+		attrMap.PutStr(u.attributeKeys.conversationID, *spanData.CorrelationId)
 	}
-	attrMap.PutInt(payloadSizeBytesAttrKey, int64(spanData.BinaryAttachmentSize+spanData.XmlAttachmentSize+spanData.MetadataSize))
+	attrMap.PutInt(u.attributeKeys.payloadSizeBytes, int64(spanData.BinaryAttachmentSize+spanData.XmlAttachmentSize+spanData.MetadataSize))
 	attrMap.PutStr(clientUsernameAttrKey, spanData.ClientUsername)
 	attrMap.PutStr(clientNameAttrKey, spanData.ClientName)
 	attrMap.PutInt(receiveTimeAttrKey, spanData.BrokerReceiveTimeUnixNano)
-	attrMap.PutStr(destinationAttrKey, spanData.Topic)
+	attrMap.PutStr(u.attributeKeys.destination, spanData.Topic)
 
 	var deliveryMode string
 	switch spanData.DeliveryMode {
@@ -274,21 +424,21 @@ func (u *solaceMessageUnmarshallerV1) mapClientSpanAttributes(spanData *model_v1
 
 	hostIPLen := len(spanData.HostIp)
 	if hostIPLen == 4 || hostIPLen == 16 {
-		attrMap.PutStr(hostIPAttrKey, net.IP(spanData.HostIp).String())
+		attrMap.PutStr(u.attributeKeys.hostIP, net.IP(spanData.HostIp).String())
 	} else {
 		u.logger.Warn("Host ip attribute has an illegal length", zap.Int("length", hostIPLen))
 		u.metrics.recordRecoverableUnmarshallingError()
 	}
-	attrMap.PutInt(hostPortAttrKey, int64(spanData.HostPort))
+	attrMap.PutInt(u.attributeKeys.hostPort, int64(spanData.HostPort))
 
 	peerIPLen := len(spanData.HostIp)
 	if peerIPLen == 4 || peerIPLen == 16 {
-		attrMap.PutStr(peerIPAttrKey, net.IP(spanData.PeerIp).String())
+		attrMap.PutStr(u.attributeKeys.peerIP, net.IP(spanData.PeerIp).String())
 	} else {
 		u.logger.Warn("Peer ip attribute has an illegal length", zap.Int("length", peerIPLen))
 		u.metrics.recordRecoverableUnmarshallingError()
 	}
-	attrMap.PutInt(peerPortAttrKey, int64(spanData.PeerPort))
+	attrMap.PutInt(u.attributeKeys.peerPort, int64(spanData.PeerPort))
 
 	attrMap.PutBool(droppedUserPropertiesAttrKey, spanData.DroppedApplicationMessageProperties)
 	for key, value := range spanData.UserProperties {
@@ -247,7 +247,7 @@ func TestSolaceMessageUnmarshallerUnmarshal(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			u := newTracesUnmarshaller(zap.NewNop(), newTestMetrics(t))
+			u := newTracesUnmarshaller(zap.NewNop(), newTestMetrics(t), &Config{SpanNameFrom: spanNameFromPayload, SpanKind: spanKindConsumer, SemconvVersion: semconvVersion116})
 			traces, err := u.unmarshal(tt.message)
 			if tt.err != nil {
 				require.Error(t, err)
@@ -390,6 +390,340 @@ func TestUnmarshallerMapClientSpanData(t *testing.T) {
 	}
 }
 
+func TestUnmarshallerClientSpanName(t *testing.T) {
+	spanData := &model_v1.SpanData{
+		Topic: "orders/created",
+		UserProperties: map[string]*model_v1.SpanData_UserPropertyValue{
+			"operation": {
+				Value: &model_v1.SpanData_UserPropertyValue_StringValue{StringValue: "create order"},
+			},
+			"reply": {
+				Value: &model_v1.SpanData_UserPropertyValue_DestinationValue{DestinationValue: "orders/replies"},
+			},
+			"count": {
+				Value: &model_v1.SpanData_UserPropertyValue_Int32Value{Int32Value: 1},
+			},
+		},
+	}
+	tests := []struct {
+		name         string
+		spanNameFrom string
+		data         *model_v1.SpanData
+		want         string
+	}{
+		{
+			name:         "Payload",
+			spanNameFrom: spanNameFromPayload,
+			data:         spanData,
+			want:         "(topic) receive",
+		},
+		{
+			name:         "Topic",
+			spanNameFrom: spanNameFromTopic,
+			data:         spanData,
+			want:         "orders/created receive",
+		},
+		{
+			name:         "Topic Missing",
+			spanNameFrom: spanNameFromTopic,
+			data:         &model_v1.SpanData{},
+			want:         "(topic) receive",
+		},
+		{
+			name:         "Header",
+			spanNameFrom: "header:operation",
+			data:         spanData,
+			want:         "create order",
+		},
+		{
+			name:         "Header Destination",
+			spanNameFrom: "header:reply",
+			data:         spanData,
+			want:         "orders/replies",
+		},
+		{
+			name:         "Header Not A String",
+			spanNameFrom: "header:count",
+			data:         spanData,
+			want:         "(topic) receive",
+		},
+		{
+			name:         "Header Missing",
+			spanNameFrom: "header:missing",
+			data:         spanData,
+			want:         "(topic) receive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTestV1Unmarshaller(t)
+			u.spanNameFrom = tt.spanNameFrom
+			actual := ptrace.NewTraces().ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+			u.mapClientSpanData(tt.data, actual)
+			assert.Equal(t, tt.want, actual.Name())
+		})
+	}
+}
+
+func TestUnmarshallerSpanKind(t *testing.T) {
+	tests := []struct {
+		spanKind string
+		want     ptrace.SpanKind
+	}{
+		{spanKind: spanKindConsumer, want: ptrace.SpanKindConsumer},
+		{spanKind: spanKindServer, want: ptrace.SpanKindServer},
+		{spanKind: spanKindInternal, want: ptrace.SpanKindInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spanKind, func(t *testing.T) {
+			u := newTracesUnmarshaller(zap.NewNop(), newTestMetrics(t), &Config{SpanNameFrom: spanNameFromPayload, SpanKind: tt.spanKind, SemconvVersion: semconvVersion116})
+			actual := ptrace.NewTraces().ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+			u.(*solaceTracesUnmarshaller).v1.(*solaceMessageUnmarshallerV1).mapClientSpanData(&model_v1.SpanData{}, actual)
+			assert.Equal(t, tt.want, actual.Kind())
+		})
+	}
+}
+
+func TestUnmarshallerDefaultSpanKind(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	u := newTracesUnmarshaller(zap.NewNop(), newTestMetrics(t), cfg)
+	actual := ptrace.NewTraces().ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	u.(*solaceTracesUnmarshaller).v1.(*solaceMessageUnmarshallerV1).mapClientSpanData(&model_v1.SpanData{}, actual)
+	// spans are consumer spans following the messaging semantic conventions
+	assert.Equal(t, ptrace.SpanKindConsumer, actual.Kind())
+}
+
+func TestUnmarshallerSpanStatus(t *testing.T) {
+	spanStatus := SpanStatusConfig{
+		Header: "outcome",
+		Values: map[string]string{"success": spanStatusOk, "failure": spanStatusError, "pending": spanStatusUnset},
+	}
+	newSpanData := func(outcome string, errorDescription string) *model_v1.SpanData {
+		spanData := &model_v1.SpanData{ErrorDescription: errorDescription}
+		if outcome != "" {
+			spanData.UserProperties = map[string]*model_v1.SpanData_UserPropertyValue{
+				"outcome": {Value: &model_v1.SpanData_UserPropertyValue_StringValue{StringValue: outcome}},
+			}
+		}
+		return spanData
+	}
+	tests := []struct {
+		name        string
+		spanStatus  SpanStatusConfig
+		spanData    *model_v1.SpanData
+		wantCode    ptrace.StatusCode
+		wantMessage string
+	}{
+		{name: "Success", spanStatus: spanStatus, spanData: newSpanData("success", ""), wantCode: ptrace.StatusCodeOk},
+		{name: "Failure", spanStatus: spanStatus, spanData: newSpanData("failure", ""), wantCode: ptrace.StatusCodeError},
+		{name: "Unset", spanStatus: spanStatus, spanData: newSpanData("pending", ""), wantCode: ptrace.StatusCodeUnset},
+		{name: "Unmapped Value", spanStatus: spanStatus, spanData: newSpanData("unknown", ""), wantCode: ptrace.StatusCodeUnset},
+		{name: "Missing Header", spanStatus: spanStatus, spanData: newSpanData("", ""), wantCode: ptrace.StatusCodeUnset},
+		{name: "Broker Error Kept", spanStatus: spanStatus, spanData: newSpanData("success", "queue full"), wantCode: ptrace.StatusCodeError, wantMessage: "queue full"},
+		{name: "Not Configured", spanData: newSpanData("failure", ""), wantCode: ptrace.StatusCodeUnset},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTracesUnmarshaller(zap.NewNop(), newTestMetrics(t), &Config{SpanNameFrom: spanNameFromPayload, SpanKind: spanKindConsumer, SemconvVersion: semconvVersion116, SpanStatus: tt.spanStatus})
+			actual := ptrace.NewTraces().ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+			u.(*solaceTracesUnmarshaller).v1.(*solaceMessageUnmarshallerV1).mapClientSpanData(tt.spanData, actual)
+			assert.Equal(t, tt.wantCode, actual.Status().Code())
+			assert.Equal(t, tt.wantMessage, actual.Status().Message())
+		})
+	}
+}
+
+func TestUnmarshallerTagRedelivered(t *testing.T) {
+	topic := "_telemetry/broker/trace/receive/v1"
+	data, err := proto.Marshal(&model_v1.SpanData{
+		TraceId: []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		SpanId:  []byte{7, 6, 5, 4, 3, 2, 1, 0},
+	})
+	require.NoError(t, err)
+	tests := []struct {
+		name           string
+		tagRedelivered bool
+		header         *amqp.MessageHeader
+		want           bool
+	}{
+		{name: "Redelivered", tagRedelivered: true, header: &amqp.MessageHeader{DeliveryCount: 2}, want: true},
+		{name: "First Delivery", tagRedelivered: true, header: &amqp.MessageHeader{}},
+		{name: "No Header", tagRedelivered: true},
+		{name: "Tagging Disabled", header: &amqp.MessageHeader{DeliveryCount: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTracesUnmarshaller(zap.NewNop(), newTestMetrics(t), &Config{SpanNameFrom: spanNameFromPayload, SpanKind: spanKindConsumer, SemconvVersion: semconvVersion116, Redelivery: RedeliveryConfig{TagSpans: tt.tagRedelivered}})
+			traces, err := u.unmarshal(&inboundMessage{
+				Data:       [][]byte{data},
+				Header:     tt.header,
+				Properties: &amqp.MessageProperties{To: &topic},
+			})
+			require.NoError(t, err)
+			redelivered, ok := traces.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Attributes().Get("messaging.solace.redelivered")
+			assert.Equal(t, tt.want, ok)
+			if tt.want {
+				assert.True(t, redelivered.Bool())
+			}
+		})
+	}
+}
+
+func TestUnmarshallerPropagateTraceContext(t *testing.T) {
+	payloadTraceID := [16]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	payloadSpanID := [8]byte{7, 6, 5, 4, 3, 2, 1, 0}
+	traceState := "broker=1"
+	newSpanData := func(userProperties map[string]string) *model_v1.SpanData {
+		spanData := &model_v1.SpanData{
+			TraceId:        payloadTraceID[:],
+			SpanId:         payloadSpanID[:],
+			TraceState:     &traceState,
+			UserProperties: map[string]*model_v1.SpanData_UserPropertyValue{},
+		}
+		for key, value := range userProperties {
+			spanData.UserProperties[key] = &model_v1.SpanData_UserPropertyValue{
+				Value: &model_v1.SpanData_UserPropertyValue_StringValue{StringValue: value},
+			}
+		}
+		return spanData
+	}
+	producerTraceID := [16]byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	producerSpanID := [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	tests := []struct {
+		name                        string
+		traceContext                TraceContextConfig
+		spanData                    *model_v1.SpanData
+		wantTraceID                 pcommon.TraceID
+		wantParentSpanID            pcommon.SpanID
+		wantTraceState              string
+		expectedUnmarshallingErrors interface{}
+	}{
+		{
+			name:             "With Traceparent",
+			traceContext:     TraceContextConfig{Enabled: true, TraceparentHeader: "traceparent", TracestateHeader: "tracestate"},
+			spanData:         newSpanData(map[string]string{"traceparent": traceparent, "tracestate": "producer=abc"}),
+			wantTraceID:      producerTraceID,
+			wantParentSpanID: producerSpanID,
+			wantTraceState:   "producer=abc",
+		},
+		{
+			name:             "With Custom Header Without Tracestate",
+			traceContext:     TraceContextConfig{Enabled: true, TraceparentHeader: "x-trace", TracestateHeader: "tracestate"},
+			spanData:         newSpanData(map[string]string{"x-trace": traceparent}),
+			wantTraceID:      producerTraceID,
+			wantParentSpanID: producerSpanID,
+		},
+		{
+			name:           "Without Traceparent",
+			traceContext:   TraceContextConfig{Enabled: true, TraceparentHeader: "traceparent", TracestateHeader: "tracestate"},
+			spanData:       newSpanData(map[string]string{"tracestate": "producer=abc"}),
+			wantTraceID:    payloadTraceID,
+			wantTraceState: traceState,
+		},
+		{
+			name:                        "With Invalid Traceparent",
+			traceContext:                TraceContextConfig{Enabled: true, TraceparentHeader: "traceparent", TracestateHeader: "tracestate"},
+			spanData:                    newSpanData(map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"}),
+			wantTraceID:                 payloadTraceID,
+			wantTraceState:              traceState,
+			expectedUnmarshallingErrors: 1,
+		},
+		{
+			name:           "Disabled",
+			traceContext:   TraceContextConfig{TraceparentHeader: "traceparent", TracestateHeader: "tracestate"},
+			spanData:       newSpanData(map[string]string{"traceparent": traceparent}),
+			wantTraceID:    payloadTraceID,
+			wantTraceState: traceState,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTestV1Unmarshaller(t)
+			u.traceContext = tt.traceContext
+			actual := ptrace.NewTraces().ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+			u.mapClientSpanData(tt.spanData, actual)
+			assert.Equal(t, tt.wantTraceID, actual.TraceID())
+			assert.Equal(t, tt.wantParentSpanID, actual.ParentSpanID())
+			// the span keeps the span ID of the broker
+			assert.Equal(t, pcommon.SpanID(payloadSpanID), actual.SpanID())
+			assert.Equal(t, tt.wantTraceState, actual.TraceState().AsRaw())
+			validateMetric(t, u.metrics.views.recoverableUnmarshallingErrors, tt.expectedUnmarshallingErrors)
+		})
+	}
+}
+
+func TestUnmarshallerSpanLink(t *testing.T) {
+	payloadTraceID := [16]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	payloadSpanID := [8]byte{7, 6, 5, 4, 3, 2, 1, 0}
+	newSpanData := func(userProperties map[string]string) *model_v1.SpanData {
+		spanData := &model_v1.SpanData{
+			TraceId:        payloadTraceID[:],
+			SpanId:         payloadSpanID[:],
+			UserProperties: map[string]*model_v1.SpanData_UserPropertyValue{},
+		}
+		for key, value := range userProperties {
+			spanData.UserProperties[key] = &model_v1.SpanData_UserPropertyValue{
+				Value: &model_v1.SpanData_UserPropertyValue_StringValue{StringValue: value},
+			}
+		}
+		return spanData
+	}
+	correlatedTraceID := [16]byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	correlatedSpanID := [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+	const correlation = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	tests := []struct {
+		name                        string
+		linkHeader                  string
+		spanData                    *model_v1.SpanData
+		wantLink                    bool
+		expectedUnmarshallingErrors interface{}
+	}{
+		{
+			name:       "With Correlation",
+			linkHeader: "correlation",
+			spanData:   newSpanData(map[string]string{"correlation": correlation}),
+			wantLink:   true,
+		},
+		{
+			name:       "Without Correlation",
+			linkHeader: "correlation",
+			spanData:   newSpanData(map[string]string{"traceparent": correlation}),
+		},
+		{
+			name:                        "With Invalid Correlation",
+			linkHeader:                  "correlation",
+			spanData:                    newSpanData(map[string]string{"correlation": "order-1234"}),
+			expectedUnmarshallingErrors: 1,
+		},
+		{
+			name:     "Not Configured",
+			spanData: newSpanData(map[string]string{"correlation": correlation}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTestV1Unmarshaller(t)
+			u.linkHeader = tt.linkHeader
+			actual := ptrace.NewTraces().ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+			u.mapClientSpanData(tt.spanData, actual)
+			// the correlated span is linked, the span keeps the trace of the broker
+			assert.Equal(t, pcommon.TraceID(payloadTraceID), actual.TraceID())
+			assert.True(t, actual.ParentSpanID().IsEmpty())
+			if tt.wantLink {
+				require.Equal(t, 1, actual.Links().Len())
+				assert.Equal(t, pcommon.TraceID(correlatedTraceID), actual.Links().At(0).TraceID())
+				assert.Equal(t, pcommon.SpanID(correlatedSpanID), actual.Links().At(0).SpanID())
+			} else {
+				assert.Equal(t, 0, actual.Links().Len())
+			}
+			validateMetric(t, u.metrics.views.recoverableUnmarshallingErrors, tt.expectedUnmarshallingErrors)
+		})
+	}
+}
+
 func TestUnmarshallerMapClientSpanAttributes(t *testing.T) {
 	var (
 		protocolVersion      = "5.0"
@@ -566,6 +900,84 @@ func TestUnmarshallerMapClientSpanAttributes(t *testing.T) {
 	}
 }
 
+func TestUnmarshallerMapClientSpanAttributesSemconvVersion(t *testing.T) {
+	var (
+		protocolVersion      = "5.0"
+		applicationMessageID = "someMessageID"
+		correlationID        = "someConversationID"
+	)
+	spanData := &model_v1.SpanData{
+		Protocol:             "MQTT",
+		ProtocolVersion:      &protocolVersion,
+		ApplicationMessageId: &applicationMessageID,
+		CorrelationId:        &correlationID,
+		BinaryAttachmentSize: 1000,
+		ClientUsername:       "someClientUsername",
+		DeliveryMode:         model_v1.SpanData_DIRECT,
+		Topic:                "someTopic",
+		HostIp:               []byte{1, 2, 3, 4},
+		HostPort:             55555,
+		PeerIp:               []byte{5, 6, 7, 8},
+		PeerPort:             12345,
+	}
+	tests := []struct {
+		semconvVersion string
+		want           map[string]interface{}
+		absent         []string
+	}{
+		{
+			semconvVersion: semconvVersion116,
+			want: map[string]interface{}{
+				"messaging.protocol":                   "MQTT",
+				"messaging.protocol_version":           "5.0",
+				"messaging.message_id":                 "someMessageID",
+				"messaging.conversation_id":            "someConversationID",
+				"messaging.message_payload_size_bytes": int64(1000),
+				"messaging.destination":                "someTopic",
+				"net.host.ip":                          "1.2.3.4",
+				"net.host.port":                        int64(55555),
+				"net.peer.ip":                          "5.6.7.8",
+				"net.peer.port":                        int64(12345),
+			},
+			absent: []string{"messaging.message.id", "messaging.destination.name", "net.sock.peer.addr"},
+		},
+		{
+			semconvVersion: semconvVersion117,
+			want: map[string]interface{}{
+				"net.app.protocol.name":                "MQTT",
+				"net.app.protocol.version":             "5.0",
+				"messaging.message.id":                 "someMessageID",
+				"messaging.message.conversation_id":    "someConversationID",
+				"messaging.message.payload_size_bytes": int64(1000),
+				"messaging.destination.name":           "someTopic",
+				"net.sock.host.addr":                   "1.2.3.4",
+				"net.host.port":                        int64(55555),
+				"net.sock.peer.addr":                   "5.6.7.8",
+				"net.sock.peer.port":                   int64(12345),
+			},
+			absent: []string{"messaging.protocol", "messaging.message_id", "messaging.destination", "net.host.ip", "net.peer.ip", "net.peer.port"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.semconvVersion, func(t *testing.T) {
+			u := newTracesUnmarshaller(zap.NewNop(), newTestMetrics(t), &Config{SpanNameFrom: spanNameFromPayload, SpanKind: spanKindConsumer, SemconvVersion: tt.semconvVersion})
+			actual := pcommon.NewMap()
+			u.(*solaceTracesUnmarshaller).v1.(*solaceMessageUnmarshallerV1).mapClientSpanAttributes(spanData, actual)
+			raw := actual.AsRaw()
+			for key, value := range tt.want {
+				assert.Equal(t, value, raw[key], key)
+			}
+			for _, key := range tt.absent {
+				assert.NotContains(t, raw, key)
+			}
+			// the attributes specific to Solace are named the same in every version
+			assert.Equal(t, "someClientUsername", raw["messaging.solace.client_username"])
+			assert.Equal(t, "SolacePubSub+", raw["messaging.system"])
+			assert.Equal(t, "receive", raw["messaging.operation"])
+		})
+	}
+}
+
 // Validate that all event types are properly handled and appended into the span data
 func TestUnmarshallerEvents(t *testing.T) {
 	someErrorString := "some error"
@@ -1061,5 +1473,5 @@ func TestSolaceMessageUnmarshallerV1InsertUserPropertyUnsupportedType(t *testing
 
 func newTestV1Unmarshaller(t *testing.T) *solaceMessageUnmarshallerV1 {
 	m := newTestMetrics(t)
-	return &solaceMessageUnmarshallerV1{zap.NewNop(), m}
+	return &solaceMessageUnmarshallerV1{zap.NewNop(), m, spanNameFromPayload, ptrace.SpanKindConsumer, TraceContextConfig{}, "", nil, "", toAttributeKeys(semconvVersion116), false}
 }